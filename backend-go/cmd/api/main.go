@@ -11,8 +11,11 @@ import (
 
 	"wizardmatch-backend/internal/config"
 	"wizardmatch-backend/internal/db"
-	"wizardmatch-backend/internal/handler"
 	internalhttp "wizardmatch-backend/internal/http"
+	"wizardmatch-backend/internal/jobs"
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
 func main() {
@@ -28,7 +31,18 @@ func main() {
 	}
 	defer database.Close()
 
-	handler.SetDependencies(database)
+	store := repository.New(database.Pool)
+	hub := realtime.NewHub()
+	events := realtime.NewRecorder(store, realtime.NewNotifier(database.Pool))
+	listenCtx, stopListening := context.WithCancel(ctx)
+	go realtime.NewListener(database.Pool.Config().ConnConfig, hub).Run(listenCtx)
+	go service.NewEventWatcher(store, events).Run(listenCtx, time.Minute)
+
+	runner := jobs.NewRunner(store, jobs.Options{Workers: cfg.JobWorkers})
+	matcher := service.NewMatchingService(store, database.Pool).WithEvents(events)
+	runner.Register(service.JobGenerateMatches, matcher.RunGenerateMatchesJob)
+	runner.Register(service.JobMatchNewUsers, matcher.RunMatchNewUsersJob)
+	runner.Start(ctx)
 
 	router := internalhttp.NewRouter(internalhttp.RouterOptions{
 		FrontendURL:        cfg.FrontendURL,
@@ -37,6 +51,10 @@ func main() {
 		GoogleClientID:     cfg.GoogleClientID,
 		GoogleClientSecret: cfg.GoogleClientSecret,
 		GoogleRedirectURL:  cfg.GoogleRedirectURL,
+		Store:              store,
+		Matcher:            matcher,
+		Hub:                hub,
+		Events:             events,
 	})
 
 	server := &http.Server{
@@ -58,7 +76,14 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Shutdown does not wait for hijacked WebSocket connections, and would
+	// wait forever for event streams, so end both through the hub.
+	stopListening()
+	hub.Close()
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Printf("shutdown failed: %v", err)
 	}
+	if err := runner.Shutdown(shutdownCtx); err != nil {
+		log.Printf("job runner shutdown failed: %v", err)
+	}
 }
@@ -14,6 +14,7 @@ import (
 	"wizardmatch-backend/internal/config"
 	"wizardmatch-backend/internal/db"
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
 type seedQuestion struct {
@@ -59,7 +60,7 @@ func main() {
 	fmt.Println("Seed complete")
 }
 
-func ensureActiveCampaign(ctx context.Context, store *repository.Queries) (repository.Campaign, error) {
+func ensureActiveCampaign(ctx context.Context, store repository.Querier) (repository.Campaign, error) {
 	existing, err := store.GetActiveCampaign(ctx)
 	if err == nil && existing.ID != uuid.Nil {
 		return existing, nil
@@ -89,7 +90,7 @@ func ensureActiveCampaign(ctx context.Context, store *repository.Queries) (repos
 	return campaign, nil
 }
 
-func seedQuestions(ctx context.Context, store *repository.Queries, campaignID uuid.UUID) error {
+func seedQuestions(ctx context.Context, store repository.Querier, campaignID uuid.UUID) error {
 	questionsExisting, err := store.ListQuestions(ctx)
 	if err == nil && len(questionsExisting) > 0 {
 		return nil
@@ -263,7 +264,7 @@ func seedQuestions(ctx context.Context, store *repository.Queries, campaignID uu
 	return nil
 }
 
-func seedUsers(ctx context.Context, store *repository.Queries) error {
+func seedUsers(ctx context.Context, store repository.Querier) error {
 	users := []struct {
 		Email     string
 		FirstName string
@@ -285,6 +286,15 @@ func seedUsers(ctx context.Context, store *repository.Queries) error {
 			continue
 		}
 
+		genders, err := service.NormalizeGenders(u.Gender)
+		if err != nil {
+			return fmt.Errorf("seed user %s: %w", u.Email, err)
+		}
+		seeking, err := service.NormalizeSeeking(u.Seeking)
+		if err != nil {
+			return fmt.Errorf("seed user %s: %w", u.Email, err)
+		}
+
 		_, err = store.CreateUser(ctx, repository.CreateUserParams{
 			Email:             u.Email,
 			GoogleID:          pgtype.Text{Valid: false},
@@ -309,6 +319,8 @@ func seedUsers(ctx context.Context, store *repository.Queries) error {
 			LastLogin:         pgtype.Timestamptz{Valid: false},
 			IsActive:          true,
 			SurveyCompleted:   false,
+			Genders:           genders,
+			SeekingGenders:    seeking,
 		})
 		if err != nil {
 			return fmt.Errorf("seed user %s: %w", u.Email, err)
@@ -317,7 +329,7 @@ func seedUsers(ctx context.Context, store *repository.Queries) error {
 	return nil
 }
 
-func seedResponses(ctx context.Context, store *repository.Queries, campaignID uuid.UUID) error {
+func seedResponses(ctx context.Context, store repository.Querier, campaignID uuid.UUID) error {
 	questions, err := store.ListQuestions(ctx)
 	if err != nil {
 		return err
@@ -351,6 +363,7 @@ func seedResponses(ctx context.Context, store *repository.Queries, campaignID uu
 				CampaignID: pgtype.UUID{Bytes: campaignID, Valid: true},
 				QuestionID: q.ID,
 				AnswerType: q.QuestionType,
+				Importance: service.ImportanceSomewhat,
 			}
 
 			switch q.QuestionType {
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"wizardmatch-backend/internal/repository/memory"
+	"wizardmatch-backend/internal/service"
+)
+
+// report is what a simulation run measures. Coverage is the share of users
+// with at least one match; blocking pairs are unmatched candidates who both
+// prefer each other to their weakest match or still have room for one.
+type report struct {
+	Users              int                    `json:"users"`
+	Crushes            int                    `json:"crushes"`
+	CandidatePairs     int                    `json:"candidatePairs"`
+	Matches            int                    `json:"matches"`
+	Coverage           float64                `json:"coverage"`
+	MeanScore          float64                `json:"meanScore"`
+	MutualCrushMatches int                    `json:"mutualCrushMatches"`
+	BlockingPairs      int                    `json:"blockingPairs"`
+	TierDistribution   map[string]int         `json:"tierDistribution"`
+	Fairness           service.FairnessReport `json:"fairness"`
+	Config             service.MatchingConfig `json:"config"`
+	DurationSeconds    float64                `json:"durationSeconds"`
+}
+
+func main() {
+	opts := defaultPopulationOptions()
+	flag.IntVar(&opts.Users, "users", opts.Users, "number of users to generate")
+	flag.Float64Var(&opts.WomanShare, "women", opts.WomanShare, "share of users who are women")
+	flag.Float64Var(&opts.NonbinaryShare, "nonbinary", opts.NonbinaryShare, "share of users who are nonbinary; the rest are men")
+	flag.Float64Var(&opts.SeekingSame, "seeking-same", opts.SeekingSame, "share of users seeking their own gender")
+	flag.Float64Var(&opts.SeekingAny, "seeking-any", opts.SeekingAny, "share of users seeking every gender; the rest seek the opposite one")
+	flag.IntVar(&opts.Clusters, "clusters", opts.Clusters, "number of latent user types answers are drawn from")
+	flag.Float64Var(&opts.Correlation, "correlation", opts.Correlation, "chance an answer follows the user's type instead of being random")
+	flag.Float64Var(&opts.CrushRate, "crush-rate", opts.CrushRate, "share of users naming a crush")
+	flag.Float64Var(&opts.MutualRate, "mutual-rate", opts.MutualRate, "chance a crush names the user back")
+	flag.Int64Var(&opts.Seed, "seed", opts.Seed, "random seed for the population")
+	configPath := flag.String("config", "", "campaign config JSON to match with (default: the default matching config)")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if err := opts.validate(); err != nil {
+		log.Fatalf("invalid options: %v", err)
+	}
+	config := []byte("{}")
+	if *configPath != "" {
+		raw, err := os.ReadFile(*configPath)
+		if err != nil {
+			log.Fatalf("failed to read config: %v", err)
+		}
+		config = raw
+	}
+
+	result, err := simulate(context.Background(), config, opts)
+	if err != nil {
+		log.Fatalf("simulation failed: %v", err)
+	}
+	if *asJSON {
+		encoded, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to encode report: %v", err)
+		}
+		fmt.Println(string(encoded))
+		return
+	}
+	printReport(result)
+}
+
+// simulate generates a population in a fresh in-memory store and runs the
+// matching pipeline on it without writing any matches.
+func simulate(ctx context.Context, config []byte, opts populationOptions) (*report, error) {
+	store := memory.New()
+	pop, err := generatePopulation(ctx, store, config, opts)
+	if err != nil {
+		return nil, fmt.Errorf("generate population: %w", err)
+	}
+
+	started := time.Now()
+	preview, err := service.NewMatchingService(store, nil).PreviewMatches(ctx, pop.campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report{
+		Users:              preview.TotalUsers,
+		Crushes:            pop.crushes,
+		CandidatePairs:     preview.CandidatePairs,
+		Matches:            preview.Matches,
+		MeanScore:          preview.AverageScore,
+		MutualCrushMatches: preview.MutualCrushMatches,
+		BlockingPairs:      preview.BlockingPairs,
+		TierDistribution:   preview.TierDistribution,
+		Fairness:           preview.Fairness,
+		Config:             preview.Config,
+		DurationSeconds:    time.Since(started).Seconds(),
+	}
+	if preview.TotalUsers > 0 {
+		result.Coverage = float64(preview.TotalUsers-len(preview.UnmatchedUsers)) / float64(preview.TotalUsers)
+	}
+	return result, nil
+}
+
+func printReport(r *report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Strategy\t%s\n", r.Config.Strategy)
+	fmt.Fprintf(w, "Users\t%d\n", r.Users)
+	fmt.Fprintf(w, "Crushes\t%d\n", r.Crushes)
+	fmt.Fprintf(w, "Candidate pairs\t%d\n", r.CandidatePairs)
+	fmt.Fprintf(w, "Matches\t%d\n", r.Matches)
+	fmt.Fprintf(w, "Coverage\t%.1f%%\n", r.Coverage*100)
+	fmt.Fprintf(w, "Mean score\t%.2f\n", r.MeanScore)
+	fmt.Fprintf(w, "Mutual crush matches\t%d\n", r.MutualCrushMatches)
+	fmt.Fprintf(w, "Blocking pairs\t%d\n", r.BlockingPairs)
+	fmt.Fprintf(w, "Duration\t%.2fs\n", r.DurationSeconds)
+	w.Flush()
+
+	fmt.Println("\nFairness")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Gini\t%.4f\n", r.Fairness.Gini)
+	fmt.Fprintf(w, "Matches per user\t%d-%d\n", r.Fairness.MinMatches, r.Fairness.MaxMatches)
+	fmt.Fprintf(w, "Unmatched users\t%d\n", r.Fairness.UnmatchedUsers)
+	fmt.Fprintf(w, "Below minimum\t%d\n", r.Fairness.BelowMinimum)
+	fmt.Fprintf(w, "Same program\t%.1f%%\n", r.Fairness.SameProgramShare*100)
+	fmt.Fprintf(w, "Same year\t%.1f%%\n", r.Fairness.SameYearShare*100)
+	w.Flush()
+
+	fmt.Println("\nTiers")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIER\tMATCHES")
+	for _, tier := range r.Config.Tiers {
+		fmt.Fprintf(w, "%s\t%d\n", tier.Name, r.TierDistribution[tier.Name])
+	}
+	w.Flush()
+}
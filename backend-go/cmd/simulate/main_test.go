@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"wizardmatch-backend/internal/repository/memory"
+	"wizardmatch-backend/internal/service"
+)
+
+func TestGeneratePopulationFollowsOptions(t *testing.T) {
+	opts := defaultPopulationOptions()
+	opts.Users = 200
+	opts.WomanShare, opts.NonbinaryShare = 1, 0
+	opts.SeekingSame, opts.SeekingAny = 0, 0
+	opts.CrushRate, opts.MutualRate = 1, 1
+
+	pop, err := generatePopulation(context.Background(), memory.New(), []byte("{}"), opts)
+	if err != nil {
+		t.Fatalf("generate population: %v", err)
+	}
+	if len(pop.users) != opts.Users {
+		t.Fatalf("expected %d users, got %d", opts.Users, len(pop.users))
+	}
+	for _, user := range pop.users {
+		if user.Genders[0] != service.GenderWoman || user.SeekingGenders[0] != service.GenderMan {
+			t.Fatalf("expected women seeking men only, got %v seeking %v", user.Genders, user.SeekingGenders)
+		}
+	}
+	// Nobody seeks anyone in the population, so there is no one to name.
+	if pop.crushes != 0 {
+		t.Fatalf("expected no crushes, got %d", pop.crushes)
+	}
+}
+
+func TestSimulateReportsMetrics(t *testing.T) {
+	opts := defaultPopulationOptions()
+	opts.Users = 120
+
+	first, err := simulate(context.Background(), []byte("{}"), opts)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if first.Users != opts.Users || first.Matches == 0 {
+		t.Fatalf("expected matches among %d users, got %+v", opts.Users, first)
+	}
+	if first.Coverage <= 0 || first.Coverage > 1 {
+		t.Fatalf("expected coverage in (0, 1], got %v", first.Coverage)
+	}
+	if first.BlockingPairs != 0 {
+		t.Fatalf("expected the greedy default to leave no blocking pairs, got %d", first.BlockingPairs)
+	}
+
+	second, err := simulate(context.Background(), []byte("{}"), opts)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if second.Matches != first.Matches || second.MeanScore != first.MeanScore {
+		t.Fatalf("expected the same seed to reproduce the run, got %d/%v and %d/%v", first.Matches, first.MeanScore, second.Matches, second.MeanScore)
+	}
+}
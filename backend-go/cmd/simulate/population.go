@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+// populationOptions controls the synthetic population. Shares are fractions
+// of all users; men make up whatever the woman and nonbinary shares leave.
+type populationOptions struct {
+	Users          int
+	WomanShare     float64
+	NonbinaryShare float64
+	// Users seek their own gender, everyone, or otherwise the opposite
+	// binary gender; nonbinary users who seek the opposite seek everyone.
+	SeekingSame float64
+	SeekingAny  float64
+	// Every user belongs to one of Clusters latent types. Each answer and
+	// bio word follows the user's type with probability Correlation and is
+	// uniformly random otherwise, so 0 gives unrelated answers and 1 gives
+	// identical answers within a type.
+	Clusters    int
+	Correlation float64
+	// CrushRate is the share of users naming a crush, and MutualRate the
+	// chance that a crush names them back.
+	CrushRate  float64
+	MutualRate float64
+	Seed       int64
+}
+
+func defaultPopulationOptions() populationOptions {
+	return populationOptions{
+		Users:          500,
+		WomanShare:     0.5,
+		NonbinaryShare: 0.05,
+		SeekingSame:    0.1,
+		SeekingAny:     0.1,
+		Clusters:       4,
+		Correlation:    0.6,
+		CrushRate:      0.3,
+		MutualRate:     0.2,
+		Seed:           1,
+	}
+}
+
+func (o populationOptions) validate() error {
+	switch {
+	case o.Users < 2:
+		return fmt.Errorf("users must be at least 2")
+	case o.WomanShare < 0 || o.NonbinaryShare < 0 || o.WomanShare+o.NonbinaryShare > 1:
+		return fmt.Errorf("woman and nonbinary shares must be non-negative and sum to at most 1")
+	case o.SeekingSame < 0 || o.SeekingAny < 0 || o.SeekingSame+o.SeekingAny > 1:
+		return fmt.Errorf("seeking shares must be non-negative and sum to at most 1")
+	case o.Clusters < 1:
+		return fmt.Errorf("clusters must be at least 1")
+	case o.Correlation < 0 || o.Correlation > 1:
+		return fmt.Errorf("correlation must be between 0 and 1")
+	case o.CrushRate < 0 || o.CrushRate > 1 || o.MutualRate < 0 || o.MutualRate > 1:
+		return fmt.Errorf("crush and mutual rates must be between 0 and 1")
+	}
+	return nil
+}
+
+type simQuestion struct {
+	Category string
+	Text     string
+	Type     string
+	Options  []string
+	Weight   float64
+}
+
+const scaleMax = 5
+
+var simQuestions = []simQuestion{
+	{Category: "personality", Text: "I feel energized after social gatherings.", Type: "scale", Weight: 1.0},
+	{Category: "personality", Text: "I enjoy trying new things spontaneously.", Type: "scale", Weight: 1.0},
+	{Category: "personality", Text: "I like to plan things well ahead.", Type: "scale", Weight: 0.8},
+	{Category: "values", Text: "What matters most in a relationship?", Type: "multiple_choice", Options: []string{"Trust", "Shared goals", "Sense of humor", "Emotional support", "Adventure"}, Weight: 1.0},
+	{Category: "values", Text: "I value consistent communication.", Type: "scale", Weight: 1.0},
+	{Category: "values", Text: "Faith plays a big part in my life.", Type: "scale", Weight: 0.9},
+	{Category: "lifestyle", Text: "What is your ideal weekend?", Type: "multiple_choice", Options: []string{"Quiet study or self-care", "Hanging out with close friends", "Campus events", "Outdoor adventure", "Creative projects"}, Weight: 0.9},
+	{Category: "lifestyle", Text: "How late do you usually sleep?", Type: "scale", Weight: 0.8},
+	{Category: "interests", Text: "Pick a vibe that sounds fun.", Type: "multiple_choice", Options: []string{"Coffee and deep talks", "Game night", "Art museum date", "Sports fest", "Food crawl"}, Weight: 0.8},
+	{Category: "interests", Text: "Favorite creative outlet?", Type: "multiple_choice", Options: []string{"Music", "Design", "Writing", "Photography", "Dance"}, Weight: 0.8},
+}
+
+var (
+	simPrograms = []string{"Computer Science", "Information Technology", "Business Administration", "Psychology", "Engineering", "Multimedia Arts"}
+	simHobbies  = []string{"hiking", "anime", "basketball", "baking", "chess", "photography", "guitar", "poetry", "gaming", "volunteering", "running", "painting", "coding", "karaoke", "films", "thrifting", "coffee", "swimming"}
+)
+
+const bioWords = 4
+
+// cluster is a latent user type: the answer it favors for every question
+// and the hobbies its members tend to mention.
+type cluster struct {
+	answers []int
+	hobbies []string
+}
+
+type population struct {
+	campaign repository.Campaign
+	users    []repository.User
+	crushes  int
+}
+
+// generatePopulation creates a campaign with the simulation questions and
+// opts.Users users who completed the survey, along with their crushes.
+func generatePopulation(ctx context.Context, store repository.Querier, config []byte, opts populationOptions) (*population, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	now := time.Now().UTC()
+	campaign, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Simulation",
+		SurveyOpenDate:         now.Add(-48 * time.Hour),
+		SurveyCloseDate:        now.Add(-24 * time.Hour),
+		ProfileUpdateStartDate: now.Add(-24 * time.Hour),
+		ProfileUpdateEndDate:   now,
+		ResultsReleaseDate:     now.Add(24 * time.Hour),
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+		Config:                 config,
+		AlgorithmVersion:       pgtype.Text{String: "v1", Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]repository.Question, 0, len(simQuestions))
+	for i, q := range simQuestions {
+		options, err := json.Marshal(q.Options)
+		if err != nil {
+			return nil, err
+		}
+		question, err := store.CreateQuestion(ctx, repository.CreateQuestionParams{
+			CampaignID:   pgtype.UUID{Bytes: campaign.ID, Valid: true},
+			Category:     q.Category,
+			QuestionText: q.Text,
+			QuestionType: q.Type,
+			Options:      options,
+			Weight:       pgtype.Numeric{Int: big.NewInt(int64(math.Round(q.Weight * 100))), Exp: -2, Valid: true},
+			IsActive:     true,
+			OrderIndex:   int32(i + 1),
+		})
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
+
+	clusters := make([]cluster, opts.Clusters)
+	for i := range clusters {
+		for _, q := range simQuestions {
+			clusters[i].answers = append(clusters[i].answers, randomAnswer(rng, q))
+		}
+		for _, j := range rng.Perm(len(simHobbies))[:bioWords+2] {
+			clusters[i].hobbies = append(clusters[i].hobbies, simHobbies[j])
+		}
+	}
+
+	pop := &population{campaign: campaign}
+	for i := 0; i < opts.Users; i++ {
+		gender := pickGender(rng, opts)
+		group := clusters[rng.Intn(len(clusters))]
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:             fmt.Sprintf("sim%05d@simulation.local", i+1),
+			FirstName:         fmt.Sprintf("Sim%05d", i+1),
+			LastName:          "User",
+			Program:           pgtype.Text{String: simPrograms[rng.Intn(len(simPrograms))], Valid: true},
+			YearLevel:         pgtype.Int4{Int32: int32(rng.Intn(5) + 1), Valid: true},
+			Gender:            pgtype.Text{String: gender, Valid: true},
+			DateOfBirth:       pgtype.Date{Time: now.AddDate(-18-rng.Intn(7), 0, -rng.Intn(365)), Valid: true},
+			Bio:               pgtype.Text{String: randomBio(rng, group, opts.Correlation), Valid: true},
+			ProfileVisibility: "Matches Only",
+			Preferences:       []byte("{}"),
+			IsActive:          true,
+			SurveyCompleted:   true,
+			Genders:           []string{gender},
+			SeekingGenders:    pickSeeking(rng, gender, opts),
+		})
+		if err != nil {
+			return nil, err
+		}
+		pop.users = append(pop.users, user)
+
+		for j, q := range simQuestions {
+			value := group.answers[j]
+			if rng.Float64() >= opts.Correlation {
+				value = randomAnswer(rng, q)
+			}
+			params := repository.CreateSurveyResponseParams{
+				UserID:     user.ID,
+				CampaignID: pgtype.UUID{Bytes: campaign.ID, Valid: true},
+				QuestionID: questions[j].ID,
+				AnswerType: q.Type,
+				Importance: service.ImportanceSomewhat,
+			}
+			if q.Type == "scale" {
+				params.AnswerValue = pgtype.Int4{Int32: int32(value), Valid: true}
+			} else {
+				params.AnswerText = pgtype.Text{String: q.Options[value], Valid: true}
+			}
+			if _, err := store.CreateSurveyResponse(ctx, params); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := pop.addCrushes(ctx, store, rng, opts); err != nil {
+		return nil, err
+	}
+	return pop, nil
+}
+
+// randomAnswer returns a scale value, or an option index for multiple choice.
+func randomAnswer(rng *rand.Rand, q simQuestion) int {
+	if q.Type == "scale" {
+		return rng.Intn(scaleMax) + 1
+	}
+	return rng.Intn(len(q.Options))
+}
+
+func randomBio(rng *rand.Rand, group cluster, correlation float64) string {
+	words := make([]string, 0, bioWords)
+	for len(words) < bioWords {
+		if rng.Float64() < correlation {
+			words = append(words, group.hobbies[rng.Intn(len(group.hobbies))])
+		} else {
+			words = append(words, simHobbies[rng.Intn(len(simHobbies))])
+		}
+	}
+	return "Into " + strings.Join(words, ", ") + "."
+}
+
+func pickGender(rng *rand.Rand, opts populationOptions) string {
+	roll := rng.Float64()
+	switch {
+	case roll < opts.WomanShare:
+		return service.GenderWoman
+	case roll < opts.WomanShare+opts.NonbinaryShare:
+		return service.GenderNonbinary
+	}
+	return service.GenderMan
+}
+
+func pickSeeking(rng *rand.Rand, gender string, opts populationOptions) []string {
+	everyone := []string{service.GenderMan, service.GenderNonbinary, service.GenderWoman}
+	roll := rng.Float64()
+	switch {
+	case roll < opts.SeekingSame:
+		return []string{gender}
+	case roll < opts.SeekingSame+opts.SeekingAny:
+		return everyone
+	}
+	switch gender {
+	case service.GenderMan:
+		return []string{service.GenderWoman}
+	case service.GenderWoman:
+		return []string{service.GenderMan}
+	}
+	return everyone
+}
+
+// addCrushes lets opts.CrushRate of the users name someone whose gender they
+// seek; with probability opts.MutualRate the crush names them back.
+func (p *population) addCrushes(ctx context.Context, store repository.Querier, rng *rand.Rand, opts populationOptions) error {
+	named := map[[2]uuid.UUID]bool{}
+	add := func(from repository.User, to repository.User) error {
+		if from.ID == to.ID || named[[2]uuid.UUID{from.ID, to.ID}] {
+			return nil
+		}
+		named[[2]uuid.UUID{from.ID, to.ID}] = true
+		p.crushes++
+		_, err := store.CreateCrush(ctx, repository.CreateCrushParams{
+			UserID:     from.ID,
+			CampaignID: p.campaign.ID,
+			CrushEmail: to.Email,
+			CrushName:  pgtype.Text{String: to.FirstName + " " + to.LastName, Valid: true},
+		})
+		return err
+	}
+
+	for _, user := range p.users {
+		if rng.Float64() >= opts.CrushRate {
+			continue
+		}
+		var candidates []repository.User
+		for _, other := range p.users {
+			if other.ID != user.ID && seeks(user, other) {
+				candidates = append(candidates, other)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		crush := candidates[rng.Intn(len(candidates))]
+		if err := add(user, crush); err != nil {
+			return err
+		}
+		if rng.Float64() < opts.MutualRate {
+			if err := add(crush, user); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func seeks(user repository.User, other repository.User) bool {
+	for _, gender := range user.SeekingGenders {
+		for _, theirs := range other.Genders {
+			if gender == theirs {
+				return true
+			}
+		}
+	}
+	return false
+}
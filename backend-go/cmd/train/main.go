@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/config"
+	"wizardmatch-backend/internal/db"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+// proposal is written for admins to review. The "matching" section can be
+// used as is in a new campaign's config; question weights are applied per
+// question through the admin API.
+type proposal struct {
+	Matching        service.MatchingConfig   `json:"matching"`
+	QuestionWeights []service.QuestionWeight `json:"questionWeights"`
+}
+
+func main() {
+	opts := service.DefaultTrainingOptions()
+	campaigns := flag.String("campaigns", "", "comma-separated campaign IDs to train on (default: every inactive campaign)")
+	out := flag.String("out", "proposed-weights.json", "where to write the proposed weights")
+	flag.Float64Var(&opts.Holdout, "holdout", opts.Holdout, "fraction of matches held out for evaluation")
+	flag.IntVar(&opts.Epochs, "epochs", opts.Epochs, "gradient descent iterations")
+	flag.Float64Var(&opts.LearningRate, "learning-rate", opts.LearningRate, "gradient descent step size")
+	flag.Float64Var(&opts.L2, "l2", opts.L2, "L2 regularization strength")
+	flag.Int64Var(&opts.Seed, "seed", opts.Seed, "seed for the holdout split")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config load failed: %v", err)
+	}
+
+	ctx := context.Background()
+	database, err := db.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("database connection failed: %v", err)
+	}
+	defer database.Close()
+
+	store := repository.New(database.Pool)
+	opts.CampaignIDs, err = trainingCampaigns(ctx, store, *campaigns)
+	if err != nil {
+		log.Fatalf("failed to select campaigns: %v", err)
+	}
+	if len(opts.CampaignIDs) == 0 {
+		log.Fatalf("no campaigns to train on")
+	}
+
+	report, err := service.NewMatchingService(store, nil).TrainWeights(ctx, opts)
+	if err != nil {
+		log.Fatalf("training failed: %v", err)
+	}
+	printReport(report)
+
+	encoded, err := json.MarshalIndent(proposal{Matching: report.Matching, QuestionWeights: report.QuestionWeights}, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to encode proposal: %v", err)
+	}
+	if err := os.WriteFile(*out, append(encoded, '\n'), 0o644); err != nil {
+		log.Fatalf("failed to write proposal: %v", err)
+	}
+	fmt.Printf("\nProposed weights written to %s\n", *out)
+}
+
+// trainingCampaigns parses the -campaigns flag, defaulting to every inactive
+// campaign, most recent first.
+func trainingCampaigns(ctx context.Context, store repository.Querier, value string) ([]uuid.UUID, error) {
+	if value != "" {
+		var ids []uuid.UUID
+		for _, part := range strings.Split(value, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid campaign ID %q", part)
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+
+	campaigns, err := store.ListCampaigns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	for _, campaign := range campaigns {
+		if !campaign.IsActive.Bool {
+			ids = append(ids, campaign.ID)
+		}
+	}
+	return ids, nil
+}
+
+func printReport(report *service.TrainingReport) {
+	fmt.Printf("Trained on %d matches (%d skipped without a clear outcome)\n\n", report.Examples, report.Skipped)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tSPLIT\tMATCHES\tMUTUAL\tLOG LOSS\tACCURACY\tAUC")
+	for _, m := range report.Metrics {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.4f\t%.3f\t%s\n", m.Model, m.Split, m.Examples, m.Positives, m.LogLoss, m.Accuracy, formatAUC(m.AUC))
+	}
+	w.Flush()
+
+	fmt.Println("\nCategory weights")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tCOEFFICIENT\tPROPOSED")
+	categories := make([]string, 0, len(report.Matching.Weights))
+	for category := range report.Matching.Weights {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		fmt.Fprintf(w, "%s\t%+.3f\t%.3f\n", category, report.CategoryCoefficients[category], report.Matching.Weights[category])
+	}
+	fmt.Fprintf(w, "bio\t\t%.2f\n", report.Matching.BioWeight)
+	w.Flush()
+
+	fmt.Println("\nQuestion weights")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tCURRENT\tPROPOSED\tQUESTION")
+	for _, q := range report.QuestionWeights {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%s\n", q.Category, q.CurrentWeight, q.ProposedWeight, q.Question)
+	}
+	w.Flush()
+}
+
+func formatAUC(value float64) string {
+	if math.IsNaN(value) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.3f", value)
+}
@@ -18,6 +18,7 @@ type Config struct {
 	GoogleRedirectURL  string        `mapstructure:"GOOGLE_REDIRECT_URL"`
 	AccessTokenTTL     time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
 	EnableDevLogin     bool          `mapstructure:"ENABLE_DEV_LOGIN"`
+	JobWorkers         int           `mapstructure:"JOB_WORKERS"`
 }
 
 func Load() (Config, error) {
@@ -35,6 +36,7 @@ func Load() (Config, error) {
 	viper.SetDefault("ACCESS_TOKEN_TTL", "24h")
 	viper.SetDefault("ENABLE_DEV_LOGIN", true)
 	viper.SetDefault("ADMIN_EMAIL", "admin@wizardmatch.ai")
+	viper.SetDefault("JOB_WORKERS", 1)
 
 	// Explicitly bind environment variables
 	_ = viper.BindEnv("ENV")
@@ -46,6 +48,7 @@ func Load() (Config, error) {
 	_ = viper.BindEnv("GOOGLE_CLIENT_ID")
 	_ = viper.BindEnv("GOOGLE_CLIENT_SECRET")
 	_ = viper.BindEnv("GOOGLE_REDIRECT_URL")
+	_ = viper.BindEnv("JOB_WORKERS")
 
 	_ = viper.ReadInConfig()
 
@@ -9,27 +9,26 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"wizardmatch-backend/internal/realtime"
 	"wizardmatch-backend/internal/repository"
 	"wizardmatch-backend/internal/service"
 )
 
-type AdminHandler struct{}
+type AdminHandler struct {
+	store   repository.Querier
+	matcher *service.MatchingService
+	events  realtime.Publisher
+}
 
-func NewAdminHandler() *AdminHandler {
-	return &AdminHandler{}
+func NewAdminHandler(store repository.Querier, matcher *service.MatchingService, events realtime.Publisher) *AdminHandler {
+	return &AdminHandler{store: store, matcher: matcher, events: events}
 }
 
 func (h *AdminHandler) GetStats(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	totalUsers, _ := store.CountUsers(c)
-	completed, _ := store.CountCompletedSurveys(c)
-	matches, _ := store.CountMatches(c)
-	activeUsers, _ := store.CountActiveUsers(c)
+	totalUsers, _ := h.store.CountUsers(c)
+	completed, _ := h.store.CountCompletedSurveys(c)
+	matches, _ := h.store.CountMatches(c)
+	activeUsers, _ := h.store.CountActiveUsers(c)
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -47,14 +46,8 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	page, limit := parsePagination(c)
 	search := c.Query("search")
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	if search != "" {
-		rows, err := store.SearchUsersAdmin(c, repository.SearchUsersAdminParams{
+		rows, err := h.store.SearchUsersAdmin(c, repository.SearchUsersAdminParams{
 			FirstName: "%" + search + "%",
 			Limit:     int32(limit),
 			Offset:    int32((page - 1) * limit),
@@ -63,7 +56,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 			respondError(c, http.StatusInternalServerError, "Failed to load users")
 			return
 		}
-		total, _ := store.CountUsersSearch(c, "%"+search+"%")
+		total, _ := h.store.CountUsersSearch(c, "%"+search+"%")
 
 		respondJSON(c, http.StatusOK, gin.H{
 			"success":    true,
@@ -73,7 +66,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	rows, err := store.ListUsersAdmin(c, repository.ListUsersAdminParams{
+	rows, err := h.store.ListUsersAdmin(c, repository.ListUsersAdminParams{
 		Limit:  int32(limit),
 		Offset: int32((page - 1) * limit),
 	})
@@ -81,7 +74,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, "Failed to load users")
 		return
 	}
-	total, _ := store.CountUsers(c)
+	total, _ := h.store.CountUsers(c)
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success":    true,
@@ -111,12 +104,6 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	params := repository.UpdateUserAdminParams{
 		ID:              userUUID,
 		Email:           optionalString(payload.Email),
@@ -128,7 +115,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		IsActive:        boolPointerValue(payload.IsActive),
 	}
 
-	updated, err := store.UpdateUserAdmin(c, params)
+	updated, err := h.store.UpdateUserAdmin(c, params)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to update user")
 		return
@@ -148,13 +135,7 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	if err := store.DeleteUser(c, userUUID); err != nil {
+	if err := h.store.DeleteUser(c, userUUID); err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to delete user")
 		return
 	}
@@ -167,24 +148,34 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 
 func (h *AdminHandler) CreateQuestion(c *gin.Context) {
 	var payload struct {
-		Category     string   `json:"category"`
-		QuestionText string   `json:"questionText"`
-		QuestionType string   `json:"questionType"`
-		Options      []byte   `json:"options"`
-		Weight       *float64 `json:"weight"`
-		OrderIndex   int32    `json:"orderIndex"`
-		CampaignID   string   `json:"campaignId"`
+		Category     string          `json:"category"`
+		QuestionText string          `json:"questionText"`
+		QuestionType string          `json:"questionType"`
+		Options      []byte          `json:"options"`
+		Scoring      json.RawMessage `json:"scoring"`
+		Weight       *float64        `json:"weight"`
+		OrderIndex   int32           `json:"orderIndex"`
+		CampaignID   string          `json:"campaignId"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		respondError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
-
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	if _, err := service.ParseScoring(payload.Scoring); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !validWeight(payload.Weight) {
+		respondError(c, http.StatusBadRequest, "Weight must be between 0 and 9.99")
+		return
+	}
+
+	// questions.weight is NOT NULL; a question created without one gets the
+	// column's default.
+	if payload.Weight == nil {
+		weight := 1.0
+		payload.Weight = &weight
+	}
 
 	var campaignID pgtype.UUID
 	if payload.CampaignID != "" {
@@ -193,7 +184,7 @@ func (h *AdminHandler) CreateQuestion(c *gin.Context) {
 		}
 	}
 
-	question, err := store.CreateQuestion(c, repository.CreateQuestionParams{
+	question, err := h.store.CreateQuestion(c, repository.CreateQuestionParams{
 		CampaignID:   campaignID,
 		Category:     payload.Category,
 		QuestionText: payload.QuestionText,
@@ -202,6 +193,7 @@ func (h *AdminHandler) CreateQuestion(c *gin.Context) {
 		Weight:       numericFromPointer(payload.Weight),
 		IsActive:     true,
 		OrderIndex:   payload.OrderIndex,
+		Scoring:      scoringValue(payload.Scoring),
 	})
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to create question")
@@ -223,26 +215,29 @@ func (h *AdminHandler) UpdateQuestion(c *gin.Context) {
 	}
 
 	var payload struct {
-		Category     *string  `json:"category"`
-		QuestionText *string  `json:"questionText"`
-		QuestionType *string  `json:"questionType"`
-		Options      []byte   `json:"options"`
-		Weight       *float64 `json:"weight"`
-		OrderIndex   *int32   `json:"orderIndex"`
-		IsActive     *bool    `json:"isActive"`
+		Category     *string         `json:"category"`
+		QuestionText *string         `json:"questionText"`
+		QuestionType *string         `json:"questionType"`
+		Options      []byte          `json:"options"`
+		Scoring      json.RawMessage `json:"scoring"`
+		Weight       *float64        `json:"weight"`
+		OrderIndex   *int32          `json:"orderIndex"`
+		IsActive     *bool           `json:"isActive"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		respondError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
-
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	if _, err := service.ParseScoring(payload.Scoring); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !validWeight(payload.Weight) {
+		respondError(c, http.StatusBadRequest, "Weight must be between 0 and 9.99")
 		return
 	}
 
-	question, err := store.UpdateQuestion(c, repository.UpdateQuestionParams{
+	question, err := h.store.UpdateQuestion(c, repository.UpdateQuestionParams{
 		ID:           questionUUID,
 		Category:     optionalString(payload.Category),
 		QuestionText: optionalString(payload.QuestionText),
@@ -251,6 +246,7 @@ func (h *AdminHandler) UpdateQuestion(c *gin.Context) {
 		Weight:       numericFromPointer(payload.Weight),
 		IsActive:     boolPointerValue(payload.IsActive),
 		Column8:      optionalOrderIndex(payload.OrderIndex),
+		Scoring:      scoringValue(payload.Scoring),
 	})
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to update question")
@@ -271,13 +267,7 @@ func (h *AdminHandler) DeleteQuestion(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	if err := store.DeleteQuestion(c, questionUUID); err != nil {
+	if err := h.store.DeleteQuestion(c, questionUUID); err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to delete question")
 		return
 	}
@@ -288,14 +278,18 @@ func (h *AdminHandler) DeleteQuestion(c *gin.Context) {
 	})
 }
 
+// GenerateMatches queues a match generation job; poll it via /admin/jobs.
+// With dryRun=true the pipeline runs inline and only a preview is returned.
 func (h *AdminHandler) GenerateMatches(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	if c.Query("dryRun") == "true" {
+		h.PreviewMatches(c)
 		return
 	}
+	h.StartGenerateMatchesJob(c)
+}
 
-	active, err := store.GetActiveCampaign(c)
+func (h *AdminHandler) PreviewMatches(c *gin.Context) {
+	active, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load active campaign")
 		return
@@ -305,30 +299,23 @@ func (h *AdminHandler) GenerateMatches(c *gin.Context) {
 		return
 	}
 
-	matcher := service.NewMatchingService(store)
-	created, totalUsers, err := matcher.GenerateAllMatches(c, active.ID)
+	preview, err := h.matcher.PreviewMatches(c, active.ID)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "Failed to generate matches")
+		respondError(c, http.StatusInternalServerError, "Failed to preview matches")
 		return
 	}
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
-		"data":    gin.H{"matchesCreated": created, "totalUsers": totalUsers},
-		"message": "Matches generated successfully",
+		"data":    preview,
+		"message": "Match preview generated; no matches were changed",
 	})
 }
 
 func (h *AdminHandler) GetAllMatches(c *gin.Context) {
 	page, limit := parsePagination(c)
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	matches, err := store.ListMatches(c, repository.ListMatchesParams{
+	matches, err := h.store.ListMatches(c, repository.ListMatchesParams{
 		Limit:  int32(limit),
 		Offset: int32((page - 1) * limit),
 	})
@@ -336,12 +323,12 @@ func (h *AdminHandler) GetAllMatches(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, "Failed to load matches")
 		return
 	}
-	total, _ := store.CountMatchesAll(c)
+	total, _ := h.store.CountMatchesAll(c)
 
 	response := make([]gin.H, 0, len(matches))
 	for _, match := range matches {
-		user1, _ := store.GetUserByID(c, match.User1ID)
-		user2, _ := store.GetUserByID(c, match.User2ID)
+		user1, _ := h.store.GetUserByID(c, match.User1ID)
+		user2, _ := h.store.GetUserByID(c, match.User2ID)
 		response = append(response, gin.H{
 			"id":                 match.ID,
 			"user1":              user1,
@@ -383,19 +370,13 @@ func (h *AdminHandler) CreateManualMatch(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	active, _ := store.GetActiveCampaign(c)
+	active, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if active.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: active.ID, Valid: true}
 	}
 
-	match, err := store.CreateMatch(c, repository.CreateMatchParams{
+	match, err := h.matcher.CreateManualMatch(c, repository.CreateMatchParams{
 		CampaignID:         campaignID,
 		User1ID:            user1,
 		User2ID:            user2,
@@ -411,6 +392,9 @@ func (h *AdminHandler) CreateManualMatch(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, "Failed to create match")
 		return
 	}
+	if !campaignID.Valid || service.ActionAllowed(campaignPhase(active), "view_matches") {
+		publish(c, h.events, realtime.EventMatchCreated, gin.H{"matchId": match.ID, "campaignId": match.CampaignID}, user1, user2)
+	}
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -426,13 +410,7 @@ func (h *AdminHandler) DeleteMatch(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	if err := store.DeleteMatch(c, matchUUID); err != nil {
+	if err := h.store.DeleteMatch(c, matchUUID); err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to delete match")
 		return
 	}
@@ -444,13 +422,7 @@ func (h *AdminHandler) DeleteMatch(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetEligibleUsers(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	users, err := store.ListEligibleUsers(c)
+	users, err := h.store.ListEligibleUsers(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load users")
 		return
@@ -490,14 +462,8 @@ func (h *AdminHandler) UpdateSettings(c *gin.Context) {
 		updatedBy = pgtype.UUID{Bytes: id, Valid: true}
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	valueJSON, _ := json.Marshal(payload.Value)
-	setting, err := store.UpsertAdminSetting(c, repository.UpsertAdminSettingParams{
+	setting, err := h.store.UpsertAdminSetting(c, repository.UpsertAdminSettingParams{
 		SettingKey:   payload.Key,
 		SettingValue: valueJSON,
 		UpdatedBy:    updatedBy,
@@ -515,13 +481,7 @@ func (h *AdminHandler) UpdateSettings(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetTestimonials(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	testimonials, err := store.ListTestimonials(c)
+	testimonials, err := h.store.ListTestimonials(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load testimonials")
 		return
@@ -549,13 +509,7 @@ func (h *AdminHandler) ApproveTestimonial(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	updated, err := store.UpdateTestimonialApproval(c, repository.UpdateTestimonialApprovalParams{
+	updated, err := h.store.UpdateTestimonialApproval(c, repository.UpdateTestimonialApprovalParams{
 		ID:          testimonialUUID,
 		IsApproved:  payload.IsApproved,
 		IsPublished: payload.IsPublished,
@@ -649,11 +603,17 @@ func numericFromPointer(value *float64) pgtype.Numeric {
 	if value == nil {
 		return pgtype.Numeric{Valid: false}
 	}
-	var numeric pgtype.Numeric
-	if err := numeric.Scan(*value); err != nil {
-		return pgtype.Numeric{Valid: false}
+	return service.NumericFromFloat(*value)
+}
+
+// validWeight reports whether a question weight fits questions.weight,
+// a NUMERIC(3,2), once rounded to two places as NumericFromFloat stores it.
+func validWeight(weight *float64) bool {
+	if weight == nil {
+		return true
 	}
-	return numeric
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(*weight, 'f', 2, 64), 64)
+	return err == nil && rounded >= 0 && rounded <= 9.99
 }
 
 func optionalOrderIndex(value *int32) interface{} {
@@ -662,3 +622,12 @@ func optionalOrderIndex(value *int32) interface{} {
 	}
 	return *value
 }
+
+// scoringValue stores an omitted or null scoring spec as NULL, so updates
+// leave the question's current scorer alone.
+func scoringValue(value json.RawMessage) []byte {
+	if len(value) == 0 || string(value) == "null" {
+		return nil
+	}
+	return value
+}
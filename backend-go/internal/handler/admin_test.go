@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestQuestionWeightsAreStored(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	h := NewAdminHandler(store, nil, nil)
+	router := gin.New()
+	router.POST("/questions", h.CreateQuestion)
+	router.PUT("/questions/:questionId", h.UpdateQuestion)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+	weight := func(question repository.Question) float64 {
+		t.Helper()
+		stored, err := store.GetQuestionByID(context.Background(), question.ID)
+		if err != nil {
+			t.Fatalf("get question: %v", err)
+		}
+		if !stored.Weight.Valid {
+			t.Fatalf("expected a stored weight, got NULL")
+		}
+		return numericFloat(stored.Weight)
+	}
+
+	// A question created without a weight gets the column default.
+	created := send(http.MethodPost, "/questions", `{"category": "values", "questionText": "Q?", "questionType": "scale"}`)
+	if created.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", created.Code, created.Body)
+	}
+	var result struct {
+		Data repository.Question `json:"data"`
+	}
+	if err := json.Unmarshal(created.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := weight(result.Data); got != 1 {
+		t.Fatalf("expected the default weight, got %v", got)
+	}
+
+	path := "/questions/" + result.Data.ID.String()
+	if updated := send(http.MethodPut, path, `{"weight": 2.5}`); updated.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", updated.Code, updated.Body)
+	}
+	if got := weight(result.Data); got != 2.5 {
+		t.Fatalf("expected the updated weight, got %v", got)
+	}
+	if tooLarge := send(http.MethodPut, path, `{"weight": 12}`); tooLarge.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a weight outside NUMERIC(3,2), got %d", tooLarge.Code)
+	}
+	if roundsUp := send(http.MethodPut, path, `{"weight": 9.999}`); roundsUp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a weight that rounds to 10, got %d", roundsUp.Code)
+	}
+}
@@ -10,45 +10,41 @@ import (
 	"wizardmatch-backend/internal/repository"
 )
 
-type AnalyticsHandler struct{}
+type AnalyticsHandler struct {
+	store repository.Querier
+}
 
-func NewAnalyticsHandler() *AnalyticsHandler {
-	return &AnalyticsHandler{}
+func NewAnalyticsHandler(store repository.Querier) *AnalyticsHandler {
+	return &AnalyticsHandler{store: store}
 }
 
 func (h *AnalyticsHandler) GetOverview(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
 	}
 
-	activeUsers, _ := store.CountActiveUsers(c)
+	activeUsers, _ := h.store.CountActiveUsers(c)
 	completed := int64(0)
 	matches := int64(0)
 	mutual := int64(0)
 	avgScore := float64(0)
 	if campaignID.Valid {
-		completed, _ = store.CountCompletedSurveysByCampaign(c, campaignID)
-		matches, _ = store.CountMatchesByCampaign(c, campaignID)
-		mutual, _ = store.CountMutualMatchesByCampaign(c, campaignID)
-		avgScore, _ = store.AverageCompatibilityScoreByCampaign(c, campaignID)
+		completed, _ = h.store.CountCompletedSurveysByCampaign(c, campaignID)
+		matches, _ = h.store.CountMatchesByCampaign(c, campaignID)
+		mutual, _ = h.store.CountMutualMatchesByCampaign(c, campaignID)
+		avgScore, _ = h.store.AverageCompatibilityScoreByCampaign(c, campaignID)
 	} else {
-		completed, _ = store.CountCompletedSurveys(c)
-		matches, _ = store.CountMatches(c)
-		mutual, _ = store.CountMutualMatches(c)
-		avgScore, _ = store.AverageCompatibilityScore(c)
+		completed, _ = h.store.CountCompletedSurveys(c)
+		matches, _ = h.store.CountMatches(c)
+		mutual, _ = h.store.CountMutualMatches(c)
+		avgScore, _ = h.store.AverageCompatibilityScore(c)
 	}
 
 	var topPrograms []repository.TopProgramsRow
 	if campaignID.Valid {
-		byCampaign, _ := store.TopProgramsByCampaign(c, repository.TopProgramsByCampaignParams{
+		byCampaign, _ := h.store.TopProgramsByCampaign(c, repository.TopProgramsByCampaignParams{
 			CampaignID: campaignID,
 			Limit:      10,
 		})
@@ -60,7 +56,7 @@ func (h *AnalyticsHandler) GetOverview(c *gin.Context) {
 			})
 		}
 	} else {
-		topPrograms, _ = store.TopPrograms(c, 10)
+		topPrograms, _ = h.store.TopPrograms(c, 10)
 	}
 	programs := make([]gin.H, 0, len(topPrograms))
 	for _, row := range topPrograms {
@@ -84,24 +80,18 @@ func (h *AnalyticsHandler) GetOverview(c *gin.Context) {
 }
 
 func (h *AnalyticsHandler) GetParticipants(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
 	}
 
-	activeUsers, _ := store.CountActiveUsers(c)
+	activeUsers, _ := h.store.CountActiveUsers(c)
 	completed := int64(0)
 	if campaignID.Valid {
-		completed, _ = store.CountCompletedSurveysByCampaign(c, campaignID)
+		completed, _ = h.store.CountCompletedSurveysByCampaign(c, campaignID)
 	} else {
-		completed, _ = store.CountCompletedSurveys(c)
+		completed, _ = h.store.CountCompletedSurveys(c)
 	}
 
 	respondJSON(c, http.StatusOK, gin.H{
@@ -116,13 +106,7 @@ func (h *AnalyticsHandler) GetParticipants(c *gin.Context) {
 }
 
 func (h *AnalyticsHandler) GetByProgram(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
@@ -130,7 +114,7 @@ func (h *AnalyticsHandler) GetByProgram(c *gin.Context) {
 
 	var rows []repository.ProgramsWithCompletionRow
 	if campaignID.Valid {
-		byCampaign, _ := store.ProgramsWithCompletionByCampaign(c, campaignID)
+		byCampaign, _ := h.store.ProgramsWithCompletionByCampaign(c, campaignID)
 		rows = make([]repository.ProgramsWithCompletionRow, 0, len(byCampaign))
 		for _, row := range byCampaign {
 			rows = append(rows, repository.ProgramsWithCompletionRow{
@@ -140,7 +124,7 @@ func (h *AnalyticsHandler) GetByProgram(c *gin.Context) {
 			})
 		}
 	} else {
-		rows, _ = store.ProgramsWithCompletion(c)
+		rows, _ = h.store.ProgramsWithCompletion(c)
 	}
 	data := make([]gin.H, 0, len(rows))
 	for _, row := range rows {
@@ -159,13 +143,7 @@ func (h *AnalyticsHandler) GetByProgram(c *gin.Context) {
 }
 
 func (h *AnalyticsHandler) GetByYearLevel(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
@@ -173,7 +151,7 @@ func (h *AnalyticsHandler) GetByYearLevel(c *gin.Context) {
 
 	var rows []repository.YearLevelsWithCompletionRow
 	if campaignID.Valid {
-		byCampaign, _ := store.YearLevelsWithCompletionByCampaign(c, campaignID)
+		byCampaign, _ := h.store.YearLevelsWithCompletionByCampaign(c, campaignID)
 		rows = make([]repository.YearLevelsWithCompletionRow, 0, len(byCampaign))
 		for _, row := range byCampaign {
 			rows = append(rows, repository.YearLevelsWithCompletionRow{
@@ -183,7 +161,7 @@ func (h *AnalyticsHandler) GetByYearLevel(c *gin.Context) {
 			})
 		}
 	} else {
-		rows, _ = store.YearLevelsWithCompletion(c)
+		rows, _ = h.store.YearLevelsWithCompletion(c)
 	}
 	data := make([]gin.H, 0, len(rows))
 	for _, row := range rows {
@@ -202,13 +180,7 @@ func (h *AnalyticsHandler) GetByYearLevel(c *gin.Context) {
 }
 
 func (h *AnalyticsHandler) GetSuccessRate(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	campaignID := pgtype.UUID{Valid: false}
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
@@ -218,18 +190,18 @@ func (h *AnalyticsHandler) GetSuccessRate(c *gin.Context) {
 	mutual := int64(0)
 	revealed := int64(0)
 	if campaignID.Valid {
-		totalMatches, _ = store.CountMatchesByCampaign(c, campaignID)
-		mutual, _ = store.CountMutualMatchesByCampaign(c, campaignID)
-		revealed, _ = store.CountRevealedMatchesByCampaign(c, campaignID)
+		totalMatches, _ = h.store.CountMatchesByCampaign(c, campaignID)
+		mutual, _ = h.store.CountMutualMatchesByCampaign(c, campaignID)
+		revealed, _ = h.store.CountRevealedMatchesByCampaign(c, campaignID)
 	} else {
-		totalMatches, _ = store.CountMatches(c)
-		mutual, _ = store.CountMutualMatches(c)
-		revealed, _ = store.CountRevealedMatches(c)
+		totalMatches, _ = h.store.CountMatches(c)
+		mutual, _ = h.store.CountMutualMatches(c)
+		revealed, _ = h.store.CountRevealedMatches(c)
 	}
 
 	var rows []repository.MatchesByTierRow
 	if campaignID.Valid {
-		byCampaign, _ := store.MatchesByTierByCampaign(c, campaignID)
+		byCampaign, _ := h.store.MatchesByTierByCampaign(c, campaignID)
 		rows = make([]repository.MatchesByTierRow, 0, len(byCampaign))
 		for _, row := range byCampaign {
 			rows = append(rows, repository.MatchesByTierRow{
@@ -238,7 +210,7 @@ func (h *AnalyticsHandler) GetSuccessRate(c *gin.Context) {
 			})
 		}
 	} else {
-		rows, _ = store.MatchesByTier(c)
+		rows, _ = h.store.MatchesByTier(c)
 	}
 	tiers := make([]gin.H, 0, len(rows))
 	for _, row := range rows {
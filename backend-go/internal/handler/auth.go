@@ -25,9 +25,11 @@ type AuthHandlerOptions struct {
 	GoogleClientSecret string
 	GoogleRedirectURL  string
 	AdminEmails        []string
+	Store              repository.Querier
 }
 
 type AuthHandler struct {
+	store              repository.Querier
 	jwtSecret          []byte
 	frontendURL        string
 	enableDevLogin     bool
@@ -54,6 +56,7 @@ func NewAuthHandler(options AuthHandlerOptions) *AuthHandler {
 	}
 
 	return &AuthHandler{
+		store:              options.Store,
 		jwtSecret:          []byte(options.JwtSecret),
 		frontendURL:        options.FrontendURL,
 		enableDevLogin:     options.EnableDevLogin,
@@ -104,18 +107,12 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	existing, err := store.GetUserByEmail(c, user.Email)
+	existing, err := h.store.GetUserByEmail(c, user.Email)
 	newUser := false
 
 	if err != nil || existing.ID == uuid.Nil {
 		studentID := generateStudentID(user.Email)
-		created, err := store.CreateUser(c, repository.CreateUserParams{
+		created, err := h.store.CreateUser(c, repository.CreateUserParams{
 			Email:             user.Email,
 			GoogleID:          pgtype.Text{String: user.ID, Valid: user.ID != ""},
 			StudentID:         pgtype.Text{String: studentID, Valid: studentID != ""},
@@ -126,6 +123,8 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 			ProfileVisibility: "Matches Only",
 			IsActive:          true,
 			SurveyCompleted:   false,
+			Genders:           []string{},
+			SeekingGenders:    []string{},
 		})
 		if err != nil {
 			respondError(c, http.StatusInternalServerError, "failed to create user")
@@ -152,19 +151,13 @@ func (h *AuthHandler) GetSession(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
-	user, err := store.GetUserByID(c, userUUID)
+	user, err := h.store.GetUserByID(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "User not found")
 		return
@@ -203,15 +196,9 @@ func (h *AuthHandler) DevLogin(c *gin.Context) {
 	}
 
 	email := c.DefaultQuery("email", "dev@wizardmatch.ai")
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	user, err := store.GetUserByEmail(c, email)
+	user, err := h.store.GetUserByEmail(c, email)
 	if err != nil || user.ID == uuid.Nil {
-		created, err := store.CreateUser(c, repository.CreateUserParams{
+		created, err := h.store.CreateUser(c, repository.CreateUserParams{
 			Email:             email,
 			StudentID:         pgtype.Text{String: "DEV-0001", Valid: true},
 			FirstName:         "Dev",
@@ -221,6 +208,8 @@ func (h *AuthHandler) DevLogin(c *gin.Context) {
 			ProfileVisibility: "Matches Only",
 			IsActive:          true,
 			SurveyCompleted:   false,
+			Genders:           []string{},
+			SeekingGenders:    []string{},
 		})
 		if err != nil {
 			respondError(c, http.StatusInternalServerError, "failed to create user")
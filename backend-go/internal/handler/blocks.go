@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// BlockHandler lets users block each other. A block hides the two users from
+// each other whoever placed it; only the blocker can lift it.
+type BlockHandler struct {
+	store repository.Querier
+}
+
+func NewBlockHandler(store repository.Querier) *BlockHandler {
+	return &BlockHandler{store: store}
+}
+
+type blockUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *BlockHandler) ListBlocks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	blocks, err := h.store.ListUserBlocksByBlocker(c, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load blocked users")
+		return
+	}
+
+	formatted := make([]gin.H, 0, len(blocks))
+	for _, block := range blocks {
+		user, err := h.store.GetUserByID(c, block.BlockedID)
+		if err != nil {
+			continue
+		}
+		formatted = append(formatted, gin.H{
+			"userId":    block.BlockedID,
+			"firstName": user.FirstName,
+			"lastName":  user.LastName,
+			"reason":    textValue(block.Reason),
+			"blockedAt": block.CreatedAt,
+		})
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    formatted,
+		"count":   len(formatted),
+	})
+}
+
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	targetUUID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid target user ID")
+		return
+	}
+	if targetUUID == userUUID {
+		respondError(c, http.StatusBadRequest, "You cannot block yourself")
+		return
+	}
+
+	// The reason is optional, so an empty body is fine.
+	var req blockUserRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if _, err := h.store.GetUserByID(c, targetUUID); err != nil {
+		respondError(c, http.StatusNotFound, "User not found")
+		return
+	}
+	if _, err := blockUser(c, h.store, userUUID, targetUUID, req.Reason); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to block user")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"message": "User blocked",
+	})
+}
+
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	targetUUID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid target user ID")
+		return
+	}
+
+	removed, err := h.store.DeleteUserBlock(c, repository.DeleteUserBlockParams{
+		BlockerID: userUUID,
+		BlockedID: targetUUID,
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unblock user")
+		return
+	}
+	if removed == 0 {
+		respondError(c, http.StatusNotFound, "User is not blocked")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"message": "User unblocked",
+	})
+}
+
+func blockUser(c *gin.Context, store repository.Querier, blockerID uuid.UUID, blockedID uuid.UUID, reason string) (repository.UserBlock, error) {
+	return store.CreateUserBlock(c, repository.CreateUserBlockParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		Reason:    pgtype.Text{String: reason, Valid: reason != ""},
+	})
+}
+
+// blockedUsers returns everyone the user blocked or was blocked by, for
+// handlers to leave out of what they list.
+func blockedUsers(c *gin.Context, store repository.Querier, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	ids, err := store.ListBlockedUserIDs(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
+// pairBlocked reports whether either user blocked the other. A failed lookup
+// counts as blocked so an outage never lets a blocked user through.
+func pairBlocked(c *gin.Context, store repository.Querier, user1 uuid.UUID, user2 uuid.UUID) bool {
+	blocked, err := store.IsPairBlocked(c, repository.IsPairBlockedParams{
+		BlockerID: user1,
+		BlockedID: user2,
+	})
+	return err != nil || blocked
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+	"wizardmatch-backend/internal/service"
+)
+
+func TestBlocksHideUsersFromEachOther(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	users := map[string]uuid.UUID{}
+	for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:           name + "@example.com",
+			FirstName:       name,
+			LastName:        "Test",
+			IsActive:        true,
+			SurveyCompleted: true,
+			DateOfBirth:     pgtype.Date{Time: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users[name] = user.ID
+	}
+	// Matches outside any campaign, with no campaign running, are held to
+	// no phase once unlocked.
+	for _, other := range []string{"bob", "carol"} {
+		match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: users["alice"], User2ID: users[other]})
+		if err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+		if _, err := store.UnlockMatchMessaging(ctx, repository.UnlockMatchMessagingParams{
+			ID:                    match.ID,
+			MessagingUnlockReason: pgtype.Text{String: service.UnlockReasonAdmin, Valid: true},
+		}); err != nil {
+			t.Fatalf("unlock match: %v", err)
+		}
+		if _, err := store.CreateMessage(ctx, repository.CreateMessageParams{
+			MatchID:     match.ID,
+			SenderID:    users[other],
+			RecipientID: users["alice"],
+			Content:     "hi",
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+	bobMatch, err := store.GetMatchByUsers(ctx, repository.GetMatchByUsersParams{User1ID: users["alice"], User2ID: users["bob"]})
+	if err != nil {
+		t.Fatalf("get match: %v", err)
+	}
+
+	blocks := NewBlockHandler(store)
+	matches := NewMatchHandler(store, nil, nil)
+	messages := NewMessageHandler(store, nil)
+	crushes := NewCrushHandler(store, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.GetHeader("X-User")) })
+	router.GET("/users/blocks", blocks.ListBlocks)
+	router.POST("/users/blocks/:userId", blocks.BlockUser)
+	router.DELETE("/users/blocks/:userId", blocks.UnblockUser)
+	router.GET("/matches", matches.GetMatches)
+	router.GET("/matches/potential", matches.GetPotentialMatches)
+	router.POST("/matches/:matchId/reveal", matches.RevealMatch)
+	router.POST("/matches/:matchId/interest", matches.MarkInterest)
+	router.POST("/matches/interest/:targetUserId", matches.InterestUser)
+	router.GET("/messages/conversations", messages.GetConversations)
+	router.GET("/messages/unread-count", messages.GetUnreadCount)
+	router.POST("/messages/send/:matchId", messages.SendMessage)
+	router.GET("/crush-list/crushed-by", crushes.GetCrushedBy)
+	router.GET("/crush-list/mutual", crushes.GetMutualCrushes)
+	request := func(user, method, path, body string, want int) gin.H {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User", users[user].String())
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != want {
+			t.Fatalf("%s %s: expected %d, got %d %s", method, path, want, recorder.Code, recorder.Body)
+		}
+		var result gin.H
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode %s: %v", recorder.Body, err)
+		}
+		return result
+	}
+	count := func(result gin.H) int {
+		return int(result["count"].(float64))
+	}
+
+	unread := func(user string) float64 {
+		return request(user, http.MethodGet, "/messages/unread-count", "", http.StatusOK)["data"].(map[string]any)["unreadCount"].(float64)
+	}
+	if got := unread("alice"); got != 2 {
+		t.Fatalf("expected 2 unread messages, got %v", got)
+	}
+
+	request("alice", http.MethodPost, "/users/blocks/"+users["alice"].String(), "", http.StatusBadRequest)
+	request("alice", http.MethodPost, "/users/blocks/"+users["bob"].String(), `{"reason": "rude"}`, http.StatusOK)
+	request("dave", http.MethodPost, "/users/blocks/"+users["alice"].String(), "", http.StatusOK)
+	if listed := request("alice", http.MethodGet, "/users/blocks", "", http.StatusOK); count(listed) != 1 {
+		t.Fatalf("expected alice to list one block, got %v", listed)
+	}
+
+	// The block hides the pair from both sides, whoever placed it.
+	if result := request("alice", http.MethodGet, "/matches", "", http.StatusOK); count(result) != 1 {
+		t.Fatalf("expected alice to see only carol's match, got %v", result)
+	}
+	if result := request("bob", http.MethodGet, "/matches", "", http.StatusOK); count(result) != 0 {
+		t.Fatalf("expected bob to lose the match, got %v", result)
+	}
+	conversations := request("alice", http.MethodGet, "/messages/conversations", "", http.StatusOK)
+	if count(conversations) != 1 || conversations["data"].([]any)[0].(map[string]any)["otherUser"].(map[string]any)["id"] != users["carol"].String() {
+		t.Fatalf("expected alice to see only carol's conversation, got %v", conversations)
+	}
+	if got := unread("alice"); got != 1 {
+		t.Fatalf("expected bob's message to stop counting as unread, got %v", got)
+	}
+	request("bob", http.MethodPost, "/messages/send/"+bobMatch.ID.String(), `{"content": "hello?"}`, http.StatusForbidden)
+	potential := request("alice", http.MethodGet, "/matches/potential", "", http.StatusOK)
+	if count(potential) != 1 || potential["data"].([]any)[0].(map[string]any)["id"] != users["erin"].String() {
+		t.Fatalf("expected only erin as a potential match, got %v", potential)
+	}
+
+	request("bob", http.MethodPost, "/matches/"+bobMatch.ID.String()+"/interest", `{"interested": true}`, http.StatusNotFound)
+	request("bob", http.MethodPost, "/matches/"+bobMatch.ID.String()+"/reveal", "", http.StatusNotFound)
+	request("alice", http.MethodPost, "/matches/interest/"+users["dave"].String(), "", http.StatusNotFound)
+
+	// Only the blocker can lift a block.
+	request("bob", http.MethodDelete, "/users/blocks/"+users["alice"].String(), "", http.StatusNotFound)
+	request("alice", http.MethodDelete, "/users/blocks/"+users["bob"].String(), "", http.StatusOK)
+	request("bob", http.MethodPost, "/messages/send/"+bobMatch.ID.String(), `{"content": "hello?"}`, http.StatusCreated)
+
+	surveyOpen, surveyClose, profileStart, profileEnd, results := campaignDates(false)
+	campaign, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Spring",
+		SurveyOpenDate:         surveyOpen,
+		SurveyCloseDate:        surveyClose,
+		ProfileUpdateStartDate: profileStart,
+		ProfileUpdateEndDate:   profileEnd,
+		ResultsReleaseDate:     results,
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	for _, admirer := range []string{"dave", "erin"} {
+		if _, err := store.CreateCrush(ctx, repository.CreateCrushParams{
+			UserID:     users[admirer],
+			CampaignID: campaign.ID,
+			CrushEmail: "alice@example.com",
+		}); err != nil {
+			t.Fatalf("create crush: %v", err)
+		}
+	}
+	crushedBy := request("alice", http.MethodGet, "/crush-list/crushed-by", "", http.StatusOK)
+	if data := crushedBy["data"].(map[string]any); data["count"].(float64) != 1 {
+		t.Fatalf("expected dave's crush to be hidden, got %v", data)
+	}
+	for _, crush := range []string{"dave", "erin"} {
+		if _, err := store.CreateCrush(ctx, repository.CreateCrushParams{
+			UserID:     users["alice"],
+			CampaignID: campaign.ID,
+			CrushEmail: crush + "@example.com",
+			IsMutual:   true,
+		}); err != nil {
+			t.Fatalf("create crush: %v", err)
+		}
+	}
+	if mutual := request("alice", http.MethodGet, "/crush-list/mutual", "", http.StatusOK); count(mutual) != 1 {
+		t.Fatalf("expected the mutual crush with dave to be hidden, got %v", mutual)
+	}
+}
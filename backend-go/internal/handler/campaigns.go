@@ -10,22 +10,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
-type CampaignHandler struct{}
+type CampaignHandler struct {
+	store repository.Querier
+}
 
-func NewCampaignHandler() *CampaignHandler {
-	return &CampaignHandler{}
+func NewCampaignHandler(store repository.Querier) *CampaignHandler {
+	return &CampaignHandler{store: store}
 }
 
 func (h *CampaignHandler) GetActiveCampaign(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "No active campaign found")
 		return
@@ -60,13 +57,7 @@ func (h *CampaignHandler) GetCampaignById(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetCampaignByID(c, campaignUUID)
+	campaign, err := h.store.GetCampaignByID(c, campaignUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Campaign not found")
 		return
@@ -101,21 +92,15 @@ func (h *CampaignHandler) GetCampaignStats(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetCampaignByID(c, campaignUUID)
+	campaign, err := h.store.GetCampaignByID(c, campaignUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Campaign not found")
 		return
 	}
 
-	participants, _ := store.CountParticipantsByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
-	matches, _ := store.CountMatchesByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
-	completed, _ := store.CountCompletedSurveysByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
+	participants, _ := h.store.CountParticipantsByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
+	matches, _ := h.store.CountMatchesByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
+	completed, _ := h.store.CountCompletedSurveysByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -149,12 +134,6 @@ func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	params, err := buildCampaignParams(req)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
@@ -173,7 +152,7 @@ func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
 		AlgorithmVersion:       params.AlgorithmVersion,
 	}
 
-	campaign, err := store.CreateCampaign(c, createParams)
+	campaign, err := h.store.CreateCampaign(c, createParams)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to create campaign")
 		return
@@ -199,12 +178,6 @@ func (h *CampaignHandler) UpdateCampaign(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	params, err := buildCampaignParams(req)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
@@ -212,7 +185,7 @@ func (h *CampaignHandler) UpdateCampaign(c *gin.Context) {
 	}
 	params.ID = campaignUUID
 
-	updated, err := store.UpdateCampaign(c, params)
+	updated, err := h.store.UpdateCampaign(c, params)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to update campaign")
 		return
@@ -232,13 +205,7 @@ func (h *CampaignHandler) DeleteCampaign(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	if err := store.DeleteCampaign(c, campaignUUID); err != nil {
+	if err := h.store.DeleteCampaign(c, campaignUUID); err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to delete campaign")
 		return
 	}
@@ -250,13 +217,7 @@ func (h *CampaignHandler) DeleteCampaign(c *gin.Context) {
 }
 
 func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaigns, err := store.ListCampaigns(c)
+	campaigns, err := h.store.ListCampaigns(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load campaigns")
 		return
@@ -272,13 +233,7 @@ func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
 func (h *CampaignHandler) CheckActionAllowed(c *gin.Context) {
 	action := c.Param("action")
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondJSON(c, http.StatusOK, gin.H{
 			"success": true,
@@ -290,7 +245,15 @@ func (h *CampaignHandler) CheckActionAllowed(c *gin.Context) {
 		return
 	}
 
-	allowed := actionAllowed(campaignPhase(campaign), action)
+	allowed := service.ActionAllowed(campaignPhase(campaign), action)
+	if action == "send_messages" {
+		rules, err := loadMessagingRules(c, h.store, pgtype.UUID{Bytes: campaign.ID, Valid: true})
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load messaging policy")
+			return
+		}
+		allowed = rules.open()
+	}
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -340,6 +303,14 @@ func buildCampaignParams(req createCampaignRequest) (repository.UpdateCampaignPa
 	if len(config) == 0 {
 		config = nil
 	}
+	if config != nil {
+		if _, err := service.ParseMatchingConfig(config); err != nil {
+			return repository.UpdateCampaignParams{}, err
+		}
+		if _, err := service.ParseMessagingPolicy(config); err != nil {
+			return repository.UpdateCampaignParams{}, err
+		}
+	}
 
 	params := repository.UpdateCampaignParams{
 		Name:                   req.Name,
@@ -360,61 +331,7 @@ func buildCampaignParams(req createCampaignRequest) (repository.UpdateCampaignPa
 }
 
 func campaignPhase(campaign repository.Campaign) string {
-	now := time.Now()
-	if now.Before(campaign.SurveyOpenDate) {
-		return "pre_launch"
-	}
-	if now.Before(campaign.SurveyCloseDate) {
-		return "survey_open"
-	}
-	if now.Before(campaign.ProfileUpdateStartDate) {
-		return "survey_closed"
-	}
-	if now.Before(campaign.ProfileUpdateEndDate) {
-		return "profile_update"
-	}
-	return "results_released"
-}
-
-func actionAllowed(phase string, action string) bool {
-	permissions := map[string]map[string]bool{
-		"view_landing": {
-			"pre_launch":       true,
-			"survey_open":      true,
-			"survey_closed":    true,
-			"profile_update":   true,
-			"results_released": true,
-		},
-		"sign_up": {
-			"survey_open": true,
-		},
-		"take_survey": {
-			"survey_open": true,
-		},
-		"edit_survey": {
-			"survey_open": true,
-		},
-		"submit_crush_list": {
-			"survey_open": true,
-		},
-		"edit_profile": {
-			"survey_open":    true,
-			"profile_update": true,
-		},
-		"view_matches": {
-			"profile_update":   true,
-			"results_released": true,
-		},
-		"send_messages": {
-			"profile_update":   true,
-			"results_released": true,
-		},
-	}
-
-	if phasePermissions, ok := permissions[action]; ok {
-		return phasePermissions[phase]
-	}
-	return false
+	return service.CampaignPhase(campaign, time.Now())
 }
 
 func campaignTimeRemaining(campaign repository.Campaign, phase string) (int64, string) {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -22,3 +23,18 @@ func TestCampaignPhaseTransitions(t *testing.T) {
 		t.Fatalf("expected survey_closed, got %s", phase)
 	}
 }
+
+func TestBuildCampaignParamsValidatesConfig(t *testing.T) {
+	req := createCampaignRequest{
+		Name:   "Spring",
+		Config: json.RawMessage(`{"matching": {"weights": {"personality": 0.6, "values": 0.6}}}`),
+	}
+	if _, err := buildCampaignParams(req); err == nil {
+		t.Fatalf("expected weights that do not sum to 1 to be rejected")
+	}
+
+	req.Config = json.RawMessage(`{"matching": {"weights": {"personality": 0.6, "values": 0.4}}}`)
+	if _, err := buildCampaignParams(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -17,6 +17,18 @@ func getUserID(c *gin.Context) (string, bool) {
 	return userID, ok
 }
 
+func currentUserUUID(c *gin.Context) pgtype.UUID {
+	userID, ok := getUserID(c)
+	if !ok {
+		return pgtype.UUID{}
+	}
+	parsed, err := uuid.Parse(userID)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
+
 func getUserEmail(c *gin.Context) (string, bool) {
 	value, exists := c.Get("userEmail")
 	if !exists {
@@ -9,13 +9,17 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"wizardmatch-backend/internal/realtime"
 	"wizardmatch-backend/internal/repository"
 )
 
-type CrushHandler struct{}
+type CrushHandler struct {
+	store  repository.Querier
+	events realtime.Publisher
+}
 
-func NewCrushHandler() *CrushHandler {
-	return &CrushHandler{}
+func NewCrushHandler(store repository.Querier, events realtime.Publisher) *CrushHandler {
+	return &CrushHandler{store: store, events: events}
 }
 
 type crushEntry struct {
@@ -50,19 +54,13 @@ func (h *CrushHandler) SubmitCrushList(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "No active campaign: "+err.Error())
 		return
 	}
 
-	if err := store.DeleteCrushesForUserCampaign(c, repository.DeleteCrushesForUserCampaignParams{
+	if err := h.store.DeleteCrushesForUserCampaign(c, repository.DeleteCrushesForUserCampaignParams{
 		UserID:     userUUID,
 		CampaignID: campaign.ID,
 	}); err != nil {
@@ -70,6 +68,18 @@ func (h *CrushHandler) SubmitCrushList(c *gin.Context) {
 		return
 	}
 
+	// A crush is mutual when the person named has the user on their own list.
+	admirers := map[uuid.UUID]repository.CrushList{}
+	if user, err := h.store.GetUserByID(c, userUUID); err == nil {
+		entries, _ := h.store.ListCrushesByEmailCampaign(c, repository.ListCrushesByEmailCampaignParams{
+			CrushEmail: strings.ToLower(user.Email),
+			CampaignID: campaign.ID,
+		})
+		for _, entry := range entries {
+			admirers[entry.UserID] = entry
+		}
+	}
+
 	mutualCount := 0
 	created := make([]repository.CrushList, 0, len(req.Crushes))
 	for _, crush := range req.Crushes {
@@ -79,15 +89,9 @@ func (h *CrushHandler) SubmitCrushList(c *gin.Context) {
 		}
 
 		isMutual := false
-		mutualCrushes, _ := store.ListCrushesByEmailCampaign(c, repository.ListCrushesByEmailCampaignParams{
-			CrushEmail: email,
-			CampaignID: campaign.ID,
-		})
-		for _, entry := range mutualCrushes {
-			if entry.UserID != userUUID {
-				isMutual = true
-				break
-			}
+		var admirer repository.CrushList
+		if crushUser, err := h.store.GetUserByEmail(c, email); err == nil && crushUser.ID != userUUID {
+			admirer, isMutual = admirers[crushUser.ID]
 		}
 
 		crushName := crush.Name
@@ -95,7 +99,7 @@ func (h *CrushHandler) SubmitCrushList(c *gin.Context) {
 			mutualCount++
 		}
 
-		createdCrush, err := store.CreateCrush(c, repository.CreateCrushParams{
+		createdCrush, err := h.store.CreateCrush(c, repository.CreateCrushParams{
 			UserID:     userUUID,
 			CampaignID: campaign.ID,
 			CrushEmail: email,
@@ -104,8 +108,19 @@ func (h *CrushHandler) SubmitCrushList(c *gin.Context) {
 			IsMutual:   isMutual,
 			NudgeSent:  false,
 		})
-		if err == nil {
-			created = append(created, createdCrush)
+		if err != nil {
+			continue
+		}
+		created = append(created, createdCrush)
+		if isMutual {
+			if !admirer.IsMutual {
+				_ = h.store.MarkCrushMutual(c, admirer.ID)
+			}
+			// Identities stay hidden until results are released, so the
+			// event only says that the campaign has a new mutual crush.
+			event := realtime.NewEvent(realtime.EventCrushMutual, gin.H{"campaignId": campaign.ID}, userUUID, admirer.UserID)
+			event.Key = "crush:" + campaign.ID.String() + ":" + pairKeyString(userUUID, admirer.UserID)
+			publishEvent(c, h.events, event)
 		}
 	}
 
@@ -139,19 +154,13 @@ func (h *CrushHandler) GetCrushList(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "No active campaign: "+err.Error())
 		return
 	}
 
-	crushes, err := store.ListCrushesForUserCampaign(c, repository.ListCrushesForUserCampaignParams{
+	crushes, err := h.store.ListCrushesForUserCampaign(c, repository.ListCrushesForUserCampaignParams{
 		UserID:     userUUID,
 		CampaignID: campaign.ID,
 	})
@@ -184,19 +193,13 @@ func (h *CrushHandler) GetMutualCrushes(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "No active campaign")
 		return
 	}
 
-	crushes, err := store.ListCrushesForUserCampaign(c, repository.ListCrushesForUserCampaignParams{
+	crushes, err := h.store.ListCrushesForUserCampaign(c, repository.ListCrushesForUserCampaignParams{
 		UserID:     userUUID,
 		CampaignID: campaign.ID,
 	})
@@ -205,11 +208,22 @@ func (h *CrushHandler) GetMutualCrushes(c *gin.Context) {
 		return
 	}
 
+	blocked, err := blockedUsers(c, h.store, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load mutual crushes")
+		return
+	}
 	mutual := make([]repository.CrushList, 0)
 	for _, entry := range crushes {
-		if entry.IsMutual {
-			mutual = append(mutual, entry)
+		if !entry.IsMutual {
+			continue
 		}
+		if len(blocked) > 0 {
+			if crushUser, err := h.store.GetUserByEmail(c, entry.CrushEmail); err == nil && blocked[crushUser.ID] {
+				continue
+			}
+		}
+		mutual = append(mutual, entry)
 	}
 
 	respondJSON(c, http.StatusOK, gin.H{
@@ -232,19 +246,13 @@ func (h *CrushHandler) GetCrushedBy(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	campaign, err := store.GetActiveCampaign(c)
+	campaign, err := h.store.GetActiveCampaign(c)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "No active campaign")
 		return
 	}
 
-	user, err := store.GetUserByID(c, userUUID)
+	user, err := h.store.GetUserByID(c, userUUID)
 	if err != nil {
 		respondJSON(c, http.StatusOK, gin.H{
 			"success": true,
@@ -253,10 +261,21 @@ func (h *CrushHandler) GetCrushedBy(c *gin.Context) {
 		return
 	}
 
-	crushedBy, _ := store.ListCrushesByEmailCampaign(c, repository.ListCrushesByEmailCampaignParams{
+	crushes, _ := h.store.ListCrushesByEmailCampaign(c, repository.ListCrushesByEmailCampaignParams{
 		CrushEmail: user.Email,
 		CampaignID: campaign.ID,
 	})
+	blocked, err := blockedUsers(c, h.store, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load crushes")
+		return
+	}
+	crushedBy := make([]repository.CrushList, 0, len(crushes))
+	for _, crush := range crushes {
+		if !blocked[crush.UserID] {
+			crushedBy = append(crushedBy, crush)
+		}
+	}
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -276,13 +295,7 @@ func (h *CrushHandler) GetCampaignCrushes(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	crushes, err := store.ListCrushesForCampaign(c, campaignUUID)
+	crushes, err := h.store.ListCrushesForCampaign(c, campaignUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load campaign crushes")
 		return
@@ -295,6 +308,14 @@ func (h *CrushHandler) GetCampaignCrushes(c *gin.Context) {
 	})
 }
 
+// pairKeyString names a pair of users the same whichever way round.
+func pairKeyString(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
 func pluralize(count int) string {
 	if count == 1 {
 		return ""
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestCrushListFindsMutualCrushes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	surveyOpen, surveyClose, profileStart, profileEnd, results := campaignDates(false)
+	campaign, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Spring",
+		SurveyOpenDate:         surveyOpen,
+		SurveyCloseDate:        surveyClose,
+		ProfileUpdateStartDate: profileStart,
+		ProfileUpdateEndDate:   profileEnd,
+		ResultsReleaseDate:     results,
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	users := map[string]uuid.UUID{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: name + "@example.com", IsActive: true})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users[name] = user.ID
+	}
+
+	hub := realtime.NewHub()
+	alice, carol := hub.Subscribe(users["alice"]), hub.Subscribe(users["carol"])
+	crushes := NewCrushHandler(store, realtime.NewRecorder(store, hub))
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.Query("user")) })
+	router.POST("/crush-list", crushes.SubmitCrushList)
+	submit := func(from, to string) string {
+		recorder := httptest.NewRecorder()
+		body := `{"crushes": [{"email": "` + strings.ToUpper(to) + `@example.com"}]}`
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/crush-list?user="+users[from].String(), strings.NewReader(body)))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("submit: %d %s", recorder.Code, recorder.Body)
+		}
+		return recorder.Body.String()
+	}
+
+	// Sharing a crush with someone does not make it mutual.
+	submit("alice", "bob")
+	if body := submit("carol", "bob"); !strings.Contains(body, `"mutualCount":0`) {
+		t.Fatalf("expected no mutual crush, got %s", body)
+	}
+	if body := submit("bob", "alice"); !strings.Contains(body, `"mutualCount":1`) {
+		t.Fatalf("expected a mutual crush, got %s", body)
+	}
+	// Resubmitting does not announce the same pair again.
+	submit("bob", "alice")
+
+	aliceList, err := store.ListCrushesForUserCampaign(ctx, repository.ListCrushesForUserCampaignParams{
+		UserID:     users["alice"],
+		CampaignID: campaign.ID,
+	})
+	if err != nil || len(aliceList) != 1 || !aliceList[0].IsMutual {
+		t.Fatalf("expected alice's crush flagged mutual, got %+v (%v)", aliceList, err)
+	}
+	if event := <-alice.Events(); event.Type != realtime.EventCrushMutual || strings.Contains(string(event.Data), "example.com") {
+		t.Fatalf("expected an anonymous mutual crush event, got %+v", event)
+	}
+	select {
+	case event := <-alice.Events():
+		t.Fatalf("expected one announcement, got %+v", event)
+	case event := <-carol.Events():
+		t.Fatalf("expected nothing for carol, got %+v", event)
+	default:
+	}
+}
@@ -1,5 +1,11 @@
 package handler
 
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
 type appError struct {
 	message string
 	status  int
@@ -8,3 +14,8 @@ type appError struct {
 func (e appError) Error() string {
 	return e.message
 }
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+)
+
+const (
+	sseHeartbeat  = 25 * time.Second
+	sseReplayPage = 100
+)
+
+// EventStreamHandler streams the user's events as Server-Sent Events, for
+// clients on networks that block WebSockets. Events carry the ID of their
+// entry in the event log, so a client reconnecting with Last-Event-ID gets
+// whatever it missed in between.
+type EventStreamHandler struct {
+	store repository.Querier
+	hub   *realtime.Hub
+}
+
+func NewEventStreamHandler(store repository.Querier, hub *realtime.Hub) *EventStreamHandler {
+	return &EventStreamHandler{store: store, hub: hub}
+}
+
+// Stream replays the events logged after Last-Event-ID (or the lastEventId
+// query parameter, for clients that cannot set headers) and then streams
+// new ones until the client goes away. Without either, the stream starts
+// with a ready event whose ID marks where it picked up.
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var after int64
+	if lastEventID != "" {
+		after, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || after < 0 {
+			respondError(c, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	// Subscribing before reading the log means nothing raised in between
+	// is missed; the replay and the subscription may overlap instead, and
+	// events already replayed are skipped.
+	sub := h.hub.Subscribe(userUUID)
+	defer sub.Close()
+
+	resumed := lastEventID != ""
+	if !resumed {
+		after, err = h.store.LatestUserEventID(c)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to open event stream")
+			return
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !resumed {
+		if err := writeServerEvent(c.Writer, after, "ready", []byte("{}")); err != nil {
+			return
+		}
+	}
+	replayed, err := h.replay(c, userUUID, after)
+	if err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if event.ID != 0 && event.ID <= replayed {
+				continue
+			}
+			if event.Truncated && event.ID != 0 {
+				h.restoreData(c, userUUID, &event)
+			}
+			if err := writeServerEvent(c.Writer, event.ID, event.Type, event.Data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// replay writes the user's events logged after the given ID and returns the
+// ID of the last one written.
+func (h *EventStreamHandler) replay(c *gin.Context, userID uuid.UUID, after int64) (int64, error) {
+	for {
+		events, err := h.store.ListUserEventsAfter(c, repository.ListUserEventsAfterParams{
+			UserID: pgtype.UUID{Bytes: userID, Valid: true},
+			ID:     after,
+			Limit:  sseReplayPage,
+		})
+		if err != nil {
+			return after, err
+		}
+		for _, event := range events {
+			if err := writeServerEvent(c.Writer, event.ID, event.EventType, event.Payload); err != nil {
+				return after, err
+			}
+			after = event.ID
+		}
+		if len(events) < sseReplayPage {
+			return after, nil
+		}
+	}
+}
+
+// restoreData fills in the data of an event that was too large to publish
+// from its entry in the log.
+func (h *EventStreamHandler) restoreData(c *gin.Context, userID uuid.UUID, event *realtime.Event) {
+	logged, err := h.store.ListUserEventsAfter(c, repository.ListUserEventsAfterParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		ID:     event.ID - 1,
+		Limit:  1,
+	})
+	if err == nil && len(logged) == 1 && logged[0].ID == event.ID {
+		event.Data = logged[0].Payload
+		event.Truncated = false
+	}
+}
+
+// writeServerEvent writes one event in the text/event-stream format. Events
+// the log never took have no ID, and leave the client's last ID as it was.
+func writeServerEvent(w io.Writer, id int64, eventType string, data []byte) error {
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	frame := ""
+	if id != 0 {
+		frame = "id: " + strconv.FormatInt(id, 10) + "\n"
+	}
+	_, err := fmt.Fprintf(w, "%sevent: %s\ndata: %s\n\n", frame, eventType, data)
+	return err
+}
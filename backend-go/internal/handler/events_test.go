@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+// readServerEvent reads the next event off a text/event-stream, skipping
+// comments, and returns its fields.
+func readServerEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func TestEventStreamResumesFromLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	var users []uuid.UUID
+	for i := 0; i < 2; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: fmt.Sprintf("user%d@example.com", i), IsActive: true})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	hub := realtime.NewHub()
+	events := realtime.NewRecorder(store, hub)
+	for _, content := range []string{"first", "second"} {
+		if err := events.Publish(ctx, realtime.NewEvent(realtime.EventMessageCreated, map[string]string{"content": content}, users[0])); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	if err := events.Publish(ctx, realtime.NewEvent(realtime.EventMessageCreated, map[string]string{"content": "not yours"}, users[1])); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.Query("user")) })
+	router.GET("/events", NewEventStreamHandler(store, hub).Stream)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	open := func(lastEventID string) (*bufio.Reader, func()) {
+		streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		request, _ := http.NewRequestWithContext(streamCtx, http.MethodGet, server.URL+"/events?user="+users[0].String(), nil)
+		if lastEventID != "" {
+			request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("expected an event stream, got %d %s", response.StatusCode, response.Header.Get("Content-Type"))
+		}
+		return bufio.NewReader(response.Body), func() {
+			cancel()
+			response.Body.Close()
+		}
+	}
+
+	// A client that saw the first event gets the second replayed, then live
+	// events, and never another user's.
+	stream, done := open("1")
+	defer done()
+	replayed := readServerEvent(t, stream)
+	if replayed["id"] != "2" || replayed["event"] != realtime.EventMessageCreated || replayed["data"] != `{"content":"second"}` {
+		t.Fatalf("expected the second message replayed, got %v", replayed)
+	}
+	if err := events.Publish(ctx, realtime.NewEvent(realtime.EventMatchCreated, map[string]string{"matchId": "m"}, users[0])); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	live := readServerEvent(t, stream)
+	if live["id"] != "4" || live["event"] != realtime.EventMatchCreated {
+		t.Fatalf("expected the new match live, got %v", live)
+	}
+
+	// A client connecting afresh starts from the end of the log.
+	fresh, doneFresh := open("")
+	defer doneFresh()
+	if ready := readServerEvent(t, fresh); ready["event"] != "ready" || ready["id"] != "4" {
+		t.Fatalf("expected a ready event at the end of the log, got %v", ready)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events?user="+users[0].String()+"&lastEventId=abc", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected a malformed Last-Event-ID to be refused, got %d", recorder.Code)
+	}
+}
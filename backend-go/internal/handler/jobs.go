@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/jobs"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+type generateMatchesJobRequest struct {
+	CampaignID string `json:"campaignId"`
+	Publish    *bool  `json:"publish"`
+}
+
+type matchNewUsersJobRequest struct {
+	CampaignID string   `json:"campaignId"`
+	UserIDs    []string `json:"userIds"`
+}
+
+func jobPayload(job repository.Job) gin.H {
+	return gin.H{
+		"id":              job.ID,
+		"type":            job.JobType,
+		"status":          job.Status,
+		"payload":         jsonRaw(job.Payload),
+		"progress":        jsonRaw(job.Progress),
+		"errorMessage":    textValue(job.ErrorMessage),
+		"attempts":        job.Attempts,
+		"maxAttempts":     job.MaxAttempts,
+		"cancelRequested": job.CancelRequested,
+		"createdBy":       uuidValue(job.CreatedBy),
+		"createdAt":       job.CreatedAt,
+		"startedAt":       job.StartedAt,
+		"finishedAt":      job.FinishedAt,
+		"updatedAt":       job.UpdatedAt,
+	}
+}
+
+func (h *AdminHandler) StartGenerateMatchesJob(c *gin.Context) {
+	var req generateMatchesJobRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid request")
+			return
+		}
+	}
+
+	var campaignID uuid.UUID
+	if req.CampaignID != "" {
+		parsed, err := uuid.Parse(req.CampaignID)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid campaign ID")
+			return
+		}
+		campaignID = parsed
+	} else {
+		resolved, ok := matchRunCampaign(c, h.store)
+		if !ok {
+			return
+		}
+		campaignID = resolved
+	}
+
+	publish := c.Query("publish") != "false"
+	if req.Publish != nil {
+		publish = *req.Publish
+	}
+	payload := service.GenerateMatchesPayload{CampaignID: campaignID, Publish: publish}
+
+	job, err := queueGenerateMatches(c, h.store, payload, currentUserUUID(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to queue match generation")
+		return
+	}
+
+	respondJSON(c, http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    jobPayload(job),
+		"message": "Match generation queued",
+	})
+}
+
+// StartMatchNewUsersJob queues incremental matching for users who completed
+// the survey after the campaign's matches were generated.
+func (h *AdminHandler) StartMatchNewUsersJob(c *gin.Context) {
+	var req matchNewUsersJobRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid request")
+			return
+		}
+	}
+
+	var campaignID uuid.UUID
+	if req.CampaignID != "" {
+		parsed, err := uuid.Parse(req.CampaignID)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid campaign ID")
+			return
+		}
+		campaignID = parsed
+	} else {
+		resolved, ok := matchRunCampaign(c, h.store)
+		if !ok {
+			return
+		}
+		campaignID = resolved
+	}
+
+	payload := service.MatchNewUsersPayload{CampaignID: campaignID}
+	for _, raw := range req.UserIDs {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		payload.UserIDs = append(payload.UserIDs, userID)
+	}
+
+	job, err := queueMatchNewUsers(c, h.store, payload, currentUserUUID(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to queue incremental matching")
+		return
+	}
+
+	respondJSON(c, http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    jobPayload(job),
+		"message": "Incremental matching queued",
+	})
+}
+
+// queueLateMatching matches a user who finishes the survey after the active
+// campaign's matches were published. It is best effort: the survey is
+// complete either way and admins can rerun incremental matching.
+func queueLateMatching(c *gin.Context, store repository.Querier, userID uuid.UUID) {
+	active, err := store.GetActiveCampaign(c)
+	if err != nil {
+		return
+	}
+	if _, err := store.GetPublishedMatchRun(c, active.ID); err != nil {
+		return
+	}
+	_, _ = queueMatchNewUsers(c, store, service.MatchNewUsersPayload{
+		CampaignID: active.ID,
+		UserIDs:    []uuid.UUID{userID},
+	}, pgtype.UUID{Bytes: userID, Valid: true})
+}
+
+func queueGenerateMatches(c *gin.Context, store repository.Querier, payload service.GenerateMatchesPayload, createdBy pgtype.UUID) (repository.Job, error) {
+	raw, _ := json.Marshal(payload)
+	return store.CreateJob(c, repository.CreateJobParams{
+		JobType:     service.JobGenerateMatches,
+		Payload:     raw,
+		MaxAttempts: 3,
+		CreatedBy:   createdBy,
+	})
+}
+
+func queueMatchNewUsers(c *gin.Context, store repository.Querier, payload service.MatchNewUsersPayload, createdBy pgtype.UUID) (repository.Job, error) {
+	raw, _ := json.Marshal(payload)
+	return store.CreateJob(c, repository.CreateJobParams{
+		JobType:     service.JobMatchNewUsers,
+		Payload:     raw,
+		MaxAttempts: 3,
+		CreatedBy:   createdBy,
+	})
+}
+
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	list, err := h.store.ListJobs(c, repository.ListJobsParams{
+		JobType: c.DefaultQuery("type", service.JobGenerateMatches),
+		Limit:   int32(limit),
+		Offset:  int32((page - 1) * limit),
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load jobs")
+		return
+	}
+
+	response := make([]gin.H, 0, len(list))
+	for _, job := range list {
+		response = append(response, jobPayload(job))
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+		"count":   len(response),
+	})
+}
+
+func (h *AdminHandler) GetJob(c *gin.Context) {
+	job, ok := loadJob(c, h.store)
+	if !ok {
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    jobPayload(job),
+	})
+}
+
+func (h *AdminHandler) GetJobReport(c *gin.Context) {
+	job, ok := loadJob(c, h.store)
+	if !ok {
+		return
+	}
+	if job.Status != jobs.StatusSucceeded {
+		respondJSON(c, http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Job has not finished successfully",
+			"data":    jobPayload(job),
+		})
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    jsonRaw(job.Result),
+	})
+}
+
+func (h *AdminHandler) CancelJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.store.RequestJobCancel(c, jobID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(c, http.StatusConflict, "Job is not queued or running")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to cancel job")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    jobPayload(job),
+		"message": "Cancellation requested",
+	})
+}
+
+func loadJob(c *gin.Context, store repository.Querier) (repository.Job, bool) {
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid job ID")
+		return repository.Job{}, false
+	}
+
+	job, err := store.GetJobByID(c, jobID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Job not found")
+		return repository.Job{}, false
+	}
+	return job, true
+}
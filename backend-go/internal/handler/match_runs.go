@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+// matchRunCampaign resolves the campaign from the campaignId query parameter,
+// falling back to the active campaign.
+func matchRunCampaign(c *gin.Context, store repository.Querier) (uuid.UUID, bool) {
+	if raw := c.Query("campaignId"); raw != "" {
+		campaignID, err := uuid.Parse(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid campaign ID")
+			return uuid.Nil, false
+		}
+		return campaignID, true
+	}
+
+	active, err := store.GetActiveCampaign(c)
+	if err != nil || active.ID == uuid.Nil {
+		respondError(c, http.StatusBadRequest, "No active campaign found")
+		return uuid.Nil, false
+	}
+	return active.ID, true
+}
+
+func matchRunPayload(run repository.MatchRun) gin.H {
+	return gin.H{
+		"id":               run.ID,
+		"campaignId":       run.CampaignID,
+		"status":           run.Status,
+		"algorithmVersion": run.AlgorithmVersion,
+		"config":           jsonRaw(run.Config),
+		"totalUsers":       run.TotalUsers,
+		"candidatePairs":   run.CandidatePairs,
+		"totalMatches":     run.TotalMatches,
+		"fairness":         jsonRaw(run.Fairness),
+		"errorMessage":     textValue(run.ErrorMessage),
+		"createdBy":        uuidValue(run.CreatedBy),
+		"startedAt":        run.StartedAt,
+		"finishedAt":       run.FinishedAt,
+		"publishedAt":      run.PublishedAt,
+	}
+}
+
+func publishPayload(result service.PublishResult) gin.H {
+	return gin.H{
+		"run":        matchRunPayload(result.Run),
+		"kept":       result.Kept,
+		"added":      result.Added,
+		"removed":    result.Removed,
+		"superseded": result.Superseded,
+	}
+}
+
+func (h *AdminHandler) ListMatchRuns(c *gin.Context) {
+	campaignID, ok := matchRunCampaign(c, h.store)
+	if !ok {
+		return
+	}
+
+	runs, err := h.store.ListMatchRunsByCampaign(c, campaignID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load match runs")
+		return
+	}
+
+	response := make([]gin.H, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, matchRunPayload(run))
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+		"count":   len(response),
+	})
+}
+
+func (h *AdminHandler) GetMatchRun(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("runId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid run ID")
+		return
+	}
+	page, limit := parsePagination(c)
+
+	run, err := h.store.GetMatchRunByID(c, runID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Match run not found")
+		return
+	}
+	results, err := h.store.ListMatchRunResults(c, repository.ListMatchRunResultsParams{
+		RunID:  runID,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load match run results")
+		return
+	}
+	total, _ := h.store.CountMatchRunResults(c, runID)
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"run": matchRunPayload(run), "results": results},
+		"pagination": paginationPayload(page, limit, total),
+	})
+}
+
+// CreateMatchRun queues a generation job that stores a new run without
+// publishing it. The run's ID is in the job's result once it finishes.
+func (h *AdminHandler) CreateMatchRun(c *gin.Context) {
+	campaignID, ok := matchRunCampaign(c, h.store)
+	if !ok {
+		return
+	}
+
+	job, err := queueGenerateMatches(c, h.store, service.GenerateMatchesPayload{CampaignID: campaignID}, currentUserUUID(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to queue match run")
+		return
+	}
+
+	respondJSON(c, http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    jobPayload(job),
+		"message": "Match run queued",
+	})
+}
+
+func (h *AdminHandler) PublishMatchRun(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("runId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid run ID")
+		return
+	}
+
+	result, err := h.matcher.PublishMatchRun(c, runID)
+	if err != nil {
+		respondMatchRunError(c, err, "Failed to publish match run")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    publishPayload(result),
+		"message": "Match run published",
+	})
+}
+
+func (h *AdminHandler) RollbackMatchRun(c *gin.Context) {
+	campaignID, ok := matchRunCampaign(c, h.store)
+	if !ok {
+		return
+	}
+
+	result, err := h.matcher.RollbackMatchRun(c, campaignID)
+	if err != nil {
+		respondMatchRunError(c, err, "Failed to roll back match run")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    publishPayload(result),
+		"message": "Rolled back to the previous match run",
+	})
+}
+
+func respondMatchRunError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		respondError(c, http.StatusNotFound, "Match run not found")
+	case errors.Is(err, service.ErrMatchRunNotPublishable), errors.Is(err, service.ErrNoPreviousMatchRun):
+		respondError(c, http.StatusConflict, err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, fallback)
+	}
+}
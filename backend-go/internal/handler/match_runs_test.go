@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository/memory"
+	"wizardmatch-backend/internal/service"
+)
+
+func TestRespondMatchRunError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{pgx.ErrNoRows, http.StatusNotFound},
+		{fmt.Errorf("publish: %w", service.ErrMatchRunNotPublishable), http.StatusConflict},
+		{service.ErrNoPreviousMatchRun, http.StatusConflict},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		respondMatchRunError(c, tc.err, "failed")
+		if recorder.Code != tc.status {
+			t.Fatalf("expected %d for %v, got %d", tc.status, tc.err, recorder.Code)
+		}
+	}
+}
+
+func TestCreateMatchRunQueuesJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	h := NewAdminHandler(store, nil, nil)
+	router := gin.New()
+	router.POST("/admin/match-runs", h.CreateMatchRun)
+
+	campaignID := uuid.New()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/match-runs?campaignId="+campaignID.String(), nil))
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", recorder.Code, recorder.Body)
+	}
+	var response struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	job, err := store.GetJobByID(context.Background(), response.Data.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	var payload service.GenerateMatchesPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if job.JobType != service.JobGenerateMatches || payload.CampaignID != campaignID || payload.Publish {
+		t.Fatalf("expected an unpublished generation job for the campaign, got %s %+v", job.JobType, payload)
+	}
+}
@@ -8,13 +8,19 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"wizardmatch-backend/internal/realtime"
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
-type MatchHandler struct{}
+type MatchHandler struct {
+	store   repository.Querier
+	matcher *service.MatchingService
+	events  realtime.Publisher
+}
 
-func NewMatchHandler() *MatchHandler {
-	return &MatchHandler{}
+func NewMatchHandler(store repository.Querier, matcher *service.MatchingService, events realtime.Publisher) *MatchHandler {
+	return &MatchHandler{store: store, matcher: matcher, events: events}
 }
 
 func (h *MatchHandler) GetMatches(c *gin.Context) {
@@ -30,13 +36,7 @@ func (h *MatchHandler) GetMatches(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	user, err := store.GetUserByID(c, userUUID)
+	user, err := h.store.GetUserByID(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "User not found")
 		return
@@ -46,7 +46,13 @@ func (h *MatchHandler) GetMatches(c *gin.Context) {
 		return
 	}
 
-	matches, err := store.ListMatchesForUser(c, userUUID)
+	matches, err := h.store.ListMatchesForUser(c, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load matches")
+		return
+	}
+
+	blocked, err := blockedUsers(c, h.store, userUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load matches")
 		return
@@ -58,8 +64,11 @@ func (h *MatchHandler) GetMatches(c *gin.Context) {
 		if match.User1ID == userUUID {
 			otherUserID = match.User2ID
 		}
+		if blocked[otherUserID] {
+			continue
+		}
 
-		otherUser, err := store.GetUserByID(c, otherUserID)
+		otherUser, err := h.store.GetUserByID(c, otherUserID)
 		if err != nil {
 			continue
 		}
@@ -116,13 +125,7 @@ func (h *MatchHandler) GetMatchById(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -132,11 +135,17 @@ func (h *MatchHandler) GetMatchById(c *gin.Context) {
 		return
 	}
 
+	// Users who blocked each other no longer see their match.
+	if pairBlocked(c, h.store, match.User1ID, match.User2ID) {
+		respondError(c, http.StatusNotFound, "Match not found")
+		return
+	}
+
 	otherUserID := match.User1ID
 	if match.User1ID == userUUID {
 		otherUserID = match.User2ID
 	}
-	otherUser, err := store.GetUserByID(c, otherUserID)
+	otherUser, err := h.store.GetUserByID(c, otherUserID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load match")
 		return
@@ -147,27 +156,32 @@ func (h *MatchHandler) GetMatchById(c *gin.Context) {
 		_ = json.Unmarshal(match.SharedInterests, &shared)
 	}
 
+	data := gin.H{
+		"id": match.ID,
+		"matchedUser": gin.H{
+			"id":              otherUser.ID,
+			"firstName":       otherUser.FirstName,
+			"lastName":        maskLastName(otherUser.LastName, match.IsRevealed),
+			"program":         textValue(otherUser.Program),
+			"yearLevel":       intValue(otherUser.YearLevel),
+			"profilePhotoUrl": revealText(otherUser.ProfilePhotoUrl, match.IsRevealed),
+			"bio":             revealText(otherUser.Bio, match.IsRevealed),
+			"instagramHandle": revealText(otherUser.InstagramHandle, match.IsRevealed),
+			"facebookProfile": revealText(otherUser.FacebookProfile, match.IsRevealed),
+		},
+		"compatibilityScore": numericFloat(match.CompatibilityScore),
+		"matchTier":          textValue(match.MatchTier),
+		"sharedInterests":    shared,
+		"isRevealed":         match.IsRevealed,
+		"isMutualInterest":   match.IsMutualInterest,
+	}
+	if explanation, err := h.matcher.ExplainMatch(c, match, userUUID); err == nil {
+		data["explanation"] = explanation
+	}
+
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"id": match.ID,
-			"matchedUser": gin.H{
-				"id":              otherUser.ID,
-				"firstName":       otherUser.FirstName,
-				"lastName":        maskLastName(otherUser.LastName, match.IsRevealed),
-				"program":         textValue(otherUser.Program),
-				"yearLevel":       intValue(otherUser.YearLevel),
-				"profilePhotoUrl": revealText(otherUser.ProfilePhotoUrl, match.IsRevealed),
-				"bio":             revealText(otherUser.Bio, match.IsRevealed),
-				"instagramHandle": revealText(otherUser.InstagramHandle, match.IsRevealed),
-				"facebookProfile": revealText(otherUser.FacebookProfile, match.IsRevealed),
-			},
-			"compatibilityScore": numericFloat(match.CompatibilityScore),
-			"matchTier":          textValue(match.MatchTier),
-			"sharedInterests":    shared,
-			"isRevealed":         match.IsRevealed,
-			"isMutualInterest":   match.IsMutualInterest,
-		},
+		"data":    data,
 	})
 }
 
@@ -190,13 +204,7 @@ func (h *MatchHandler) RevealMatch(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -206,11 +214,17 @@ func (h *MatchHandler) RevealMatch(c *gin.Context) {
 		return
 	}
 
-	updated, err := store.RevealMatch(c, matchUUID)
+	if pairBlocked(c, h.store, match.User1ID, match.User2ID) {
+		respondError(c, http.StatusNotFound, "Match not found")
+		return
+	}
+
+	updated, err := h.store.RevealMatch(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to reveal match")
 		return
 	}
+	publish(c, h.events, realtime.EventMatchUpdated, updated, updated.User1ID, updated.User2ID)
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -251,13 +265,7 @@ func (h *MatchHandler) MarkInterest(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -267,7 +275,12 @@ func (h *MatchHandler) MarkInterest(c *gin.Context) {
 		return
 	}
 
-	_, err = store.CreateInteraction(c, repository.CreateInteractionParams{
+	if pairBlocked(c, h.store, match.User1ID, match.User2ID) {
+		respondError(c, http.StatusNotFound, "Match not found")
+		return
+	}
+
+	_, err = h.store.CreateInteraction(c, repository.CreateInteractionParams{
 		MatchID:         matchUUID,
 		UserID:          userUUID,
 		InteractionType: interactionType(req.Interested),
@@ -279,19 +292,21 @@ func (h *MatchHandler) MarkInterest(c *gin.Context) {
 	}
 
 	if req.Interested {
-		other, err := store.FindInterestByMatchOtherUser(c, repository.FindInterestByMatchOtherUserParams{
+		other, err := h.store.FindInterestByMatchOtherUser(c, repository.FindInterestByMatchOtherUserParams{
 			MatchID: matchUUID,
 			UserID:  userUUID,
 		})
 		if err == nil && other.ID != uuid.Nil {
-			_, _ = store.UpdateMatchInterest(c, repository.UpdateMatchInterestParams{
-				ID:                matchUUID,
-				IsMutualInterest:  true,
-				MessagingUnlocked: true,
-			})
+			mutual, err := recordMutualInterest(c, h.store, matchUUID)
+			if err == nil {
+				publishEvent(c, h.events, mutualInterestEvent(mutual))
+			}
 			respondJSON(c, http.StatusOK, gin.H{
 				"success": true,
-				"data":    gin.H{"isMutualInterest": true},
+				"data": gin.H{
+					"isMutualInterest":  true,
+					"messagingUnlocked": mutual.MessagingUnlocked,
+				},
 				"message": "It's a match! You both are interested in each other!",
 			})
 			return
@@ -304,8 +319,10 @@ func (h *MatchHandler) MarkInterest(c *gin.Context) {
 	})
 }
 
+// Block also blocks the other user of the reported match.
 type reportMatchRequest struct {
 	Reason string `json:"reason"`
+	Block  bool   `json:"block"`
 }
 
 func (h *MatchHandler) ReportMatch(c *gin.Context) {
@@ -333,13 +350,7 @@ func (h *MatchHandler) ReportMatch(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -350,7 +361,7 @@ func (h *MatchHandler) ReportMatch(c *gin.Context) {
 	}
 
 	metadata, _ := json.Marshal(map[string]string{"reason": req.Reason})
-	_, err = store.CreateInteraction(c, repository.CreateInteractionParams{
+	_, err = h.store.CreateInteraction(c, repository.CreateInteractionParams{
 		MatchID:         matchUUID,
 		UserID:          userUUID,
 		InteractionType: "report",
@@ -361,8 +372,20 @@ func (h *MatchHandler) ReportMatch(c *gin.Context) {
 		return
 	}
 
+	if req.Block {
+		otherUserID := match.User1ID
+		if match.User1ID == userUUID {
+			otherUserID = match.User2ID
+		}
+		if _, err := blockUser(c, h.store, userUUID, otherUserID, req.Reason); err != nil {
+			respondError(c, http.StatusInternalServerError, "Report submitted, but failed to block user")
+			return
+		}
+	}
+
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
+		"blocked": req.Block,
 		"message": "Report submitted. We will review it shortly.",
 	})
 }
@@ -404,13 +427,18 @@ func (h *MatchHandler) GetPotentialMatches(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
+	cfg := service.DefaultMatchingConfig()
+	if active, err := h.store.GetActiveCampaign(c); err == nil {
+		if parsed, err := service.ParseMatchingConfig(active.Config); err == nil {
+			cfg = parsed
+		}
 	}
 
-	users, err := store.ListPotentialMatches(c, userUUID)
+	users, err := h.store.ListPotentialMatches(c, repository.ListPotentialMatchesParams{
+		UserID:    userUUID,
+		MinAge:    int32(cfg.MinAge),
+		MaxAgeGap: int32(cfg.MaxAgeGap),
+	})
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load potential matches")
 		return
@@ -455,13 +483,7 @@ func (h *MatchHandler) PassUser(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.FindOrCreateMatchForUsers(c, repository.FindOrCreateMatchForUsersParams{
+	match, err := h.store.FindOrCreateMatchForUsers(c, repository.FindOrCreateMatchForUsersParams{
 		Column1: userUUID,
 		Column2: targetUUID,
 	})
@@ -470,7 +492,7 @@ func (h *MatchHandler) PassUser(c *gin.Context) {
 		return
 	}
 
-	_, err = store.CreateInteraction(c, repository.CreateInteractionParams{
+	_, err = h.store.CreateInteraction(c, repository.CreateInteractionParams{
 		MatchID:         match.ID,
 		UserID:          userUUID,
 		InteractionType: "pass",
@@ -506,13 +528,12 @@ func (h *MatchHandler) InterestUser(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	if pairBlocked(c, h.store, userUUID, targetUUID) {
+		respondError(c, http.StatusNotFound, "User not found")
 		return
 	}
 
-	match, err := store.FindOrCreateMatchForUsers(c, repository.FindOrCreateMatchForUsersParams{
+	match, err := h.store.FindOrCreateMatchForUsers(c, repository.FindOrCreateMatchForUsersParams{
 		Column1: userUUID,
 		Column2: targetUUID,
 	})
@@ -521,7 +542,7 @@ func (h *MatchHandler) InterestUser(c *gin.Context) {
 		return
 	}
 
-	_, err = store.CreateInteraction(c, repository.CreateInteractionParams{
+	_, err = h.store.CreateInteraction(c, repository.CreateInteractionParams{
 		MatchID:         match.ID,
 		UserID:          userUUID,
 		InteractionType: "interest",
@@ -532,20 +553,20 @@ func (h *MatchHandler) InterestUser(c *gin.Context) {
 		return
 	}
 
-	other, err := store.FindInterestByMatchOtherUser(c, repository.FindInterestByMatchOtherUserParams{
+	other, err := h.store.FindInterestByMatchOtherUser(c, repository.FindInterestByMatchOtherUserParams{
 		MatchID: match.ID,
 		UserID:  userUUID,
 	})
 	if err == nil && other.ID != uuid.Nil {
-		_, _ = store.UpdateMatchInterest(c, repository.UpdateMatchInterestParams{
-			ID:                match.ID,
-			IsMutualInterest:  true,
-			MessagingUnlocked: true,
-		})
+		mutual, err := recordMutualInterest(c, h.store, match.ID)
+		if err == nil {
+			publishEvent(c, h.events, mutualInterestEvent(mutual))
+		}
 		respondJSON(c, http.StatusOK, gin.H{
-			"success":          true,
-			"isMutualInterest": true,
-			"message":          "It's a match! You both are interested in each other!",
+			"success":           true,
+			"isMutualInterest":  true,
+			"messagingUnlocked": mutual.MessagingUnlocked,
+			"message":           "It's a match! You both are interested in each other!",
 		})
 		return
 	}
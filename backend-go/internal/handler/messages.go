@@ -1,19 +1,35 @@
 package handler
 
 import (
+	"encoding/base64"
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"wizardmatch-backend/internal/realtime"
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
-type MessageHandler struct{}
+// messageEditWindow is how long after sending a message its sender may
+// still edit it. Unsending has no deadline.
+const messageEditWindow = 15 * time.Minute
 
-func NewMessageHandler() *MessageHandler {
-	return &MessageHandler{}
+type MessageHandler struct {
+	store  repository.Querier
+	events realtime.Publisher
+}
+
+func NewMessageHandler(store repository.Querier, events realtime.Publisher) *MessageHandler {
+	return &MessageHandler{store: store, events: events}
 }
 
 type sendMessageRequest struct {
@@ -45,13 +61,7 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -62,8 +72,8 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	if !match.MessagingUnlocked {
-		respondError(c, http.StatusBadRequest, "Messaging is not yet available for this match")
+	match, ok = h.checkMessaging(c, match)
+	if !ok {
 		return
 	}
 
@@ -72,7 +82,7 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		recipient = match.User2ID
 	}
 
-	message, err := store.CreateMessage(c, repository.CreateMessageParams{
+	message, err := h.store.CreateMessage(c, repository.CreateMessageParams{
 		MatchID:     matchUUID,
 		SenderID:    userUUID,
 		RecipientID: recipient,
@@ -82,6 +92,7 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, "Failed to send message")
 		return
 	}
+	publish(c, h.events, realtime.EventMessageCreated, message, message.SenderID, message.RecipientID)
 
 	respondJSON(c, http.StatusCreated, gin.H{
 		"success": true,
@@ -90,6 +101,73 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 	})
 }
 
+// checkMessaging applies the campaign's messaging policy to the match,
+// unlocking it if the policy now allows, and responds with the reason when
+// the match cannot message. Users who blocked each other never can.
+func (h *MessageHandler) checkMessaging(c *gin.Context, match repository.Match) (repository.Match, bool) {
+	blocked, err := h.store.IsPairBlocked(c, repository.IsPairBlockedParams{
+		BlockerID: match.User1ID,
+		BlockedID: match.User2ID,
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check messaging availability")
+		return match, false
+	}
+	if blocked {
+		respondError(c, http.StatusForbidden, "You can no longer message this user")
+		return match, false
+	}
+
+	rules, err := loadMessagingRules(c, h.store, match.CampaignID)
+	if err == nil {
+		match, err = h.syncMessaging(c, match, rules)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check messaging availability")
+		return match, false
+	}
+	if denial := messagingDenial(match, rules); denial != "" {
+		respondError(c, http.StatusBadRequest, denial)
+		return match, false
+	}
+	return match, true
+}
+
+// syncMessaging tells both users when their match has just been unlocked.
+func (h *MessageHandler) syncMessaging(c *gin.Context, match repository.Match, rules messagingRules) (repository.Match, error) {
+	synced, err := syncMessaging(c, h.store, match, rules)
+	if err == nil && synced.MessagingUnlocked && !match.MessagingUnlocked {
+		publish(c, h.events, realtime.EventMatchUpdated, synced, synced.User1ID, synced.User2ID)
+	}
+	return synced, err
+}
+
+// publishReceipts tells the senders, and the reader's other connections,
+// which messages were just read, one event per match.
+func (h *MessageHandler) publishReceipts(c *gin.Context, read []repository.Message) {
+	type receipt struct {
+		MatchID    uuid.UUID          `json:"matchId"`
+		ReaderID   uuid.UUID          `json:"readerId"`
+		MessageIDs []uuid.UUID        `json:"messageIds"`
+		ReadAt     pgtype.Timestamptz `json:"readAt"`
+		senderID   uuid.UUID
+	}
+	var receipts []*receipt
+	byMatch := map[uuid.UUID]*receipt{}
+	for _, msg := range read {
+		r, ok := byMatch[msg.MatchID]
+		if !ok {
+			r = &receipt{MatchID: msg.MatchID, ReaderID: msg.RecipientID, ReadAt: msg.ReadAt, senderID: msg.SenderID}
+			byMatch[msg.MatchID] = r
+			receipts = append(receipts, r)
+		}
+		r.MessageIDs = append(r.MessageIDs, msg.ID)
+	}
+	for _, r := range receipts {
+		publish(c, h.events, realtime.EventMessagesRead, r, r.senderID, r.ReaderID)
+	}
+}
+
 func (h *MessageHandler) GetMessages(c *gin.Context) {
 	userID, ok := getUserID(c)
 	if !ok {
@@ -109,13 +187,7 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	match, err := store.GetMatchByID(c, matchUUID)
+	match, err := h.store.GetMatchByID(c, matchUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Match not found")
 		return
@@ -124,31 +196,83 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		respondError(c, http.StatusForbidden, "You are not part of this match")
 		return
 	}
-	if !match.MessagingUnlocked {
-		respondError(c, http.StatusBadRequest, "Messaging is not yet available for this match")
+	if _, ok := h.checkMessaging(c, match); !ok {
 		return
 	}
 
-	messages, err := store.ListMessagesForMatch(c, matchUUID)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "Failed to load messages")
+	pageSize, ok := messagePageSize(c)
+	if !ok {
+		return
+	}
+	before, after := c.Query("before"), c.Query("after")
+	if before != "" && after != "" {
+		respondError(c, http.StatusBadRequest, "Use either before or after, not both")
 		return
 	}
 
+	// Pages are read one row past pageSize to learn whether there are more.
+	var messages []repository.ListMessagesBeforeRow
+	if after != "" {
+		cursor, err := decodeMessageCursor(after)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		newer, err := h.store.ListMessagesAfter(c, repository.ListMessagesAfterParams{
+			MatchID:     matchUUID,
+			ViewerID:    userUUID,
+			AfterSentAt: cursor.at,
+			AfterID:     cursor.id,
+			PageSize:    int32(pageSize + 1),
+		})
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load messages")
+			return
+		}
+		for _, msg := range newer {
+			messages = append(messages, repository.ListMessagesBeforeRow(msg))
+		}
+	} else {
+		params := repository.ListMessagesBeforeParams{MatchID: matchUUID, ViewerID: userUUID, PageSize: int32(pageSize + 1)}
+		if before != "" {
+			cursor, err := decodeMessageCursor(before)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid cursor")
+				return
+			}
+			params.BeforeSentAt = pgtype.Timestamptz{Time: cursor.at, Valid: true}
+			params.BeforeID = pgtype.UUID{Bytes: cursor.id, Valid: true}
+		}
+		messages, err = h.store.ListMessagesBefore(c, params)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load messages")
+			return
+		}
+	}
+	hasMore := len(messages) > pageSize
+	if hasMore {
+		messages = messages[:pageSize]
+	}
+	if after == "" {
+		// Older pages come newest first; conversations read oldest first.
+		slices.Reverse(messages)
+	}
+
 	formatted := make([]gin.H, 0, len(messages))
 	for _, msg := range messages {
-		sender, _ := store.GetUserByID(c, msg.SenderID)
 		formatted = append(formatted, gin.H{
 			"id":       msg.ID,
 			"content":  msg.Content,
 			"senderId": msg.SenderID,
 			"isRead":   msg.IsRead,
 			"sentAt":   msg.SentAt,
+			"editedAt": msg.EditedAt,
+			"unsent":   msg.UnsentAt.Valid,
 			"sender": gin.H{
-				"id":              sender.ID,
-				"firstName":       sender.FirstName,
-				"lastName":        sender.LastName,
-				"profilePhotoUrl": textValue(sender.ProfilePhotoUrl),
+				"id":              msg.SenderID,
+				"firstName":       msg.SenderFirstName,
+				"lastName":        msg.SenderLastName,
+				"profilePhotoUrl": textValue(msg.SenderProfilePhotoUrl),
 			},
 		})
 	}
@@ -160,16 +284,29 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		}
 	}
 	if len(messageIDs) > 0 {
-		_ = store.MarkMessagesRead(c, repository.MarkMessagesReadParams{
+		read, err := h.store.MarkMessagesRead(c, repository.MarkMessagesReadParams{
 			Column1:     messageIDs,
 			RecipientID: userUUID,
 		})
+		if err == nil {
+			h.publishReceipts(c, read)
+		}
+	}
+
+	// before pages back through older messages and after polls for newer
+	// ones. An empty page hands the client's cursors back unchanged.
+	paging := gin.H{"before": nullableCursor(before), "after": nullableCursor(after), "hasMore": hasMore}
+	if len(messages) > 0 {
+		oldest, newest := messages[0], messages[len(messages)-1]
+		paging["before"] = encodeCursor(oldest.SentAt, oldest.ID)
+		paging["after"] = encodeCursor(newest.SentAt, newest.ID)
 	}
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
 		"data":    formatted,
 		"count":   len(formatted),
+		"paging":  paging,
 	})
 }
 
@@ -196,12 +333,6 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	messageIDs := make([]uuid.UUID, 0, len(req.MessageIds))
 	for _, id := range req.MessageIds {
 		parsed, err := uuid.Parse(id)
@@ -215,41 +346,179 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	if err := store.MarkMessagesRead(c, repository.MarkMessagesReadParams{
+	read, err := h.store.MarkMessagesRead(c, repository.MarkMessagesReadParams{
 		Column1:     messageIDs,
 		RecipientID: userUUID,
-	}); err != nil {
+	})
+	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to mark messages as read")
 		return
 	}
+	h.publishReceipts(c, read)
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
-		"data":    gin.H{"markedCount": len(messageIDs)},
+		"data":    gin.H{"markedCount": len(read)},
 		"message": "Marked messages as read",
 	})
 }
 
-func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
+type editMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// EditMessage rewrites one of the user's own messages within
+// messageEditWindow of sending it. What it said before is kept for
+// moderation.
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	userUUID, message, ok := h.ownMessage(c)
+	if !ok {
+		return
+	}
+
+	var req editMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Content == "" {
+		respondError(c, http.StatusBadRequest, "Message content cannot be empty")
+		return
+	}
+	if message.UnsentAt.Valid {
+		respondError(c, http.StatusBadRequest, "Message has been unsent")
+		return
+	}
+	if time.Since(message.SentAt) > messageEditWindow {
+		respondError(c, http.StatusBadRequest, "Messages can only be edited within "+strconv.Itoa(int(messageEditWindow.Minutes()))+" minutes of sending")
+		return
+	}
+
+	match, err := h.store.GetMatchByID(c, message.MatchID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Match not found")
+		return
+	}
+	if _, ok := h.checkMessaging(c, match); !ok {
+		return
+	}
+
+	edited, err := h.store.EditMessage(c, repository.EditMessageParams{ID: message.ID, Content: req.Content})
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(c, http.StatusBadRequest, "Message has been unsent")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to edit message")
+		return
+	}
+	publish(c, h.events, realtime.EventMessageUpdated, edited, userUUID, edited.RecipientID)
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    edited,
+		"message": "Message edited",
+	})
+}
+
+// UnsendMessage clears one of the user's own messages, leaving a tombstone
+// in its place for both users.
+func (h *MessageHandler) UnsendMessage(c *gin.Context) {
+	userUUID, message, ok := h.ownMessage(c)
+	if !ok {
+		return
+	}
+
+	unsent, err := h.store.UnsendMessage(c, message.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(c, http.StatusBadRequest, "Message has already been unsent")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unsend message")
+		return
+	}
+	publish(c, h.events, realtime.EventMessageUpdated, unsent, userUUID, unsent.RecipientID)
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    unsent,
+		"message": "Message unsent",
+	})
+}
+
+// ownMessage loads the message named in the path, responding with an error
+// unless the user sent it.
+func (h *MessageHandler) ownMessage(c *gin.Context) (uuid.UUID, repository.Message, bool) {
 	userID, ok := getUserID(c)
 	if !ok {
 		unauthorized(c)
-		return
+		return uuid.Nil, repository.Message{}, false
 	}
 
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, repository.Message{}, false
+	}
+
+	messageUUID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid message ID")
+		return uuid.Nil, repository.Message{}, false
+	}
+
+	message, err := h.store.GetMessageByID(c, messageUUID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Message not found")
+		return uuid.Nil, repository.Message{}, false
+	}
+	if message.SenderID != userUUID {
+		respondError(c, http.StatusForbidden, "You can only change your own messages")
+		return uuid.Nil, repository.Message{}, false
+	}
+	return userUUID, message, true
+}
+
+// GetMessageEdits shows moderators a message as it is now along with
+// everything it said before, including content its sender unsent.
+func (h *MessageHandler) GetMessageEdits(c *gin.Context) {
+	messageUUID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	message, err := h.store.GetMessageByID(c, messageUUID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Message not found")
+		return
+	}
+	edits, err := h.store.ListMessageEdits(c, messageUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load message history")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"message": message,
+			"edits":   edits,
+		},
+	})
+}
+
+func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
-	count, err := store.CountUnreadMessages(c, userUUID)
+	count, err := h.store.CountUnreadMessages(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to get unread count")
 		return
@@ -274,87 +543,216 @@ func (h *MessageHandler) GetConversations(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	pageSize, ok := messagePageSize(c)
+	if !ok {
 		return
 	}
+	params := repository.ListConversationsPageParams{UserID: userUUID, PageSize: int32(pageSize + 1)}
+	before := c.Query("before")
+	if before != "" {
+		cursor, err := decodeMessageCursor(before)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		params.BeforeSentAt = pgtype.Timestamptz{Time: cursor.at, Valid: true}
+		params.BeforeMatchID = pgtype.UUID{Bytes: cursor.id, Valid: true}
+	}
 
-	conversations, err := store.ListConversationsForUser(c, userUUID)
+	conversations, err := h.store.ListConversationsPage(c, params)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load conversations")
 		return
 	}
+	hasMore := len(conversations) > pageSize
+	if hasMore {
+		conversations = conversations[:pageSize]
+	}
 
+	rulesByCampaign := map[pgtype.UUID]messagingRules{}
 	formatted := make([]gin.H, 0, len(conversations))
-	for _, msg := range conversations {
-		match, err := store.GetMatchByID(c, msg.MatchID)
-		if err != nil || !match.MessagingUnlocked {
-			continue
+	for _, conversation := range conversations {
+		match := conversation.Match
+		rules, seen := rulesByCampaign[match.CampaignID]
+		if !seen {
+			if rules, err = loadMessagingRules(c, h.store, match.CampaignID); err != nil {
+				continue
+			}
+			rulesByCampaign[match.CampaignID] = rules
 		}
-		otherID := match.User1ID
-		if otherID == userUUID {
-			otherID = match.User2ID
+		if match, err = h.syncMessaging(c, match, rules); err != nil || messagingDenial(match, rules) != "" {
+			continue
 		}
-		otherUser, _ := store.GetUserByID(c, otherID)
-
-		unreadCount, _ := store.CountUnreadMessagesForMatch(c, repository.CountUnreadMessagesForMatchParams{
-			MatchID:     msg.MatchID,
-			RecipientID: userUUID,
-		})
 
 		formatted = append(formatted, gin.H{
-			"matchId": msg.MatchID,
+			"matchId": conversation.MatchID,
 			"match":   match,
 			"otherUser": gin.H{
-				"id":              otherUser.ID,
-				"firstName":       otherUser.FirstName,
-				"lastName":        otherUser.LastName,
-				"profilePhotoUrl": textValue(otherUser.ProfilePhotoUrl),
+				"id":              conversation.OtherUserID,
+				"firstName":       conversation.OtherFirstName,
+				"lastName":        conversation.OtherLastName,
+				"profilePhotoUrl": textValue(conversation.OtherProfilePhotoUrl),
 			},
 			"lastMessage": gin.H{
-				"content": msg.Content,
-				"sentAt":  msg.SentAt,
+				"content": conversation.Content,
+				"sentAt":  conversation.SentAt,
+				"unsent":  conversation.UnsentAt.Valid,
 			},
-			"unreadCount": unreadCount,
+			"unreadCount": conversation.UnreadCount,
 		})
 	}
 
+	// The cursor follows the last conversation read, not the last shown:
+	// conversations closed by their campaign's policy are skipped, so a page
+	// may come back short while more remain.
+	paging := gin.H{"before": nullableCursor(before), "hasMore": hasMore}
+	if len(conversations) > 0 {
+		last := conversations[len(conversations)-1]
+		paging["before"] = encodeCursor(last.SentAt, last.MatchID)
+	}
+
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
 		"data":    formatted,
 		"count":   len(formatted),
+		"paging":  paging,
 	})
 }
 
-func (h *MessageHandler) UnlockMessaging(c *gin.Context) {
-	campaignID := c.Param("campaignId")
-	campaignUUID, err := uuid.Parse(campaignID)
+// DeleteConversation hides the conversation's messages so far from the
+// user, and only the user: the other side keeps the full history, and
+// anything sent afterwards shows up again. Hidden messages count as read.
+func (h *MessageHandler) DeleteConversation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
 	if err != nil {
-		respondError(c, http.StatusBadRequest, "Invalid campaign ID")
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	matchUUID, err := uuid.Parse(c.Param("matchId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid match ID")
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	match, err := h.store.GetMatchByID(c, matchUUID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "Match not found")
+		return
+	}
+	if match.User1ID != userUUID && match.User2ID != userUUID {
+		respondError(c, http.StatusForbidden, "You are not part of this match")
 		return
 	}
 
-	if err := store.UnlockMessagingByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true}); err != nil {
-		respondError(c, http.StatusInternalServerError, "Failed to unlock messaging")
+	read, err := h.store.MarkMatchMessagesRead(c, repository.MarkMatchMessagesReadParams{
+		MatchID:     matchUUID,
+		RecipientID: userUUID,
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete conversation")
 		return
 	}
+	h.publishReceipts(c, read)
+	if _, err := h.store.DeleteConversationForUser(c, repository.DeleteConversationForUserParams{
+		UserID:  userUUID,
+		MatchID: matchUUID,
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete conversation")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"message": "Conversation deleted",
+	})
+}
 
-	count, err := store.CountMatchesByCampaign(c, pgtype.UUID{Bytes: campaignUUID, Valid: true})
+func (h *MessageHandler) UnlockMessaging(c *gin.Context) {
+	campaignID := c.Param("campaignId")
+	campaignUUID, err := uuid.Parse(campaignID)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "Failed to load match count")
+		respondError(c, http.StatusBadRequest, "Invalid campaign ID")
 		return
 	}
 
+	unlocked, err := h.store.UnlockMessagingByCampaign(c, repository.UnlockMessagingByCampaignParams{
+		CampaignID:            pgtype.UUID{Bytes: campaignUUID, Valid: true},
+		MessagingUnlockReason: pgtype.Text{String: service.UnlockReasonAdmin, Valid: true},
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unlock messaging")
+		return
+	}
+	for _, match := range unlocked {
+		publish(c, h.events, realtime.EventMatchUpdated, match, match.User1ID, match.User2ID)
+	}
+
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
-		"data":    gin.H{"unlockedCount": count},
+		"data":    gin.H{"unlockedCount": len(unlocked)},
 		"message": "Messaging unlocked",
 	})
 }
+
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+)
+
+func messagePageSize(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultMessagePageSize, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxMessagePageSize {
+		respondError(c, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxMessagePageSize))
+		return 0, false
+	}
+	return limit, true
+}
+
+// messageCursor is a keyset position: the time and ID of the row a page
+// ended on. Clients get it encoded and pass it back as is.
+type messageCursor struct {
+	at time.Time
+	id uuid.UUID
+}
+
+func encodeCursor(at time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(at.UnixNano(), 10) + "." + id.String()))
+}
+
+func decodeMessageCursor(raw string) (messageCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return messageCursor{}, err
+	}
+	nanos, id, found := strings.Cut(string(decoded), ".")
+	if !found {
+		return messageCursor{}, errors.New("malformed cursor")
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return messageCursor{}, err
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return messageCursor{}, err
+	}
+	return messageCursor{at: time.Unix(0, unixNano), id: parsed}, nil
+}
+
+func nullableCursor(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return cursor
+}
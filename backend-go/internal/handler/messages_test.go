@@ -0,0 +1,396 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+	"wizardmatch-backend/internal/service"
+)
+
+// campaignDates returns dates putting a campaign in the survey_open phase,
+// or in profile_update once the survey has closed.
+func campaignDates(surveyClosed bool) (surveyOpen, surveyClose, profileStart, profileEnd, results time.Time) {
+	now := time.Now()
+	if surveyClosed {
+		now = now.Add(-3 * time.Hour)
+	}
+	return now.Add(-time.Hour), now.Add(time.Hour), now.Add(2 * time.Hour), now.Add(4 * time.Hour), now.Add(5 * time.Hour)
+}
+
+func TestMessagingFollowsCampaignPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	surveyOpen, surveyClose, profileStart, profileEnd, results := campaignDates(false)
+	campaign, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Spring",
+		SurveyOpenDate:         surveyOpen,
+		SurveyCloseDate:        surveyClose,
+		ProfileUpdateStartDate: profileStart,
+		ProfileUpdateEndDate:   profileEnd,
+		ResultsReleaseDate:     results,
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+		Config:                 []byte("{}"),
+	})
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+	var users []uuid.UUID
+	for i := 0; i < 2; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: "User",
+			IsActive:  true,
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{CampaignID: campaignID, User1ID: users[0], User2ID: users[1]})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+
+	matches := NewMatchHandler(store, nil, nil)
+	messages := NewMessageHandler(store, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.GetHeader("X-User")) })
+	router.POST("/matches/:matchId/interest", matches.MarkInterest)
+	router.POST("/messages/:matchId", messages.SendMessage)
+	router.GET("/messages/:matchId", messages.GetMessages)
+	router.POST("/admin/campaigns/:campaignId/unlock", messages.UnlockMessaging)
+	send := func(user uuid.UUID, method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("X-User", user.String())
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	messagePath := "/messages/" + match.ID.String()
+
+	// Mutual interest during the survey does not open messaging yet.
+	send(users[0], http.MethodPost, "/matches/"+match.ID.String()+"/interest", `{"interested": true}`)
+	mutual := send(users[1], http.MethodPost, "/matches/"+match.ID.String()+"/interest", `{"interested": true}`)
+	var marked struct {
+		Data struct {
+			IsMutualInterest  bool `json:"isMutualInterest"`
+			MessagingUnlocked bool `json:"messagingUnlocked"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(mutual.Body.Bytes(), &marked); err != nil || !marked.Data.IsMutualInterest || marked.Data.MessagingUnlocked {
+		t.Fatalf("expected a locked mutual match during the survey, got %s", mutual.Body)
+	}
+	if early := send(users[0], http.MethodPost, messagePath, `{"content": "hi"}`); early.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 before messaging opens, got %d", early.Code)
+	}
+
+	// Once the campaign reaches profile updates the match unlocks on use.
+	surveyOpen, surveyClose, profileStart, profileEnd, results = campaignDates(true)
+	if _, err := store.UpdateCampaign(ctx, repository.UpdateCampaignParams{
+		ID:                     campaign.ID,
+		Name:                   campaign.Name,
+		SurveyOpenDate:         surveyOpen,
+		SurveyCloseDate:        surveyClose,
+		ProfileUpdateStartDate: profileStart,
+		ProfileUpdateEndDate:   profileEnd,
+		ResultsReleaseDate:     results,
+	}); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if sent := send(users[0], http.MethodPost, messagePath, `{"content": "hi"}`); sent.Code != http.StatusCreated {
+		t.Fatalf("expected 201 once messaging opens, got %d: %s", sent.Code, sent.Body)
+	}
+	match, _ = store.GetMatchByID(ctx, match.ID)
+	if !match.MessagingUnlockedAt.Valid || match.MessagingUnlockReason.String != service.UnlockReasonMutualInterest {
+		t.Fatalf("expected the unlock to be recorded, got %+v", match)
+	}
+	if read := send(users[1], http.MethodGet, messagePath, ""); read.Code != http.StatusOK {
+		t.Fatalf("expected 200 reading messages, got %d", read.Code)
+	}
+
+	// Phases outside the policy close messaging even for unlocked matches.
+	if _, err := store.UpdateCampaign(ctx, repository.UpdateCampaignParams{
+		ID:                     campaign.ID,
+		Name:                   campaign.Name,
+		SurveyOpenDate:         surveyOpen,
+		SurveyCloseDate:        surveyClose,
+		ProfileUpdateStartDate: profileStart,
+		ProfileUpdateEndDate:   profileEnd,
+		ResultsReleaseDate:     results,
+		Config:                 []byte(`{"messaging": {"unlock": "admin", "phases": ["results_released"]}}`),
+	}); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if closed := send(users[1], http.MethodGet, messagePath, ""); closed.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 outside the policy's phases, got %d", closed.Code)
+	}
+
+	// The bulk unlock only touches matches that are still locked.
+	other, err := store.CreateUser(ctx, repository.CreateUserParams{Email: "other@example.com", IsActive: true})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	locked, err := store.CreateMatch(ctx, repository.CreateMatchParams{CampaignID: campaignID, User1ID: users[0], User2ID: other.ID})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	unlock := send(users[0], http.MethodPost, "/admin/campaigns/"+campaign.ID.String()+"/unlock", "")
+	var unlocked struct {
+		Data struct {
+			UnlockedCount int64 `json:"unlockedCount"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(unlock.Body.Bytes(), &unlocked); err != nil || unlocked.Data.UnlockedCount != 1 {
+		t.Fatalf("expected one match unlocked, got %s", unlock.Body)
+	}
+	if locked, _ = store.GetMatchByID(ctx, locked.ID); locked.MessagingUnlockReason.String != service.UnlockReasonAdmin {
+		t.Fatalf("expected an admin unlock, got %+v", locked)
+	}
+}
+
+func TestMessagesAndConversationsPageByCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	var users []uuid.UUID
+	for i := 0; i < 3; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: fmt.Sprintf("User%d", i),
+			IsActive:  true,
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	// Matches outside any campaign, with no campaign running, are held to
+	// no phase once unlocked.
+	var matches []repository.Match
+	for _, other := range users[1:] {
+		match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: users[0], User2ID: other})
+		if err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+		if match, err = store.UnlockMatchMessaging(ctx, repository.UnlockMatchMessagingParams{
+			ID:                    match.ID,
+			MessagingUnlockReason: pgtype.Text{String: service.UnlockReasonAdmin, Valid: true},
+		}); err != nil {
+			t.Fatalf("unlock match: %v", err)
+		}
+		matches = append(matches, match)
+	}
+
+	messages := NewMessageHandler(store, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.GetHeader("X-User")) })
+	router.POST("/messages/send/:matchId", messages.SendMessage)
+	router.GET("/messages/conversations", messages.GetConversations)
+	router.GET("/messages/:matchId", messages.GetMessages)
+	type page struct {
+		Data []struct {
+			MatchID string `json:"matchId"`
+			Content string `json:"content"`
+			Sender  struct {
+				FirstName string `json:"firstName"`
+			} `json:"sender"`
+		} `json:"data"`
+		Paging struct {
+			Before  string `json:"before"`
+			After   string `json:"after"`
+			HasMore bool   `json:"hasMore"`
+		} `json:"paging"`
+	}
+	send := func(user uuid.UUID, method, path, body string) page {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("X-User", user.String())
+		router.ServeHTTP(recorder, request)
+		if recorder.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", method, path, recorder.Code, recorder.Body)
+		}
+		var result page
+		if method != http.MethodGet {
+			return result
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode %s: %v", recorder.Body, err)
+		}
+		return result
+	}
+	contents := func(p page) string {
+		var parts []string
+		for _, message := range p.Data {
+			parts = append(parts, message.Content)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	history := "/messages/" + matches[0].ID.String()
+	for i := 1; i <= 5; i++ {
+		send(users[i%2], http.MethodPost, "/messages/send/"+matches[0].ID.String(), fmt.Sprintf(`{"content": "m%d"}`, i))
+	}
+	send(users[2], http.MethodPost, "/messages/send/"+matches[1].ID.String(), `{"content": "latest"}`)
+
+	// History loads newest page first, each page oldest first, with the
+	// sender joined in.
+	latest := send(users[0], http.MethodGet, history+"?limit=2", "")
+	if contents(latest) != "m4,m5" || !latest.Paging.HasMore || latest.Data[0].Sender.FirstName != "User0" {
+		t.Fatalf("expected the latest two messages, got %+v", latest)
+	}
+	older := send(users[0], http.MethodGet, history+"?limit=2&before="+latest.Paging.Before, "")
+	oldest := send(users[0], http.MethodGet, history+"?limit=2&before="+older.Paging.Before, "")
+	if contents(older) != "m2,m3" || contents(oldest) != "m1" || oldest.Paging.HasMore {
+		t.Fatalf("expected to page back to the first message, got %q then %q", contents(older), contents(oldest))
+	}
+	newer := send(users[0], http.MethodGet, history+"?after="+latest.Paging.After, "")
+	if len(newer.Data) != 0 || newer.Paging.After != latest.Paging.After {
+		t.Fatalf("expected nothing newer, got %+v", newer)
+	}
+	send(users[1], http.MethodPost, "/messages/send/"+matches[0].ID.String(), `{"content": "m6"}`)
+	if newer = send(users[0], http.MethodGet, history+"?after="+latest.Paging.After, ""); contents(newer) != "m6" {
+		t.Fatalf("expected the new message after the cursor, got %q", contents(newer))
+	}
+
+	// Conversations come most recent first.
+	first := send(users[0], http.MethodGet, "/messages/conversations?limit=1", "")
+	if len(first.Data) != 1 || first.Data[0].MatchID != matches[0].ID.String() || !first.Paging.HasMore {
+		t.Fatalf("expected the busiest conversation first, got %+v", first)
+	}
+	rest := send(users[0], http.MethodGet, "/messages/conversations?limit=1&before="+first.Paging.Before, "")
+	if len(rest.Data) != 1 || rest.Data[0].MatchID != matches[1].ID.String() || rest.Paging.HasMore {
+		t.Fatalf("expected the other conversation next, got %+v", rest)
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, history+"?before=nonsense", nil)
+	request.Header.Set("X-User", users[0].String())
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected a malformed cursor to be refused, got %d", recorder.Code)
+	}
+}
+
+func TestEditUnsendAndDeleteConversation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	var users []uuid.UUID
+	for i := 0; i < 2; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: fmt.Sprintf("user%d@example.com", i), IsActive: true})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: users[0], User2ID: users[1]})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	if _, err := store.UnlockMatchMessaging(ctx, repository.UnlockMatchMessagingParams{
+		ID:                    match.ID,
+		MessagingUnlockReason: pgtype.Text{String: service.UnlockReasonAdmin, Valid: true},
+	}); err != nil {
+		t.Fatalf("unlock match: %v", err)
+	}
+
+	messages := NewMessageHandler(store, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.GetHeader("X-User")) })
+	router.POST("/messages/send/:matchId", messages.SendMessage)
+	router.GET("/messages/:matchId", messages.GetMessages)
+	router.PUT("/messages/edit/:messageId", messages.EditMessage)
+	router.POST("/messages/unsend/:messageId", messages.UnsendMessage)
+	router.DELETE("/messages/conversations/:matchId", messages.DeleteConversation)
+	router.GET("/messages/edits/:messageId", messages.GetMessageEdits)
+	request := func(user uuid.UUID, method, path, body string, want int, result any) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User", user.String())
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != want {
+			t.Fatalf("%s %s: expected %d, got %d %s", method, path, want, recorder.Code, recorder.Body)
+		}
+		if result != nil {
+			if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+				t.Fatalf("decode %s: %v", recorder.Body, err)
+			}
+		}
+	}
+	type sent struct {
+		Data repository.Message `json:"data"`
+	}
+	type history struct {
+		Data []struct {
+			Content  string  `json:"content"`
+			EditedAt *string `json:"editedAt"`
+			Unsent   bool    `json:"unsent"`
+		} `json:"data"`
+	}
+	send := func(content string) repository.Message {
+		var result sent
+		request(users[0], http.MethodPost, "/messages/send/"+match.ID.String(), `{"content": "`+content+`"}`, http.StatusCreated, &result)
+		return result.Data
+	}
+
+	first, second := send("hello"), send("second")
+	request(users[1], http.MethodPut, "/messages/edit/"+first.ID.String(), `{"content": "hijacked"}`, http.StatusForbidden, nil)
+	request(users[0], http.MethodPut, "/messages/edit/"+first.ID.String(), `{"content": "hi"}`, http.StatusOK, nil)
+	request(users[0], http.MethodPost, "/messages/unsend/"+second.ID.String(), "", http.StatusOK, nil)
+	request(users[0], http.MethodPut, "/messages/edit/"+second.ID.String(), `{"content": "back"}`, http.StatusBadRequest, nil)
+
+	// The recipient sees the edit, and a tombstone where the unsent message
+	// was; moderators still see what both said.
+	var seen history
+	request(users[1], http.MethodGet, "/messages/"+match.ID.String(), "", http.StatusOK, &seen)
+	if len(seen.Data) != 2 || seen.Data[0].Content != "hi" || seen.Data[0].EditedAt == nil ||
+		!seen.Data[1].Unsent || seen.Data[1].Content != "" {
+		t.Fatalf("expected an edited message and a tombstone, got %+v", seen.Data)
+	}
+	for message, want := range map[uuid.UUID]string{first.ID: "hello", second.ID: "second"} {
+		var edits struct {
+			Data struct {
+				Edits []repository.MessageEdit `json:"edits"`
+			} `json:"data"`
+		}
+		request(users[0], http.MethodGet, "/messages/edits/"+message.String(), "", http.StatusOK, &edits)
+		if len(edits.Data.Edits) != 1 || edits.Data.Edits[0].PreviousContent != want {
+			t.Fatalf("expected the history to keep %q, got %+v", want, edits.Data.Edits)
+		}
+	}
+
+	// Deleting the conversation hides it from the recipient only, until
+	// something new is sent.
+	send("unseen")
+	request(users[1], http.MethodDelete, "/messages/conversations/"+match.ID.String(), "", http.StatusOK, nil)
+	var mine, theirs history
+	request(users[1], http.MethodGet, "/messages/"+match.ID.String(), "", http.StatusOK, &mine)
+	request(users[0], http.MethodGet, "/messages/"+match.ID.String(), "", http.StatusOK, &theirs)
+	if len(mine.Data) != 0 || len(theirs.Data) != 3 {
+		t.Fatalf("expected only the deleting user to lose the history, got %d and %d", len(mine.Data), len(theirs.Data))
+	}
+	if unread, _ := store.CountUnreadMessages(ctx, users[1]); unread != 0 {
+		t.Fatalf("expected hidden messages to count as read, got %d unread", unread)
+	}
+	send("again")
+	request(users[1], http.MethodGet, "/messages/"+match.ID.String(), "", http.StatusOK, &mine)
+	if len(mine.Data) != 1 || mine.Data[0].Content != "again" {
+		t.Fatalf("expected new messages to show after deleting, got %+v", mine.Data)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+// messagingRules is the messaging policy of a match's campaign together with
+// the phase that campaign is in. Matches made outside a campaign follow the
+// active one; with no campaign at all, no phase applies.
+type messagingRules struct {
+	policy service.MessagingPolicy
+	phase  string
+}
+
+func loadMessagingRules(ctx context.Context, store repository.Querier, campaignID pgtype.UUID) (messagingRules, error) {
+	rules := messagingRules{policy: service.DefaultMessagingPolicy()}
+	var campaign repository.Campaign
+	var err error
+	if campaignID.Valid {
+		campaign, err = store.GetCampaignByID(ctx, campaignID.Bytes)
+	} else {
+		campaign, err = store.GetActiveCampaign(ctx)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rules, nil
+	}
+	if err != nil {
+		return rules, err
+	}
+	if rules.policy, err = service.ParseMessagingPolicy(campaign.Config); err != nil {
+		return rules, err
+	}
+	rules.phase = campaignPhase(campaign)
+	return rules, nil
+}
+
+// open reports whether the campaign phase lets unlocked matches message.
+func (r messagingRules) open() bool {
+	if r.phase == "" {
+		return true
+	}
+	if len(r.policy.Phases) == 0 {
+		return service.ActionAllowed(r.phase, "send_messages")
+	}
+	return slices.Contains(r.policy.Phases, r.phase)
+}
+
+// syncMessaging unlocks a mutually interested match once the rules allow it,
+// recording when and why, so a match that turned mutual before messaging
+// opened is unlocked the first time it is used afterwards.
+func syncMessaging(ctx context.Context, store repository.Querier, match repository.Match, rules messagingRules) (repository.Match, error) {
+	if match.MessagingUnlocked || !match.IsMutualInterest || !rules.policy.UnlocksOnMutualInterest() || !rules.open() {
+		return match, nil
+	}
+	unlocked, err := store.UnlockMatchMessaging(ctx, repository.UnlockMatchMessagingParams{
+		ID:                    match.ID,
+		MessagingUnlockReason: pgtype.Text{String: service.UnlockReasonMutualInterest, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Another request unlocked it first.
+		return store.GetMatchByID(ctx, match.ID)
+	}
+	return unlocked, err
+}
+
+// messagingDenial explains why the match cannot message right now, or
+// returns "" when it can.
+func messagingDenial(match repository.Match, rules messagingRules) string {
+	if !match.MessagingUnlocked {
+		return "Messaging is not yet available for this match"
+	}
+	if !rules.open() {
+		return "Messaging is closed during the current campaign phase"
+	}
+	return ""
+}
+
+// recordMutualInterest flags the match as mutual, unlocking messaging if
+// the campaign's policy allows it already.
+func recordMutualInterest(ctx context.Context, store repository.Querier, matchID uuid.UUID) (repository.Match, error) {
+	match, err := store.UpdateMatchInterest(ctx, repository.UpdateMatchInterestParams{
+		ID:               matchID,
+		IsMutualInterest: true,
+	})
+	if err != nil {
+		return match, err
+	}
+	rules, err := loadMessagingRules(ctx, store, match.CampaignID)
+	if err != nil {
+		return match, err
+	}
+	return syncMessaging(ctx, store, match, rules)
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
+)
+
+type createPairConstraintRequest struct {
+	CampaignID string  `json:"campaignId"`
+	User1ID    string  `json:"user1Id"`
+	User2ID    string  `json:"user2Id"`
+	Type       string  `json:"type"`
+	Reason     *string `json:"reason"`
+}
+
+type updatePairConstraintRequest struct {
+	Type   *string `json:"type"`
+	Reason *string `json:"reason"`
+}
+
+func pairConstraintPayload(constraint repository.PairConstraint) gin.H {
+	return gin.H{
+		"id":         constraint.ID,
+		"campaignId": constraint.CampaignID,
+		"user1Id":    constraint.User1ID,
+		"user2Id":    constraint.User2ID,
+		"type":       constraint.ConstraintType,
+		"reason":     textValue(constraint.Reason),
+		"createdBy":  uuidValue(constraint.CreatedBy),
+		"createdAt":  constraint.CreatedAt,
+		"updatedAt":  constraint.UpdatedAt,
+	}
+}
+
+func (h *AdminHandler) ListPairConstraints(c *gin.Context) {
+	campaignID, ok := matchRunCampaign(c, h.store)
+	if !ok {
+		return
+	}
+
+	constraints, err := h.store.ListPairConstraintsByCampaign(c, campaignID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load pair constraints")
+		return
+	}
+
+	response := make([]gin.H, 0, len(constraints))
+	for _, constraint := range constraints {
+		response = append(response, pairConstraintPayload(constraint))
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+		"count":   len(response),
+	})
+}
+
+// CreatePairConstraint stores a must-match or must-not-match pair that match
+// generation honors from the next run on. Live matches are left alone until
+// a new run is published.
+func (h *AdminHandler) CreatePairConstraint(c *gin.Context) {
+	var req createPairConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if !service.ValidPairConstraint(req.Type) {
+		respondError(c, http.StatusBadRequest, "Type must be must_match or must_not_match")
+		return
+	}
+	user1, err := uuid.Parse(req.User1ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user1 ID")
+		return
+	}
+	user2, err := uuid.Parse(req.User2ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user2 ID")
+		return
+	}
+	if user1 == user2 {
+		respondError(c, http.StatusBadRequest, "A constraint needs two different users")
+		return
+	}
+
+	var campaignID uuid.UUID
+	if req.CampaignID != "" {
+		parsed, err := uuid.Parse(req.CampaignID)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid campaign ID")
+			return
+		}
+		campaignID = parsed
+	} else {
+		resolved, ok := matchRunCampaign(c, h.store)
+		if !ok {
+			return
+		}
+		campaignID = resolved
+	}
+
+	for _, userID := range []uuid.UUID{user1, user2} {
+		if _, err := h.store.GetUserByID(c, userID); err != nil {
+			respondError(c, http.StatusNotFound, "User not found")
+			return
+		}
+	}
+
+	// The table stores each pair once, lowest user ID first.
+	if user1.String() > user2.String() {
+		user1, user2 = user2, user1
+	}
+	constraint, err := h.store.CreatePairConstraint(c, repository.CreatePairConstraintParams{
+		CampaignID:     campaignID,
+		User1ID:        user1,
+		User2ID:        user2,
+		ConstraintType: req.Type,
+		Reason:         optionalText(req.Reason),
+		CreatedBy:      currentUserUUID(c),
+	})
+	if isUniqueViolation(err) {
+		respondError(c, http.StatusConflict, "This pair already has a constraint in the campaign")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create pair constraint")
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, gin.H{
+		"success": true,
+		"data":    pairConstraintPayload(constraint),
+		"message": "Pair constraint created",
+	})
+}
+
+func (h *AdminHandler) UpdatePairConstraint(c *gin.Context) {
+	var req updatePairConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Type != nil && !service.ValidPairConstraint(*req.Type) {
+		respondError(c, http.StatusBadRequest, "Type must be must_match or must_not_match")
+		return
+	}
+
+	constraint, ok := loadPairConstraint(c, h.store)
+	if !ok {
+		return
+	}
+
+	params := repository.UpdatePairConstraintParams{
+		ID:             constraint.ID,
+		ConstraintType: constraint.ConstraintType,
+		Reason:         constraint.Reason,
+	}
+	if req.Type != nil {
+		params.ConstraintType = *req.Type
+	}
+	if req.Reason != nil {
+		params.Reason = optionalText(req.Reason)
+	}
+
+	updated, err := h.store.UpdatePairConstraint(c, params)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to update pair constraint")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"data":    pairConstraintPayload(updated),
+		"message": "Pair constraint updated",
+	})
+}
+
+func (h *AdminHandler) DeletePairConstraint(c *gin.Context) {
+	constraint, ok := loadPairConstraint(c, h.store)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeletePairConstraint(c, constraint.ID); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete pair constraint")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pair constraint deleted",
+	})
+}
+
+func loadPairConstraint(c *gin.Context, store repository.Querier) (repository.PairConstraint, bool) {
+	constraintID, err := uuid.Parse(c.Param("constraintId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid constraint ID")
+		return repository.PairConstraint{}, false
+	}
+
+	constraint, err := store.GetPairConstraintByID(c, constraintID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "Pair constraint not found")
+		return repository.PairConstraint{}, false
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load pair constraint")
+		return repository.PairConstraint{}, false
+	}
+	return constraint, true
+}
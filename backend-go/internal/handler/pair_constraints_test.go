@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestPairConstraintLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	now := time.Now()
+	if _, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Spring",
+		SurveyOpenDate:         now,
+		SurveyCloseDate:        now.Add(time.Hour),
+		ProfileUpdateStartDate: now.Add(2 * time.Hour),
+		ProfileUpdateEndDate:   now.Add(3 * time.Hour),
+		ResultsReleaseDate:     now.Add(4 * time.Hour),
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+	}); err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	var users []uuid.UUID
+	for i := 0; i < 2; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: "User",
+			IsActive:  true,
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+
+	h := NewAdminHandler(store, nil, nil)
+	router := gin.New()
+	router.GET("/constraints", h.ListPairConstraints)
+	router.POST("/constraints", h.CreatePairConstraint)
+	router.DELETE("/constraints/:constraintId", h.DeletePairConstraint)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	// The pair is given highest ID first; the handler stores it lowest first.
+	body := fmt.Sprintf(`{"user1Id": %q, "user2Id": %q, "type": "must_not_match"}`, users[1], users[0])
+	created := send(http.MethodPost, "/constraints", body)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", created.Code, created.Body)
+	}
+	if duplicate := send(http.MethodPost, "/constraints", body); duplicate.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate pair, got %d", duplicate.Code)
+	}
+	unknown := fmt.Sprintf(`{"user1Id": %q, "user2Id": %q, "type": "must_match"}`, users[0], uuid.New())
+	if missing := send(http.MethodPost, "/constraints", unknown); missing.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown user, got %d", missing.Code)
+	}
+
+	var listed struct {
+		Data []struct {
+			ID      uuid.UUID `json:"id"`
+			User1ID uuid.UUID `json:"user1Id"`
+			Type    string    `json:"type"`
+		} `json:"data"`
+	}
+	list := send(http.MethodGet, "/constraints", "")
+	if err := json.Unmarshal(list.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listed.Data) != 1 || listed.Data[0].Type != "must_not_match" {
+		t.Fatalf("unexpected constraints: %s", list.Body)
+	}
+	if lowest := min(users[0].String(), users[1].String()); listed.Data[0].User1ID.String() != lowest {
+		t.Fatalf("expected user1 to be the lowest ID %s, got %s", lowest, listed.Data[0].User1ID)
+	}
+
+	if deleted := send(http.MethodDelete, "/constraints/"+listed.Data[0].ID.String(), ""); deleted.Code != http.StatusOK {
+		t.Fatalf("expected 200 on delete, got %d", deleted.Code)
+	}
+	if again := send(http.MethodDelete, "/constraints/"+listed.Data[0].ID.String(), ""); again.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 once deleted, got %d", again.Code)
+	}
+}
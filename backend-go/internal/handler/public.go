@@ -8,20 +8,16 @@ import (
 	"wizardmatch-backend/internal/repository"
 )
 
-type PublicHandler struct{}
+type PublicHandler struct {
+	store repository.Querier
+}
 
-func NewPublicHandler() *PublicHandler {
-	return &PublicHandler{}
+func NewPublicHandler(store repository.Querier) *PublicHandler {
+	return &PublicHandler{store: store}
 }
 
 func (h *PublicHandler) GetPublicStats(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	stats, err := store.PublicStats(c)
+	stats, err := h.store.PublicStats(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load stats")
 		return
@@ -56,18 +52,12 @@ func (h *PublicHandler) CreateTestimonial(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
 	heading := req.Title
 	if heading == "" {
 		heading = "Success Story"
 	}
 
-	testimonial, err := store.CreateTestimonial(c, repository.CreateTestimonialParams{
+	testimonial, err := h.store.CreateTestimonial(c, repository.CreateTestimonialParams{
 		Name:        req.AuthorName,
 		Email:       optionalText(&req.Program),
 		Heading:     heading,
@@ -88,13 +78,7 @@ func (h *PublicHandler) CreateTestimonial(c *gin.Context) {
 }
 
 func (h *PublicHandler) ListTestimonials(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	testimonials, err := store.ListTestimonials(c)
+	testimonials, err := h.store.ListTestimonials(c)
 	if err != nil {
 		// DEBUG: Return actual error
 		respondError(c, http.StatusInternalServerError, "Failed to load testimonials: "+err.Error())
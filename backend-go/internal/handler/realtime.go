@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+type RealtimeHandler struct {
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewRealtimeHandler accepts WebSocket connections from the origins
+// allowOrigin accepts, and from clients that send no Origin at all.
+func NewRealtimeHandler(hub *realtime.Hub, allowOrigin func(origin string) bool) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || allowOrigin(origin)
+			},
+		},
+	}
+}
+
+// publish pushes an event to the users' live connections. Delivery is best
+// effort: clients catch up over REST or the event stream after reconnecting.
+func publish(c *gin.Context, events realtime.Publisher, eventType string, data any, userIDs ...uuid.UUID) {
+	publishEvent(c, events, realtime.NewEvent(eventType, data, userIDs...))
+}
+
+func publishEvent(c *gin.Context, events realtime.Publisher, event realtime.Event) {
+	if events == nil {
+		return
+	}
+	_ = events.Publish(c, event)
+}
+
+// mutualInterestEvent is raised once per match, however often its users
+// repeat their interest.
+func mutualInterestEvent(match repository.Match) realtime.Event {
+	event := realtime.NewEvent(realtime.EventMatchMutualInterest, match, match.User1ID, match.User2ID)
+	event.Key = "mutual_interest:" + match.ID.String()
+	return event
+}
+
+type realtimeMessage struct {
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// Connect upgrades the request and streams the user's events until either
+// side goes away. Clients only ever receive; anything they send is ignored.
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered with an error status.
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(userUUID)
+	defer sub.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.Events():
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteJSON(realtimeMessage{Type: event.Type, Data: event.Data, Truncated: event.Truncated}); err != nil {
+				return
+			}
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestRealtimePushesMessagesAndReceipts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.New()
+	var users []uuid.UUID
+	for i := 0; i < 2; i++ {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: fmt.Sprintf("user%d@example.com", i), IsActive: true})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	// A match outside any campaign, with no campaign running, is not held to
+	// any phase.
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: users[0], User2ID: users[1]})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	if _, err := store.UnlockMatchMessaging(ctx, repository.UnlockMatchMessagingParams{
+		ID:                    match.ID,
+		MessagingUnlockReason: pgtype.Text{String: "admin", Valid: true},
+	}); err != nil {
+		t.Fatalf("unlock match: %v", err)
+	}
+
+	hub := realtime.NewHub()
+	messages := NewMessageHandler(store, hub)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userId", c.Query("user")) })
+	router.GET("/ws", NewRealtimeHandler(hub, func(string) bool { return false }).Connect)
+	router.POST("/messages/send/:matchId", messages.SendMessage)
+	router.GET("/messages/:matchId", messages.GetMessages)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?user="+users[0].String(), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Connected(users[0]) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	request := func(user uuid.UUID, method, path, body string) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path+"?user="+user.String(), strings.NewReader(body)))
+		if recorder.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", method, path, recorder.Code, recorder.Body)
+		}
+	}
+	request(users[0], http.MethodPost, "/messages/send/"+match.ID.String(), `{"content": "hi"}`)
+	request(users[1], http.MethodGet, "/messages/"+match.ID.String(), "")
+
+	_ = conn.SetReadDeadline(deadline)
+	for _, want := range []string{realtime.EventMessageCreated, realtime.EventMessagesRead} {
+		var received realtimeMessage
+		if err := conn.ReadJSON(&received); err != nil {
+			t.Fatalf("read %s: %v", want, err)
+		}
+		if received.Type != want || len(received.Data) == 0 {
+			t.Fatalf("expected %s, got %+v", want, received)
+		}
+	}
+
+	// Browsers from origins outside the CORS rules are refused.
+	header := http.Header{"Origin": {"https://evil.example"}}
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?user="+users[1].String(), header); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a foreign origin to be refused, got %v", err)
+	}
+}
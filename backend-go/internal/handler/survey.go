@@ -9,22 +9,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
-type SurveyHandler struct{}
+type SurveyHandler struct {
+	store repository.Querier
+}
 
-func NewSurveyHandler() *SurveyHandler {
-	return &SurveyHandler{}
+func NewSurveyHandler(store repository.Querier) *SurveyHandler {
+	return &SurveyHandler{store: store}
 }
 
 func (h *SurveyHandler) GetQuestions(c *gin.Context) {
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	questions, err := store.ListQuestions(c)
+	questions, err := h.store.ListQuestions(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load questions")
 		return
@@ -50,11 +47,13 @@ func (h *SurveyHandler) GetQuestions(c *gin.Context) {
 }
 
 type submitResponseRequest struct {
-	QuestionID  string      `json:"questionId"`
-	AnswerText  *string     `json:"answerText"`
-	AnswerValue *int32      `json:"answerValue"`
-	AnswerType  string      `json:"answerType"`
-	AnswerJson  interface{} `json:"answerJson"`
+	QuestionID        string        `json:"questionId"`
+	AnswerText        *string       `json:"answerText"`
+	AnswerValue       *int32        `json:"answerValue"`
+	AnswerType        string        `json:"answerType"`
+	AnswerJson        interface{}   `json:"answerJson"`
+	AcceptableAnswers []interface{} `json:"acceptableAnswers"`
+	Importance        string        `json:"importance"`
 }
 
 func (h *SurveyHandler) SubmitResponse(c *gin.Context) {
@@ -76,25 +75,32 @@ func (h *SurveyHandler) SubmitResponse(c *gin.Context) {
 		return
 	}
 
-	userUUID, err := uuid.Parse(userID)
-	if err != nil {
-		respondError(c, http.StatusBadRequest, "Invalid user ID")
+	importance := req.Importance
+	if importance == "" {
+		importance = service.ImportanceSomewhat
+	}
+	if !service.ValidImportance(importance) {
+		respondError(c, http.StatusBadRequest, "Invalid importance")
+		return
+	}
+	if importance == service.ImportanceMandatory && len(req.AcceptableAnswers) == 0 {
+		respondError(c, http.StatusBadRequest, "Mandatory answers need at least one acceptable answer")
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
-	_, err = store.GetQuestionByID(c, questionUUID)
+	_, err = h.store.GetQuestionByID(c, questionUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "Question not found")
 		return
 	}
 
-	activeCampaign, _ := store.GetActiveCampaign(c)
+	activeCampaign, _ := h.store.GetActiveCampaign(c)
 	var campaignID pgtype.UUID
 	if activeCampaign.ID != uuid.Nil {
 		campaignID = pgtype.UUID{Bytes: activeCampaign.ID, Valid: true}
@@ -104,15 +110,21 @@ func (h *SurveyHandler) SubmitResponse(c *gin.Context) {
 	if req.AnswerJson != nil {
 		answerJSON, _ = json.Marshal(req.AnswerJson)
 	}
+	var acceptableJSON []byte
+	if len(req.AcceptableAnswers) > 0 {
+		acceptableJSON, _ = json.Marshal(req.AcceptableAnswers)
+	}
 
-	response, err := store.CreateSurveyResponse(c, repository.CreateSurveyResponseParams{
-		UserID:      userUUID,
-		CampaignID:  campaignID,
-		QuestionID:  questionUUID,
-		AnswerText:  optionalText(req.AnswerText),
-		AnswerValue: optionalInt(req.AnswerValue),
-		AnswerJson:  answerJSON,
-		AnswerType:  req.AnswerType,
+	response, err := h.store.CreateSurveyResponse(c, repository.CreateSurveyResponseParams{
+		UserID:            userUUID,
+		CampaignID:        campaignID,
+		QuestionID:        questionUUID,
+		AnswerText:        optionalText(req.AnswerText),
+		AnswerValue:       optionalInt(req.AnswerValue),
+		AnswerJson:        answerJSON,
+		AnswerType:        req.AnswerType,
+		AcceptableAnswers: acceptableJSON,
+		Importance:        importance,
 	})
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to save response")
@@ -139,13 +151,7 @@ func (h *SurveyHandler) GetResponses(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	responses, err := store.ListSurveyResponsesByUser(c, userUUID)
+	responses, err := h.store.ListSurveyResponsesByUser(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load responses")
 		return
@@ -153,13 +159,19 @@ func (h *SurveyHandler) GetResponses(c *gin.Context) {
 
 	formatted := make([]gin.H, 0, len(responses))
 	for _, r := range responses {
+		acceptable := json.RawMessage("[]")
+		if len(r.AcceptableAnswers) > 0 {
+			acceptable = r.AcceptableAnswers
+		}
 		formatted = append(formatted, gin.H{
-			"id":          r.ID,
-			"questionId":  r.QuestionID,
-			"answerText":  textValue(r.AnswerText),
-			"answerValue": intValue(r.AnswerValue),
-			"answerType":  r.AnswerType,
-			"createdAt":   r.CreatedAt,
+			"id":                r.ID,
+			"questionId":        r.QuestionID,
+			"answerText":        textValue(r.AnswerText),
+			"answerValue":       intValue(r.AnswerValue),
+			"answerType":        r.AnswerType,
+			"acceptableAnswers": acceptable,
+			"importance":        r.Importance,
+			"createdAt":         r.CreatedAt,
 		})
 	}
 
@@ -182,19 +194,13 @@ func (h *SurveyHandler) CompleteSurvey(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	questions, err := store.ListQuestions(c)
+	questions, err := h.store.ListQuestions(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load questions")
 		return
 	}
 
-	responses, err := store.ListSurveyResponsesByUser(c, userUUID)
+	responses, err := h.store.ListSurveyResponsesByUser(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load responses")
 		return
@@ -205,13 +211,14 @@ func (h *SurveyHandler) CompleteSurvey(c *gin.Context) {
 		return
 	}
 
-	if err := store.SetUserSurveyCompleted(c, repository.SetUserSurveyCompletedParams{
+	if err := h.store.SetUserSurveyCompleted(c, repository.SetUserSurveyCompletedParams{
 		ID:              userUUID,
 		SurveyCompleted: true,
 	}); err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to complete survey")
 		return
 	}
+	queueLateMatching(c, h.store, userUUID)
 
 	respondJSON(c, http.StatusOK, gin.H{
 		"success": true,
@@ -232,18 +239,12 @@ func (h *SurveyHandler) GetProgress(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	questions, err := store.ListQuestions(c)
+	questions, err := h.store.ListQuestions(c)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load questions")
 		return
 	}
-	responses, err := store.ListSurveyResponsesByUser(c, userUUID)
+	responses, err := h.store.ListSurveyResponsesByUser(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to load responses")
 		return
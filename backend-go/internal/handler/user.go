@@ -10,12 +10,15 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
-type UserHandler struct{}
+type UserHandler struct {
+	store repository.Querier
+}
 
-func NewUserHandler() *UserHandler {
-	return &UserHandler{}
+func NewUserHandler(store repository.Querier) *UserHandler {
+	return &UserHandler{store: store}
 }
 
 func (h *UserHandler) GetProfile(c *gin.Context) {
@@ -31,13 +34,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	user, err := store.GetUserByID(c, userUUID)
+	user, err := h.store.GetUserByID(c, userUUID)
 	if err != nil {
 		respondError(c, http.StatusNotFound, "User not found")
 		return
@@ -54,6 +51,8 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 			"program":           textValue(user.Program),
 			"yearLevel":         intValue(user.YearLevel),
 			"gender":            textValue(user.Gender),
+			"minPartnerAge":     intValue(user.MinPartnerAge),
+			"maxPartnerAge":     intValue(user.MaxPartnerAge),
 			"bio":               textValue(user.Bio),
 			"profilePhotoUrl":   textValue(user.ProfilePhotoUrl),
 			"username":          textValue(user.Username),
@@ -87,6 +86,8 @@ type updateProfileRequest struct {
 	ProfilePhotoUrl   *string `json:"profilePhotoUrl"`
 	SeekingGender     *string `json:"seekingGender"`
 	DateOfBirth       *string `json:"dateOfBirth"`
+	MinPartnerAge     *int32  `json:"minPartnerAge"`
+	MaxPartnerAge     *int32  `json:"maxPartnerAge"`
 }
 
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
@@ -108,12 +109,29 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
+	if (req.MinPartnerAge != nil && *req.MinPartnerAge <= 0) || (req.MaxPartnerAge != nil && *req.MaxPartnerAge <= 0) {
+		respondError(c, http.StatusBadRequest, "Partner ages must be positive")
+		return
+	}
+	if req.MinPartnerAge != nil && req.MaxPartnerAge != nil && *req.MinPartnerAge > *req.MaxPartnerAge {
+		respondError(c, http.StatusBadRequest, "Minimum partner age cannot exceed the maximum")
 		return
 	}
 
+	var genders, seekingGenders []string
+	if req.Gender != nil {
+		if genders, err = service.NormalizeGenders(*req.Gender); err != nil {
+			respondError(c, http.StatusBadRequest, "Unrecognized gender")
+			return
+		}
+	}
+	if req.SeekingGender != nil {
+		if seekingGenders, err = service.NormalizeSeeking(*req.SeekingGender); err != nil {
+			respondError(c, http.StatusBadRequest, "Unrecognized seeking gender")
+			return
+		}
+	}
+
 	params := repository.UpdateUserProfileParams{
 		ID:                userUUID,
 		Column2:           optionalString(req.FirstName),
@@ -132,9 +150,13 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		ContactPreference: optionalText(req.ContactPreference),
 		Column16:          optionalString(req.ProfileVisibility),
 		Preferences:       nil,
+		Genders:           genders,
+		SeekingGenders:    seekingGenders,
+		MinPartnerAge:     optionalInt(req.MinPartnerAge),
+		MaxPartnerAge:     optionalInt(req.MaxPartnerAge),
 	}
 
-	user, err := store.UpdateUserProfile(c, params)
+	user, err := h.store.UpdateUserProfile(c, params)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Failed to update profile")
 		return
@@ -150,6 +172,8 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 			"program":           textValue(user.Program),
 			"yearLevel":         intValue(user.YearLevel),
 			"gender":            textValue(user.Gender),
+			"minPartnerAge":     intValue(user.MinPartnerAge),
+			"maxPartnerAge":     intValue(user.MaxPartnerAge),
 			"bio":               textValue(user.Bio),
 			"profilePhotoUrl":   textValue(user.ProfilePhotoUrl),
 			"instagramHandle":   textValue(user.InstagramHandle),
@@ -184,13 +208,7 @@ func (h *UserHandler) UploadPhoto(c *gin.Context) {
 		return
 	}
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	updated, err := store.UpdateUserPhoto(c, repository.UpdateUserPhotoParams{
+	updated, err := h.store.UpdateUserPhoto(c, repository.UpdateUserPhotoParams{
 		ID:              userUUID,
 		ProfilePhotoUrl: pgtype.Text{String: req.PhotoUrl, Valid: true},
 	})
@@ -230,13 +248,7 @@ func (h *UserHandler) UpdatePreferences(c *gin.Context) {
 
 	payload, _ := json.Marshal(prefs)
 
-	store := getStore()
-	if store == nil {
-		respondError(c, http.StatusInternalServerError, "store not initialized")
-		return
-	}
-
-	updated, err := store.UpdateUserPreferences(c, repository.UpdateUserPreferencesParams{
+	updated, err := h.store.UpdateUserPreferences(c, repository.UpdateUserPreferencesParams{
 		ID:          userUUID,
 		Preferences: payload,
 	})
@@ -9,6 +9,9 @@ import (
 
 	"wizardmatch-backend/internal/handler"
 	"wizardmatch-backend/internal/middleware"
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/service"
 )
 
 type RouterOptions struct {
@@ -18,6 +21,13 @@ type RouterOptions struct {
 	GoogleClientID     string
 	GoogleClientSecret string
 	GoogleRedirectURL  string
+	Store              repository.Querier
+	Matcher            *service.MatchingService
+	// Hub holds this instance's live connections. Events are logged to
+	// Store and published straight to it unless Events publishes them some
+	// other way, such as through Postgres to every instance.
+	Hub    *realtime.Hub
+	Events realtime.Publisher
 }
 
 func NewRouter(options RouterOptions) *gin.Engine {
@@ -25,22 +35,24 @@ func NewRouter(options RouterOptions) *gin.Engine {
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	allowOrigin := func(origin string) bool {
+		// Allow localhost for development
+		if strings.HasPrefix(origin, "http://localhost") || strings.HasPrefix(origin, "http://127.0.0.1") {
+			return true
+		}
+		// Allow all Vercel deployments (production and previews)
+		if strings.HasSuffix(origin, ".vercel.app") {
+			return true
+		}
+		// Allow the configured frontend URL
+		if options.FrontendURL != "" && origin == options.FrontendURL {
+			return true
+		}
+		return false
+	}
+
 	router.Use(cors.New(cors.Config{
-		AllowOriginFunc: func(origin string) bool {
-			// Allow localhost for development
-			if strings.HasPrefix(origin, "http://localhost") || strings.HasPrefix(origin, "http://127.0.0.1") {
-				return true
-			}
-			// Allow all Vercel deployments (production and previews)
-			if strings.HasSuffix(origin, ".vercel.app") {
-				return true
-			}
-			// Allow the configured frontend URL
-			if options.FrontendURL != "" && origin == options.FrontendURL {
-				return true
-			}
-			return false
-		},
+		AllowOriginFunc:  allowOrigin,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type", "Authorization", "Origin"},
 		AllowCredentials: true,
@@ -63,17 +75,30 @@ func NewRouter(options RouterOptions) *gin.Engine {
 		GoogleClientSecret: options.GoogleClientSecret,
 		GoogleRedirectURL:  options.GoogleRedirectURL,
 		AdminEmails:        options.AdminEmails,
+		Store:              options.Store,
 	})
 
-	userHandler := handler.NewUserHandler()
-	surveyHandler := handler.NewSurveyHandler()
-	matchHandler := handler.NewMatchHandler()
-	messageHandler := handler.NewMessageHandler()
-	crushHandler := handler.NewCrushHandler()
-	campaignHandler := handler.NewCampaignHandler()
-	adminHandler := handler.NewAdminHandler()
-	analyticsHandler := handler.NewAnalyticsHandler()
-	publicHandler := handler.NewPublicHandler()
+	hub := options.Hub
+	if hub == nil {
+		hub = realtime.NewHub()
+	}
+	events := options.Events
+	if events == nil {
+		events = realtime.NewRecorder(options.Store, hub)
+	}
+
+	userHandler := handler.NewUserHandler(options.Store)
+	blockHandler := handler.NewBlockHandler(options.Store)
+	surveyHandler := handler.NewSurveyHandler(options.Store)
+	matchHandler := handler.NewMatchHandler(options.Store, options.Matcher, events)
+	messageHandler := handler.NewMessageHandler(options.Store, events)
+	crushHandler := handler.NewCrushHandler(options.Store, events)
+	campaignHandler := handler.NewCampaignHandler(options.Store)
+	adminHandler := handler.NewAdminHandler(options.Store, options.Matcher, events)
+	analyticsHandler := handler.NewAnalyticsHandler(options.Store)
+	publicHandler := handler.NewPublicHandler(options.Store)
+	realtimeHandler := handler.NewRealtimeHandler(hub, allowOrigin)
+	eventStreamHandler := handler.NewEventStreamHandler(options.Store, hub)
 
 	authMiddleware := middleware.NewAuthMiddleware(options.JwtSecret)
 	adminMiddleware := middleware.NewAdminMiddleware(options.AdminEmails)
@@ -90,6 +115,9 @@ func NewRouter(options RouterOptions) *gin.Engine {
 		api.PUT("/users/profile", authMiddleware.RequireAuth(), userHandler.UpdateProfile)
 		api.POST("/users/profile/photo", authMiddleware.RequireAuth(), userHandler.UploadPhoto)
 		api.PUT("/users/preferences", authMiddleware.RequireAuth(), userHandler.UpdatePreferences)
+		api.GET("/users/blocks", authMiddleware.RequireAuth(), blockHandler.ListBlocks)
+		api.POST("/users/blocks/:userId", authMiddleware.RequireAuth(), blockHandler.BlockUser)
+		api.DELETE("/users/blocks/:userId", authMiddleware.RequireAuth(), blockHandler.UnblockUser)
 
 		api.GET("/survey/questions", surveyHandler.GetQuestions)
 		api.POST("/survey/responses", authMiddleware.RequireAuth(), surveyHandler.SubmitResponse)
@@ -111,7 +139,13 @@ func NewRouter(options RouterOptions) *gin.Engine {
 		api.GET("/messages/:matchId", authMiddleware.RequireAuth(), messageHandler.GetMessages)
 		api.POST("/messages/send/:matchId", authMiddleware.RequireAuth(), messageHandler.SendMessage)
 		api.PUT("/messages/read", authMiddleware.RequireAuth(), messageHandler.MarkAsRead)
+		api.PUT("/messages/edit/:messageId", authMiddleware.RequireAuth(), messageHandler.EditMessage)
+		api.POST("/messages/unsend/:messageId", authMiddleware.RequireAuth(), messageHandler.UnsendMessage)
+		api.DELETE("/messages/conversations/:matchId", authMiddleware.RequireAuth(), messageHandler.DeleteConversation)
+		api.GET("/messages/edits/:messageId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), messageHandler.GetMessageEdits)
 		api.POST("/messages/unlock/:campaignId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), messageHandler.UnlockMessaging)
+		api.GET("/ws", authMiddleware.RequireStreamAuth(), realtimeHandler.Connect)
+		api.GET("/events", authMiddleware.RequireStreamAuth(), eventStreamHandler.Stream)
 
 		api.POST("/crush-list", authMiddleware.RequireAuth(), crushHandler.SubmitCrushList)
 		api.GET("/crush-list", authMiddleware.RequireAuth(), crushHandler.GetCrushList)
@@ -137,8 +171,24 @@ func NewRouter(options RouterOptions) *gin.Engine {
 		api.PUT("/admin/questions/:questionId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.UpdateQuestion)
 		api.DELETE("/admin/questions/:questionId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.DeleteQuestion)
 		api.POST("/admin/generate-matches", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GenerateMatches)
+		api.POST("/admin/generate-matches/preview", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.PreviewMatches)
+		api.GET("/admin/jobs", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.ListJobs)
+		api.POST("/admin/jobs/generate-matches", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.StartGenerateMatchesJob)
+		api.POST("/admin/jobs/match-new-users", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.StartMatchNewUsersJob)
+		api.GET("/admin/jobs/:jobId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GetJob)
+		api.GET("/admin/jobs/:jobId/report", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GetJobReport)
+		api.POST("/admin/jobs/:jobId/cancel", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.CancelJob)
+		api.GET("/admin/match-runs", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.ListMatchRuns)
+		api.POST("/admin/match-runs", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.CreateMatchRun)
+		api.POST("/admin/match-runs/rollback", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.RollbackMatchRun)
+		api.GET("/admin/match-runs/:runId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GetMatchRun)
+		api.POST("/admin/match-runs/:runId/publish", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.PublishMatchRun)
 		api.GET("/admin/matches", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GetAllMatches)
 		api.POST("/admin/manual-match", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.CreateManualMatch)
+		api.GET("/admin/pair-constraints", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.ListPairConstraints)
+		api.POST("/admin/pair-constraints", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.CreatePairConstraint)
+		api.PUT("/admin/pair-constraints/:constraintId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.UpdatePairConstraint)
+		api.DELETE("/admin/pair-constraints/:constraintId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.DeletePairConstraint)
 		api.DELETE("/admin/matches/:matchId", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.DeleteMatch)
 		api.GET("/admin/eligible-users", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.GetEligibleUsers)
 		api.PUT("/admin/settings", authMiddleware.RequireAuth(), adminMiddleware.RequireAdmin(), adminHandler.UpdateSettings)
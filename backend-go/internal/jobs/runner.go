@@ -0,0 +1,283 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Store is the part of the repository the runner needs.
+type Store interface {
+	ClaimJob(ctx context.Context, arg repository.ClaimJobParams) (repository.Job, error)
+	HeartbeatJob(ctx context.Context, arg repository.HeartbeatJobParams) (bool, error)
+	CompleteJob(ctx context.Context, arg repository.CompleteJobParams) error
+	FailJob(ctx context.Context, arg repository.FailJobParams) error
+	MarkJobCancelled(ctx context.Context, arg repository.MarkJobCancelledParams) error
+	ReleaseJob(ctx context.Context, arg repository.ReleaseJobParams) error
+	FailAbandonedJobs(ctx context.Context, staleBefore pgtype.Timestamptz) (int64, error)
+}
+
+// Handler executes one job. The returned value is stored as the job result.
+// Handlers must return promptly once ctx is cancelled, which happens when an
+// admin cancels the job or the process shuts down.
+type Handler func(ctx context.Context, job repository.Job, progress *Progress) (any, error)
+
+// Progress is handed to a handler to expose its progress. The runner stores
+// the latest value with every heartbeat and once more when the job ends.
+type Progress struct {
+	mu     sync.Mutex
+	source func() any
+}
+
+// Track sets a function that is polled for the current progress.
+func (p *Progress) Track(source func() any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source = source
+}
+
+func (p *Progress) Set(value any) {
+	p.Track(func() any { return value })
+}
+
+func (p *Progress) encode() []byte {
+	p.mu.Lock()
+	source := p.source
+	p.mu.Unlock()
+	if source == nil {
+		return []byte("{}")
+	}
+	raw, err := json.Marshal(source())
+	if err != nil {
+		return []byte("{}")
+	}
+	return raw
+}
+
+type Options struct {
+	WorkerID          string
+	Workers           int
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long a running job may go without a heartbeat before
+	// another worker reclaims it, e.g. after the process was killed.
+	StaleAfter time.Duration
+}
+
+type Runner struct {
+	store    Store
+	opts     Options
+	handlers map[string]Handler
+	types    []string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(store Store, opts Options) *Runner {
+	if opts.WorkerID == "" {
+		host, _ := os.Hostname()
+		opts.WorkerID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = 2 * time.Second
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = 30 * time.Second
+	}
+	return &Runner{store: store, opts: opts, handlers: map[string]Handler{}}
+}
+
+func (r *Runner) Register(jobType string, handler Handler) {
+	if _, ok := r.handlers[jobType]; !ok {
+		r.types = append(r.types, jobType)
+	}
+	r.handlers[jobType] = handler
+}
+
+// Start launches the workers. They run until Shutdown is called or ctx ends.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	for i := 0; i < r.opts.Workers; i++ {
+		r.wg.Add(1)
+		go func(worker int) {
+			defer r.wg.Done()
+			r.work(ctx, fmt.Sprintf("%s/%d", r.opts.WorkerID, worker))
+		}(i)
+	}
+}
+
+// Shutdown stops claiming jobs, cancels running ones (they are put back in
+// the queue) and waits for the workers to finish or ctx to expire.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) work(ctx context.Context, workerID string) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		job, err := r.store.ClaimJob(ctx, repository.ClaimJobParams{
+			WorkerID:    pgtype.Text{String: workerID, Valid: true},
+			JobTypes:    r.types,
+			StaleBefore: pgtype.Timestamptz{Time: time.Now().Add(-r.opts.StaleAfter), Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.failAbandoned(ctx)
+			} else if ctx.Err() == nil {
+				log.Printf("jobs: claim failed: %v", err)
+			}
+			timer.Reset(r.opts.PollInterval)
+			continue
+		}
+
+		r.run(ctx, workerID, job)
+		timer.Reset(0)
+	}
+}
+
+func (r *Runner) failAbandoned(ctx context.Context) {
+	stale := pgtype.Timestamptz{Time: time.Now().Add(-r.opts.StaleAfter), Valid: true}
+	if _, err := r.store.FailAbandonedJobs(ctx, stale); err != nil && ctx.Err() == nil {
+		log.Printf("jobs: failing abandoned jobs: %v", err)
+	}
+}
+
+func (r *Runner) run(ctx context.Context, workerID string, job repository.Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Every write below is conditional on still holding the lock, so a worker
+	// that lost the job to another never overwrites its outcome.
+	lock := pgtype.Text{String: workerID, Valid: true}
+
+	progress := &Progress{}
+	var cancelled, lost atomic.Bool
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(r.opts.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+			}
+			cancelRequested, err := r.store.HeartbeatJob(context.WithoutCancel(ctx), repository.HeartbeatJobParams{
+				ID:       job.ID,
+				Progress: progress.encode(),
+				LockedBy: lock,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Another worker reclaimed the job as stale; it owns the
+				// outcome now.
+				log.Printf("jobs: lost the lock on %s", job.ID)
+				lost.Store(true)
+				cancel()
+				return
+			}
+			if err != nil {
+				log.Printf("jobs: heartbeat for %s failed: %v", job.ID, err)
+				continue
+			}
+			if cancelRequested {
+				cancelled.Store(true)
+				cancel()
+			}
+		}
+	}()
+
+	result, err := r.execute(jobCtx, job, progress)
+	close(stopHeartbeat)
+	<-heartbeatDone
+	if lost.Load() {
+		return
+	}
+
+	finishCtx, cancelFinish := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancelFinish()
+	state := progress.encode()
+	switch {
+	case err == nil:
+		raw, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			err = r.store.FailJob(finishCtx, repository.FailJobParams{
+				ID:           job.ID,
+				Progress:     state,
+				ErrorMessage: pgtype.Text{String: marshalErr.Error(), Valid: true},
+				LockedBy:     lock,
+			})
+			break
+		}
+		err = r.store.CompleteJob(finishCtx, repository.CompleteJobParams{ID: job.ID, Progress: state, Result: raw, LockedBy: lock})
+	case cancelled.Load():
+		err = r.store.MarkJobCancelled(finishCtx, repository.MarkJobCancelledParams{ID: job.ID, Progress: state, LockedBy: lock})
+	case ctx.Err() != nil:
+		err = r.store.ReleaseJob(finishCtx, repository.ReleaseJobParams{ID: job.ID, Progress: state, LockedBy: lock})
+	default:
+		err = r.store.FailJob(finishCtx, repository.FailJobParams{
+			ID:           job.ID,
+			Progress:     state,
+			ErrorMessage: pgtype.Text{String: err.Error(), Valid: true},
+			LockedBy:     lock,
+		})
+	}
+	if err != nil {
+		log.Printf("jobs: recording outcome of %s failed: %v", job.ID, err)
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, job repository.Job, progress *Progress) (result any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return r.handlers[job.JobType](ctx, job, progress)
+}
@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	queue    []repository.Job
+	outcome  map[uuid.UUID]string
+	result   map[uuid.UUID][]byte
+	progress map[uuid.UUID][]byte
+	cancel   map[uuid.UUID]bool
+	lost     map[uuid.UUID]bool
+	done     chan uuid.UUID
+}
+
+func newFakeStore(jobs ...repository.Job) *fakeStore {
+	return &fakeStore{
+		queue:    jobs,
+		outcome:  map[uuid.UUID]string{},
+		result:   map[uuid.UUID][]byte{},
+		progress: map[uuid.UUID][]byte{},
+		cancel:   map[uuid.UUID]bool{},
+		lost:     map[uuid.UUID]bool{},
+		done:     make(chan uuid.UUID, len(jobs)),
+	}
+}
+
+func (f *fakeStore) ClaimJob(ctx context.Context, arg repository.ClaimJobParams) (repository.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.queue) == 0 {
+		return repository.Job{}, pgx.ErrNoRows
+	}
+	job := f.queue[0]
+	f.queue = f.queue[1:]
+	job.Status = StatusRunning
+	return job, nil
+}
+
+func (f *fakeStore) HeartbeatJob(ctx context.Context, arg repository.HeartbeatJobParams) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lost[arg.ID] {
+		return false, pgx.ErrNoRows
+	}
+	f.progress[arg.ID] = arg.Progress
+	return f.cancel[arg.ID], nil
+}
+
+func (f *fakeStore) finish(id uuid.UUID, status string, progress []byte) {
+	f.mu.Lock()
+	f.outcome[id] = status
+	f.progress[id] = progress
+	f.mu.Unlock()
+	f.done <- id
+}
+
+func (f *fakeStore) CompleteJob(ctx context.Context, arg repository.CompleteJobParams) error {
+	f.mu.Lock()
+	f.result[arg.ID] = arg.Result
+	f.mu.Unlock()
+	f.finish(arg.ID, StatusSucceeded, arg.Progress)
+	return nil
+}
+
+func (f *fakeStore) FailJob(ctx context.Context, arg repository.FailJobParams) error {
+	f.finish(arg.ID, StatusFailed, arg.Progress)
+	return nil
+}
+
+func (f *fakeStore) MarkJobCancelled(ctx context.Context, arg repository.MarkJobCancelledParams) error {
+	f.finish(arg.ID, StatusCancelled, arg.Progress)
+	return nil
+}
+
+func (f *fakeStore) ReleaseJob(ctx context.Context, arg repository.ReleaseJobParams) error {
+	f.finish(arg.ID, StatusQueued, arg.Progress)
+	return nil
+}
+
+func (f *fakeStore) FailAbandonedJobs(ctx context.Context, staleBefore pgtype.Timestamptz) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeStore) status(id uuid.UUID) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.outcome[id]
+}
+
+func waitFor(t *testing.T, store *fakeStore, id uuid.UUID) {
+	t.Helper()
+	select {
+	case got := <-store.done:
+		if got != id {
+			t.Fatalf("expected job %s to finish, got %s", id, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("job %s did not finish", id)
+	}
+}
+
+func testRunner(store Store) *Runner {
+	return NewRunner(store, Options{
+		WorkerID:          "test",
+		PollInterval:      5 * time.Millisecond,
+		HeartbeatInterval: 5 * time.Millisecond,
+	})
+}
+
+func TestRunnerRecordsResults(t *testing.T) {
+	ok := repository.Job{ID: uuid.New(), JobType: "echo", Payload: []byte(`"hi"`)}
+	broken := repository.Job{ID: uuid.New(), JobType: "echo", Payload: []byte(`"fail"`)}
+	panics := repository.Job{ID: uuid.New(), JobType: "echo", Payload: []byte(`"panic"`)}
+	store := newFakeStore(ok, broken, panics)
+
+	runner := testRunner(store)
+	runner.Register("echo", func(ctx context.Context, job repository.Job, progress *Progress) (any, error) {
+		progress.Set(map[string]int{"step": 1})
+		switch string(job.Payload) {
+		case `"fail"`:
+			return nil, errors.New("boom")
+		case `"panic"`:
+			panic("unexpected")
+		}
+		return map[string]string{"echo": string(job.Payload)}, nil
+	})
+	runner.Start(context.Background())
+	defer runner.Shutdown(context.Background())
+
+	waitFor(t, store, ok.ID)
+	waitFor(t, store, broken.ID)
+	waitFor(t, store, panics.ID)
+
+	if store.status(ok.ID) != StatusSucceeded || string(store.result[ok.ID]) != `{"echo":"\"hi\""}` {
+		t.Fatalf("unexpected outcome for successful job: %s %s", store.status(ok.ID), store.result[ok.ID])
+	}
+	if string(store.progress[ok.ID]) != `{"step":1}` {
+		t.Fatalf("expected final progress to be stored, got %s", store.progress[ok.ID])
+	}
+	if store.status(broken.ID) != StatusFailed || store.status(panics.ID) != StatusFailed {
+		t.Fatalf("expected failing and panicking jobs to fail, got %s and %s", store.status(broken.ID), store.status(panics.ID))
+	}
+}
+
+func TestRunnerCancelsOnRequest(t *testing.T) {
+	job := repository.Job{ID: uuid.New(), JobType: "wait"}
+	store := newFakeStore(job)
+	store.cancel[job.ID] = true
+
+	runner := testRunner(store)
+	runner.Register("wait", func(ctx context.Context, job repository.Job, progress *Progress) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	runner.Start(context.Background())
+	defer runner.Shutdown(context.Background())
+
+	waitFor(t, store, job.ID)
+	if store.status(job.ID) != StatusCancelled {
+		t.Fatalf("expected job to be cancelled, got %s", store.status(job.ID))
+	}
+}
+
+func TestRunnerShutdownRequeuesRunningJob(t *testing.T) {
+	job := repository.Job{ID: uuid.New(), JobType: "wait"}
+	store := newFakeStore(job)
+	started := make(chan struct{})
+
+	runner := testRunner(store)
+	runner.Register("wait", func(ctx context.Context, job repository.Job, progress *Progress) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	runner.Start(context.Background())
+	<-started
+
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	waitFor(t, store, job.ID)
+	if store.status(job.ID) != StatusQueued {
+		t.Fatalf("expected job to be released back to the queue, got %s", store.status(job.ID))
+	}
+}
+
+func TestRunnerStopsAfterLosingTheLock(t *testing.T) {
+	job := repository.Job{ID: uuid.New(), JobType: "wait"}
+	store := newFakeStore(job)
+	store.lost[job.ID] = true
+	returned := make(chan struct{})
+
+	runner := testRunner(store)
+	runner.Register("wait", func(ctx context.Context, job repository.Job, progress *Progress) (any, error) {
+		defer close(returned)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	runner.Start(context.Background())
+
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the job to be cancelled once its lock was lost")
+	}
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if status := store.status(job.ID); status != "" {
+		t.Fatalf("expected no outcome from a worker without the lock, got %s", status)
+	}
+}
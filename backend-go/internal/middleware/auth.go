@@ -24,32 +24,54 @@ func NewAuthMiddleware(secret string) *AuthMiddleware {
 
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Authentication required"})
-			c.Abort()
-			return
-		}
+		m.authenticate(c, bearerToken(c))
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return m.jwtSecret, nil
-		})
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid token"})
-			c.Abort()
-			return
+// RequireStreamAuth also accepts the token in the "token" query parameter,
+// since browsers cannot set headers on WebSocket and EventSource requests.
+func (m *AuthMiddleware) RequireStreamAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			token = c.Query("token")
 		}
+		m.authenticate(c, token)
+	}
+}
 
-		claims, ok := token.Claims.(*Claims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid token"})
-			c.Abort()
-			return
-		}
+func bearerToken(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(authHeader, "Bearer ")
+}
+
+func (m *AuthMiddleware) authenticate(c *gin.Context, tokenString string) {
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Authentication required"})
+		c.Abort()
+		return
+	}
 
-		c.Set("userId", claims.UserID)
-		c.Set("userEmail", claims.Email)
-		c.Next()
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return m.jwtSecret, nil
+	})
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid token"})
+		c.Abort()
+		return
 	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	c.Set("userId", claims.UserID)
+	c.Set("userEmail", claims.Email)
+	c.Next()
 }
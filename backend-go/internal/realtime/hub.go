@@ -0,0 +1,180 @@
+// Package realtime pushes events to the live connections of users. Each API
+// instance keeps a Hub of its own connections; events are published through
+// Postgres NOTIFY so every instance delivers them, whichever one raised them.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventMessageCreated      = "message.created"
+	EventMessagesRead        = "messages.read"
+	EventMessageUpdated      = "message.updated"
+	EventMatchCreated        = "match.created"
+	EventMatchUpdated        = "match.updated"
+	EventMatchMutualInterest = "match.mutual_interest"
+	EventCrushMutual         = "crush.mutual"
+	EventPhaseChanged        = "campaign.phase_changed"
+)
+
+// subscriptionBuffer is how many events a connection may fall behind before
+// the hub drops it.
+const subscriptionBuffer = 64
+
+// Event is delivered to every connection of the users in UserIDs, or of
+// everyone when Broadcast is set. Data is left out when it was too large to
+// publish; clients then refetch over REST.
+//
+// ID is the event's entry in the event log once a Recorder has logged it.
+// Key, when set, makes the event one-off: a Recorder logs and delivers only
+// the first event with a given key.
+type Event struct {
+	ID        int64           `json:"id,omitempty"`
+	Type      string          `json:"type"`
+	UserIDs   []uuid.UUID     `json:"userIds"`
+	Broadcast bool            `json:"broadcast,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+	Key       string          `json:"-"`
+}
+
+func NewEvent(eventType string, data any, userIDs ...uuid.UUID) Event {
+	event := Event{Type: eventType, UserIDs: userIDs}
+	if raw, err := json.Marshal(data); err == nil {
+		event.Data = raw
+	} else {
+		event.Truncated = true
+	}
+	return event
+}
+
+// NewBroadcast returns an event for every user.
+func NewBroadcast(eventType string, data any) Event {
+	event := NewEvent(eventType, data)
+	event.Broadcast = true
+	return event
+}
+
+// Publisher sends an event to its users, wherever they are connected.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+	closed      bool
+}
+
+// Subscription receives the events of one user's connection. Its channel is
+// closed when the subscription ends, whether the connection went away, the
+// hub dropped it for falling behind or the hub shut down.
+type Subscription struct {
+	UserID uuid.UUID
+	events chan Event
+	hub    *Hub
+}
+
+var _ Publisher = (*Hub)(nil)
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[uuid.UUID]map[*Subscription]struct{}{}}
+}
+
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := &Subscription{UserID: userID, events: make(chan Event, subscriptionBuffer), hub: h}
+	if h.closed {
+		close(sub.events)
+		return sub
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	return sub
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// remove must be called with h.mu held.
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.subscribers[sub.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.UserID)
+	}
+	close(sub.events)
+}
+
+// Dispatch delivers the event to this instance's connections. A connection
+// that cannot keep up is dropped rather than allowed to stall the others.
+func (h *Hub) Dispatch(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if event.Broadcast {
+		for _, subs := range h.subscribers {
+			h.deliver(subs, event)
+		}
+		return
+	}
+	for _, userID := range event.UserIDs {
+		h.deliver(h.subscribers[userID], event)
+	}
+}
+
+// deliver must be called with h.mu held.
+func (h *Hub) deliver(subs map[*Subscription]struct{}, event Event) {
+	for sub := range subs {
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Publish delivers the event locally, which is all a single instance needs.
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	h.Dispatch(event)
+	return nil
+}
+
+// Connected reports how many connections the user has on this instance.
+func (h *Hub) Connected(userID uuid.UUID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[userID])
+}
+
+// Close ends every subscription and refuses new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHubDeliversToTheEventsUsers(t *testing.T) {
+	hub := NewHub()
+	alice, bob := uuid.New(), uuid.New()
+	phone, laptop, other := hub.Subscribe(alice), hub.Subscribe(alice), hub.Subscribe(bob)
+
+	if err := hub.Publish(context.Background(), NewEvent(EventMessageCreated, map[string]string{"content": "hi"}, alice)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for _, sub := range []*Subscription{phone, laptop} {
+		event := <-sub.Events()
+		if event.Type != EventMessageCreated || string(event.Data) != `{"content":"hi"}` {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	}
+	select {
+	case event := <-other.Events():
+		t.Fatalf("expected nothing for another user, got %+v", event)
+	default:
+	}
+
+	phone.Close()
+	phone.Close()
+	if hub.Connected(alice) != 1 {
+		t.Fatalf("expected one connection left, got %d", hub.Connected(alice))
+	}
+	hub.Close()
+	if _, ok := <-laptop.Events(); ok {
+		t.Fatalf("expected closing the hub to end subscriptions")
+	}
+	if _, ok := <-hub.Subscribe(bob).Events(); ok {
+		t.Fatalf("expected a closed hub to refuse subscriptions")
+	}
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	user := uuid.New()
+	sub := hub.Subscribe(user)
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Dispatch(NewEvent(EventMatchUpdated, i, user))
+	}
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	if received != subscriptionBuffer || hub.Connected(user) != 0 {
+		t.Fatalf("expected the subscriber to be dropped after %d events, got %d", subscriptionBuffer, received)
+	}
+}
+
+func TestEncodePayloadTruncatesLargeEvents(t *testing.T) {
+	payload, err := encodePayload(NewEvent(EventMessageCreated, strings.Repeat("x", maxPayload), uuid.New()))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if len(payload) > maxPayload || !strings.Contains(string(payload), `"truncated":true`) {
+		t.Fatalf("expected a truncated payload, got %d bytes", len(payload))
+	}
+}
@@ -0,0 +1,105 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres channel events are published on.
+const Channel = "wizardmatch_events"
+
+// maxPayload keeps notifications under the 8000 byte limit Postgres puts on
+// NOTIFY payloads.
+const maxPayload = 7900
+
+// Notifier publishes events with pg_notify, so that every instance running
+// a Listener delivers them, this one included.
+type Notifier struct {
+	pool *pgxpool.Pool
+}
+
+var _ Publisher = (*Notifier)(nil)
+
+func NewNotifier(pool *pgxpool.Pool) *Notifier {
+	return &Notifier{pool: pool}
+}
+
+func (n *Notifier) Publish(ctx context.Context, event Event) error {
+	payload, err := encodePayload(event)
+	if err != nil {
+		return err
+	}
+	_, err = n.pool.Exec(ctx, "SELECT pg_notify($1, $2)", Channel, string(payload))
+	return err
+}
+
+// encodePayload drops the event data when the event would not fit in a
+// notification.
+func encodePayload(event Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil || len(payload) <= maxPayload {
+		return payload, err
+	}
+	event.Data = nil
+	event.Truncated = true
+	return json.Marshal(event)
+}
+
+// Listener holds a connection of its own outside the pool, since a pooled
+// connection would stop listening once handed to another query.
+type Listener struct {
+	config *pgx.ConnConfig
+	hub    *Hub
+	retry  time.Duration
+}
+
+func NewListener(config *pgx.ConnConfig, hub *Hub) *Listener {
+	return &Listener{config: config, hub: hub, retry: 2 * time.Second}
+}
+
+// Run feeds the hub with every event published on Channel until ctx is
+// cancelled, reconnecting whenever the connection drops. Events published
+// while it reconnects are missed.
+func (l *Listener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("realtime listener: %v; reconnecting in %s", err, l.retry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, l.config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("realtime listener: dropping malformed event: %v", err)
+			continue
+		}
+		l.hub.Dispatch(event)
+	}
+}
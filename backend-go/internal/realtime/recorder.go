@@ -0,0 +1,73 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// EventLog stores events for clients to replay; repository.Querier is one.
+type EventLog interface {
+	CreateUserEvent(ctx context.Context, arg repository.CreateUserEventParams) (repository.UserEvent, error)
+}
+
+// Recorder logs events before passing them on, so that a client which lost
+// its connection can catch up on what it missed. Each user gets an entry of
+// their own, and a broadcast a single entry; the events passed on carry the
+// ID of their entry.
+type Recorder struct {
+	log  EventLog
+	next Publisher
+}
+
+var _ Publisher = (*Recorder)(nil)
+
+func NewRecorder(log EventLog, next Publisher) *Recorder {
+	return &Recorder{log: log, next: next}
+}
+
+func (r *Recorder) Publish(ctx context.Context, event Event) error {
+	if event.Broadcast {
+		return r.record(ctx, event, pgtype.UUID{}, event.Key)
+	}
+	var errs []error
+	for _, userID := range event.UserIDs {
+		key := event.Key
+		if key != "" {
+			key += ":" + userID.String()
+		}
+		errs = append(errs, r.record(ctx, event, pgtype.UUID{Bytes: userID, Valid: true}, key))
+	}
+	return errors.Join(errs...)
+}
+
+// record logs the event for one user, or for everyone when userID is null,
+// and passes it on. An event the log fails to take is still delivered live.
+func (r *Recorder) record(ctx context.Context, event Event, userID pgtype.UUID, key string) error {
+	payload := event.Data
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	entry, err := r.log.CreateUserEvent(ctx, repository.CreateUserEventParams{
+		UserID:    userID,
+		EventType: event.Type,
+		Payload:   payload,
+		DedupeKey: pgtype.Text{String: key, Valid: key != ""},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already logged under the same key.
+		return nil
+	}
+	event.ID = entry.ID
+	event.Key = ""
+	if userID.Valid {
+		event.UserIDs = []uuid.UUID{userID.Bytes}
+	}
+	return errors.Join(err, r.next.Publish(ctx, event))
+}
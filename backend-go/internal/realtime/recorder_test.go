@@ -0,0 +1,81 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestRecorderLogsEventsPerUser(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	var users []uuid.UUID
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: email, IsActive: true})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	alice, bob := users[0], users[1]
+
+	hub := NewHub()
+	recorder := NewRecorder(store, hub)
+	aliceSub, bobSub := hub.Subscribe(alice), hub.Subscribe(bob)
+
+	if err := recorder.Publish(ctx, NewEvent(EventMessageCreated, map[string]string{"content": "hi"}, alice, bob)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	keyed := NewEvent(EventCrushMutual, nil, alice)
+	keyed.Key = "crush"
+	for i := 0; i < 2; i++ {
+		if err := recorder.Publish(ctx, keyed); err != nil {
+			t.Fatalf("publish keyed: %v", err)
+		}
+	}
+	broadcast := NewBroadcast(EventPhaseChanged, map[string]string{"phase": "survey_open"})
+	broadcast.Key = "phase"
+	for i := 0; i < 2; i++ {
+		if err := recorder.Publish(ctx, broadcast); err != nil {
+			t.Fatalf("publish broadcast: %v", err)
+		}
+	}
+
+	// Alice sees her copy of the message, the keyed event once and the
+	// broadcast once, each carrying the ID of its log entry.
+	for _, want := range []string{EventMessageCreated, EventCrushMutual, EventPhaseChanged} {
+		event := <-aliceSub.Events()
+		if event.Type != want || event.ID == 0 || event.Key != "" {
+			t.Fatalf("expected %s with an ID, got %+v", want, event)
+		}
+		if !event.Broadcast && (len(event.UserIDs) != 1 || event.UserIDs[0] != alice) {
+			t.Fatalf("expected the event to be addressed to alice alone, got %v", event.UserIDs)
+		}
+	}
+	for _, want := range []string{EventMessageCreated, EventPhaseChanged} {
+		if event := <-bobSub.Events(); event.Type != want {
+			t.Fatalf("expected %s for bob, got %+v", want, event)
+		}
+	}
+	select {
+	case event := <-aliceSub.Events():
+		t.Fatalf("expected duplicates to be dropped, got %+v", event)
+	default:
+	}
+
+	logged, err := store.ListUserEventsAfter(ctx, repository.ListUserEventsAfterParams{
+		UserID: pgtype.UUID{Bytes: bob, Valid: true},
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(logged) != 2 || logged[0].EventType != EventMessageCreated || string(logged[0].Payload) != `{"content":"hi"}` || logged[1].UserID.Valid {
+		t.Fatalf("expected bob's message and the broadcast in the log, got %+v", logged)
+	}
+}
@@ -12,7 +12,7 @@ import (
 )
 
 const averageCompatibilityScore = `-- name: AverageCompatibilityScore :one
-SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches
+SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE superseded_at IS NULL
 `
 
 func (q *Queries) AverageCompatibilityScore(ctx context.Context) (float64, error) {
@@ -23,7 +23,7 @@ func (q *Queries) AverageCompatibilityScore(ctx context.Context) (float64, error
 }
 
 const averageCompatibilityScoreByCampaign = `-- name: AverageCompatibilityScoreByCampaign :one
-SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE campaign_id = $1
+SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL
 `
 
 func (q *Queries) AverageCompatibilityScoreByCampaign(ctx context.Context, campaignID pgtype.UUID) (float64, error) {
@@ -56,7 +56,7 @@ func (q *Queries) CountCompletedSurveys(ctx context.Context) (int64, error) {
 }
 
 const countMatches = `-- name: CountMatches :one
-SELECT COUNT(*) FROM matches
+SELECT COUNT(*) FROM matches WHERE superseded_at IS NULL
 `
 
 func (q *Queries) CountMatches(ctx context.Context) (int64, error) {
@@ -67,7 +67,7 @@ func (q *Queries) CountMatches(ctx context.Context) (int64, error) {
 }
 
 const countMatchesByCampaign = `-- name: CountMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE campaign_id = $1
+SELECT COUNT(*) FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL
 `
 
 func (q *Queries) CountMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
@@ -78,7 +78,7 @@ func (q *Queries) CountMatchesByCampaign(ctx context.Context, campaignID pgtype.
 }
 
 const countMutualMatches = `-- name: CountMutualMatches :one
-SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE
+SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND superseded_at IS NULL
 `
 
 func (q *Queries) CountMutualMatches(ctx context.Context) (int64, error) {
@@ -89,7 +89,7 @@ func (q *Queries) CountMutualMatches(ctx context.Context) (int64, error) {
 }
 
 const countMutualMatchesByCampaign = `-- name: CountMutualMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND campaign_id = $1
+SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND campaign_id = $1 AND superseded_at IS NULL
 `
 
 func (q *Queries) CountMutualMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
@@ -100,7 +100,7 @@ func (q *Queries) CountMutualMatchesByCampaign(ctx context.Context, campaignID p
 }
 
 const countRevealedMatches = `-- name: CountRevealedMatches :one
-SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE
+SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND superseded_at IS NULL
 `
 
 func (q *Queries) CountRevealedMatches(ctx context.Context) (int64, error) {
@@ -111,7 +111,7 @@ func (q *Queries) CountRevealedMatches(ctx context.Context) (int64, error) {
 }
 
 const countRevealedMatchesByCampaign = `-- name: CountRevealedMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND campaign_id = $1
+SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND campaign_id = $1 AND superseded_at IS NULL
 `
 
 func (q *Queries) CountRevealedMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
@@ -124,6 +124,7 @@ func (q *Queries) CountRevealedMatchesByCampaign(ctx context.Context, campaignID
 const matchesByTier = `-- name: MatchesByTier :many
 SELECT match_tier, COUNT(*) AS count
 FROM matches
+WHERE superseded_at IS NULL
 GROUP BY match_tier
 `
 
@@ -155,7 +156,7 @@ func (q *Queries) MatchesByTier(ctx context.Context) ([]MatchesByTierRow, error)
 const matchesByTierByCampaign = `-- name: MatchesByTierByCampaign :many
 SELECT match_tier, COUNT(*) AS count
 FROM matches
-WHERE campaign_id = $1
+WHERE campaign_id = $1 AND superseded_at IS NULL
 GROUP BY match_tier
 `
 
@@ -256,7 +256,8 @@ func (q *Queries) UpdateCampaign(ctx context.Context, arg UpdateCampaignParams)
 const updateCampaignStats = `-- name: UpdateCampaignStats :exec
 UPDATE campaigns SET
     total_participants = $2,
-    total_matches_generated = $3
+    total_matches_generated = $3,
+    algorithm_version = $4
 WHERE id = $1
 `
 
@@ -264,9 +265,15 @@ type UpdateCampaignStatsParams struct {
 	ID                    uuid.UUID   `json:"id"`
 	TotalParticipants     pgtype.Int4 `json:"total_participants"`
 	TotalMatchesGenerated pgtype.Int4 `json:"total_matches_generated"`
+	AlgorithmVersion      pgtype.Text `json:"algorithm_version"`
 }
 
 func (q *Queries) UpdateCampaignStats(ctx context.Context, arg UpdateCampaignStatsParams) error {
-	_, err := q.db.Exec(ctx, updateCampaignStats, arg.ID, arg.TotalParticipants, arg.TotalMatchesGenerated)
+	_, err := q.db.Exec(ctx, updateCampaignStats,
+		arg.ID,
+		arg.TotalParticipants,
+		arg.TotalMatchesGenerated,
+		arg.AlgorithmVersion,
+	)
 	return err
 }
@@ -185,3 +185,12 @@ func (q *Queries) ListCrushesForUserCampaign(ctx context.Context, arg ListCrushe
 	}
 	return items, nil
 }
+
+const markCrushMutual = `-- name: MarkCrushMutual :exec
+UPDATE crush_lists SET is_mutual = TRUE WHERE id = $1
+`
+
+func (q *Queries) MarkCrushMutual(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markCrushMutual, id)
+	return err
+}
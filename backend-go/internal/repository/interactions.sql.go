@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createInteraction = `-- name: CreateInteraction :one
@@ -67,3 +68,105 @@ func (q *Queries) FindInterestByMatchOtherUser(ctx context.Context, arg FindInte
 	)
 	return i, err
 }
+
+const listMatchOutcomesByCampaign = `-- name: ListMatchOutcomesByCampaign :many
+SELECT
+    m.id,
+    m.user1_id,
+    m.user2_id,
+    m.compatibility_score,
+    BOOL_OR(i.user_id = m.user1_id AND i.interaction_type = 'interest')::boolean AS user1_interested,
+    BOOL_OR(i.user_id = m.user2_id AND i.interaction_type = 'interest')::boolean AS user2_interested,
+    BOOL_OR(i.user_id = m.user1_id AND i.interaction_type IN ('interest', 'not_interested', 'pass'))::boolean AS user1_decided,
+    BOOL_OR(i.user_id = m.user2_id AND i.interaction_type IN ('interest', 'not_interested', 'pass'))::boolean AS user2_decided
+FROM matches m
+JOIN interactions i ON i.match_id = m.id
+WHERE m.campaign_id = $1
+GROUP BY m.id
+ORDER BY m.id
+`
+
+type ListMatchOutcomesByCampaignRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	User1ID            uuid.UUID      `json:"user1_id"`
+	User2ID            uuid.UUID      `json:"user2_id"`
+	CompatibilityScore pgtype.Numeric `json:"compatibility_score"`
+	User1Interested    bool           `json:"user1_interested"`
+	User2Interested    bool           `json:"user2_interested"`
+	User1Decided       bool           `json:"user1_decided"`
+	User2Decided       bool           `json:"user2_decided"`
+}
+
+func (q *Queries) ListMatchOutcomesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]ListMatchOutcomesByCampaignRow, error) {
+	rows, err := q.db.Query(ctx, listMatchOutcomesByCampaign, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMatchOutcomesByCampaignRow{}
+	for rows.Next() {
+		var i ListMatchOutcomesByCampaignRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.User1ID,
+			&i.User2ID,
+			&i.CompatibilityScore,
+			&i.User1Interested,
+			&i.User2Interested,
+			&i.User1Decided,
+			&i.User2Decided,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPairHistory = `-- name: ListPairHistory :many
+SELECT
+    m.user1_id,
+    m.user2_id,
+    COALESCE(BOOL_OR(m.campaign_id IS NOT NULL AND m.campaign_id <> $1), FALSE)::boolean AS matched,
+    COALESCE(BOOL_OR(i.interaction_type IN ('not_interested', 'report')), FALSE)::boolean AS rejected
+FROM matches m
+LEFT JOIN interactions i ON i.match_id = m.id
+GROUP BY m.user1_id, m.user2_id
+HAVING BOOL_OR(m.campaign_id IS DISTINCT FROM $1)
+    OR BOOL_OR(i.interaction_type IN ('not_interested', 'report'))
+`
+
+type ListPairHistoryRow struct {
+	User1ID  uuid.UUID `json:"user1_id"`
+	User2ID  uuid.UUID `json:"user2_id"`
+	Matched  bool      `json:"matched"`
+	Rejected bool      `json:"rejected"`
+}
+
+func (q *Queries) ListPairHistory(ctx context.Context, campaignID pgtype.UUID) ([]ListPairHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listPairHistory, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPairHistoryRow{}
+	for rows.Next() {
+		var i ListPairHistoryRow
+		if err := rows.Scan(
+			&i.User1ID,
+			&i.User2ID,
+			&i.Matched,
+			&i.Rejected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
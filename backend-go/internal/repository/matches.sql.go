@@ -9,10 +9,11 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const getMatchByID = `-- name: GetMatchByID :one
-SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at FROM matches WHERE id = $1 LIMIT 1
+SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at FROM matches WHERE id = $1 AND superseded_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetMatchByID(ctx context.Context, id uuid.UUID) (Match, error) {
@@ -35,12 +36,15 @@ func (q *Queries) GetMatchByID(ctx context.Context, id uuid.UUID) (Match, error)
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
 
 const listMatchesForUser = `-- name: ListMatchesForUser :many
-SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at FROM matches WHERE user1_id = $1 OR user2_id = $1 ORDER BY compatibility_score DESC
+SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at FROM matches WHERE (user1_id = $1 OR user2_id = $1) AND superseded_at IS NULL ORDER BY compatibility_score DESC
 `
 
 func (q *Queries) ListMatchesForUser(ctx context.Context, user1ID uuid.UUID) ([]Match, error) {
@@ -69,6 +73,9 @@ func (q *Queries) ListMatchesForUser(ctx context.Context, user1ID uuid.UUID) ([]
 			&i.CreatedAt,
 			&i.RevealedAt,
 			&i.UpdatedAt,
+			&i.MessagingUnlockedAt,
+			&i.MessagingUnlockReason,
+			&i.SupersededAt,
 		); err != nil {
 			return nil, err
 		}
@@ -81,7 +88,7 @@ func (q *Queries) ListMatchesForUser(ctx context.Context, user1ID uuid.UUID) ([]
 }
 
 const revealMatch = `-- name: RevealMatch :one
-UPDATE matches SET is_revealed = TRUE, revealed_at = NOW() WHERE id = $1 RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at
+UPDATE matches SET is_revealed = TRUE, revealed_at = NOW() WHERE id = $1 RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
 `
 
 func (q *Queries) RevealMatch(ctx context.Context, id uuid.UUID) (Match, error) {
@@ -104,6 +111,52 @@ func (q *Queries) RevealMatch(ctx context.Context, id uuid.UUID) (Match, error)
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
+	)
+	return i, err
+}
+
+const unlockMatchMessaging = `-- name: UnlockMatchMessaging :one
+UPDATE matches
+SET
+    messaging_unlocked = TRUE,
+    messaging_unlocked_at = NOW(),
+    messaging_unlock_reason = $2,
+    updated_at = NOW()
+WHERE id = $1 AND NOT messaging_unlocked
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
+`
+
+type UnlockMatchMessagingParams struct {
+	ID                    uuid.UUID   `json:"id"`
+	MessagingUnlockReason pgtype.Text `json:"messaging_unlock_reason"`
+}
+
+func (q *Queries) UnlockMatchMessaging(ctx context.Context, arg UnlockMatchMessagingParams) (Match, error) {
+	row := q.db.QueryRow(ctx, unlockMatchMessaging, arg.ID, arg.MessagingUnlockReason)
+	var i Match
+	err := row.Scan(
+		&i.ID,
+		&i.CampaignID,
+		&i.User1ID,
+		&i.User2ID,
+		&i.CompatibilityScore,
+		&i.MatchTier,
+		&i.SharedInterests,
+		&i.RankForUser1,
+		&i.RankForUser2,
+		&i.IsRevealed,
+		&i.IsMutualInterest,
+		&i.IsMutualCrush,
+		&i.MessagingUnlocked,
+		&i.CreatedAt,
+		&i.RevealedAt,
+		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
@@ -112,20 +165,18 @@ const updateMatchInterest = `-- name: UpdateMatchInterest :one
 UPDATE matches
 SET
     is_mutual_interest = $2,
-    messaging_unlocked = $3,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
 `
 
 type UpdateMatchInterestParams struct {
-	ID                uuid.UUID `json:"id"`
-	IsMutualInterest  bool      `json:"is_mutual_interest"`
-	MessagingUnlocked bool      `json:"messaging_unlocked"`
+	ID               uuid.UUID `json:"id"`
+	IsMutualInterest bool      `json:"is_mutual_interest"`
 }
 
 func (q *Queries) UpdateMatchInterest(ctx context.Context, arg UpdateMatchInterestParams) (Match, error) {
-	row := q.db.QueryRow(ctx, updateMatchInterest, arg.ID, arg.IsMutualInterest, arg.MessagingUnlocked)
+	row := q.db.QueryRow(ctx, updateMatchInterest, arg.ID, arg.IsMutualInterest)
 	var i Match
 	err := row.Scan(
 		&i.ID,
@@ -144,6 +195,9 @@ func (q *Queries) UpdateMatchInterest(ctx context.Context, arg UpdateMatchIntere
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
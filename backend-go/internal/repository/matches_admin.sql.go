@@ -13,7 +13,7 @@ import (
 )
 
 const countMatchesAll = `-- name: CountMatchesAll :one
-SELECT COUNT(*) FROM matches
+SELECT COUNT(*) FROM matches WHERE superseded_at IS NULL
 `
 
 func (q *Queries) CountMatchesAll(ctx context.Context) (int64, error) {
@@ -36,7 +36,7 @@ INSERT INTO matches (
     is_mutual_crush,
     is_revealed
 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
 `
 
 type CreateMatchParams struct {
@@ -83,6 +83,9 @@ func (q *Queries) CreateMatch(ctx context.Context, arg CreateMatchParams) (Match
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
@@ -105,8 +108,87 @@ func (q *Queries) DeleteMatchesByCampaign(ctx context.Context, campaignID pgtype
 	return err
 }
 
+const insertMatchIfAbsent = `-- name: InsertMatchIfAbsent :many
+INSERT INTO matches (
+    campaign_id,
+    user1_id,
+    user2_id,
+    compatibility_score,
+    match_tier,
+    shared_interests,
+    rank_for_user1,
+    rank_for_user2,
+    is_mutual_crush,
+    is_revealed
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE)
+ON CONFLICT (campaign_id, user1_id, user2_id) DO NOTHING
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
+`
+
+type InsertMatchIfAbsentParams struct {
+	CampaignID         pgtype.UUID    `json:"campaign_id"`
+	User1ID            uuid.UUID      `json:"user1_id"`
+	User2ID            uuid.UUID      `json:"user2_id"`
+	CompatibilityScore pgtype.Numeric `json:"compatibility_score"`
+	MatchTier          pgtype.Text    `json:"match_tier"`
+	SharedInterests    []byte         `json:"shared_interests"`
+	RankForUser1       pgtype.Int4    `json:"rank_for_user1"`
+	RankForUser2       pgtype.Int4    `json:"rank_for_user2"`
+	IsMutualCrush      bool           `json:"is_mutual_crush"`
+}
+
+func (q *Queries) InsertMatchIfAbsent(ctx context.Context, arg InsertMatchIfAbsentParams) ([]Match, error) {
+	rows, err := q.db.Query(ctx, insertMatchIfAbsent,
+		arg.CampaignID,
+		arg.User1ID,
+		arg.User2ID,
+		arg.CompatibilityScore,
+		arg.MatchTier,
+		arg.SharedInterests,
+		arg.RankForUser1,
+		arg.RankForUser2,
+		arg.IsMutualCrush,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Match{}
+	for rows.Next() {
+		var i Match
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.User1ID,
+			&i.User2ID,
+			&i.CompatibilityScore,
+			&i.MatchTier,
+			&i.SharedInterests,
+			&i.RankForUser1,
+			&i.RankForUser2,
+			&i.IsRevealed,
+			&i.IsMutualInterest,
+			&i.IsMutualCrush,
+			&i.MessagingUnlocked,
+			&i.CreatedAt,
+			&i.RevealedAt,
+			&i.UpdatedAt,
+			&i.MessagingUnlockedAt,
+			&i.MessagingUnlockReason,
+			&i.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMatches = `-- name: ListMatches :many
-SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at FROM matches ORDER BY compatibility_score DESC LIMIT $1 OFFSET $2
+SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at FROM matches WHERE superseded_at IS NULL ORDER BY compatibility_score DESC LIMIT $1 OFFSET $2
 `
 
 type ListMatchesParams struct {
@@ -140,6 +222,53 @@ func (q *Queries) ListMatches(ctx context.Context, arg ListMatchesParams) ([]Mat
 			&i.CreatedAt,
 			&i.RevealedAt,
 			&i.UpdatedAt,
+			&i.MessagingUnlockedAt,
+			&i.MessagingUnlockReason,
+			&i.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchesByCampaign = `-- name: ListMatchesByCampaign :many
+SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL ORDER BY compatibility_score DESC
+`
+
+func (q *Queries) ListMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]Match, error) {
+	rows, err := q.db.Query(ctx, listMatchesByCampaign, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Match{}
+	for rows.Next() {
+		var i Match
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.User1ID,
+			&i.User2ID,
+			&i.CompatibilityScore,
+			&i.MatchTier,
+			&i.SharedInterests,
+			&i.RankForUser1,
+			&i.RankForUser2,
+			&i.IsRevealed,
+			&i.IsMutualInterest,
+			&i.IsMutualCrush,
+			&i.MessagingUnlocked,
+			&i.CreatedAt,
+			&i.RevealedAt,
+			&i.UpdatedAt,
+			&i.MessagingUnlockedAt,
+			&i.MessagingUnlockReason,
+			&i.SupersededAt,
 		); err != nil {
 			return nil, err
 		}
@@ -151,16 +280,71 @@ func (q *Queries) ListMatches(ctx context.Context, arg ListMatchesParams) ([]Mat
 	return items, nil
 }
 
-const unlockMessagingByCampaign = `-- name: UnlockMessagingByCampaign :exec
-UPDATE matches SET messaging_unlocked = TRUE, updated_at = NOW()
-WHERE campaign_id = $1
+const lockCampaignMatches = `-- name: LockCampaignMatches :exec
+SELECT pg_advisory_xact_lock(hashtextextended($1::uuid::text, 0))
 `
 
-func (q *Queries) UnlockMessagingByCampaign(ctx context.Context, campaignID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, unlockMessagingByCampaign, campaignID)
+func (q *Queries) LockCampaignMatches(ctx context.Context, campaignID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockCampaignMatches, campaignID)
 	return err
 }
 
+const unlockMessagingByCampaign = `-- name: UnlockMessagingByCampaign :many
+UPDATE matches
+SET
+    messaging_unlocked = TRUE,
+    messaging_unlocked_at = NOW(),
+    messaging_unlock_reason = $2,
+    updated_at = NOW()
+WHERE campaign_id = $1 AND NOT messaging_unlocked
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
+`
+
+type UnlockMessagingByCampaignParams struct {
+	CampaignID            pgtype.UUID `json:"campaign_id"`
+	MessagingUnlockReason pgtype.Text `json:"messaging_unlock_reason"`
+}
+
+func (q *Queries) UnlockMessagingByCampaign(ctx context.Context, arg UnlockMessagingByCampaignParams) ([]Match, error) {
+	rows, err := q.db.Query(ctx, unlockMessagingByCampaign, arg.CampaignID, arg.MessagingUnlockReason)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Match{}
+	for rows.Next() {
+		var i Match
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.User1ID,
+			&i.User2ID,
+			&i.CompatibilityScore,
+			&i.MatchTier,
+			&i.SharedInterests,
+			&i.RankForUser1,
+			&i.RankForUser2,
+			&i.IsRevealed,
+			&i.IsMutualInterest,
+			&i.IsMutualCrush,
+			&i.MessagingUnlocked,
+			&i.CreatedAt,
+			&i.RevealedAt,
+			&i.UpdatedAt,
+			&i.MessagingUnlockedAt,
+			&i.MessagingUnlockReason,
+			&i.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateMatch = `-- name: UpdateMatch :one
 UPDATE matches
 SET
@@ -169,7 +353,7 @@ SET
     is_revealed = COALESCE($4, is_revealed),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
 `
 
 type UpdateMatchParams struct {
@@ -204,6 +388,9 @@ func (q *Queries) UpdateMatch(ctx context.Context, arg UpdateMatchParams) (Match
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
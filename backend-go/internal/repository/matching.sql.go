@@ -7,91 +7,138 @@ package repository
 
 import (
 	"context"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const countCrushByUserEmailCampaign = `-- name: CountCrushByUserEmailCampaign :one
-SELECT COUNT(*) FROM crush_lists
-WHERE user_id = $1 AND campaign_id = $2 AND crush_email = $3
+const listSurveyResponsesWithQuestionsByCampaign = `-- name: ListSurveyResponsesWithQuestionsByCampaign :many
+SELECT
+    sr.user_id,
+    sr.question_id,
+    sr.answer_text,
+    sr.answer_value,
+    sr.answer_json,
+    sr.answer_type,
+    sr.acceptable_answers,
+    sr.importance,
+    q.category AS question_category,
+    q.weight AS question_weight,
+    q.scoring AS question_scoring
+FROM survey_responses sr
+JOIN questions q ON q.id = sr.question_id
+JOIN users u ON u.id = sr.user_id
+WHERE sr.campaign_id = $1 AND u.survey_completed = TRUE AND u.is_active = TRUE
 `
 
-type CountCrushByUserEmailCampaignParams struct {
-	UserID     uuid.UUID `json:"user_id"`
-	CampaignID uuid.UUID `json:"campaign_id"`
-	CrushEmail string    `json:"crush_email"`
+type ListSurveyResponsesWithQuestionsByCampaignRow struct {
+	UserID            uuid.UUID      `json:"user_id"`
+	QuestionID        uuid.UUID      `json:"question_id"`
+	AnswerText        pgtype.Text    `json:"answer_text"`
+	AnswerValue       pgtype.Int4    `json:"answer_value"`
+	AnswerJson        []byte         `json:"answer_json"`
+	AnswerType        string         `json:"answer_type"`
+	AcceptableAnswers []byte         `json:"acceptable_answers"`
+	Importance        string         `json:"importance"`
+	QuestionCategory  string         `json:"question_category"`
+	QuestionWeight    pgtype.Numeric `json:"question_weight"`
+	QuestionScoring   []byte         `json:"question_scoring"`
 }
 
-func (q *Queries) CountCrushByUserEmailCampaign(ctx context.Context, arg CountCrushByUserEmailCampaignParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countCrushByUserEmailCampaign, arg.UserID, arg.CampaignID, arg.CrushEmail)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) ListSurveyResponsesWithQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]ListSurveyResponsesWithQuestionsByCampaignRow, error) {
+	rows, err := q.db.Query(ctx, listSurveyResponsesWithQuestionsByCampaign, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSurveyResponsesWithQuestionsByCampaignRow{}
+	for rows.Next() {
+		var i ListSurveyResponsesWithQuestionsByCampaignRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.QuestionID,
+			&i.AnswerText,
+			&i.AnswerValue,
+			&i.AnswerJson,
+			&i.AnswerType,
+			&i.AcceptableAnswers,
+			&i.Importance,
+			&i.QuestionCategory,
+			&i.QuestionWeight,
+			&i.QuestionScoring,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const listSurveyResponsesWithQuestionsByUserCampaign = `-- name: ListSurveyResponsesWithQuestionsByUserCampaign :many
+const listSurveyResponsesWithQuestionsForUsers = `-- name: ListSurveyResponsesWithQuestionsForUsers :many
 SELECT
-    sr.id,
     sr.user_id,
-    sr.campaign_id,
     sr.question_id,
     sr.answer_text,
     sr.answer_value,
     sr.answer_json,
     sr.answer_type,
-    sr.created_at,
-    sr.updated_at,
+    sr.acceptable_answers,
+    sr.importance,
+    q.question_text,
     q.category AS question_category,
-    q.weight AS question_weight
+    q.weight AS question_weight,
+    q.scoring AS question_scoring
 FROM survey_responses sr
 JOIN questions q ON q.id = sr.question_id
-WHERE sr.user_id = $1 AND sr.campaign_id = $2
+WHERE sr.user_id = ANY($1::uuid[]) AND sr.campaign_id = $2
+ORDER BY q.order_index
 `
 
-type ListSurveyResponsesWithQuestionsByUserCampaignParams struct {
-	UserID     uuid.UUID   `json:"user_id"`
+type ListSurveyResponsesWithQuestionsForUsersParams struct {
+	UserIds    []uuid.UUID `json:"user_ids"`
 	CampaignID pgtype.UUID `json:"campaign_id"`
 }
 
-type ListSurveyResponsesWithQuestionsByUserCampaignRow struct {
-	ID               uuid.UUID      `json:"id"`
-	UserID           uuid.UUID      `json:"user_id"`
-	CampaignID       pgtype.UUID    `json:"campaign_id"`
-	QuestionID       uuid.UUID      `json:"question_id"`
-	AnswerText       pgtype.Text    `json:"answer_text"`
-	AnswerValue      pgtype.Int4    `json:"answer_value"`
-	AnswerJson       []byte         `json:"answer_json"`
-	AnswerType       string         `json:"answer_type"`
-	CreatedAt        time.Time      `json:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at"`
-	QuestionCategory string         `json:"question_category"`
-	QuestionWeight   pgtype.Numeric `json:"question_weight"`
+type ListSurveyResponsesWithQuestionsForUsersRow struct {
+	UserID            uuid.UUID      `json:"user_id"`
+	QuestionID        uuid.UUID      `json:"question_id"`
+	AnswerText        pgtype.Text    `json:"answer_text"`
+	AnswerValue       pgtype.Int4    `json:"answer_value"`
+	AnswerJson        []byte         `json:"answer_json"`
+	AnswerType        string         `json:"answer_type"`
+	AcceptableAnswers []byte         `json:"acceptable_answers"`
+	Importance        string         `json:"importance"`
+	QuestionText      string         `json:"question_text"`
+	QuestionCategory  string         `json:"question_category"`
+	QuestionWeight    pgtype.Numeric `json:"question_weight"`
+	QuestionScoring   []byte         `json:"question_scoring"`
 }
 
-func (q *Queries) ListSurveyResponsesWithQuestionsByUserCampaign(ctx context.Context, arg ListSurveyResponsesWithQuestionsByUserCampaignParams) ([]ListSurveyResponsesWithQuestionsByUserCampaignRow, error) {
-	rows, err := q.db.Query(ctx, listSurveyResponsesWithQuestionsByUserCampaign, arg.UserID, arg.CampaignID)
+func (q *Queries) ListSurveyResponsesWithQuestionsForUsers(ctx context.Context, arg ListSurveyResponsesWithQuestionsForUsersParams) ([]ListSurveyResponsesWithQuestionsForUsersRow, error) {
+	rows, err := q.db.Query(ctx, listSurveyResponsesWithQuestionsForUsers, arg.UserIds, arg.CampaignID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListSurveyResponsesWithQuestionsByUserCampaignRow{}
+	items := []ListSurveyResponsesWithQuestionsForUsersRow{}
 	for rows.Next() {
-		var i ListSurveyResponsesWithQuestionsByUserCampaignRow
+		var i ListSurveyResponsesWithQuestionsForUsersRow
 		if err := rows.Scan(
-			&i.ID,
 			&i.UserID,
-			&i.CampaignID,
 			&i.QuestionID,
 			&i.AnswerText,
 			&i.AnswerValue,
 			&i.AnswerJson,
 			&i.AnswerType,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.AcceptableAnswers,
+			&i.Importance,
+			&i.QuestionText,
 			&i.QuestionCategory,
 			&i.QuestionWeight,
+			&i.QuestionScoring,
 		); err != nil {
 			return nil, err
 		}
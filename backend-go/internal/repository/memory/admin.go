@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) UpsertAdminSetting(ctx context.Context, arg repository.UpsertAdminSettingParams) (repository.AdminSetting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if arg.UpdatedBy.Valid && s.userIndex(arg.UpdatedBy.Bytes) < 0 {
+		return repository.AdminSetting{}, foreignKeyViolation("admin_settings_updated_by_fkey")
+	}
+	now := s.now()
+	if i := indexOf(s.adminSettings, func(a repository.AdminSetting) bool { return a.SettingKey == arg.SettingKey }); i >= 0 {
+		s.adminSettings[i].SettingValue = arg.SettingValue
+		s.adminSettings[i].UpdatedBy = arg.UpdatedBy
+		s.adminSettings[i].UpdatedAt = now
+		return s.adminSettings[i], nil
+	}
+	setting := repository.AdminSetting{
+		ID:           uuid.New(),
+		SettingKey:   arg.SettingKey,
+		SettingValue: arg.SettingValue,
+		UpdatedAt:    now,
+		UpdatedBy:    arg.UpdatedBy,
+	}
+	s.adminSettings = append(s.adminSettings, setting)
+	return setting, nil
+}
+
+func (s *Store) GetAdminSettingByKey(ctx context.Context, settingKey string) (repository.AdminSetting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.adminSettings, func(a repository.AdminSetting) bool { return a.SettingKey == settingKey })
+	if i < 0 {
+		return repository.AdminSetting{}, pgx.ErrNoRows
+	}
+	return s.adminSettings[i], nil
+}
+
+func (s *Store) ListTestimonials(ctx context.Context) ([]repository.Testimonial, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.testimonials, func(repository.Testimonial) bool { return true }), nil
+}
+
+func (s *Store) CreateTestimonial(ctx context.Context, arg repository.CreateTestimonialParams) (repository.Testimonial, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	testimonial := repository.Testimonial{
+		ID:          uuid.New(),
+		Name:        arg.Name,
+		Email:       arg.Email,
+		Heading:     arg.Heading,
+		Content:     arg.Content,
+		IsApproved:  arg.IsApproved,
+		IsPublished: arg.IsPublished,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.testimonials = append(s.testimonials, testimonial)
+	return testimonial, nil
+}
+
+func (s *Store) UpdateTestimonialApproval(ctx context.Context, arg repository.UpdateTestimonialApprovalParams) (repository.Testimonial, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.testimonials, func(t repository.Testimonial) bool { return t.ID == arg.ID })
+	if i < 0 {
+		return repository.Testimonial{}, pgx.ErrNoRows
+	}
+	s.testimonials[i].IsApproved = arg.IsApproved
+	s.testimonials[i].IsPublished = arg.IsPublished
+	s.testimonials[i].UpdatedAt = s.now()
+	return s.testimonials[i], nil
+}
+
+func (s *Store) PublicStats(ctx context.Context) (repository.PublicStatsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return repository.PublicStatsRow{
+		TotalUsers:       int64(len(s.users)),
+		CompletedSurveys: int64(len(where(s.users, func(u repository.User) bool { return u.SurveyCompleted }))),
+		TotalMatches:     int64(len(where(s.matches, live))),
+	}, nil
+}
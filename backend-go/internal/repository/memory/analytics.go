@@ -0,0 +1,311 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// tally is a GROUP BY: it keeps the groups in the order they first appear
+// and sums a count and a completed count per group.
+type tally[K comparable] struct {
+	keys   []K
+	totals map[K]*[2]int64
+	users  map[K]map[uuid.UUID]bool
+}
+
+func newTally[K comparable]() *tally[K] {
+	return &tally[K]{totals: map[K]*[2]int64{}, users: map[K]map[uuid.UUID]bool{}}
+}
+
+func (t *tally[K]) add(key K, userID uuid.UUID, completed bool) {
+	totals, ok := t.totals[key]
+	if !ok {
+		totals = &[2]int64{}
+		t.totals[key] = totals
+		t.users[key] = map[uuid.UUID]bool{}
+		t.keys = append(t.keys, key)
+	}
+	totals[0]++
+	if completed {
+		totals[1]++
+	}
+	t.users[key][userID] = true
+}
+
+func (t *tally[K]) count(key K) int64     { return t.totals[key][0] }
+func (t *tally[K]) completed(key K) int64 { return t.totals[key][1] }
+func (t *tally[K]) distinct(key K) int64  { return int64(len(t.users[key])) }
+
+// byCountDesc orders the groups like ORDER BY count DESC.
+func (t *tally[K]) byCountDesc(count func(K) int64) []K {
+	keys := append([]K(nil), t.keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return count(keys[i]) > count(keys[j]) })
+	return keys
+}
+
+// campaignResponseUsers yields one user per survey response in the
+// campaign, which is what joining users onto survey_responses produces.
+func (s *Store) campaignResponseUsers(campaignID pgtype.UUID) []repository.User {
+	users := make(map[uuid.UUID]repository.User, len(s.users))
+	for _, user := range s.users {
+		users[user.ID] = user
+	}
+	rows := []repository.User{}
+	for _, response := range s.responses {
+		if user, ok := users[response.UserID]; ok && sameUUID(response.CampaignID, campaignID) {
+			rows = append(rows, user)
+		}
+	}
+	return rows
+}
+
+// countMatches counts the live matches keep accepts.
+func (s *Store) countMatches(keep func(repository.Match) bool) int64 {
+	return int64(len(where(s.matches, func(m repository.Match) bool { return live(m) && keep(m) })))
+}
+
+func (s *Store) CountActiveUsers(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.users, func(u repository.User) bool { return u.IsActive }))), nil
+}
+
+func (s *Store) CountCompletedSurveys(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.users, func(u repository.User) bool { return u.IsActive && u.SurveyCompleted }))), nil
+}
+
+func (s *Store) CountMatches(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.matches, live))), nil
+}
+
+func (s *Store) CountMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countMatches(func(m repository.Match) bool { return sameUUID(m.CampaignID, campaignID) }), nil
+}
+
+func (s *Store) CountMutualMatches(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countMatches(func(m repository.Match) bool { return m.IsMutualInterest }), nil
+}
+
+func (s *Store) CountMutualMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countMatches(func(m repository.Match) bool {
+		return m.IsMutualInterest && sameUUID(m.CampaignID, campaignID)
+	}), nil
+}
+
+func (s *Store) CountRevealedMatches(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countMatches(func(m repository.Match) bool { return m.IsRevealed }), nil
+}
+
+func (s *Store) CountRevealedMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countMatches(func(m repository.Match) bool {
+		return m.IsRevealed && sameUUID(m.CampaignID, campaignID)
+	}), nil
+}
+
+// averageScore is COALESCE(AVG(compatibility_score), 0): NULL scores are
+// left out, and no scores at all average to zero.
+func averageScore(matches []repository.Match) float64 {
+	var sum float64
+	var n int
+	for _, match := range matches {
+		if score, ok := numericValue(match.CompatibilityScore); ok {
+			sum += score
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+func (s *Store) AverageCompatibilityScore(ctx context.Context) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return averageScore(where(s.matches, live)), nil
+}
+
+func (s *Store) AverageCompatibilityScoreByCampaign(ctx context.Context, campaignID pgtype.UUID) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return averageScore(where(s.matches, func(m repository.Match) bool { return sameUUID(m.CampaignID, campaignID) && live(m) })), nil
+}
+
+func (s *Store) TopPrograms(ctx context.Context, limit int32) ([]repository.TopProgramsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[string]()
+	for _, user := range s.users {
+		if user.SurveyCompleted && user.Program.Valid {
+			groups.add(user.Program.String, user.ID, true)
+		}
+	}
+	items := []repository.TopProgramsRow{}
+	for _, program := range page(groups.byCountDesc(groups.count), limit, 0) {
+		items = append(items, repository.TopProgramsRow{
+			Program: pgtype.Text{String: program, Valid: true},
+			Count:   groups.count(program),
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) TopProgramsByCampaign(ctx context.Context, arg repository.TopProgramsByCampaignParams) ([]repository.TopProgramsByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[string]()
+	for _, user := range s.campaignResponseUsers(arg.CampaignID) {
+		if user.SurveyCompleted && user.Program.Valid {
+			groups.add(user.Program.String, user.ID, true)
+		}
+	}
+	items := []repository.TopProgramsByCampaignRow{}
+	for _, program := range page(groups.byCountDesc(groups.count), arg.Limit, 0) {
+		items = append(items, repository.TopProgramsByCampaignRow{
+			Program: pgtype.Text{String: program, Valid: true},
+			Count:   groups.count(program),
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) ProgramsWithCompletion(ctx context.Context) ([]repository.ProgramsWithCompletionRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[string]()
+	for _, user := range s.users {
+		if user.IsActive && user.Program.Valid {
+			groups.add(user.Program.String, user.ID, user.SurveyCompleted)
+		}
+	}
+	items := []repository.ProgramsWithCompletionRow{}
+	for _, program := range groups.byCountDesc(groups.count) {
+		items = append(items, repository.ProgramsWithCompletionRow{
+			Program:   pgtype.Text{String: program, Valid: true},
+			Total:     groups.count(program),
+			Completed: groups.completed(program),
+		})
+	}
+	return items, nil
+}
+
+// ProgramsWithCompletionByCampaign counts distinct users per program but,
+// like the SQL query, sums completion over every joined response row.
+func (s *Store) ProgramsWithCompletionByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.ProgramsWithCompletionByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[string]()
+	for _, user := range s.campaignResponseUsers(campaignID) {
+		if user.Program.Valid {
+			groups.add(user.Program.String, user.ID, user.SurveyCompleted)
+		}
+	}
+	items := []repository.ProgramsWithCompletionByCampaignRow{}
+	for _, program := range groups.byCountDesc(groups.distinct) {
+		items = append(items, repository.ProgramsWithCompletionByCampaignRow{
+			Program:   pgtype.Text{String: program, Valid: true},
+			Total:     groups.distinct(program),
+			Completed: groups.completed(program),
+		})
+	}
+	return items, nil
+}
+
+func sortedYears(groups *tally[int32]) []int32 {
+	years := append([]int32(nil), groups.keys...)
+	sort.Slice(years, func(i, j int) bool { return years[i] < years[j] })
+	return years
+}
+
+func (s *Store) YearLevelsWithCompletion(ctx context.Context) ([]repository.YearLevelsWithCompletionRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[int32]()
+	for _, user := range s.users {
+		if user.IsActive && user.YearLevel.Valid {
+			groups.add(user.YearLevel.Int32, user.ID, user.SurveyCompleted)
+		}
+	}
+	items := []repository.YearLevelsWithCompletionRow{}
+	for _, year := range sortedYears(groups) {
+		items = append(items, repository.YearLevelsWithCompletionRow{
+			YearLevel: pgtype.Int4{Int32: year, Valid: true},
+			Total:     groups.count(year),
+			Completed: groups.completed(year),
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) YearLevelsWithCompletionByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.YearLevelsWithCompletionByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := newTally[int32]()
+	for _, user := range s.campaignResponseUsers(campaignID) {
+		if user.YearLevel.Valid {
+			groups.add(user.YearLevel.Int32, user.ID, user.SurveyCompleted)
+		}
+	}
+	items := []repository.YearLevelsWithCompletionByCampaignRow{}
+	for _, year := range sortedYears(groups) {
+		items = append(items, repository.YearLevelsWithCompletionByCampaignRow{
+			YearLevel: pgtype.Int4{Int32: year, Valid: true},
+			Total:     groups.distinct(year),
+			Completed: groups.completed(year),
+		})
+	}
+	return items, nil
+}
+
+func tierCounts(matches []repository.Match) ([]pgtype.Text, map[pgtype.Text]int64) {
+	tiers := []pgtype.Text{}
+	counts := map[pgtype.Text]int64{}
+	for _, match := range matches {
+		if _, ok := counts[match.MatchTier]; !ok {
+			tiers = append(tiers, match.MatchTier)
+		}
+		counts[match.MatchTier]++
+	}
+	return tiers, counts
+}
+
+func (s *Store) MatchesByTier(ctx context.Context) ([]repository.MatchesByTierRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tiers, counts := tierCounts(where(s.matches, live))
+	items := []repository.MatchesByTierRow{}
+	for _, tier := range tiers {
+		items = append(items, repository.MatchesByTierRow{MatchTier: tier, Count: counts[tier]})
+	}
+	return items, nil
+}
+
+func (s *Store) MatchesByTierByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.MatchesByTierByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tiers, counts := tierCounts(where(s.matches, func(m repository.Match) bool { return sameUUID(m.CampaignID, campaignID) && live(m) }))
+	items := []repository.MatchesByTierByCampaignRow{}
+	for _, tier := range tiers {
+		items = append(items, repository.MatchesByTierByCampaignRow{MatchTier: tier, Count: counts[tier]})
+	}
+	return items, nil
+}
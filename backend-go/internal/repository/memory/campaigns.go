@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) campaignIndex(id uuid.UUID) int {
+	return indexOf(s.campaigns, func(c repository.Campaign) bool { return c.ID == id })
+}
+
+func (s *Store) activeCampaign() (repository.Campaign, bool) {
+	for i := len(s.campaigns) - 1; i >= 0; i-- {
+		if s.campaigns[i].IsActive.Valid && s.campaigns[i].IsActive.Bool {
+			return s.campaigns[i], true
+		}
+	}
+	return repository.Campaign{}, false
+}
+
+func (s *Store) GetActiveCampaign(ctx context.Context) (repository.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	campaign, ok := s.activeCampaign()
+	if !ok {
+		return repository.Campaign{}, pgx.ErrNoRows
+	}
+	return campaign, nil
+}
+
+func (s *Store) GetCampaignByID(ctx context.Context, id uuid.UUID) (repository.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.campaignIndex(id)
+	if i < 0 {
+		return repository.Campaign{}, pgx.ErrNoRows
+	}
+	return s.campaigns[i], nil
+}
+
+func (s *Store) ListCampaigns(ctx context.Context) ([]repository.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.campaigns, func(repository.Campaign) bool { return true }), nil
+}
+
+func (s *Store) CreateCampaign(ctx context.Context, arg repository.CreateCampaignParams) (repository.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	campaign := repository.Campaign{
+		ID:                     uuid.New(),
+		Name:                   arg.Name,
+		SurveyOpenDate:         arg.SurveyOpenDate,
+		SurveyCloseDate:        arg.SurveyCloseDate,
+		ProfileUpdateStartDate: arg.ProfileUpdateStartDate,
+		ProfileUpdateEndDate:   arg.ProfileUpdateEndDate,
+		ResultsReleaseDate:     arg.ResultsReleaseDate,
+		IsActive:               arg.IsActive,
+		TotalParticipants:      pgtype.Int4{Int32: 0, Valid: true},
+		TotalMatchesGenerated:  pgtype.Int4{Int32: 0, Valid: true},
+		AlgorithmVersion:       arg.AlgorithmVersion,
+		Config:                 arg.Config,
+		CreatedAt:              s.now(),
+	}
+	s.campaigns = append(s.campaigns, campaign)
+	return campaign, nil
+}
+
+func (s *Store) UpdateCampaign(ctx context.Context, arg repository.UpdateCampaignParams) (repository.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.campaignIndex(arg.ID)
+	if i < 0 {
+		return repository.Campaign{}, pgx.ErrNoRows
+	}
+	campaign := &s.campaigns[i]
+	campaign.Name = arg.Name
+	campaign.SurveyOpenDate = arg.SurveyOpenDate
+	campaign.SurveyCloseDate = arg.SurveyCloseDate
+	campaign.ProfileUpdateStartDate = arg.ProfileUpdateStartDate
+	campaign.ProfileUpdateEndDate = arg.ProfileUpdateEndDate
+	campaign.ResultsReleaseDate = arg.ResultsReleaseDate
+	if arg.IsActive.Valid {
+		campaign.IsActive = arg.IsActive
+	}
+	if arg.Config != nil {
+		campaign.Config = arg.Config
+	}
+	if arg.AlgorithmVersion.Valid {
+		campaign.AlgorithmVersion = arg.AlgorithmVersion
+	}
+	return *campaign, nil
+}
+
+// DeleteCampaign clears the campaign from questions, responses and matches
+// and drops the rows that cannot outlive it, as the foreign keys declare.
+func (s *Store) DeleteCampaign(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if deleteWhere(&s.campaigns, func(c repository.Campaign) bool { return c.ID == id }) == 0 {
+		return nil
+	}
+	campaignID := pgtype.UUID{Bytes: id, Valid: true}
+	for i := range s.questions {
+		if s.questions[i].CampaignID == campaignID {
+			s.questions[i].CampaignID = pgtype.UUID{}
+		}
+	}
+	for i := range s.responses {
+		if s.responses[i].CampaignID == campaignID {
+			s.responses[i].CampaignID = pgtype.UUID{}
+		}
+	}
+	for i := range s.matches {
+		if s.matches[i].CampaignID == campaignID {
+			s.matches[i].CampaignID = pgtype.UUID{}
+		}
+	}
+	deleteWhere(&s.crushes, func(c repository.CrushList) bool { return c.CampaignID == id })
+	deleteWhere(&s.constraints, func(c repository.PairConstraint) bool { return c.CampaignID == id })
+	deleteWhere(&s.vectors, func(v repository.TextVector) bool { return v.CampaignID == id })
+	runs := map[uuid.UUID]bool{}
+	deleteWhere(&s.runs, func(r repository.MatchRun) bool {
+		runs[r.ID] = r.CampaignID == id
+		return r.CampaignID == id
+	})
+	deleteWhere(&s.runResults, func(r repository.MatchRunResult) bool { return runs[r.RunID] })
+	return nil
+}
+
+func (s *Store) UpdateCampaignStats(ctx context.Context, arg repository.UpdateCampaignStatsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.campaignIndex(arg.ID); i >= 0 {
+		s.campaigns[i].TotalParticipants = arg.TotalParticipants
+		s.campaigns[i].TotalMatchesGenerated = arg.TotalMatchesGenerated
+		s.campaigns[i].AlgorithmVersion = arg.AlgorithmVersion
+	}
+	return nil
+}
+
+func (s *Store) CountParticipantsByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := map[uuid.UUID]bool{}
+	for _, response := range s.responses {
+		if sameUUID(response.CampaignID, campaignID) {
+			users[response.UserID] = true
+		}
+	}
+	return int64(len(users)), nil
+}
+
+func (s *Store) CountCompletedSurveysByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	completed := s.userFlags(func(u repository.User) bool { return u.SurveyCompleted })
+	users := map[uuid.UUID]bool{}
+	for _, response := range s.responses {
+		if sameUUID(response.CampaignID, campaignID) && completed[response.UserID] {
+			users[response.UserID] = true
+		}
+	}
+	return int64(len(users)), nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) DeleteCrushesForUserCampaign(ctx context.Context, arg repository.DeleteCrushesForUserCampaignParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleteWhere(&s.crushes, func(c repository.CrushList) bool {
+		return c.UserID == arg.UserID && c.CampaignID == arg.CampaignID
+	})
+	return nil
+}
+
+func (s *Store) CreateCrush(ctx context.Context, arg repository.CreateCrushParams) (repository.CrushList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userIndex(arg.UserID) < 0 {
+		return repository.CrushList{}, foreignKeyViolation("crush_lists_user_id_fkey")
+	}
+	if s.campaignIndex(arg.CampaignID) < 0 {
+		return repository.CrushList{}, foreignKeyViolation("crush_lists_campaign_id_fkey")
+	}
+	if indexOf(s.crushes, func(c repository.CrushList) bool {
+		return c.UserID == arg.UserID && c.CrushEmail == arg.CrushEmail && c.CampaignID == arg.CampaignID
+	}) >= 0 {
+		return repository.CrushList{}, uniqueViolation("crush_lists_user_id_crush_email_campaign_id_key")
+	}
+	crush := repository.CrushList{
+		ID:         uuid.New(),
+		UserID:     arg.UserID,
+		CampaignID: arg.CampaignID,
+		CrushEmail: arg.CrushEmail,
+		CrushName:  arg.CrushName,
+		IsMatched:  arg.IsMatched,
+		IsMutual:   arg.IsMutual,
+		NudgeSent:  arg.NudgeSent,
+		CreatedAt:  s.now(),
+	}
+	s.crushes = append(s.crushes, crush)
+	return crush, nil
+}
+
+func (s *Store) ListCrushesForUserCampaign(ctx context.Context, arg repository.ListCrushesForUserCampaignParams) ([]repository.CrushList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.crushes, func(c repository.CrushList) bool {
+		return c.UserID == arg.UserID && c.CampaignID == arg.CampaignID
+	}), nil
+}
+
+func (s *Store) ListCrushesByEmailCampaign(ctx context.Context, arg repository.ListCrushesByEmailCampaignParams) ([]repository.CrushList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return where(s.crushes, func(c repository.CrushList) bool {
+		return c.CrushEmail == arg.CrushEmail && c.CampaignID == arg.CampaignID
+	}), nil
+}
+
+func (s *Store) ListCrushesForCampaign(ctx context.Context, campaignID uuid.UUID) ([]repository.CrushList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.crushes, func(c repository.CrushList) bool { return c.CampaignID == campaignID }), nil
+}
+
+func (s *Store) MarkCrushMutual(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := indexOf(s.crushes, func(c repository.CrushList) bool { return c.ID == id }); i >= 0 {
+		s.crushes[i].IsMutual = true
+	}
+	return nil
+}
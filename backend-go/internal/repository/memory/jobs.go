@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) jobIndex(id uuid.UUID) int {
+	return indexOf(s.jobs, func(j repository.Job) bool { return j.ID == id })
+}
+
+func (s *Store) CreateJob(ctx context.Context, arg repository.CreateJobParams) (repository.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if arg.CreatedBy.Valid && s.userIndex(arg.CreatedBy.Bytes) < 0 {
+		return repository.Job{}, foreignKeyViolation("jobs_created_by_fkey")
+	}
+	now := s.now()
+	job := repository.Job{
+		ID:          uuid.New(),
+		JobType:     arg.JobType,
+		Status:      "queued",
+		Payload:     arg.Payload,
+		Progress:    []byte("{}"),
+		MaxAttempts: arg.MaxAttempts,
+		CreatedBy:   arg.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.jobs = append(s.jobs, job)
+	return job, nil
+}
+
+func (s *Store) GetJobByID(ctx context.Context, id uuid.UUID) (repository.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.jobIndex(id)
+	if i < 0 {
+		return repository.Job{}, pgx.ErrNoRows
+	}
+	return s.jobs[i], nil
+}
+
+func (s *Store) ListJobs(ctx context.Context, arg repository.ListJobsParams) ([]repository.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return page(newestFirst(s.jobs, func(j repository.Job) bool { return j.JobType == arg.JobType }), arg.Limit, arg.Offset), nil
+}
+
+// ClaimJob takes the oldest claimable job: queued, or running with a
+// heartbeat older than StaleBefore, and not out of attempts or cancelled.
+func (s *Store) ClaimJob(ctx context.Context, arg repository.ClaimJobParams) (repository.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.jobs, func(j repository.Job) bool {
+		if !slices.Contains(arg.JobTypes, j.JobType) || j.CancelRequested || j.Attempts >= j.MaxAttempts {
+			return false
+		}
+		stale := j.HeartbeatAt.Valid && arg.StaleBefore.Valid && j.HeartbeatAt.Time.Before(arg.StaleBefore.Time)
+		return j.Status == "queued" || (j.Status == "running" && stale)
+	})
+	if i < 0 {
+		return repository.Job{}, pgx.ErrNoRows
+	}
+	now := timestamptz(s.now())
+	job := &s.jobs[i]
+	job.Status = "running"
+	job.Attempts++
+	job.LockedBy = arg.WorkerID
+	job.HeartbeatAt = now
+	if !job.StartedAt.Valid {
+		job.StartedAt = now
+	}
+	job.UpdatedAt = now.Time
+	return *job, nil
+}
+
+// HeartbeatJob returns pgx.ErrNoRows once another worker holds the job.
+func (s *Store) HeartbeatJob(ctx context.Context, arg repository.HeartbeatJobParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.jobIndex(arg.ID)
+	if i < 0 || !sameText(s.jobs[i].LockedBy, arg.LockedBy) {
+		return false, pgx.ErrNoRows
+	}
+	now := s.now()
+	job := &s.jobs[i]
+	job.Progress = arg.Progress
+	job.HeartbeatAt = timestamptz(now)
+	job.UpdatedAt = now
+	return job.CancelRequested, nil
+}
+
+// finishJob moves the job to a final status and unlocks it, unless another
+// worker has taken it over.
+func (s *Store) finishJob(id uuid.UUID, lockedBy pgtype.Text, status string, progress []byte, update func(*repository.Job)) {
+	i := s.jobIndex(id)
+	if i < 0 || !sameText(s.jobs[i].LockedBy, lockedBy) {
+		return
+	}
+	now := s.now()
+	job := &s.jobs[i]
+	job.Status = status
+	job.Progress = progress
+	job.LockedBy = pgtype.Text{}
+	job.FinishedAt = timestamptz(now)
+	job.UpdatedAt = now
+	if update != nil {
+		update(job)
+	}
+}
+
+func (s *Store) CompleteJob(ctx context.Context, arg repository.CompleteJobParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finishJob(arg.ID, arg.LockedBy, "succeeded", arg.Progress, func(job *repository.Job) { job.Result = arg.Result })
+	return nil
+}
+
+func (s *Store) FailJob(ctx context.Context, arg repository.FailJobParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finishJob(arg.ID, arg.LockedBy, "failed", arg.Progress, func(job *repository.Job) { job.ErrorMessage = arg.ErrorMessage })
+	return nil
+}
+
+func (s *Store) MarkJobCancelled(ctx context.Context, arg repository.MarkJobCancelledParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finishJob(arg.ID, arg.LockedBy, "cancelled", arg.Progress, nil)
+	return nil
+}
+
+func (s *Store) ReleaseJob(ctx context.Context, arg repository.ReleaseJobParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.jobIndex(arg.ID)
+	if i < 0 || !sameText(s.jobs[i].LockedBy, arg.LockedBy) {
+		return nil
+	}
+	job := &s.jobs[i]
+	job.Status = "queued"
+	job.Attempts = max(job.Attempts-1, 0)
+	job.Progress = arg.Progress
+	job.LockedBy = pgtype.Text{}
+	job.HeartbeatAt = pgtype.Timestamptz{}
+	job.UpdatedAt = s.now()
+	return nil
+}
+
+// RequestJobCancel cancels a queued job outright and flags a running one
+// for its worker. Finished jobs return pgx.ErrNoRows.
+func (s *Store) RequestJobCancel(ctx context.Context, id uuid.UUID) (repository.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.jobIndex(id)
+	if i < 0 || (s.jobs[i].Status != "queued" && s.jobs[i].Status != "running") {
+		return repository.Job{}, pgx.ErrNoRows
+	}
+	now := s.now()
+	job := &s.jobs[i]
+	job.CancelRequested = true
+	if job.Status == "queued" {
+		job.Status = "cancelled"
+		job.FinishedAt = timestamptz(now)
+	}
+	job.UpdatedAt = now
+	return *job, nil
+}
+
+// FailAbandonedJobs finishes stale jobs that will not be claimed again: a
+// job whose cancellation was requested is cancelled, one out of attempts
+// fails.
+func (s *Store) FailAbandonedJobs(ctx context.Context, staleBefore pgtype.Timestamptz) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var failed int64
+	now := s.now()
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		stale := job.HeartbeatAt.Valid && staleBefore.Valid && job.HeartbeatAt.Time.Before(staleBefore.Time)
+		if job.Status != "running" || !stale || !job.CancelRequested && job.Attempts < job.MaxAttempts {
+			continue
+		}
+		if job.CancelRequested {
+			job.Status = "cancelled"
+		} else {
+			job.Status = "failed"
+			job.ErrorMessage = pgtype.Text{String: "worker stopped responding too many times", Valid: true}
+		}
+		job.LockedBy = pgtype.Text{}
+		job.FinishedAt = timestamptz(now)
+		job.UpdatedAt = now
+		failed++
+	}
+	return failed, nil
+}
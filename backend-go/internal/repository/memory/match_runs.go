@@ -0,0 +1,348 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) runIndex(id uuid.UUID) int {
+	return indexOf(s.runs, func(r repository.MatchRun) bool { return r.ID == id })
+}
+
+func (s *Store) CreateMatchRun(ctx context.Context, arg repository.CreateMatchRunParams) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.campaignIndex(arg.CampaignID) < 0 {
+		return repository.MatchRun{}, foreignKeyViolation("match_runs_campaign_id_fkey")
+	}
+	if arg.CreatedBy.Valid && s.userIndex(arg.CreatedBy.Bytes) < 0 {
+		return repository.MatchRun{}, foreignKeyViolation("match_runs_created_by_fkey")
+	}
+	run := repository.MatchRun{
+		ID:               uuid.New(),
+		CampaignID:       arg.CampaignID,
+		Status:           "running",
+		AlgorithmVersion: arg.AlgorithmVersion,
+		Config:           arg.Config,
+		CreatedBy:        arg.CreatedBy,
+		StartedAt:        s.now(),
+	}
+	s.runs = append(s.runs, run)
+	return run, nil
+}
+
+func (s *Store) CompleteMatchRun(ctx context.Context, arg repository.CompleteMatchRunParams) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.runIndex(arg.ID)
+	if i < 0 {
+		return repository.MatchRun{}, pgx.ErrNoRows
+	}
+	run := &s.runs[i]
+	run.Status = "completed"
+	run.TotalUsers = arg.TotalUsers
+	run.CandidatePairs = arg.CandidatePairs
+	run.TotalMatches = arg.TotalMatches
+	run.Fairness = arg.Fairness
+	run.FinishedAt = timestamptz(s.now())
+	return *run, nil
+}
+
+func (s *Store) FailMatchRun(ctx context.Context, arg repository.FailMatchRunParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.runIndex(arg.ID); i >= 0 {
+		s.runs[i].Status = "failed"
+		s.runs[i].ErrorMessage = arg.ErrorMessage
+		s.runs[i].FinishedAt = timestamptz(s.now())
+	}
+	return nil
+}
+
+func (s *Store) GetMatchRunByID(ctx context.Context, id uuid.UUID) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.runIndex(id)
+	if i < 0 {
+		return repository.MatchRun{}, pgx.ErrNoRows
+	}
+	return s.runs[i], nil
+}
+
+func (s *Store) ListMatchRunsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.runs, func(r repository.MatchRun) bool { return r.CampaignID == campaignID }), nil
+}
+
+func (s *Store) GetPublishedMatchRun(ctx context.Context, campaignID uuid.UUID) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.runs, func(r repository.MatchRun) bool {
+		return r.CampaignID == campaignID && r.Status == "published"
+	})
+	if i < 0 {
+		return repository.MatchRun{}, pgx.ErrNoRows
+	}
+	return s.runs[i], nil
+}
+
+// GetLastSupersededMatchRun orders by published_at DESC, which puts a run
+// that was never published first, as Postgres sorts NULLs.
+func (s *Store) GetLastSupersededMatchRun(ctx context.Context, campaignID uuid.UUID) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := where(s.runs, func(r repository.MatchRun) bool {
+		return r.CampaignID == campaignID && r.Status == "superseded"
+	})
+	if len(runs) == 0 {
+		return repository.MatchRun{}, pgx.ErrNoRows
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		a, b := runs[i].PublishedAt, runs[j].PublishedAt
+		if a.Valid != b.Valid {
+			return !a.Valid
+		}
+		return a.Time.After(b.Time)
+	})
+	return runs[0], nil
+}
+
+func (s *Store) SupersedePublishedMatchRun(ctx context.Context, campaignID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.runs {
+		if s.runs[i].CampaignID == campaignID && s.runs[i].Status == "published" {
+			s.runs[i].Status = "superseded"
+		}
+	}
+	return nil
+}
+
+// PublishMatchRun enforces idx_match_runs_published: a campaign has at most
+// one published run, so the current one must be superseded first.
+func (s *Store) PublishMatchRun(ctx context.Context, id uuid.UUID) (repository.MatchRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.runIndex(id)
+	if i < 0 {
+		return repository.MatchRun{}, pgx.ErrNoRows
+	}
+	campaignID := s.runs[i].CampaignID
+	if indexOf(s.runs, func(r repository.MatchRun) bool {
+		return r.ID != id && r.CampaignID == campaignID && r.Status == "published"
+	}) >= 0 {
+		return repository.MatchRun{}, uniqueViolation("idx_match_runs_published")
+	}
+	s.runs[i].Status = "published"
+	s.runs[i].PublishedAt = timestamptz(s.now())
+	return s.runs[i], nil
+}
+
+func (s *Store) CreateMatchRunResult(ctx context.Context, arg repository.CreateMatchRunResultParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertRunResult(repository.MatchRunResult{
+		RunID:              arg.RunID,
+		User1ID:            arg.User1ID,
+		User2ID:            arg.User2ID,
+		CompatibilityScore: arg.CompatibilityScore,
+		MatchTier:          arg.MatchTier,
+		SharedInterests:    arg.SharedInterests,
+		RankForUser1:       arg.RankForUser1,
+		RankForUser2:       arg.RankForUser2,
+		IsMutualCrush:      arg.IsMutualCrush,
+	})
+}
+
+// AppendMatchRunResult skips a pair the run already has and otherwise
+// counts the new result in the run's total.
+func (s *Store) AppendMatchRunResult(ctx context.Context, arg repository.AppendMatchRunResultParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.insertRunResult(repository.MatchRunResult{
+		RunID:              arg.RunID,
+		User1ID:            arg.User1ID,
+		User2ID:            arg.User2ID,
+		CompatibilityScore: arg.CompatibilityScore,
+		MatchTier:          arg.MatchTier,
+		SharedInterests:    arg.SharedInterests,
+		RankForUser1:       arg.RankForUser1,
+		RankForUser2:       arg.RankForUser2,
+		IsMutualCrush:      arg.IsMutualCrush,
+	})
+	if isUniqueViolation(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.runs[s.runIndex(arg.RunID)].TotalMatches++
+	return nil
+}
+
+func (s *Store) insertRunResult(result repository.MatchRunResult) error {
+	if s.runIndex(result.RunID) < 0 {
+		return foreignKeyViolation("match_run_results_run_id_fkey")
+	}
+	if !result.CompatibilityScore.Valid {
+		return notNullViolation("match_run_results", "compatibility_score")
+	}
+	if s.userIndex(result.User1ID) < 0 {
+		return foreignKeyViolation("match_run_results_user1_id_fkey")
+	}
+	if s.userIndex(result.User2ID) < 0 {
+		return foreignKeyViolation("match_run_results_user2_id_fkey")
+	}
+	if indexOf(s.runResults, func(r repository.MatchRunResult) bool {
+		return r.RunID == result.RunID && r.User1ID == result.User1ID && r.User2ID == result.User2ID
+	}) >= 0 {
+		return uniqueViolation("match_run_results_run_id_user1_id_user2_id_key")
+	}
+	result.ID = uuid.New()
+	s.runResults = append(s.runResults, result)
+	return nil
+}
+
+func (s *Store) ListMatchRunResults(ctx context.Context, arg repository.ListMatchRunResultsParams) ([]repository.MatchRunResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.runResults, func(r repository.MatchRunResult) bool { return r.RunID == arg.RunID })
+	byScoreDesc(items, func(r repository.MatchRunResult) pgtype.Numeric { return r.CompatibilityScore })
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) CountMatchRunResults(ctx context.Context, runID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.runResults, func(r repository.MatchRunResult) bool { return r.RunID == runID }))), nil
+}
+
+// runPairs indexes a run's results by pair, in both orders, since the
+// queries that publish a run match live pairs either way round.
+func (s *Store) runPairs(runID uuid.UUID) map[[2]uuid.UUID]repository.MatchRunResult {
+	pairs := map[[2]uuid.UUID]repository.MatchRunResult{}
+	for _, result := range s.runResults {
+		if result.RunID == runID {
+			pairs[[2]uuid.UUID{result.User1ID, result.User2ID}] = result
+			pairs[[2]uuid.UUID{result.User2ID, result.User1ID}] = result
+		}
+	}
+	return pairs
+}
+
+// UpdateMatchesFromRun copies the run's scores onto the campaign's live
+// matches for the same pairs, swapping the ranks when a match stores the
+// pair the other way round.
+func (s *Store) UpdateMatchesFromRun(ctx context.Context, arg repository.UpdateMatchesFromRunParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pairs := s.runPairs(arg.RunID)
+	now := s.now()
+	var updated int64
+	for i := range s.matches {
+		match := &s.matches[i]
+		result, ok := pairs[[2]uuid.UUID{match.User1ID, match.User2ID}]
+		if !ok || !sameUUID(match.CampaignID, arg.CampaignID) {
+			continue
+		}
+		match.CompatibilityScore = result.CompatibilityScore
+		match.MatchTier = result.MatchTier
+		match.SharedInterests = result.SharedInterests
+		match.RankForUser1, match.RankForUser2 = result.RankForUser1, result.RankForUser2
+		if match.User1ID != result.User1ID {
+			match.RankForUser1, match.RankForUser2 = result.RankForUser2, result.RankForUser1
+		}
+		match.IsMutualCrush = result.IsMutualCrush
+		match.SupersededAt = pgtype.Timestamptz{}
+		match.UpdatedAt = now
+		updated++
+	}
+	return updated, nil
+}
+
+// DeleteMatchesNotInRun keeps the matches with messages, a rejection or a
+// report, which SupersedeMatchesNotInRun hides instead.
+func (s *Store) DeleteMatchesNotInRun(ctx context.Context, arg repository.DeleteMatchesNotInRunParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pairs := s.runPairs(arg.RunID)
+	removed := s.deleteMatches(func(m repository.Match) bool {
+		_, inRun := pairs[[2]uuid.UUID{m.User1ID, m.User2ID}]
+		return sameUUID(m.CampaignID, arg.CampaignID) && !inRun && !s.worthKeeping(m.ID)
+	})
+	return int64(removed), nil
+}
+
+// worthKeeping reports whether the match has messages, or an interaction
+// ListPairHistory counts as a rejection.
+func (s *Store) worthKeeping(matchID uuid.UUID) bool {
+	return indexOf(s.messages, func(m repository.Message) bool { return m.MatchID == matchID }) >= 0 ||
+		indexOf(s.interactions, func(i repository.Interaction) bool {
+			return i.MatchID == matchID && (i.InteractionType == "not_interested" || i.InteractionType == "report")
+		}) >= 0
+}
+
+func (s *Store) SupersedeMatchesNotInRun(ctx context.Context, arg repository.SupersedeMatchesNotInRunParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pairs := s.runPairs(arg.RunID)
+	now := s.now()
+	var superseded int64
+	for i := range s.matches {
+		match := &s.matches[i]
+		_, inRun := pairs[[2]uuid.UUID{match.User1ID, match.User2ID}]
+		if inRun || !live(*match) || !sameUUID(match.CampaignID, arg.CampaignID) {
+			continue
+		}
+		match.SupersededAt = timestamptz(now)
+		match.UpdatedAt = now
+		superseded++
+	}
+	return superseded, nil
+}
+
+// InsertMatchesFromRun adds the run's pairs the campaign does not have yet,
+// in either order.
+func (s *Store) InsertMatchesFromRun(ctx context.Context, arg repository.InsertMatchesFromRunParams) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live := map[[2]uuid.UUID]bool{}
+	for _, match := range s.matches {
+		if sameUUID(match.CampaignID, arg.CampaignID) {
+			live[[2]uuid.UUID{match.User1ID, match.User2ID}] = true
+			live[[2]uuid.UUID{match.User2ID, match.User1ID}] = true
+		}
+	}
+	inserted := []repository.Match{}
+	for _, result := range s.runResults {
+		if result.RunID != arg.RunID || live[[2]uuid.UUID{result.User1ID, result.User2ID}] {
+			continue
+		}
+		match, _, err := s.insertMatch(repository.Match{
+			CampaignID:         arg.CampaignID,
+			User1ID:            result.User1ID,
+			User2ID:            result.User2ID,
+			CompatibilityScore: result.CompatibilityScore,
+			MatchTier:          result.MatchTier,
+			SharedInterests:    result.SharedInterests,
+			RankForUser1:       result.RankForUser1,
+			RankForUser2:       result.RankForUser2,
+			IsMutualCrush:      result.IsMutualCrush,
+		})
+		if isUniqueViolation(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, match)
+	}
+	return inserted, nil
+}
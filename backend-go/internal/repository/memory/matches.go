@@ -0,0 +1,431 @@
+package memory
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) matchIndex(id uuid.UUID) int {
+	return indexOf(s.matches, func(m repository.Match) bool { return m.ID == id })
+}
+
+// matchKeyIndex finds the match holding the matches_campaign_pair_key
+// unique key, which treats a NULL campaign as equal to another NULL.
+func (s *Store) matchKeyIndex(campaignID pgtype.UUID, user1, user2 uuid.UUID) int {
+	return indexOf(s.matches, func(m repository.Match) bool {
+		return m.CampaignID == campaignID && m.User1ID == user1 && m.User2ID == user2
+	})
+}
+
+// insertMatch checks the foreign keys and fills in the column defaults. It
+// reports a unique violation, with the index of the existing row, when the
+// campaign already has the pair.
+func (s *Store) insertMatch(match repository.Match) (repository.Match, int, error) {
+	if match.CampaignID.Valid && s.campaignIndex(match.CampaignID.Bytes) < 0 {
+		return repository.Match{}, -1, foreignKeyViolation("matches_campaign_id_fkey")
+	}
+	if s.userIndex(match.User1ID) < 0 {
+		return repository.Match{}, -1, foreignKeyViolation("matches_user1_id_fkey")
+	}
+	if s.userIndex(match.User2ID) < 0 {
+		return repository.Match{}, -1, foreignKeyViolation("matches_user2_id_fkey")
+	}
+	if i := s.matchKeyIndex(match.CampaignID, match.User1ID, match.User2ID); i >= 0 {
+		return repository.Match{}, i, uniqueViolation("matches_campaign_pair_key")
+	}
+	now := s.now()
+	match.ID = uuid.New()
+	match.CreatedAt = now
+	match.UpdatedAt = now
+	s.matches = append(s.matches, match)
+	return match, len(s.matches) - 1, nil
+}
+
+// live reports whether the match is shown to its users: a publish that
+// superseded it keeps the row but hides it.
+func live(m repository.Match) bool {
+	return !m.SupersededAt.Valid
+}
+
+// superseded reports whether the match with the id exists and is hidden.
+func (s *Store) superseded(id uuid.UUID) bool {
+	i := s.matchIndex(id)
+	return i >= 0 && !live(s.matches[i])
+}
+
+// deleteMatches drops the matches matching along with their interactions
+// and messages, and with them the conversations users deleted.
+func (s *Store) deleteMatches(match func(repository.Match) bool) int {
+	gone := map[uuid.UUID]bool{}
+	removed := deleteWhere(&s.matches, func(m repository.Match) bool {
+		if match(m) {
+			gone[m.ID] = true
+			return true
+		}
+		return false
+	})
+	if removed > 0 {
+		deleteWhere(&s.interactions, func(i repository.Interaction) bool { return gone[i.MatchID] })
+		s.deleteMessages(func(m repository.Message) bool { return gone[m.MatchID] })
+		deleteWhere(&s.deletions, func(d repository.ConversationDeletion) bool { return gone[d.MatchID] })
+	}
+	return removed
+}
+
+func (s *Store) ListMatchesForUser(ctx context.Context, user1ID uuid.UUID) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.matches, func(m repository.Match) bool {
+		return (m.User1ID == user1ID || m.User2ID == user1ID) && live(m)
+	})
+	byScoreDesc(items, func(m repository.Match) pgtype.Numeric { return m.CompatibilityScore })
+	return items, nil
+}
+
+func (s *Store) GetMatchByID(ctx context.Context, id uuid.UUID) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.matchIndex(id)
+	if i < 0 || !live(s.matches[i]) {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	return s.matches[i], nil
+}
+
+func (s *Store) RevealMatch(ctx context.Context, id uuid.UUID) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.matchIndex(id)
+	if i < 0 {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	s.matches[i].IsRevealed = true
+	s.matches[i].RevealedAt = timestamptz(s.now())
+	return s.matches[i], nil
+}
+
+func (s *Store) UpdateMatchInterest(ctx context.Context, arg repository.UpdateMatchInterestParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.matchIndex(arg.ID)
+	if i < 0 {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	s.matches[i].IsMutualInterest = arg.IsMutualInterest
+	s.matches[i].UpdatedAt = s.now()
+	return s.matches[i], nil
+}
+
+// UnlockMatchMessaging returns pgx.ErrNoRows for a match that is already
+// unlocked, keeping its original unlock time and reason.
+func (s *Store) UnlockMatchMessaging(ctx context.Context, arg repository.UnlockMatchMessagingParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.matchIndex(arg.ID)
+	if i < 0 || s.matches[i].MessagingUnlocked {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	s.unlockMessaging(&s.matches[i], arg.MessagingUnlockReason, s.now())
+	return s.matches[i], nil
+}
+
+func (s *Store) unlockMessaging(match *repository.Match, reason pgtype.Text, now time.Time) {
+	match.MessagingUnlocked = true
+	match.MessagingUnlockedAt = timestamptz(now)
+	match.MessagingUnlockReason = reason
+	match.UpdatedAt = now
+}
+
+func (s *Store) ListMatches(ctx context.Context, arg repository.ListMatchesParams) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.matches, live)
+	byScoreDesc(items, func(m repository.Match) pgtype.Numeric { return m.CompatibilityScore })
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) ListMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.matches, func(m repository.Match) bool { return sameUUID(m.CampaignID, campaignID) && live(m) })
+	byScoreDesc(items, func(m repository.Match) pgtype.Numeric { return m.CompatibilityScore })
+	return items, nil
+}
+
+func (s *Store) CountMatchesAll(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.matches, live))), nil
+}
+
+func (s *Store) CreateMatch(ctx context.Context, arg repository.CreateMatchParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	match, _, err := s.insertMatch(repository.Match{
+		CampaignID:         arg.CampaignID,
+		User1ID:            arg.User1ID,
+		User2ID:            arg.User2ID,
+		CompatibilityScore: arg.CompatibilityScore,
+		MatchTier:          arg.MatchTier,
+		SharedInterests:    arg.SharedInterests,
+		RankForUser1:       arg.RankForUser1,
+		RankForUser2:       arg.RankForUser2,
+		IsMutualCrush:      arg.IsMutualCrush,
+		IsRevealed:         arg.IsRevealed,
+	})
+	return match, err
+}
+
+func (s *Store) InsertMatchIfAbsent(ctx context.Context, arg repository.InsertMatchIfAbsentParams) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	match, _, err := s.insertMatch(repository.Match{
+		CampaignID:         arg.CampaignID,
+		User1ID:            arg.User1ID,
+		User2ID:            arg.User2ID,
+		CompatibilityScore: arg.CompatibilityScore,
+		MatchTier:          arg.MatchTier,
+		SharedInterests:    arg.SharedInterests,
+		RankForUser1:       arg.RankForUser1,
+		RankForUser2:       arg.RankForUser2,
+		IsMutualCrush:      arg.IsMutualCrush,
+	})
+	if isUniqueViolation(err) {
+		return []repository.Match{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []repository.Match{match}, nil
+}
+
+// LockCampaignMatches stands in for the advisory lock. The store's mutex
+// already serializes every query, and there are no transactions to hold a
+// lock across, so there is nothing to do.
+func (s *Store) LockCampaignMatches(ctx context.Context, campaignID uuid.UUID) error {
+	return nil
+}
+
+func (s *Store) UpdateMatch(ctx context.Context, arg repository.UpdateMatchParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.matchIndex(arg.ID)
+	if i < 0 {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	match := &s.matches[i]
+	if arg.CompatibilityScore.Valid {
+		match.CompatibilityScore = arg.CompatibilityScore
+	}
+	if arg.MatchTier.Valid {
+		match.MatchTier = arg.MatchTier
+	}
+	match.IsRevealed = arg.IsRevealed
+	match.UpdatedAt = s.now()
+	return *match, nil
+}
+
+func (s *Store) DeleteMatch(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteMatches(func(m repository.Match) bool { return m.ID == id })
+	return nil
+}
+
+func (s *Store) DeleteMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteMatches(func(m repository.Match) bool { return sameUUID(m.CampaignID, campaignID) })
+	return nil
+}
+
+func (s *Store) UnlockMessagingByCampaign(ctx context.Context, arg repository.UnlockMessagingByCampaignParams) ([]repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	unlocked := []repository.Match{}
+	for i := range s.matches {
+		if sameUUID(s.matches[i].CampaignID, arg.CampaignID) && !s.matches[i].MessagingUnlocked {
+			s.unlockMessaging(&s.matches[i], arg.MessagingUnlockReason, now)
+			unlocked = append(unlocked, s.matches[i])
+		}
+	}
+	return unlocked, nil
+}
+
+// asUUID unwraps the untyped parameters of FindOrCreateMatchForUsers.
+func asUUID(value interface{}) uuid.UUID {
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v
+	case pgtype.UUID:
+		return v.Bytes
+	case string:
+		parsed, _ := uuid.Parse(v)
+		return parsed
+	}
+	return uuid.Nil
+}
+
+// FindOrCreateMatchForUsers stores the pair lowest ID first, outside any
+// campaign, and touches the existing row when the pair is already there.
+func (s *Store) FindOrCreateMatchForUsers(ctx context.Context, arg repository.FindOrCreateMatchForUsersParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user1, user2 := asUUID(arg.Column1), asUUID(arg.Column2)
+	if uuidLess(user2, user1) {
+		user1, user2 = user2, user1
+	}
+	match, i, err := s.insertMatch(repository.Match{
+		User1ID:            user1,
+		User2ID:            user2,
+		CompatibilityScore: pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		MatchTier:          pgtype.Text{String: "potential", Valid: true},
+		SharedInterests:    []byte("{}"),
+	})
+	if isUniqueViolation(err) {
+		s.matches[i].UpdatedAt = s.now()
+		return s.matches[i], nil
+	}
+	return match, err
+}
+
+func (s *Store) GetMatchByUsers(ctx context.Context, arg repository.GetMatchByUsersParams) (repository.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.matches, func(m repository.Match) bool {
+		return samePair(m.User1ID, m.User2ID, arg.User1ID, arg.User2ID)
+	})
+	if i < 0 {
+		return repository.Match{}, pgx.ErrNoRows
+	}
+	return s.matches[i], nil
+}
+
+func (s *Store) insertInteraction(matchID, userID uuid.UUID, interactionType string, metadata []byte) (repository.Interaction, error) {
+	if s.matchIndex(matchID) < 0 {
+		return repository.Interaction{}, foreignKeyViolation("interactions_match_id_fkey")
+	}
+	if s.userIndex(userID) < 0 {
+		return repository.Interaction{}, foreignKeyViolation("interactions_user_id_fkey")
+	}
+	interaction := repository.Interaction{
+		ID:              uuid.New(),
+		MatchID:         matchID,
+		UserID:          userID,
+		InteractionType: interactionType,
+		Metadata:        metadata,
+		CreatedAt:       s.now(),
+	}
+	s.interactions = append(s.interactions, interaction)
+	return interaction, nil
+}
+
+func (s *Store) CreateInteraction(ctx context.Context, arg repository.CreateInteractionParams) (repository.Interaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertInteraction(arg.MatchID, arg.UserID, arg.InteractionType, arg.Metadata)
+}
+
+func (s *Store) RecordUserInteraction(ctx context.Context, arg repository.RecordUserInteractionParams) (repository.Interaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertInteraction(arg.MatchID, arg.UserID, arg.InteractionType, arg.Metadata)
+}
+
+func (s *Store) FindInterestByMatchOtherUser(ctx context.Context, arg repository.FindInterestByMatchOtherUserParams) (repository.Interaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.interactions, func(in repository.Interaction) bool {
+		return in.MatchID == arg.MatchID && in.UserID != arg.UserID && in.InteractionType == "interest"
+	})
+	if i < 0 {
+		return repository.Interaction{}, pgx.ErrNoRows
+	}
+	return s.interactions[i], nil
+}
+
+// ListMatchOutcomesByCampaign only returns matches with at least one
+// interaction, since the SQL query inner joins them.
+func (s *Store) ListMatchOutcomesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.ListMatchOutcomesByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	decided := []string{"interest", "not_interested", "pass"}
+	items := []repository.ListMatchOutcomesByCampaignRow{}
+	for _, match := range s.matches {
+		if !sameUUID(match.CampaignID, campaignID) {
+			continue
+		}
+		row := repository.ListMatchOutcomesByCampaignRow{
+			ID:                 match.ID,
+			User1ID:            match.User1ID,
+			User2ID:            match.User2ID,
+			CompatibilityScore: match.CompatibilityScore,
+		}
+		joined := false
+		for _, in := range s.interactions {
+			if in.MatchID != match.ID {
+				continue
+			}
+			joined = true
+			switch in.UserID {
+			case match.User1ID:
+				row.User1Interested = row.User1Interested || in.InteractionType == "interest"
+				row.User1Decided = row.User1Decided || slices.Contains(decided, in.InteractionType)
+			case match.User2ID:
+				row.User2Interested = row.User2Interested || in.InteractionType == "interest"
+				row.User2Decided = row.User2Decided || slices.Contains(decided, in.InteractionType)
+			}
+		}
+		if joined {
+			items = append(items, row)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return uuidLess(items[i].ID, items[j].ID) })
+	return items, nil
+}
+
+// ListPairHistory groups every match by pair. A pair counts as matched when
+// it was matched in another campaign, and as rejected when either side passed
+// on it or reported the other in any campaign, this one included. Pairs with
+// neither are left out.
+func (s *Store) ListPairHistory(ctx context.Context, campaignID pgtype.UUID) ([]repository.ListPairHistoryRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rejected := map[uuid.UUID]bool{}
+	for _, in := range s.interactions {
+		if in.InteractionType == "not_interested" || in.InteractionType == "report" {
+			rejected[in.MatchID] = true
+		}
+	}
+	items := []repository.ListPairHistoryRow{}
+	pairs := map[[2]uuid.UUID]int{}
+	outside := map[[2]uuid.UUID]bool{}
+	for _, match := range s.matches {
+		key := [2]uuid.UUID{match.User1ID, match.User2ID}
+		i, ok := pairs[key]
+		if !ok {
+			i = len(items)
+			pairs[key] = i
+			items = append(items, repository.ListPairHistoryRow{User1ID: match.User1ID, User2ID: match.User2ID})
+		}
+		items[i].Matched = items[i].Matched || (match.CampaignID.Valid && match.CampaignID != campaignID)
+		items[i].Rejected = items[i].Rejected || rejected[match.ID]
+		// campaign_id IS DISTINCT FROM $1
+		if match.CampaignID != campaignID {
+			outside[key] = true
+		}
+	}
+	return where(items, func(row repository.ListPairHistoryRow) bool {
+		return row.Rejected || outside[[2]uuid.UUID{row.User1ID, row.User2ID}]
+	}), nil
+}
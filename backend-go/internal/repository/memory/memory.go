@@ -0,0 +1,240 @@
+// Package memory is an in-memory implementation of repository.Querier for
+// tests and tools that run without a database. It follows the SQL queries
+// closely: rows come back in the same order, unique keys and foreign keys
+// fail with the same Postgres error codes, and deletes cascade the way the
+// migrations declare.
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// Store keeps each table as a slice in insertion order, guarded by one
+// mutex. The clock never repeats, so created_at order and slice order agree.
+type Store struct {
+	mu    sync.Mutex
+	clock time.Time
+
+	adminSettings []repository.AdminSetting
+	blocks        []repository.UserBlock
+	campaigns     []repository.Campaign
+	constraints   []repository.PairConstraint
+	crushes       []repository.CrushList
+	deletions     []repository.ConversationDeletion
+	edits         []repository.MessageEdit
+	events        []repository.UserEvent
+	interactions  []repository.Interaction
+	jobs          []repository.Job
+	matches       []repository.Match
+	messages      []repository.Message
+	questions     []repository.Question
+	responses     []repository.SurveyResponse
+	runResults    []repository.MatchRunResult
+	runs          []repository.MatchRun
+	testimonials  []repository.Testimonial
+	users         []repository.User
+	vectors       []repository.TextVector
+
+	// lastEventID stands in for the user_events id sequence.
+	lastEventID int64
+
+	// answered indexes responses by (user_id, question_id), the key their
+	// upsert conflicts on.
+	answered map[[2]uuid.UUID]int
+}
+
+var _ repository.Querier = (*Store)(nil)
+
+func New() *Store {
+	return &Store{answered: map[[2]uuid.UUID]int{}}
+}
+
+// now stands in for NOW(). Each call returns a later time than the last.
+func (s *Store) now() time.Time {
+	now := time.Now()
+	if !now.After(s.clock) {
+		now = s.clock.Add(time.Microsecond)
+	}
+	s.clock = now
+	return now
+}
+
+func timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("insert or update violates foreign key constraint %q", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+func checkViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("new row violates check constraint %q", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+func notNullViolation(table, column string) error {
+	return &pgconn.PgError{
+		Severity:   "ERROR",
+		Code:       "23502",
+		Message:    fmt.Sprintf("null value in column %q of relation %q violates not-null constraint", column, table),
+		TableName:  table,
+		ColumnName: column,
+	}
+}
+
+// indexOf returns the position of the first row matching, or -1.
+func indexOf[T any](rows []T, match func(T) bool) int {
+	for i, row := range rows {
+		if match(row) {
+			return i
+		}
+	}
+	return -1
+}
+
+// where returns the rows matching, in order.
+func where[T any](rows []T, match func(T) bool) []T {
+	items := []T{}
+	for _, row := range rows {
+		if match(row) {
+			items = append(items, row)
+		}
+	}
+	return items
+}
+
+// newestFirst returns the rows matching in reverse insertion order, which is
+// ORDER BY created_at DESC.
+func newestFirst[T any](rows []T, match func(T) bool) []T {
+	items := []T{}
+	for i := len(rows) - 1; i >= 0; i-- {
+		if match(rows[i]) {
+			items = append(items, rows[i])
+		}
+	}
+	return items
+}
+
+// deleteWhere drops the rows matching and reports how many went.
+func deleteWhere[T any](rows *[]T, match func(T) bool) int {
+	kept := (*rows)[:0]
+	for _, row := range *rows {
+		if !match(row) {
+			kept = append(kept, row)
+		}
+	}
+	removed := len(*rows) - len(kept)
+	clear((*rows)[len(kept):])
+	*rows = kept
+	return removed
+}
+
+func page[T any](rows []T, limit, offset int32) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if int(offset) >= len(rows) {
+		return rows[:0]
+	}
+	rows = rows[offset:]
+	if limit >= 0 && int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func numericValue(n pgtype.Numeric) (float64, bool) {
+	value, err := n.Float64Value()
+	if err != nil || !value.Valid {
+		return 0, false
+	}
+	return value.Float64, true
+}
+
+// byScoreDesc sorts like ORDER BY compatibility_score DESC, where Postgres
+// puts NULLs first. Ties keep their insertion order.
+func byScoreDesc[T any](rows []T, score func(T) pgtype.Numeric) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, aok := numericValue(score(rows[i]))
+		b, bok := numericValue(score(rows[j]))
+		if aok != bok {
+			return !aok
+		}
+		return a > b
+	})
+}
+
+// sameUUID compares nullable columns the way = does in a WHERE clause: NULL
+// matches nothing.
+func sameUUID(a, b pgtype.UUID) bool {
+	return a.Valid && b.Valid && a.Bytes == b.Bytes
+}
+
+func uuidLess(a, b uuid.UUID) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
+
+func samePair(a1, a2, b1, b2 uuid.UUID) bool {
+	return (a1 == b1 && a2 == b2) || (a1 == b2 && a2 == b1)
+}
+
+// ilike reports whether value matches a Postgres ILIKE pattern, where %
+// matches any run of characters, _ matches one, and a backslash escapes.
+func ilike(value, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(value)
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func createUser(t *testing.T, store *Store, email string) repository.User {
+	t.Helper()
+	user, err := store.CreateUser(context.Background(), repository.CreateUserParams{Email: email, IsActive: true})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func TestUniqueKeysFailLikePostgres(t *testing.T) {
+	store := New()
+	createUser(t, store, "a@example.com")
+	_, err := store.CreateUser(context.Background(), repository.CreateUserParams{Email: "a@example.com"})
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		t.Fatalf("expected a unique violation, got %v", err)
+	}
+	if _, err := store.GetUserByEmail(context.Background(), "b@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+	}
+}
+
+func TestFindOrCreateMatchForUsersReusesThePair(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	a, b := createUser(t, store, "a@example.com"), createUser(t, store, "b@example.com")
+
+	first, err := store.FindOrCreateMatchForUsers(ctx, repository.FindOrCreateMatchForUsersParams{Column1: a.ID, Column2: b.ID})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	second, err := store.FindOrCreateMatchForUsers(ctx, repository.FindOrCreateMatchForUsersParams{Column1: b.ID, Column2: a.ID})
+	if err != nil {
+		t.Fatalf("find match: %v", err)
+	}
+	if first.ID != second.ID || !uuidLess(first.User1ID, first.User2ID) {
+		t.Fatalf("expected one match stored lowest ID first, got %+v and %+v", first, second)
+	}
+	if !second.UpdatedAt.After(first.UpdatedAt) {
+		t.Fatalf("expected the conflict to touch updated_at")
+	}
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	a, b := createUser(t, store, "a@example.com"), createUser(t, store, "b@example.com")
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: a.ID, User2ID: b.ID})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	if _, err := store.CreateMessage(ctx, repository.CreateMessageParams{MatchID: match.ID, SenderID: b.ID, RecipientID: a.ID, Content: "hi"}); err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	job, err := store.CreateJob(ctx, repository.CreateJobParams{JobType: "match", MaxAttempts: 3, CreatedBy: pgtype.UUID{Bytes: a.ID, Valid: true}})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	if err := store.DeleteUser(ctx, a.ID); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if matches, _ := store.ListMatchesForUser(ctx, b.ID); len(matches) != 0 {
+		t.Fatalf("expected the match to go with the user, got %d", len(matches))
+	}
+	if unread, _ := store.CountUnreadMessages(ctx, a.ID); unread != 0 {
+		t.Fatalf("expected the messages to go with the match, got %d", unread)
+	}
+	if job, _ = store.GetJobByID(ctx, job.ID); job.CreatedBy.Valid {
+		t.Fatalf("expected the job to outlive its creator")
+	}
+	if _, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: a.ID, User2ID: b.ID}); err == nil {
+		t.Fatalf("expected a match with a deleted user to break the foreign key")
+	}
+}
+
+func TestUnreadCountsSkipHiddenMessages(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	a, b := createUser(t, store, "a@example.com"), createUser(t, store, "b@example.com")
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{User1ID: a.ID, User2ID: b.ID})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	send := func() {
+		t.Helper()
+		if _, err := store.CreateMessage(ctx, repository.CreateMessageParams{MatchID: match.ID, SenderID: b.ID, RecipientID: a.ID, Content: "hi"}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	send()
+	if _, err := store.DeleteConversationForUser(ctx, repository.DeleteConversationForUserParams{UserID: a.ID, MatchID: match.ID}); err != nil {
+		t.Fatalf("delete conversation: %v", err)
+	}
+	send()
+	if unread, _ := store.CountUnreadMessages(ctx, a.ID); unread != 1 {
+		t.Fatalf("expected only the message after the deletion to count, got %d", unread)
+	}
+	page, _ := store.ListConversationsPage(ctx, repository.ListConversationsPageParams{UserID: a.ID, PageSize: 10})
+	if len(page) != 1 || page[0].UnreadCount != 1 || page[0].Match.ID != match.ID {
+		t.Fatalf("expected one conversation with its match and one unread message, got %+v", page)
+	}
+
+	if _, err := store.CreateUserBlock(ctx, repository.CreateUserBlockParams{BlockerID: b.ID, BlockedID: a.ID}); err != nil {
+		t.Fatalf("block user: %v", err)
+	}
+	if unread, _ := store.CountUnreadMessages(ctx, a.ID); unread != 0 {
+		t.Fatalf("expected a block to hide the messages, got %d unread", unread)
+	}
+	if page, _ := store.ListConversationsPage(ctx, repository.ListConversationsPageParams{UserID: a.ID, PageSize: 10}); len(page) != 0 {
+		t.Fatalf("expected a block to hide the conversation, got %+v", page)
+	}
+}
+
+func TestJobOutcomesNeedTheLock(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	job, err := store.CreateJob(ctx, repository.CreateJobParams{JobType: "match", MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	worker := func(name string) pgtype.Text { return pgtype.Text{String: name, Valid: true} }
+	later := pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true}
+	claim := func(name string) {
+		t.Helper()
+		if _, err := store.ClaimJob(ctx, repository.ClaimJobParams{WorkerID: worker(name), JobTypes: []string{"match"}, StaleBefore: later}); err != nil {
+			t.Fatalf("claim job: %v", err)
+		}
+	}
+
+	// A worker that went quiet and lost the job cannot record an outcome.
+	claim("first")
+	claim("second")
+	if err := store.CompleteJob(ctx, repository.CompleteJobParams{ID: job.ID, LockedBy: worker("first")}); err != nil {
+		t.Fatalf("complete job: %v", err)
+	}
+	if job, _ = store.GetJobByID(ctx, job.ID); job.Status != "running" || job.LockedBy.String != "second" {
+		t.Fatalf("expected the second worker to keep the job, got %s by %s", job.Status, job.LockedBy.String)
+	}
+
+	// A cancelled job whose worker died is finished by the sweep, whatever
+	// attempts it has left.
+	if _, err := store.RequestJobCancel(ctx, job.ID); err != nil {
+		t.Fatalf("cancel job: %v", err)
+	}
+	if swept, _ := store.FailAbandonedJobs(ctx, later); swept != 1 {
+		t.Fatalf("expected the sweep to finish the job, got %d", swept)
+	}
+	if job, _ = store.GetJobByID(ctx, job.ID); job.Status != "cancelled" || job.ErrorMessage.Valid {
+		t.Fatalf("expected the job to be cancelled, got %s %q", job.Status, job.ErrorMessage.String)
+	}
+}
+
+func TestILike(t *testing.T) {
+	cases := []struct {
+		value, pattern string
+		want           bool
+	}{
+		{"Jordan", "%jor%", true},
+		{"Jordan", "j_rdan", true},
+		{"Jordan", "jor", false},
+		{"50% off", `50\%%`, true},
+		{"a.b", "a.b", true},
+		{"axb", "a.b", false},
+	}
+	for _, tc := range cases {
+		if got := ilike(tc.value, tc.pattern); got != tc.want {
+			t.Fatalf("ilike(%q, %q) = %v, want %v", tc.value, tc.pattern, got, tc.want)
+		}
+	}
+}
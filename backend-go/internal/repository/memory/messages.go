@@ -0,0 +1,338 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// ListConversationsForUser returns the latest message of each conversation
+// the user is in, ordered by match ID like the DISTINCT ON query.
+func (s *Store) ListConversationsForUser(ctx context.Context, senderID uuid.UUID) ([]repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latest := map[uuid.UUID]repository.Message{}
+	for _, message := range s.messages {
+		if message.SenderID != senderID && message.RecipientID != senderID {
+			continue
+		}
+		if current, ok := latest[message.MatchID]; !ok || !message.SentAt.Before(current.SentAt) {
+			latest[message.MatchID] = message
+		}
+	}
+	items := make([]repository.Message, 0, len(latest))
+	for _, message := range latest {
+		items = append(items, message)
+	}
+	sort.Slice(items, func(i, j int) bool { return uuidLess(items[i].MatchID, items[j].MatchID) })
+	return items, nil
+}
+
+func (s *Store) ListMessagesForMatch(ctx context.Context, matchID uuid.UUID) ([]repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.messages, func(m repository.Message) bool { return m.MatchID == matchID })
+	sort.SliceStable(items, func(i, j int) bool { return items[i].SentAt.Before(items[j].SentAt) })
+	return items, nil
+}
+
+// keysetBefore orders rows by (time, id) the way Postgres compares row
+// values.
+func keysetBefore(at time.Time, id uuid.UUID, otherAt time.Time, otherID uuid.UUID) bool {
+	return at.Before(otherAt) || at.Equal(otherAt) && uuidLess(id, otherID)
+}
+
+// hiddenFrom reports whether the viewer deleted the message's conversation
+// after it was sent.
+func (s *Store) hiddenFrom(viewerID uuid.UUID, message repository.Message) bool {
+	return indexOf(s.deletions, func(d repository.ConversationDeletion) bool {
+		return d.UserID == viewerID && d.MatchID == message.MatchID && !message.SentAt.After(d.DeletedAt)
+	}) >= 0
+}
+
+func (s *Store) messageWithSender(message repository.Message) repository.ListMessagesBeforeRow {
+	var sender repository.User
+	if i := s.userIndex(message.SenderID); i >= 0 {
+		sender = s.users[i]
+	}
+	return repository.ListMessagesBeforeRow{
+		ID:                    message.ID,
+		MatchID:               message.MatchID,
+		SenderID:              message.SenderID,
+		RecipientID:           message.RecipientID,
+		Content:               message.Content,
+		IsRead:                message.IsRead,
+		SentAt:                message.SentAt,
+		ReadAt:                message.ReadAt,
+		UpdatedAt:             message.UpdatedAt,
+		EditedAt:              message.EditedAt,
+		UnsentAt:              message.UnsentAt,
+		SenderFirstName:       sender.FirstName,
+		SenderLastName:        sender.LastName,
+		SenderProfilePhotoUrl: sender.ProfilePhotoUrl,
+	}
+}
+
+func (s *Store) ListMessagesBefore(ctx context.Context, arg repository.ListMessagesBeforeParams) ([]repository.ListMessagesBeforeRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.messages, func(m repository.Message) bool {
+		return m.MatchID == arg.MatchID && !s.hiddenFrom(arg.ViewerID, m) &&
+			(!arg.BeforeSentAt.Valid || keysetBefore(m.SentAt, m.ID, arg.BeforeSentAt.Time, arg.BeforeID.Bytes))
+	})
+	sort.SliceStable(items, func(i, j int) bool {
+		return keysetBefore(items[j].SentAt, items[j].ID, items[i].SentAt, items[i].ID)
+	})
+	rows := []repository.ListMessagesBeforeRow{}
+	for _, message := range page(items, arg.PageSize, 0) {
+		rows = append(rows, s.messageWithSender(message))
+	}
+	return rows, nil
+}
+
+func (s *Store) ListMessagesAfter(ctx context.Context, arg repository.ListMessagesAfterParams) ([]repository.ListMessagesAfterRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := where(s.messages, func(m repository.Message) bool {
+		return m.MatchID == arg.MatchID && !s.hiddenFrom(arg.ViewerID, m) &&
+			keysetBefore(arg.AfterSentAt, arg.AfterID, m.SentAt, m.ID)
+	})
+	sort.SliceStable(items, func(i, j int) bool {
+		return keysetBefore(items[i].SentAt, items[i].ID, items[j].SentAt, items[j].ID)
+	})
+	rows := []repository.ListMessagesAfterRow{}
+	for _, message := range page(items, arg.PageSize, 0) {
+		rows = append(rows, repository.ListMessagesAfterRow(s.messageWithSender(message)))
+	}
+	return rows, nil
+}
+
+// ListConversationsPage returns the latest message of each conversation the
+// user is in, most recent first, with the other user, the match and the
+// unread count. Conversations with a blocked user or on a superseded match
+// are left out.
+func (s *Store) ListConversationsPage(ctx context.Context, arg repository.ListConversationsPageParams) ([]repository.ListConversationsPageRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latest := map[uuid.UUID]repository.Message{}
+	unread := map[uuid.UUID]int64{}
+	for _, message := range s.messages {
+		if message.SenderID != arg.UserID && message.RecipientID != arg.UserID {
+			continue
+		}
+		if s.hiddenFrom(arg.UserID, message) || s.pairBlocked(message.SenderID, message.RecipientID) {
+			continue
+		}
+		if current, ok := latest[message.MatchID]; !ok || keysetBefore(current.SentAt, current.ID, message.SentAt, message.ID) {
+			latest[message.MatchID] = message
+		}
+		if message.RecipientID == arg.UserID && !message.IsRead {
+			unread[message.MatchID]++
+		}
+	}
+	items := []repository.Message{}
+	for _, message := range latest {
+		if s.superseded(message.MatchID) {
+			continue
+		}
+		if !arg.BeforeSentAt.Valid || keysetBefore(message.SentAt, message.MatchID, arg.BeforeSentAt.Time, arg.BeforeMatchID.Bytes) {
+			items = append(items, message)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return keysetBefore(items[j].SentAt, items[j].MatchID, items[i].SentAt, items[i].MatchID)
+	})
+	rows := []repository.ListConversationsPageRow{}
+	for _, message := range page(items, arg.PageSize, 0) {
+		otherID := message.SenderID
+		if otherID == arg.UserID {
+			otherID = message.RecipientID
+		}
+		i, m := s.userIndex(otherID), s.matchIndex(message.MatchID)
+		if i < 0 || m < 0 {
+			continue
+		}
+		other := s.users[i]
+		rows = append(rows, repository.ListConversationsPageRow{
+			ID:                   message.ID,
+			MatchID:              message.MatchID,
+			SenderID:             message.SenderID,
+			RecipientID:          message.RecipientID,
+			Content:              message.Content,
+			SentAt:               message.SentAt,
+			UnsentAt:             message.UnsentAt,
+			OtherUserID:          other.ID,
+			OtherFirstName:       other.FirstName,
+			OtherLastName:        other.LastName,
+			OtherProfilePhotoUrl: other.ProfilePhotoUrl,
+			UnreadCount:          unread[message.MatchID],
+			Match:                s.matches[m],
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) CreateMessage(ctx context.Context, arg repository.CreateMessageParams) (repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matchIndex(arg.MatchID) < 0 {
+		return repository.Message{}, foreignKeyViolation("messages_match_id_fkey")
+	}
+	if s.userIndex(arg.SenderID) < 0 {
+		return repository.Message{}, foreignKeyViolation("messages_sender_id_fkey")
+	}
+	if s.userIndex(arg.RecipientID) < 0 {
+		return repository.Message{}, foreignKeyViolation("messages_recipient_id_fkey")
+	}
+	now := s.now()
+	message := repository.Message{
+		ID:          uuid.New(),
+		MatchID:     arg.MatchID,
+		SenderID:    arg.SenderID,
+		RecipientID: arg.RecipientID,
+		Content:     arg.Content,
+		SentAt:      now,
+		UpdatedAt:   now,
+	}
+	s.messages = append(s.messages, message)
+	return message, nil
+}
+
+func (s *Store) CountUnreadMessages(ctx context.Context, recipientID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.messages, func(m repository.Message) bool {
+		return m.RecipientID == recipientID && !m.IsRead &&
+			!s.hiddenFrom(recipientID, m) && !s.pairBlocked(m.SenderID, m.RecipientID) && !s.superseded(m.MatchID)
+	}))), nil
+}
+
+func (s *Store) CountUnreadMessagesForMatch(ctx context.Context, arg repository.CountUnreadMessagesForMatchParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.messages, func(m repository.Message) bool {
+		return m.MatchID == arg.MatchID && m.RecipientID == arg.RecipientID && !m.IsRead
+	}))), nil
+}
+
+func (s *Store) MarkMessagesRead(ctx context.Context, arg repository.MarkMessagesReadParams) ([]repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timestamptz(s.now())
+	read := []repository.Message{}
+	for i, message := range s.messages {
+		if slices.Contains(arg.Column1, message.ID) && message.RecipientID == arg.RecipientID && !message.IsRead {
+			s.messages[i].IsRead = true
+			s.messages[i].ReadAt = now
+			read = append(read, s.messages[i])
+		}
+	}
+	return read, nil
+}
+
+func (s *Store) MarkMatchMessagesRead(ctx context.Context, arg repository.MarkMatchMessagesReadParams) ([]repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timestamptz(s.now())
+	read := []repository.Message{}
+	for i, message := range s.messages {
+		if message.MatchID == arg.MatchID && message.RecipientID == arg.RecipientID && !message.IsRead {
+			s.messages[i].IsRead = true
+			s.messages[i].ReadAt = now
+			read = append(read, s.messages[i])
+		}
+	}
+	return read, nil
+}
+
+func (s *Store) GetMessageByID(ctx context.Context, id uuid.UUID) (repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.messages, func(m repository.Message) bool { return m.ID == id })
+	if i < 0 {
+		return repository.Message{}, pgx.ErrNoRows
+	}
+	return s.messages[i], nil
+}
+
+// revise keeps what the message said in its edit history and rewrites it,
+// the way EditMessage and UnsendMessage do. Unsent messages are left alone.
+func (s *Store) revise(id uuid.UUID, change func(message *repository.Message, now time.Time)) (repository.Message, error) {
+	i := indexOf(s.messages, func(m repository.Message) bool { return m.ID == id && !m.UnsentAt.Valid })
+	if i < 0 {
+		return repository.Message{}, pgx.ErrNoRows
+	}
+	now := s.now()
+	s.edits = append(s.edits, repository.MessageEdit{
+		ID:              uuid.New(),
+		MessageID:       id,
+		PreviousContent: s.messages[i].Content,
+		CreatedAt:       now,
+	})
+	change(&s.messages[i], now)
+	s.messages[i].UpdatedAt = now
+	return s.messages[i], nil
+}
+
+func (s *Store) EditMessage(ctx context.Context, arg repository.EditMessageParams) (repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revise(arg.ID, func(message *repository.Message, now time.Time) {
+		message.Content = arg.Content
+		message.EditedAt = timestamptz(now)
+	})
+}
+
+func (s *Store) UnsendMessage(ctx context.Context, id uuid.UUID) (repository.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revise(id, func(message *repository.Message, now time.Time) {
+		message.Content = ""
+		message.UnsentAt = timestamptz(now)
+	})
+}
+
+func (s *Store) ListMessageEdits(ctx context.Context, messageID uuid.UUID) ([]repository.MessageEdit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return where(s.edits, func(e repository.MessageEdit) bool { return e.MessageID == messageID }), nil
+}
+
+// deleteMessages drops the messages matching along with their edit history.
+func (s *Store) deleteMessages(match func(repository.Message) bool) {
+	gone := map[uuid.UUID]bool{}
+	deleteWhere(&s.messages, func(m repository.Message) bool {
+		if match(m) {
+			gone[m.ID] = true
+			return true
+		}
+		return false
+	})
+	deleteWhere(&s.edits, func(e repository.MessageEdit) bool { return gone[e.MessageID] })
+}
+
+func (s *Store) DeleteConversationForUser(ctx context.Context, arg repository.DeleteConversationForUserParams) (repository.ConversationDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userIndex(arg.UserID) < 0 {
+		return repository.ConversationDeletion{}, foreignKeyViolation("conversation_deletions_user_id_fkey")
+	}
+	if s.matchIndex(arg.MatchID) < 0 {
+		return repository.ConversationDeletion{}, foreignKeyViolation("conversation_deletions_match_id_fkey")
+	}
+	deletion := repository.ConversationDeletion{UserID: arg.UserID, MatchID: arg.MatchID, DeletedAt: s.now()}
+	if i := indexOf(s.deletions, func(d repository.ConversationDeletion) bool {
+		return d.UserID == arg.UserID && d.MatchID == arg.MatchID
+	}); i >= 0 {
+		s.deletions[i] = deletion
+		return deletion, nil
+	}
+	s.deletions = append(s.deletions, deletion)
+	return deletion, nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) constraintIndex(id uuid.UUID) int {
+	return indexOf(s.constraints, func(c repository.PairConstraint) bool { return c.ID == id })
+}
+
+func validConstraintType(constraintType string) bool {
+	return constraintType == "must_match" || constraintType == "must_not_match"
+}
+
+func (s *Store) ListPairConstraintsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]repository.PairConstraint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.constraints, func(c repository.PairConstraint) bool { return c.CampaignID == campaignID }), nil
+}
+
+func (s *Store) GetPairConstraintByID(ctx context.Context, id uuid.UUID) (repository.PairConstraint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.constraintIndex(id)
+	if i < 0 {
+		return repository.PairConstraint{}, pgx.ErrNoRows
+	}
+	return s.constraints[i], nil
+}
+
+func (s *Store) CreatePairConstraint(ctx context.Context, arg repository.CreatePairConstraintParams) (repository.PairConstraint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.campaignIndex(arg.CampaignID) < 0 {
+		return repository.PairConstraint{}, foreignKeyViolation("pair_constraints_campaign_id_fkey")
+	}
+	if s.userIndex(arg.User1ID) < 0 {
+		return repository.PairConstraint{}, foreignKeyViolation("pair_constraints_user1_id_fkey")
+	}
+	if s.userIndex(arg.User2ID) < 0 {
+		return repository.PairConstraint{}, foreignKeyViolation("pair_constraints_user2_id_fkey")
+	}
+	if !validConstraintType(arg.ConstraintType) {
+		return repository.PairConstraint{}, checkViolation("pair_constraints_constraint_type_check")
+	}
+	if !uuidLess(arg.User1ID, arg.User2ID) {
+		return repository.PairConstraint{}, checkViolation("pair_constraints_check")
+	}
+	if indexOf(s.constraints, func(c repository.PairConstraint) bool {
+		return c.CampaignID == arg.CampaignID && c.User1ID == arg.User1ID && c.User2ID == arg.User2ID
+	}) >= 0 {
+		return repository.PairConstraint{}, uniqueViolation("pair_constraints_campaign_id_user1_id_user2_id_key")
+	}
+	now := s.now()
+	constraint := repository.PairConstraint{
+		ID:             uuid.New(),
+		CampaignID:     arg.CampaignID,
+		User1ID:        arg.User1ID,
+		User2ID:        arg.User2ID,
+		ConstraintType: arg.ConstraintType,
+		Reason:         arg.Reason,
+		CreatedBy:      arg.CreatedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	s.constraints = append(s.constraints, constraint)
+	return constraint, nil
+}
+
+func (s *Store) UpdatePairConstraint(ctx context.Context, arg repository.UpdatePairConstraintParams) (repository.PairConstraint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.constraintIndex(arg.ID)
+	if i < 0 {
+		return repository.PairConstraint{}, pgx.ErrNoRows
+	}
+	if !validConstraintType(arg.ConstraintType) {
+		return repository.PairConstraint{}, checkViolation("pair_constraints_constraint_type_check")
+	}
+	s.constraints[i].ConstraintType = arg.ConstraintType
+	s.constraints[i].Reason = arg.Reason
+	s.constraints[i].UpdatedAt = s.now()
+	return s.constraints[i], nil
+}
+
+func (s *Store) DeletePairConstraint(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleteWhere(&s.constraints, func(c repository.PairConstraint) bool { return c.ID == id })
+	return nil
+}
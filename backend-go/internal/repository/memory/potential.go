@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// ageYears is EXTRACT(YEAR FROM age(dob)): whole years lived, or nil when
+// the birth date is NULL.
+func ageYears(dob pgtype.Date, today time.Time) *int {
+	if !dob.Valid {
+		return nil
+	}
+	years := today.Year() - dob.Time.Year()
+	if today.Month() < dob.Time.Month() || (today.Month() == dob.Time.Month() && today.Day() < dob.Time.Day()) {
+		years--
+	}
+	return &years
+}
+
+// atLeast and atMost compare an age against a nullable bound the way the
+// query does: a NULL bound passes, a NULL age against a set bound fails.
+func atLeast(age *int, bound pgtype.Int4) bool {
+	return !bound.Valid || (age != nil && *age >= int(bound.Int32))
+}
+
+func atMost(age *int, bound pgtype.Int4) bool {
+	return !bound.Valid || (age != nil && *age <= int(bound.Int32))
+}
+
+// seeksAny is cardinality(seeking) = 0 OR genders && seeking.
+func seeksAny(seeking, genders []string) bool {
+	if len(seeking) == 0 {
+		return true
+	}
+	for _, gender := range genders {
+		if slices.Contains(seeking, gender) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPotentialMatches returns up to 20 random users the given user could
+// still be matched with, leaving out anyone already matched with them, who
+// has acted on a match with them, or who is blocked either way.
+func (s *Store) ListPotentialMatches(ctx context.Context, arg repository.ListPotentialMatchesParams) ([]repository.ListPotentialMatchesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []repository.ListPotentialMatchesRow{}
+	i := s.userIndex(arg.UserID)
+	if i < 0 {
+		return items, nil
+	}
+	me := s.users[i]
+	today := time.Now()
+	meAge := ageYears(me.DateOfBirth, today)
+
+	excluded := map[uuid.UUID]bool{}
+	mine := map[uuid.UUID]bool{}
+	for _, match := range s.matches {
+		switch arg.UserID {
+		case match.User1ID:
+			excluded[match.User2ID] = true
+		case match.User2ID:
+			excluded[match.User1ID] = true
+		default:
+			continue
+		}
+		mine[match.ID] = true
+	}
+	for _, in := range s.interactions {
+		if mine[in.MatchID] && slices.Contains([]string{"pass", "interest", "not_interested"}, in.InteractionType) {
+			excluded[in.UserID] = true
+		}
+	}
+	for _, block := range s.blocks {
+		switch arg.UserID {
+		case block.BlockerID:
+			excluded[block.BlockedID] = true
+		case block.BlockedID:
+			excluded[block.BlockerID] = true
+		}
+	}
+
+	for _, user := range s.users {
+		if !user.SurveyCompleted || !user.IsActive || user.ID == arg.UserID || excluded[user.ID] {
+			continue
+		}
+		if !seeksAny(me.SeekingGenders, user.Genders) || !seeksAny(user.SeekingGenders, me.Genders) {
+			continue
+		}
+		age := ageYears(user.DateOfBirth, today)
+		minAge := int(arg.MinAge)
+		if minAge > 0 && (meAge == nil || *meAge < minAge || age == nil || *age < minAge) {
+			continue
+		}
+		if !atLeast(age, me.MinPartnerAge) || !atMost(age, me.MaxPartnerAge) ||
+			!atLeast(meAge, user.MinPartnerAge) || !atMost(meAge, user.MaxPartnerAge) {
+			continue
+		}
+		if arg.MaxAgeGap != 0 && age != nil && meAge != nil {
+			gap := *age - *meAge
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > int(arg.MaxAgeGap) {
+				continue
+			}
+		}
+		items = append(items, repository.ListPotentialMatchesRow{
+			ID:              user.ID,
+			Email:           user.Email,
+			FirstName:       user.FirstName,
+			LastName:        user.LastName,
+			Program:         user.Program,
+			YearLevel:       user.YearLevel,
+			Gender:          user.Gender,
+			SeekingGender:   user.SeekingGender,
+			ProfilePhotoUrl: user.ProfilePhotoUrl,
+			Bio:             user.Bio,
+		})
+	}
+	rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	if len(items) > 20 {
+		items = items[:20]
+	}
+	return items, nil
+}
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) questionIndex(id uuid.UUID) int {
+	return indexOf(s.questions, func(q repository.Question) bool { return q.ID == id })
+}
+
+func byOrderIndex(questions []repository.Question) []repository.Question {
+	sort.SliceStable(questions, func(i, j int) bool {
+		return questions[i].OrderIndex < questions[j].OrderIndex
+	})
+	return questions
+}
+
+func (s *Store) CreateQuestion(ctx context.Context, arg repository.CreateQuestionParams) (repository.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if arg.CampaignID.Valid && s.campaignIndex(arg.CampaignID.Bytes) < 0 {
+		return repository.Question{}, foreignKeyViolation("questions_campaign_id_fkey")
+	}
+	question := repository.Question{
+		ID:           uuid.New(),
+		CampaignID:   arg.CampaignID,
+		Category:     arg.Category,
+		QuestionText: arg.QuestionText,
+		QuestionType: arg.QuestionType,
+		Options:      arg.Options,
+		Weight:       arg.Weight,
+		IsActive:     arg.IsActive,
+		OrderIndex:   arg.OrderIndex,
+		CreatedAt:    s.now(),
+		Scoring:      arg.Scoring,
+	}
+	s.questions = append(s.questions, question)
+	return question, nil
+}
+
+func (s *Store) UpdateQuestion(ctx context.Context, arg repository.UpdateQuestionParams) (repository.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.questionIndex(arg.ID)
+	if i < 0 {
+		return repository.Question{}, pgx.ErrNoRows
+	}
+	question := &s.questions[i]
+	question.Category = arg.Category
+	question.QuestionText = arg.QuestionText
+	question.QuestionType = arg.QuestionType
+	if arg.Options != nil {
+		question.Options = arg.Options
+	}
+	if arg.Weight.Valid {
+		question.Weight = arg.Weight
+	}
+	question.IsActive = arg.IsActive
+	// order_index = COALESCE(NULLIF($8, 0), order_index)
+	if orderIndex, ok := arg.Column8.(int32); ok && orderIndex != 0 {
+		question.OrderIndex = orderIndex
+	}
+	if arg.Scoring != nil {
+		question.Scoring = arg.Scoring
+	}
+	return *question, nil
+}
+
+func (s *Store) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleteWhere(&s.questions, func(q repository.Question) bool { return q.ID == id })
+	deleteWhere(&s.responses, func(r repository.SurveyResponse) bool { return r.QuestionID == id })
+	s.indexResponses()
+	return nil
+}
+
+func (s *Store) ListQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return byOrderIndex(where(s.questions, func(q repository.Question) bool {
+		return sameUUID(q.CampaignID, campaignID)
+	})), nil
+}
+
+// ListQuestions returns the active questions of the newest active campaign.
+func (s *Store) ListQuestions(ctx context.Context) ([]repository.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	campaign, ok := s.activeCampaign()
+	if !ok {
+		return []repository.Question{}, nil
+	}
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+	return byOrderIndex(where(s.questions, func(q repository.Question) bool {
+		return q.IsActive && sameUUID(q.CampaignID, campaignID)
+	})), nil
+}
+
+func (s *Store) GetQuestionByID(ctx context.Context, id uuid.UUID) (repository.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.questionIndex(id)
+	if i < 0 {
+		return repository.Question{}, pgx.ErrNoRows
+	}
+	return s.questions[i], nil
+}
+
+// indexResponses rebuilds the upsert index after responses were deleted.
+func (s *Store) indexResponses() {
+	clear(s.answered)
+	for i, response := range s.responses {
+		s.answered[[2]uuid.UUID{response.UserID, response.QuestionID}] = i
+	}
+}
+
+// CreateSurveyResponse replaces the user's earlier answer to the question,
+// like the ON CONFLICT (user_id, question_id) clause of the SQL query.
+func (s *Store) CreateSurveyResponse(ctx context.Context, arg repository.CreateSurveyResponseParams) (repository.SurveyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userIndex(arg.UserID) < 0 {
+		return repository.SurveyResponse{}, foreignKeyViolation("survey_responses_user_id_fkey")
+	}
+	if s.questionIndex(arg.QuestionID) < 0 {
+		return repository.SurveyResponse{}, foreignKeyViolation("survey_responses_question_id_fkey")
+	}
+	if !slices.Contains([]string{"irrelevant", "a_little", "somewhat", "very", "mandatory"}, arg.Importance) {
+		return repository.SurveyResponse{}, checkViolation("survey_responses_importance_check")
+	}
+	now := s.now()
+	key := [2]uuid.UUID{arg.UserID, arg.QuestionID}
+	if i, ok := s.answered[key]; ok {
+		response := &s.responses[i]
+		response.AnswerText = arg.AnswerText
+		response.AnswerValue = arg.AnswerValue
+		response.AnswerJson = arg.AnswerJson
+		response.AnswerType = arg.AnswerType
+		response.AcceptableAnswers = arg.AcceptableAnswers
+		response.Importance = arg.Importance
+		response.UpdatedAt = now
+		return *response, nil
+	}
+	response := repository.SurveyResponse{
+		ID:                uuid.New(),
+		UserID:            arg.UserID,
+		CampaignID:        arg.CampaignID,
+		QuestionID:        arg.QuestionID,
+		AnswerText:        arg.AnswerText,
+		AnswerValue:       arg.AnswerValue,
+		AnswerJson:        arg.AnswerJson,
+		AnswerType:        arg.AnswerType,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		AcceptableAnswers: arg.AcceptableAnswers,
+		Importance:        arg.Importance,
+	}
+	s.answered[key] = len(s.responses)
+	s.responses = append(s.responses, response)
+	return response, nil
+}
+
+func (s *Store) ListSurveyResponsesByUser(ctx context.Context, userID uuid.UUID) ([]repository.SurveyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return where(s.responses, func(r repository.SurveyResponse) bool { return r.UserID == userID }), nil
+}
+
+func (s *Store) ListSurveyResponsesWithQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]repository.ListSurveyResponsesWithQuestionsByCampaignRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	eligible := s.userFlags(func(u repository.User) bool { return u.SurveyCompleted && u.IsActive })
+	questions := make(map[uuid.UUID]repository.Question, len(s.questions))
+	for _, question := range s.questions {
+		questions[question.ID] = question
+	}
+	items := []repository.ListSurveyResponsesWithQuestionsByCampaignRow{}
+	for _, response := range s.responses {
+		question, ok := questions[response.QuestionID]
+		if !ok || !sameUUID(response.CampaignID, campaignID) || !eligible[response.UserID] {
+			continue
+		}
+		items = append(items, repository.ListSurveyResponsesWithQuestionsByCampaignRow{
+			UserID:            response.UserID,
+			QuestionID:        response.QuestionID,
+			AnswerText:        response.AnswerText,
+			AnswerValue:       response.AnswerValue,
+			AnswerJson:        response.AnswerJson,
+			AnswerType:        response.AnswerType,
+			AcceptableAnswers: response.AcceptableAnswers,
+			Importance:        response.Importance,
+			QuestionCategory:  question.Category,
+			QuestionWeight:    question.Weight,
+			QuestionScoring:   question.Scoring,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) ListSurveyResponsesWithQuestionsForUsers(ctx context.Context, arg repository.ListSurveyResponsesWithQuestionsForUsersParams) ([]repository.ListSurveyResponsesWithQuestionsForUsersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type row struct {
+		order int32
+		item  repository.ListSurveyResponsesWithQuestionsForUsersRow
+	}
+	rows := []row{}
+	for _, response := range s.responses {
+		if !slices.Contains(arg.UserIds, response.UserID) || !sameUUID(response.CampaignID, arg.CampaignID) {
+			continue
+		}
+		i := s.questionIndex(response.QuestionID)
+		if i < 0 {
+			continue
+		}
+		question := s.questions[i]
+		rows = append(rows, row{question.OrderIndex, repository.ListSurveyResponsesWithQuestionsForUsersRow{
+			UserID:            response.UserID,
+			QuestionID:        response.QuestionID,
+			AnswerText:        response.AnswerText,
+			AnswerValue:       response.AnswerValue,
+			AnswerJson:        response.AnswerJson,
+			AnswerType:        response.AnswerType,
+			AcceptableAnswers: response.AcceptableAnswers,
+			Importance:        response.Importance,
+			QuestionText:      question.QuestionText,
+			QuestionCategory:  question.Category,
+			QuestionWeight:    question.Weight,
+			QuestionScoring:   question.Scoring,
+		}})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].order < rows[j].order })
+	items := make([]repository.ListSurveyResponsesWithQuestionsForUsersRow, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, r.item)
+	}
+	return items, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) ListTextVectorsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]repository.TextVector, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return where(s.vectors, func(v repository.TextVector) bool { return v.CampaignID == campaignID }), nil
+}
+
+func (s *Store) UpsertTextVectors(ctx context.Context, arg repository.UpsertTextVectorsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.campaignIndex(arg.CampaignID) < 0 {
+		return foreignKeyViolation("text_vectors_campaign_id_fkey")
+	}
+	vector := repository.TextVector{
+		CampaignID:  arg.CampaignID,
+		Field:       arg.Field,
+		Fingerprint: arg.Fingerprint,
+		Vectors:     arg.Vectors,
+		UpdatedAt:   s.now(),
+	}
+	if i := indexOf(s.vectors, func(v repository.TextVector) bool {
+		return v.CampaignID == arg.CampaignID && v.Field == arg.Field
+	}); i >= 0 {
+		s.vectors[i] = vector
+		return nil
+	}
+	s.vectors = append(s.vectors, vector)
+	return nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) CreateUserBlock(ctx context.Context, arg repository.CreateUserBlockParams) (repository.UserBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userIndex(arg.BlockerID) < 0 {
+		return repository.UserBlock{}, foreignKeyViolation("user_blocks_blocker_id_fkey")
+	}
+	if s.userIndex(arg.BlockedID) < 0 {
+		return repository.UserBlock{}, foreignKeyViolation("user_blocks_blocked_id_fkey")
+	}
+	if arg.BlockerID == arg.BlockedID {
+		return repository.UserBlock{}, checkViolation("user_blocks_check")
+	}
+	if i := indexOf(s.blocks, func(b repository.UserBlock) bool {
+		return b.BlockerID == arg.BlockerID && b.BlockedID == arg.BlockedID
+	}); i >= 0 {
+		s.blocks[i].Reason = arg.Reason
+		return s.blocks[i], nil
+	}
+	block := repository.UserBlock{
+		BlockerID: arg.BlockerID,
+		BlockedID: arg.BlockedID,
+		Reason:    arg.Reason,
+		CreatedAt: s.now(),
+	}
+	s.blocks = append(s.blocks, block)
+	return block, nil
+}
+
+func (s *Store) DeleteUserBlock(ctx context.Context, arg repository.DeleteUserBlockParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(deleteWhere(&s.blocks, func(b repository.UserBlock) bool {
+		return b.BlockerID == arg.BlockerID && b.BlockedID == arg.BlockedID
+	})), nil
+}
+
+func (s *Store) ListUserBlocksByBlocker(ctx context.Context, blockerID uuid.UUID) ([]repository.UserBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newestFirst(s.blocks, func(b repository.UserBlock) bool { return b.BlockerID == blockerID }), nil
+}
+
+func (s *Store) ListUserBlocks(ctx context.Context) ([]repository.UserBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return where(s.blocks, func(repository.UserBlock) bool { return true }), nil
+}
+
+// ListBlockedUserIDs returns everyone the user blocked or was blocked by,
+// once each like the UNION.
+func (s *Store) ListBlockedUserIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[uuid.UUID]bool{}
+	ids := []uuid.UUID{}
+	for _, block := range s.blocks {
+		other := uuid.Nil
+		switch blockerID {
+		case block.BlockerID:
+			other = block.BlockedID
+		case block.BlockedID:
+			other = block.BlockerID
+		}
+		if other != uuid.Nil && !seen[other] {
+			seen[other] = true
+			ids = append(ids, other)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) IsPairBlocked(ctx context.Context, arg repository.IsPairBlockedParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pairBlocked(arg.BlockerID, arg.BlockedID), nil
+}
+
+// pairBlocked reports whether either user blocked the other.
+func (s *Store) pairBlocked(user1, user2 uuid.UUID) bool {
+	return indexOf(s.blocks, func(b repository.UserBlock) bool {
+		return b.BlockerID == user1 && b.BlockedID == user2 ||
+			b.BlockerID == user2 && b.BlockedID == user1
+	}) >= 0
+}
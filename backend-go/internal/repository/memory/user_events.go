@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) CreateUserEvent(ctx context.Context, arg repository.CreateUserEventParams) (repository.UserEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if arg.UserID.Valid && s.userIndex(arg.UserID.Bytes) < 0 {
+		return repository.UserEvent{}, foreignKeyViolation("user_events_user_id_fkey")
+	}
+	// ON CONFLICT DO NOTHING returns no row. NULL keys never conflict.
+	if arg.DedupeKey.Valid && indexOf(s.events, func(e repository.UserEvent) bool {
+		return e.DedupeKey.Valid && e.DedupeKey.String == arg.DedupeKey.String
+	}) >= 0 {
+		return repository.UserEvent{}, pgx.ErrNoRows
+	}
+	s.lastEventID++
+	event := repository.UserEvent{
+		ID:        s.lastEventID,
+		UserID:    arg.UserID,
+		EventType: arg.EventType,
+		Payload:   slices.Clone(arg.Payload),
+		DedupeKey: arg.DedupeKey,
+		CreatedAt: s.now(),
+	}
+	s.events = append(s.events, event)
+	return event, nil
+}
+
+func (s *Store) DeleteUserEventsBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(deleteWhere(&s.events, func(e repository.UserEvent) bool {
+		return e.CreatedAt.Before(createdAt) && !e.DedupeKey.Valid
+	})), nil
+}
+
+func (s *Store) LatestUserEventID(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventID, nil
+}
+
+func (s *Store) ListUserEventsAfter(ctx context.Context, arg repository.ListUserEventsAfterParams) ([]repository.UserEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := where(s.events, func(e repository.UserEvent) bool {
+		return (sameUUID(e.UserID, arg.UserID) || !e.UserID.Valid) && e.ID > arg.ID
+	})
+	return page(events, arg.Limit, 0), nil
+}
@@ -0,0 +1,412 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func (s *Store) userIndex(id uuid.UUID) int {
+	return indexOf(s.users, func(u repository.User) bool { return u.ID == id })
+}
+
+// userFlags maps every user ID to whether the user passes keep, for the
+// queries that join users onto another table.
+func (s *Store) userFlags(keep func(repository.User) bool) map[uuid.UUID]bool {
+	flags := make(map[uuid.UUID]bool, len(s.users))
+	for _, user := range s.users {
+		flags[user.ID] = keep(user)
+	}
+	return flags
+}
+
+func sameText(a, b pgtype.Text) bool {
+	return a.Valid && b.Valid && a.String == b.String
+}
+
+// checkUser enforces the users table's unique and check constraints on a
+// row about to be written at position at, or appended when at is -1.
+func (s *Store) checkUser(user repository.User, at int) error {
+	for i, other := range s.users {
+		if i == at {
+			continue
+		}
+		switch {
+		case other.Email == user.Email:
+			return uniqueViolation("users_email_key")
+		case sameText(other.GoogleID, user.GoogleID):
+			return uniqueViolation("users_google_id_key")
+		case sameText(other.Username, user.Username):
+			return uniqueViolation("users_username_key")
+		case sameText(other.StudentID, user.StudentID):
+			return uniqueViolation("users_student_id_key")
+		}
+	}
+	if user.MinPartnerAge.Valid && user.MinPartnerAge.Int32 <= 0 {
+		return checkViolation("users_min_partner_age_check")
+	}
+	if user.MaxPartnerAge.Valid && user.MaxPartnerAge.Int32 <= 0 {
+		return checkViolation("users_max_partner_age_check")
+	}
+	if user.MinPartnerAge.Valid && user.MaxPartnerAge.Valid && user.MinPartnerAge.Int32 > user.MaxPartnerAge.Int32 {
+		return checkViolation("users_partner_age_range")
+	}
+	return nil
+}
+
+// replaceUser validates an updated copy of the user at i and stores it.
+func (s *Store) replaceUser(i int, user repository.User) (repository.User, error) {
+	if err := s.checkUser(user, i); err != nil {
+		return repository.User{}, err
+	}
+	s.users[i] = user
+	return user, nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id uuid.UUID) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.userIndex(id)
+	if i < 0 {
+		return repository.User{}, pgx.ErrNoRows
+	}
+	return s.users[i], nil
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.users, func(u repository.User) bool { return u.Email == email })
+	if i < 0 {
+		return repository.User{}, pgx.ErrNoRows
+	}
+	return s.users[i], nil
+}
+
+func (s *Store) GetUserByGoogleID(ctx context.Context, googleID pgtype.Text) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.users, func(u repository.User) bool { return sameText(u.GoogleID, googleID) })
+	if i < 0 {
+		return repository.User{}, pgx.ErrNoRows
+	}
+	return s.users[i], nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg repository.CreateUserParams) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	user := repository.User{
+		ID:                uuid.New(),
+		Email:             arg.Email,
+		GoogleID:          arg.GoogleID,
+		Username:          arg.Username,
+		StudentID:         arg.StudentID,
+		FirstName:         arg.FirstName,
+		LastName:          arg.LastName,
+		Program:           arg.Program,
+		YearLevel:         arg.YearLevel,
+		Gender:            arg.Gender,
+		SeekingGender:     arg.SeekingGender,
+		DateOfBirth:       arg.DateOfBirth,
+		ProfilePhotoUrl:   arg.ProfilePhotoUrl,
+		Bio:               arg.Bio,
+		InstagramHandle:   arg.InstagramHandle,
+		FacebookProfile:   arg.FacebookProfile,
+		SocialMediaName:   arg.SocialMediaName,
+		PhoneNumber:       arg.PhoneNumber,
+		ContactPreference: arg.ContactPreference,
+		ProfileVisibility: arg.ProfileVisibility,
+		Preferences:       arg.Preferences,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		LastLogin:         arg.LastLogin,
+		IsActive:          arg.IsActive,
+		SurveyCompleted:   arg.SurveyCompleted,
+		Genders:           arg.Genders,
+		SeekingGenders:    arg.SeekingGenders,
+	}
+	if user.Genders == nil {
+		user.Genders = []string{}
+	}
+	if user.SeekingGenders == nil {
+		user.SeekingGenders = []string{}
+	}
+	if err := s.checkUser(user, -1); err != nil {
+		return repository.User{}, err
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+// nonEmpty unwraps the UpdateUserProfile parameters wrapped in NULLIF, where
+// an empty string leaves the column alone.
+func nonEmpty(value interface{}) (string, bool) {
+	text, ok := value.(string)
+	return text, ok && text != ""
+}
+
+func (s *Store) UpdateUserProfile(ctx context.Context, arg repository.UpdateUserProfileParams) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.userIndex(arg.ID)
+	if i < 0 {
+		return repository.User{}, pgx.ErrNoRows
+	}
+	user := s.users[i]
+	if value, ok := nonEmpty(arg.Column2); ok {
+		user.FirstName = value
+	}
+	if value, ok := nonEmpty(arg.Column3); ok {
+		user.LastName = value
+	}
+	for _, field := range []struct {
+		column *pgtype.Text
+		value  pgtype.Text
+	}{
+		{&user.Program, arg.Program},
+		{&user.Gender, arg.Gender},
+		{&user.SeekingGender, arg.SeekingGender},
+		{&user.ProfilePhotoUrl, arg.ProfilePhotoUrl},
+		{&user.Bio, arg.Bio},
+		{&user.InstagramHandle, arg.InstagramHandle},
+		{&user.FacebookProfile, arg.FacebookProfile},
+		{&user.SocialMediaName, arg.SocialMediaName},
+		{&user.PhoneNumber, arg.PhoneNumber},
+		{&user.ContactPreference, arg.ContactPreference},
+	} {
+		if field.value.Valid {
+			*field.column = field.value
+		}
+	}
+	if arg.YearLevel.Valid {
+		user.YearLevel = arg.YearLevel
+	}
+	if arg.DateOfBirth.Valid {
+		user.DateOfBirth = arg.DateOfBirth
+	}
+	if value, ok := nonEmpty(arg.Column16); ok {
+		user.ProfileVisibility = value
+	}
+	if arg.Preferences != nil {
+		user.Preferences = arg.Preferences
+	}
+	if arg.Genders != nil {
+		user.Genders = arg.Genders
+	}
+	if arg.SeekingGenders != nil {
+		user.SeekingGenders = arg.SeekingGenders
+	}
+	if arg.MinPartnerAge.Valid {
+		user.MinPartnerAge = arg.MinPartnerAge
+	}
+	if arg.MaxPartnerAge.Valid {
+		user.MaxPartnerAge = arg.MaxPartnerAge
+	}
+	user.UpdatedAt = s.now()
+	return s.replaceUser(i, user)
+}
+
+func (s *Store) UpdateUserPreferences(ctx context.Context, arg repository.UpdateUserPreferencesParams) (repository.UpdateUserPreferencesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.userIndex(arg.ID)
+	if i < 0 {
+		return repository.UpdateUserPreferencesRow{}, pgx.ErrNoRows
+	}
+	s.users[i].Preferences = arg.Preferences
+	s.users[i].UpdatedAt = s.now()
+	return repository.UpdateUserPreferencesRow{ID: arg.ID, Preferences: arg.Preferences}, nil
+}
+
+func (s *Store) UpdateUserPhoto(ctx context.Context, arg repository.UpdateUserPhotoParams) (repository.UpdateUserPhotoRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.userIndex(arg.ID)
+	if i < 0 {
+		return repository.UpdateUserPhotoRow{}, pgx.ErrNoRows
+	}
+	s.users[i].ProfilePhotoUrl = arg.ProfilePhotoUrl
+	s.users[i].UpdatedAt = s.now()
+	return repository.UpdateUserPhotoRow{ID: arg.ID, ProfilePhotoUrl: arg.ProfilePhotoUrl}, nil
+}
+
+func adminRow(user repository.User) repository.ListUsersAdminRow {
+	return repository.ListUsersAdminRow{
+		ID:              user.ID,
+		Email:           user.Email,
+		StudentID:       user.StudentID,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Program:         user.Program,
+		YearLevel:       user.YearLevel,
+		SurveyCompleted: user.SurveyCompleted,
+		IsActive:        user.IsActive,
+		CreatedAt:       user.CreatedAt,
+		LastLogin:       user.LastLogin,
+	}
+}
+
+func (s *Store) ListUsersAdmin(ctx context.Context, arg repository.ListUsersAdminParams) ([]repository.ListUsersAdminRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []repository.ListUsersAdminRow{}
+	for _, user := range page(newestFirst(s.users, func(repository.User) bool { return true }), arg.Limit, arg.Offset) {
+		items = append(items, adminRow(user))
+	}
+	return items, nil
+}
+
+func (s *Store) CountUsers(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.users)), nil
+}
+
+func searchMatches(user repository.User, pattern string) bool {
+	return ilike(user.FirstName, pattern) ||
+		ilike(user.LastName, pattern) ||
+		ilike(user.Email, pattern) ||
+		(user.StudentID.Valid && ilike(user.StudentID.String, pattern))
+}
+
+func (s *Store) SearchUsersAdmin(ctx context.Context, arg repository.SearchUsersAdminParams) ([]repository.SearchUsersAdminRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matching := newestFirst(s.users, func(u repository.User) bool { return searchMatches(u, arg.FirstName) })
+	items := []repository.SearchUsersAdminRow{}
+	for _, user := range page(matching, arg.Limit, arg.Offset) {
+		items = append(items, repository.SearchUsersAdminRow(adminRow(user)))
+	}
+	return items, nil
+}
+
+func (s *Store) CountUsersSearch(ctx context.Context, firstName string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(where(s.users, func(u repository.User) bool { return searchMatches(u, firstName) }))), nil
+}
+
+func (s *Store) UpdateUserAdmin(ctx context.Context, arg repository.UpdateUserAdminParams) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.userIndex(arg.ID)
+	if i < 0 {
+		return repository.User{}, pgx.ErrNoRows
+	}
+	user := s.users[i]
+	user.Email = arg.Email
+	user.FirstName = arg.FirstName
+	user.LastName = arg.LastName
+	if arg.Program.Valid {
+		user.Program = arg.Program
+	}
+	if arg.YearLevel.Valid {
+		user.YearLevel = arg.YearLevel
+	}
+	user.SurveyCompleted = arg.SurveyCompleted
+	user.IsActive = arg.IsActive
+	user.UpdatedAt = s.now()
+	return s.replaceUser(i, user)
+}
+
+// DeleteUser removes the user with everything that cascades from it. It
+// fails like Postgres when an admin setting still names the user, since
+// that foreign key has no ON DELETE action.
+func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userIndex(id) < 0 {
+		return nil
+	}
+	userID := pgtype.UUID{Bytes: id, Valid: true}
+	if indexOf(s.adminSettings, func(a repository.AdminSetting) bool { return a.UpdatedBy == userID }) >= 0 {
+		return foreignKeyViolation("admin_settings_updated_by_fkey")
+	}
+	deleteWhere(&s.users, func(u repository.User) bool { return u.ID == id })
+
+	deleteWhere(&s.responses, func(r repository.SurveyResponse) bool { return r.UserID == id })
+	s.indexResponses()
+	s.deleteMatches(func(m repository.Match) bool { return m.User1ID == id || m.User2ID == id })
+	deleteWhere(&s.interactions, func(i repository.Interaction) bool { return i.UserID == id })
+	s.deleteMessages(func(m repository.Message) bool { return m.SenderID == id || m.RecipientID == id })
+	deleteWhere(&s.deletions, func(d repository.ConversationDeletion) bool { return d.UserID == id })
+	deleteWhere(&s.blocks, func(b repository.UserBlock) bool { return b.BlockerID == id || b.BlockedID == id })
+	deleteWhere(&s.crushes, func(c repository.CrushList) bool { return c.UserID == id })
+	deleteWhere(&s.events, func(e repository.UserEvent) bool { return e.UserID == userID })
+	deleteWhere(&s.runResults, func(r repository.MatchRunResult) bool { return r.User1ID == id || r.User2ID == id })
+	deleteWhere(&s.constraints, func(c repository.PairConstraint) bool { return c.User1ID == id || c.User2ID == id })
+
+	for i := range s.constraints {
+		if s.constraints[i].CreatedBy == userID {
+			s.constraints[i].CreatedBy = pgtype.UUID{}
+		}
+	}
+	for i := range s.runs {
+		if s.runs[i].CreatedBy == userID {
+			s.runs[i].CreatedBy = pgtype.UUID{}
+		}
+	}
+	for i := range s.jobs {
+		if s.jobs[i].CreatedBy == userID {
+			s.jobs[i].CreatedBy = pgtype.UUID{}
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListEligibleUsers(ctx context.Context) ([]repository.ListEligibleUsersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []repository.ListEligibleUsersRow{}
+	for _, user := range s.users {
+		if !user.SurveyCompleted || !user.IsActive {
+			continue
+		}
+		items = append(items, repository.ListEligibleUsersRow{
+			ID:             user.ID,
+			Email:          user.Email,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			Program:        user.Program,
+			YearLevel:      user.YearLevel,
+			Gender:         user.Gender,
+			SeekingGender:  user.SeekingGender,
+			Genders:        user.Genders,
+			SeekingGenders: user.SeekingGenders,
+			DateOfBirth:    user.DateOfBirth,
+			MinPartnerAge:  user.MinPartnerAge,
+			MaxPartnerAge:  user.MaxPartnerAge,
+			Bio:            user.Bio,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].FirstName < items[j].FirstName
+	})
+	return items, nil
+}
+
+func (s *Store) UpdateUserLastLogin(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.userIndex(id); i >= 0 {
+		s.users[i].LastLogin = timestamptz(s.now())
+	}
+	return nil
+}
+
+func (s *Store) SetUserSurveyCompleted(ctx context.Context, arg repository.SetUserSurveyCompletedParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.userIndex(arg.ID); i >= 0 {
+		s.users[i].SurveyCompleted = arg.SurveyCompleted
+		s.users[i].UpdatedAt = s.now()
+	}
+	return nil
+}
@@ -7,12 +7,28 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countUnreadMessages = `-- name: CountUnreadMessages :one
-SELECT COUNT(*) FROM messages WHERE recipient_id = $1 AND is_read = FALSE
+SELECT COUNT(*) FROM messages m
+WHERE m.recipient_id = $1 AND m.is_read = FALSE
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = m.recipient_id AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM user_blocks b
+      WHERE (b.blocker_id = m.recipient_id AND b.blocked_id = m.sender_id)
+         OR (b.blocker_id = m.sender_id AND b.blocked_id = m.recipient_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM matches mt
+      WHERE mt.id = m.match_id AND mt.superseded_at IS NOT NULL
+  )
 `
 
 func (q *Queries) CountUnreadMessages(ctx context.Context, recipientID uuid.UUID) (int64, error) {
@@ -45,7 +61,7 @@ INSERT INTO messages (
     recipient_id,
     content
 ) VALUES ($1, $2, $3, $4)
-RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at
+RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
 `
 
 type CreateMessageParams struct {
@@ -73,12 +89,91 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.SentAt,
 		&i.ReadAt,
 		&i.UpdatedAt,
+		&i.EditedAt,
+		&i.UnsentAt,
+	)
+	return i, err
+}
+
+const deleteConversationForUser = `-- name: DeleteConversationForUser :one
+INSERT INTO conversation_deletions (user_id, match_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, match_id) DO UPDATE SET deleted_at = NOW()
+RETURNING user_id, match_id, deleted_at
+`
+
+type DeleteConversationForUserParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	MatchID uuid.UUID `json:"match_id"`
+}
+
+func (q *Queries) DeleteConversationForUser(ctx context.Context, arg DeleteConversationForUserParams) (ConversationDeletion, error) {
+	row := q.db.QueryRow(ctx, deleteConversationForUser, arg.UserID, arg.MatchID)
+	var i ConversationDeletion
+	err := row.Scan(&i.UserID, &i.MatchID, &i.DeletedAt)
+	return i, err
+}
+
+const editMessage = `-- name: EditMessage :one
+WITH previous AS (
+    INSERT INTO message_edits (message_id, previous_content)
+    SELECT id, content FROM messages WHERE id = $1 AND unsent_at IS NULL
+)
+UPDATE messages
+SET content = $2, edited_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND unsent_at IS NULL
+RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
+`
+
+type EditMessageParams struct {
+	ID      uuid.UUID `json:"id"`
+	Content string    `json:"content"`
+}
+
+func (q *Queries) EditMessage(ctx context.Context, arg EditMessageParams) (Message, error) {
+	row := q.db.QueryRow(ctx, editMessage, arg.ID, arg.Content)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.MatchID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Content,
+		&i.IsRead,
+		&i.SentAt,
+		&i.ReadAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
+		&i.UnsentAt,
+	)
+	return i, err
+}
+
+const getMessageByID = `-- name: GetMessageByID :one
+SELECT id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at FROM messages WHERE id = $1
+`
+
+func (q *Queries) GetMessageByID(ctx context.Context, id uuid.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, getMessageByID, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.MatchID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Content,
+		&i.IsRead,
+		&i.SentAt,
+		&i.ReadAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
+		&i.UnsentAt,
 	)
 	return i, err
 }
 
 const listConversationsForUser = `-- name: ListConversationsForUser :many
-SELECT DISTINCT ON (match_id) id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at
+SELECT DISTINCT ON (match_id) id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
 FROM messages
 WHERE sender_id = $1 OR recipient_id = $1
 ORDER BY match_id, sent_at DESC
@@ -103,6 +198,315 @@ func (q *Queries) ListConversationsForUser(ctx context.Context, senderID uuid.UU
 			&i.SentAt,
 			&i.ReadAt,
 			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConversationsPage = `-- name: ListConversationsPage :many
+WITH latest AS (
+    SELECT DISTINCT ON (m.match_id) m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.sent_at, m.unsent_at
+    FROM messages m
+    WHERE (m.sender_id = $1 OR m.recipient_id = $1)
+      AND NOT EXISTS (
+          SELECT 1 FROM conversation_deletions d
+          WHERE d.user_id = $1 AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM user_blocks b
+          WHERE (b.blocker_id = m.sender_id AND b.blocked_id = m.recipient_id)
+             OR (b.blocker_id = m.recipient_id AND b.blocked_id = m.sender_id)
+      )
+    ORDER BY m.match_id, m.sent_at DESC, m.id DESC
+)
+SELECT l.id, l.match_id, l.sender_id, l.recipient_id, l.content, l.sent_at, l.unsent_at,
+       o.id AS other_user_id, o.first_name AS other_first_name, o.last_name AS other_last_name, o.profile_photo_url AS other_profile_photo_url,
+       (SELECT COUNT(*) FROM messages unread
+        WHERE unread.match_id = l.match_id AND unread.recipient_id = $1 AND unread.is_read = FALSE
+          AND NOT EXISTS (
+              SELECT 1 FROM conversation_deletions d
+              WHERE d.user_id = $1 AND d.match_id = unread.match_id AND unread.sent_at <= d.deleted_at
+          )) AS unread_count,
+       mt.id, mt.campaign_id, mt.user1_id, mt.user2_id, mt.compatibility_score, mt.match_tier, mt.shared_interests, mt.rank_for_user1, mt.rank_for_user2, mt.is_revealed, mt.is_mutual_interest, mt.is_mutual_crush, mt.messaging_unlocked, mt.created_at, mt.revealed_at, mt.updated_at, mt.messaging_unlocked_at, mt.messaging_unlock_reason, mt.superseded_at
+FROM latest l
+JOIN users o ON o.id = CASE WHEN l.sender_id = $1 THEN l.recipient_id ELSE l.sender_id END
+JOIN matches mt ON mt.id = l.match_id
+WHERE mt.superseded_at IS NULL
+  AND ($2::timestamptz IS NULL
+       OR (l.sent_at, l.match_id) < ($2::timestamptz, $3::uuid))
+ORDER BY l.sent_at DESC, l.match_id DESC
+LIMIT $4::int
+`
+
+type ListConversationsPageParams struct {
+	UserID        uuid.UUID          `json:"user_id"`
+	BeforeSentAt  pgtype.Timestamptz `json:"before_sent_at"`
+	BeforeMatchID pgtype.UUID        `json:"before_match_id"`
+	PageSize      int32              `json:"page_size"`
+}
+
+type ListConversationsPageRow struct {
+	ID                   uuid.UUID          `json:"id"`
+	MatchID              uuid.UUID          `json:"match_id"`
+	SenderID             uuid.UUID          `json:"sender_id"`
+	RecipientID          uuid.UUID          `json:"recipient_id"`
+	Content              string             `json:"content"`
+	SentAt               time.Time          `json:"sent_at"`
+	UnsentAt             pgtype.Timestamptz `json:"unsent_at"`
+	OtherUserID          uuid.UUID          `json:"other_user_id"`
+	OtherFirstName       string             `json:"other_first_name"`
+	OtherLastName        string             `json:"other_last_name"`
+	OtherProfilePhotoUrl pgtype.Text        `json:"other_profile_photo_url"`
+	UnreadCount          int64              `json:"unread_count"`
+	Match                Match              `json:"match"`
+}
+
+func (q *Queries) ListConversationsPage(ctx context.Context, arg ListConversationsPageParams) ([]ListConversationsPageRow, error) {
+	rows, err := q.db.Query(ctx, listConversationsPage,
+		arg.UserID,
+		arg.BeforeSentAt,
+		arg.BeforeMatchID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListConversationsPageRow{}
+	for rows.Next() {
+		var i ListConversationsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.SentAt,
+			&i.UnsentAt,
+			&i.OtherUserID,
+			&i.OtherFirstName,
+			&i.OtherLastName,
+			&i.OtherProfilePhotoUrl,
+			&i.UnreadCount,
+			&i.Match.ID,
+			&i.Match.CampaignID,
+			&i.Match.User1ID,
+			&i.Match.User2ID,
+			&i.Match.CompatibilityScore,
+			&i.Match.MatchTier,
+			&i.Match.SharedInterests,
+			&i.Match.RankForUser1,
+			&i.Match.RankForUser2,
+			&i.Match.IsRevealed,
+			&i.Match.IsMutualInterest,
+			&i.Match.IsMutualCrush,
+			&i.Match.MessagingUnlocked,
+			&i.Match.CreatedAt,
+			&i.Match.RevealedAt,
+			&i.Match.UpdatedAt,
+			&i.Match.MessagingUnlockedAt,
+			&i.Match.MessagingUnlockReason,
+			&i.Match.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageEdits = `-- name: ListMessageEdits :many
+SELECT id, message_id, previous_content, created_at FROM message_edits WHERE message_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) ListMessageEdits(ctx context.Context, messageID uuid.UUID) ([]MessageEdit, error) {
+	rows, err := q.db.Query(ctx, listMessageEdits, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MessageEdit{}
+	for rows.Next() {
+		var i MessageEdit
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessageID,
+			&i.PreviousContent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesAfter = `-- name: ListMessagesAfter :many
+SELECT m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.is_read, m.sent_at, m.read_at, m.updated_at, m.edited_at, m.unsent_at,
+       u.first_name AS sender_first_name, u.last_name AS sender_last_name, u.profile_photo_url AS sender_profile_photo_url
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE m.match_id = $1
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = $2 AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND (m.sent_at, m.id) > ($3::timestamptz, $4::uuid)
+ORDER BY m.sent_at ASC, m.id ASC
+LIMIT $5::int
+`
+
+type ListMessagesAfterParams struct {
+	MatchID     uuid.UUID `json:"match_id"`
+	ViewerID    uuid.UUID `json:"viewer_id"`
+	AfterSentAt time.Time `json:"after_sent_at"`
+	AfterID     uuid.UUID `json:"after_id"`
+	PageSize    int32     `json:"page_size"`
+}
+
+type ListMessagesAfterRow struct {
+	ID                    uuid.UUID          `json:"id"`
+	MatchID               uuid.UUID          `json:"match_id"`
+	SenderID              uuid.UUID          `json:"sender_id"`
+	RecipientID           uuid.UUID          `json:"recipient_id"`
+	Content               string             `json:"content"`
+	IsRead                bool               `json:"is_read"`
+	SentAt                time.Time          `json:"sent_at"`
+	ReadAt                pgtype.Timestamptz `json:"read_at"`
+	UpdatedAt             time.Time          `json:"updated_at"`
+	EditedAt              pgtype.Timestamptz `json:"edited_at"`
+	UnsentAt              pgtype.Timestamptz `json:"unsent_at"`
+	SenderFirstName       string             `json:"sender_first_name"`
+	SenderLastName        string             `json:"sender_last_name"`
+	SenderProfilePhotoUrl pgtype.Text        `json:"sender_profile_photo_url"`
+}
+
+func (q *Queries) ListMessagesAfter(ctx context.Context, arg ListMessagesAfterParams) ([]ListMessagesAfterRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesAfter,
+		arg.MatchID,
+		arg.ViewerID,
+		arg.AfterSentAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMessagesAfterRow{}
+	for rows.Next() {
+		var i ListMessagesAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.IsRead,
+			&i.SentAt,
+			&i.ReadAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
+			&i.SenderFirstName,
+			&i.SenderLastName,
+			&i.SenderProfilePhotoUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesBefore = `-- name: ListMessagesBefore :many
+SELECT m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.is_read, m.sent_at, m.read_at, m.updated_at, m.edited_at, m.unsent_at,
+       u.first_name AS sender_first_name, u.last_name AS sender_last_name, u.profile_photo_url AS sender_profile_photo_url
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE m.match_id = $1
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = $2 AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND ($3::timestamptz IS NULL
+       OR (m.sent_at, m.id) < ($3::timestamptz, $4::uuid))
+ORDER BY m.sent_at DESC, m.id DESC
+LIMIT $5::int
+`
+
+type ListMessagesBeforeParams struct {
+	MatchID      uuid.UUID          `json:"match_id"`
+	ViewerID     uuid.UUID          `json:"viewer_id"`
+	BeforeSentAt pgtype.Timestamptz `json:"before_sent_at"`
+	BeforeID     pgtype.UUID        `json:"before_id"`
+	PageSize     int32              `json:"page_size"`
+}
+
+type ListMessagesBeforeRow struct {
+	ID                    uuid.UUID          `json:"id"`
+	MatchID               uuid.UUID          `json:"match_id"`
+	SenderID              uuid.UUID          `json:"sender_id"`
+	RecipientID           uuid.UUID          `json:"recipient_id"`
+	Content               string             `json:"content"`
+	IsRead                bool               `json:"is_read"`
+	SentAt                time.Time          `json:"sent_at"`
+	ReadAt                pgtype.Timestamptz `json:"read_at"`
+	UpdatedAt             time.Time          `json:"updated_at"`
+	EditedAt              pgtype.Timestamptz `json:"edited_at"`
+	UnsentAt              pgtype.Timestamptz `json:"unsent_at"`
+	SenderFirstName       string             `json:"sender_first_name"`
+	SenderLastName        string             `json:"sender_last_name"`
+	SenderProfilePhotoUrl pgtype.Text        `json:"sender_profile_photo_url"`
+}
+
+func (q *Queries) ListMessagesBefore(ctx context.Context, arg ListMessagesBeforeParams) ([]ListMessagesBeforeRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesBefore,
+		arg.MatchID,
+		arg.ViewerID,
+		arg.BeforeSentAt,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMessagesBeforeRow{}
+	for rows.Next() {
+		var i ListMessagesBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.IsRead,
+			&i.SentAt,
+			&i.ReadAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
+			&i.SenderFirstName,
+			&i.SenderLastName,
+			&i.SenderProfilePhotoUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -115,7 +519,7 @@ func (q *Queries) ListConversationsForUser(ctx context.Context, senderID uuid.UU
 }
 
 const listMessagesForMatch = `-- name: ListMessagesForMatch :many
-SELECT id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at FROM messages WHERE match_id = $1 ORDER BY sent_at ASC
+SELECT id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at FROM messages WHERE match_id = $1 ORDER BY sent_at ASC
 `
 
 func (q *Queries) ListMessagesForMatch(ctx context.Context, matchID uuid.UUID) ([]Message, error) {
@@ -137,6 +541,8 @@ func (q *Queries) ListMessagesForMatch(ctx context.Context, matchID uuid.UUID) (
 			&i.SentAt,
 			&i.ReadAt,
 			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
 		); err != nil {
 			return nil, err
 		}
@@ -148,9 +554,53 @@ func (q *Queries) ListMessagesForMatch(ctx context.Context, matchID uuid.UUID) (
 	return items, nil
 }
 
-const markMessagesRead = `-- name: MarkMessagesRead :exec
+const markMatchMessagesRead = `-- name: MarkMatchMessagesRead :many
+UPDATE messages SET is_read = TRUE, read_at = NOW()
+WHERE match_id = $1 AND recipient_id = $2 AND is_read = FALSE
+RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
+`
+
+type MarkMatchMessagesReadParams struct {
+	MatchID     uuid.UUID `json:"match_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+}
+
+func (q *Queries) MarkMatchMessagesRead(ctx context.Context, arg MarkMatchMessagesReadParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, markMatchMessagesRead, arg.MatchID, arg.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.IsRead,
+			&i.SentAt,
+			&i.ReadAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markMessagesRead = `-- name: MarkMessagesRead :many
 UPDATE messages SET is_read = TRUE, read_at = NOW()
 WHERE id = ANY($1::uuid[]) AND recipient_id = $2 AND is_read = FALSE
+RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
 `
 
 type MarkMessagesReadParams struct {
@@ -158,7 +608,64 @@ type MarkMessagesReadParams struct {
 	RecipientID uuid.UUID   `json:"recipient_id"`
 }
 
-func (q *Queries) MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) error {
-	_, err := q.db.Exec(ctx, markMessagesRead, arg.Column1, arg.RecipientID)
-	return err
+func (q *Queries) MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, markMessagesRead, arg.Column1, arg.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.IsRead,
+			&i.SentAt,
+			&i.ReadAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+			&i.UnsentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unsendMessage = `-- name: UnsendMessage :one
+WITH previous AS (
+    INSERT INTO message_edits (message_id, previous_content)
+    SELECT id, content FROM messages WHERE id = $1 AND unsent_at IS NULL
+)
+UPDATE messages
+SET content = '', unsent_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND unsent_at IS NULL
+RETURNING id, match_id, sender_id, recipient_id, content, is_read, sent_at, read_at, updated_at, edited_at, unsent_at
+`
+
+func (q *Queries) UnsendMessage(ctx context.Context, id uuid.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, unsendMessage, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.MatchID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Content,
+		&i.IsRead,
+		&i.SentAt,
+		&i.ReadAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
+		&i.UnsentAt,
+	)
+	return i, err
 }
@@ -35,6 +35,12 @@ type Campaign struct {
 	CreatedAt              time.Time   `json:"created_at"`
 }
 
+type ConversationDeletion struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MatchID   uuid.UUID `json:"match_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
 type CrushList struct {
 	ID         uuid.UUID   `json:"id"`
 	UserID     uuid.UUID   `json:"user_id"`
@@ -56,23 +62,76 @@ type Interaction struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+type Job struct {
+	ID              uuid.UUID          `json:"id"`
+	JobType         string             `json:"job_type"`
+	Status          string             `json:"status"`
+	Payload         []byte             `json:"payload"`
+	Progress        []byte             `json:"progress"`
+	Result          []byte             `json:"result"`
+	ErrorMessage    pgtype.Text        `json:"error_message"`
+	Attempts        int32              `json:"attempts"`
+	MaxAttempts     int32              `json:"max_attempts"`
+	CancelRequested bool               `json:"cancel_requested"`
+	LockedBy        pgtype.Text        `json:"locked_by"`
+	HeartbeatAt     pgtype.Timestamptz `json:"heartbeat_at"`
+	CreatedBy       pgtype.UUID        `json:"created_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	FinishedAt      pgtype.Timestamptz `json:"finished_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
 type Match struct {
-	ID                 uuid.UUID          `json:"id"`
-	CampaignID         pgtype.UUID        `json:"campaign_id"`
-	User1ID            uuid.UUID          `json:"user1_id"`
-	User2ID            uuid.UUID          `json:"user2_id"`
-	CompatibilityScore pgtype.Numeric     `json:"compatibility_score"`
-	MatchTier          pgtype.Text        `json:"match_tier"`
-	SharedInterests    []byte             `json:"shared_interests"`
-	RankForUser1       pgtype.Int4        `json:"rank_for_user1"`
-	RankForUser2       pgtype.Int4        `json:"rank_for_user2"`
-	IsRevealed         bool               `json:"is_revealed"`
-	IsMutualInterest   bool               `json:"is_mutual_interest"`
-	IsMutualCrush      bool               `json:"is_mutual_crush"`
-	MessagingUnlocked  bool               `json:"messaging_unlocked"`
-	CreatedAt          time.Time          `json:"created_at"`
-	RevealedAt         pgtype.Timestamptz `json:"revealed_at"`
-	UpdatedAt          time.Time          `json:"updated_at"`
+	ID                    uuid.UUID          `json:"id"`
+	CampaignID            pgtype.UUID        `json:"campaign_id"`
+	User1ID               uuid.UUID          `json:"user1_id"`
+	User2ID               uuid.UUID          `json:"user2_id"`
+	CompatibilityScore    pgtype.Numeric     `json:"compatibility_score"`
+	MatchTier             pgtype.Text        `json:"match_tier"`
+	SharedInterests       []byte             `json:"shared_interests"`
+	RankForUser1          pgtype.Int4        `json:"rank_for_user1"`
+	RankForUser2          pgtype.Int4        `json:"rank_for_user2"`
+	IsRevealed            bool               `json:"is_revealed"`
+	IsMutualInterest      bool               `json:"is_mutual_interest"`
+	IsMutualCrush         bool               `json:"is_mutual_crush"`
+	MessagingUnlocked     bool               `json:"messaging_unlocked"`
+	CreatedAt             time.Time          `json:"created_at"`
+	RevealedAt            pgtype.Timestamptz `json:"revealed_at"`
+	UpdatedAt             time.Time          `json:"updated_at"`
+	MessagingUnlockedAt   pgtype.Timestamptz `json:"messaging_unlocked_at"`
+	MessagingUnlockReason pgtype.Text        `json:"messaging_unlock_reason"`
+	SupersededAt          pgtype.Timestamptz `json:"superseded_at"`
+}
+
+type MatchRun struct {
+	ID               uuid.UUID          `json:"id"`
+	CampaignID       uuid.UUID          `json:"campaign_id"`
+	Status           string             `json:"status"`
+	AlgorithmVersion string             `json:"algorithm_version"`
+	Config           []byte             `json:"config"`
+	TotalUsers       int32              `json:"total_users"`
+	CandidatePairs   int32              `json:"candidate_pairs"`
+	TotalMatches     int32              `json:"total_matches"`
+	ErrorMessage     pgtype.Text        `json:"error_message"`
+	CreatedBy        pgtype.UUID        `json:"created_by"`
+	StartedAt        time.Time          `json:"started_at"`
+	FinishedAt       pgtype.Timestamptz `json:"finished_at"`
+	PublishedAt      pgtype.Timestamptz `json:"published_at"`
+	Fairness         []byte             `json:"fairness"`
+}
+
+type MatchRunResult struct {
+	ID                 uuid.UUID      `json:"id"`
+	RunID              uuid.UUID      `json:"run_id"`
+	User1ID            uuid.UUID      `json:"user1_id"`
+	User2ID            uuid.UUID      `json:"user2_id"`
+	CompatibilityScore pgtype.Numeric `json:"compatibility_score"`
+	MatchTier          pgtype.Text    `json:"match_tier"`
+	SharedInterests    []byte         `json:"shared_interests"`
+	RankForUser1       pgtype.Int4    `json:"rank_for_user1"`
+	RankForUser2       pgtype.Int4    `json:"rank_for_user2"`
+	IsMutualCrush      bool           `json:"is_mutual_crush"`
 }
 
 type Message struct {
@@ -85,6 +144,27 @@ type Message struct {
 	SentAt      time.Time          `json:"sent_at"`
 	ReadAt      pgtype.Timestamptz `json:"read_at"`
 	UpdatedAt   time.Time          `json:"updated_at"`
+	EditedAt    pgtype.Timestamptz `json:"edited_at"`
+	UnsentAt    pgtype.Timestamptz `json:"unsent_at"`
+}
+
+type MessageEdit struct {
+	ID              uuid.UUID `json:"id"`
+	MessageID       uuid.UUID `json:"message_id"`
+	PreviousContent string    `json:"previous_content"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type PairConstraint struct {
+	ID             uuid.UUID   `json:"id"`
+	CampaignID     uuid.UUID   `json:"campaign_id"`
+	User1ID        uuid.UUID   `json:"user1_id"`
+	User2ID        uuid.UUID   `json:"user2_id"`
+	ConstraintType string      `json:"constraint_type"`
+	Reason         pgtype.Text `json:"reason"`
+	CreatedBy      pgtype.UUID `json:"created_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
 }
 
 type Question struct {
@@ -98,19 +178,22 @@ type Question struct {
 	IsActive     bool           `json:"is_active"`
 	OrderIndex   int32          `json:"order_index"`
 	CreatedAt    time.Time      `json:"created_at"`
+	Scoring      []byte         `json:"scoring"`
 }
 
 type SurveyResponse struct {
-	ID          uuid.UUID   `json:"id"`
-	UserID      uuid.UUID   `json:"user_id"`
-	CampaignID  pgtype.UUID `json:"campaign_id"`
-	QuestionID  uuid.UUID   `json:"question_id"`
-	AnswerText  pgtype.Text `json:"answer_text"`
-	AnswerValue pgtype.Int4 `json:"answer_value"`
-	AnswerJson  []byte      `json:"answer_json"`
-	AnswerType  string      `json:"answer_type"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID                uuid.UUID   `json:"id"`
+	UserID            uuid.UUID   `json:"user_id"`
+	CampaignID        pgtype.UUID `json:"campaign_id"`
+	QuestionID        uuid.UUID   `json:"question_id"`
+	AnswerText        pgtype.Text `json:"answer_text"`
+	AnswerValue       pgtype.Int4 `json:"answer_value"`
+	AnswerJson        []byte      `json:"answer_json"`
+	AnswerType        string      `json:"answer_type"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	AcceptableAnswers []byte      `json:"acceptable_answers"`
+	Importance        string      `json:"importance"`
 }
 
 type Testimonial struct {
@@ -125,6 +208,14 @@ type Testimonial struct {
 	UpdatedAt   time.Time   `json:"updated_at"`
 }
 
+type TextVector struct {
+	CampaignID  uuid.UUID `json:"campaign_id"`
+	Field       string    `json:"field"`
+	Fingerprint string    `json:"fingerprint"`
+	Vectors     []byte    `json:"vectors"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 type User struct {
 	ID                uuid.UUID          `json:"id"`
 	Email             string             `json:"email"`
@@ -152,4 +243,24 @@ type User struct {
 	LastLogin         pgtype.Timestamptz `json:"last_login"`
 	IsActive          bool               `json:"is_active"`
 	SurveyCompleted   bool               `json:"survey_completed"`
+	Genders           []string           `json:"genders"`
+	SeekingGenders    []string           `json:"seeking_genders"`
+	MinPartnerAge     pgtype.Int4        `json:"min_partner_age"`
+	MaxPartnerAge     pgtype.Int4        `json:"max_partner_age"`
+}
+
+type UserBlock struct {
+	BlockerID uuid.UUID   `json:"blocker_id"`
+	BlockedID uuid.UUID   `json:"blocked_id"`
+	Reason    pgtype.Text `json:"reason"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type UserEvent struct {
+	ID        int64       `json:"id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	EventType string      `json:"event_type"`
+	Payload   []byte      `json:"payload"`
+	DedupeKey pgtype.Text `json:"dedupe_key"`
+	CreatedAt time.Time   `json:"created_at"`
 }
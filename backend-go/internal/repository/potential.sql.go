@@ -21,8 +21,8 @@ VALUES (
     'potential',
     '{}'::jsonb
 )
-ON CONFLICT (user1_id, user2_id) DO UPDATE SET updated_at = NOW()
-RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at
+ON CONFLICT (campaign_id, user1_id, user2_id) DO UPDATE SET updated_at = NOW()
+RETURNING id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at
 `
 
 type FindOrCreateMatchForUsersParams struct {
@@ -50,12 +50,15 @@ func (q *Queries) FindOrCreateMatchForUsers(ctx context.Context, arg FindOrCreat
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
 
 const getMatchByUsers = `-- name: GetMatchByUsers :one
-SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at FROM matches
+SELECT id, campaign_id, user1_id, user2_id, compatibility_score, match_tier, shared_interests, rank_for_user1, rank_for_user2, is_revealed, is_mutual_interest, is_mutual_crush, messaging_unlocked, created_at, revealed_at, updated_at, messaging_unlocked_at, messaging_unlock_reason, superseded_at FROM matches
 WHERE (user1_id = $1 AND user2_id = $2) OR (user1_id = $2 AND user2_id = $1)
 LIMIT 1
 `
@@ -85,6 +88,9 @@ func (q *Queries) GetMatchByUsers(ctx context.Context, arg GetMatchByUsersParams
 		&i.CreatedAt,
 		&i.RevealedAt,
 		&i.UpdatedAt,
+		&i.MessagingUnlockedAt,
+		&i.MessagingUnlockReason,
+		&i.SupersededAt,
 	)
 	return i, err
 }
@@ -92,9 +98,21 @@ func (q *Queries) GetMatchByUsers(ctx context.Context, arg GetMatchByUsersParams
 const listPotentialMatches = `-- name: ListPotentialMatches :many
 SELECT u.id, u.email, u.first_name, u.last_name, u.program, u.year_level, u.gender, u.seeking_gender, u.profile_photo_url, u.bio
 FROM users u
+JOIN users me ON me.id = $1
+CROSS JOIN LATERAL (SELECT EXTRACT(YEAR FROM age(u.date_of_birth))::int AS years) u_age
+CROSS JOIN LATERAL (SELECT EXTRACT(YEAR FROM age(me.date_of_birth))::int AS years) me_age
 WHERE u.survey_completed = TRUE
   AND u.is_active = TRUE
   AND u.id != $1
+  AND (cardinality(me.seeking_genders) = 0 OR u.genders && me.seeking_genders)
+  AND (cardinality(u.seeking_genders) = 0 OR me.genders && u.seeking_genders)
+  AND ($2::int <= 0 OR me_age.years >= $2::int)
+  AND ($2::int <= 0 OR u_age.years >= $2::int)
+  AND (me.min_partner_age IS NULL OR u_age.years >= me.min_partner_age)
+  AND (me.max_partner_age IS NULL OR u_age.years <= me.max_partner_age)
+  AND (u.min_partner_age IS NULL OR me_age.years >= u.min_partner_age)
+  AND (u.max_partner_age IS NULL OR me_age.years <= u.max_partner_age)
+  AND ($3::int = 0 OR COALESCE(abs(u_age.years - me_age.years) <= $3::int, TRUE))
   AND u.id NOT IN (
     SELECT CASE WHEN m.user1_id = $1 THEN m.user2_id ELSE m.user1_id END
     FROM matches m
@@ -106,10 +124,21 @@ WHERE u.survey_completed = TRUE
     WHERE (m.user1_id = $1 OR m.user2_id = $1)
       AND i.interaction_type IN ('pass', 'interest', 'not_interested')
   )
+  AND NOT EXISTS (
+    SELECT 1 FROM user_blocks b
+    WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+       OR (b.blocker_id = u.id AND b.blocked_id = $1)
+  )
 ORDER BY RANDOM()
 LIMIT 20
 `
 
+type ListPotentialMatchesParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MinAge    int32     `json:"min_age"`
+	MaxAgeGap int32     `json:"max_age_gap"`
+}
+
 type ListPotentialMatchesRow struct {
 	ID              uuid.UUID   `json:"id"`
 	Email           string      `json:"email"`
@@ -123,8 +152,8 @@ type ListPotentialMatchesRow struct {
 	Bio             pgtype.Text `json:"bio"`
 }
 
-func (q *Queries) ListPotentialMatches(ctx context.Context, id uuid.UUID) ([]ListPotentialMatchesRow, error) {
-	rows, err := q.db.Query(ctx, listPotentialMatches, id)
+func (q *Queries) ListPotentialMatches(ctx context.Context, arg ListPotentialMatchesParams) ([]ListPotentialMatchesRow, error) {
+	rows, err := q.db.Query(ctx, listPotentialMatches, arg.UserID, arg.MinAge, arg.MaxAgeGap)
 	if err != nil {
 		return nil, err
 	}
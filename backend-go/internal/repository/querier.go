@@ -6,18 +6,23 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AppendMatchRunResult(ctx context.Context, arg AppendMatchRunResultParams) error
 	AverageCompatibilityScore(ctx context.Context) (float64, error)
 	AverageCompatibilityScoreByCampaign(ctx context.Context, campaignID pgtype.UUID) (float64, error)
+	ClaimJob(ctx context.Context, arg ClaimJobParams) (Job, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) error
+	CompleteMatchRun(ctx context.Context, arg CompleteMatchRunParams) (MatchRun, error)
 	CountActiveUsers(ctx context.Context) (int64, error)
 	CountCompletedSurveys(ctx context.Context) (int64, error)
 	CountCompletedSurveysByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error)
-	CountCrushByUserEmailCampaign(ctx context.Context, arg CountCrushByUserEmailCampaignParams) (int64, error)
+	CountMatchRunResults(ctx context.Context, runID uuid.UUID) (int64, error)
 	CountMatches(ctx context.Context) (int64, error)
 	CountMatchesAll(ctx context.Context) (int64, error)
 	CountMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) (int64, error)
@@ -33,61 +38,118 @@ type Querier interface {
 	CreateCampaign(ctx context.Context, arg CreateCampaignParams) (Campaign, error)
 	CreateCrush(ctx context.Context, arg CreateCrushParams) (CrushList, error)
 	CreateInteraction(ctx context.Context, arg CreateInteractionParams) (Interaction, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
 	CreateMatch(ctx context.Context, arg CreateMatchParams) (Match, error)
+	CreateMatchRun(ctx context.Context, arg CreateMatchRunParams) (MatchRun, error)
+	CreateMatchRunResult(ctx context.Context, arg CreateMatchRunResultParams) error
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreatePairConstraint(ctx context.Context, arg CreatePairConstraintParams) (PairConstraint, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
 	CreateSurveyResponse(ctx context.Context, arg CreateSurveyResponseParams) (SurveyResponse, error)
 	CreateTestimonial(ctx context.Context, arg CreateTestimonialParams) (Testimonial, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserBlock(ctx context.Context, arg CreateUserBlockParams) (UserBlock, error)
+	CreateUserEvent(ctx context.Context, arg CreateUserEventParams) (UserEvent, error)
 	DeleteCampaign(ctx context.Context, id uuid.UUID) error
+	DeleteConversationForUser(ctx context.Context, arg DeleteConversationForUserParams) (ConversationDeletion, error)
 	DeleteCrushesForUserCampaign(ctx context.Context, arg DeleteCrushesForUserCampaignParams) error
 	DeleteMatch(ctx context.Context, id uuid.UUID) error
 	DeleteMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) error
+	DeleteMatchesNotInRun(ctx context.Context, arg DeleteMatchesNotInRunParams) (int64, error)
+	DeletePairConstraint(ctx context.Context, id uuid.UUID) error
 	DeleteQuestion(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteUserBlock(ctx context.Context, arg DeleteUserBlockParams) (int64, error)
+	DeleteUserEventsBefore(ctx context.Context, createdAt time.Time) (int64, error)
+	EditMessage(ctx context.Context, arg EditMessageParams) (Message, error)
+	FailAbandonedJobs(ctx context.Context, staleBefore pgtype.Timestamptz) (int64, error)
+	FailJob(ctx context.Context, arg FailJobParams) error
+	FailMatchRun(ctx context.Context, arg FailMatchRunParams) error
 	FindInterestByMatchOtherUser(ctx context.Context, arg FindInterestByMatchOtherUserParams) (Interaction, error)
 	FindOrCreateMatchForUsers(ctx context.Context, arg FindOrCreateMatchForUsersParams) (Match, error)
 	GetActiveCampaign(ctx context.Context) (Campaign, error)
 	GetAdminSettingByKey(ctx context.Context, settingKey string) (AdminSetting, error)
 	GetCampaignByID(ctx context.Context, id uuid.UUID) (Campaign, error)
+	GetJobByID(ctx context.Context, id uuid.UUID) (Job, error)
+	GetLastSupersededMatchRun(ctx context.Context, campaignID uuid.UUID) (MatchRun, error)
 	GetMatchByID(ctx context.Context, id uuid.UUID) (Match, error)
 	GetMatchByUsers(ctx context.Context, arg GetMatchByUsersParams) (Match, error)
+	GetMatchRunByID(ctx context.Context, id uuid.UUID) (MatchRun, error)
+	GetMessageByID(ctx context.Context, id uuid.UUID) (Message, error)
+	GetPairConstraintByID(ctx context.Context, id uuid.UUID) (PairConstraint, error)
+	GetPublishedMatchRun(ctx context.Context, campaignID uuid.UUID) (MatchRun, error)
 	GetQuestionByID(ctx context.Context, id uuid.UUID) (Question, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByGoogleID(ctx context.Context, googleID pgtype.Text) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	HeartbeatJob(ctx context.Context, arg HeartbeatJobParams) (bool, error)
+	InsertMatchIfAbsent(ctx context.Context, arg InsertMatchIfAbsentParams) ([]Match, error)
+	InsertMatchesFromRun(ctx context.Context, arg InsertMatchesFromRunParams) ([]Match, error)
+	IsPairBlocked(ctx context.Context, arg IsPairBlockedParams) (bool, error)
+	LatestUserEventID(ctx context.Context) (int64, error)
+	ListBlockedUserIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
 	ListCampaigns(ctx context.Context) ([]Campaign, error)
 	ListConversationsForUser(ctx context.Context, senderID uuid.UUID) ([]Message, error)
+	ListConversationsPage(ctx context.Context, arg ListConversationsPageParams) ([]ListConversationsPageRow, error)
 	ListCrushesByEmailCampaign(ctx context.Context, arg ListCrushesByEmailCampaignParams) ([]CrushList, error)
 	ListCrushesForCampaign(ctx context.Context, campaignID uuid.UUID) ([]CrushList, error)
 	ListCrushesForUserCampaign(ctx context.Context, arg ListCrushesForUserCampaignParams) ([]CrushList, error)
 	ListEligibleUsers(ctx context.Context) ([]ListEligibleUsersRow, error)
+	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
+	ListMatchOutcomesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]ListMatchOutcomesByCampaignRow, error)
+	ListMatchRunResults(ctx context.Context, arg ListMatchRunResultsParams) ([]MatchRunResult, error)
+	ListMatchRunsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]MatchRun, error)
 	ListMatches(ctx context.Context, arg ListMatchesParams) ([]Match, error)
+	ListMatchesByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]Match, error)
 	ListMatchesForUser(ctx context.Context, user1ID uuid.UUID) ([]Match, error)
+	ListMessageEdits(ctx context.Context, messageID uuid.UUID) ([]MessageEdit, error)
+	ListMessagesAfter(ctx context.Context, arg ListMessagesAfterParams) ([]ListMessagesAfterRow, error)
+	ListMessagesBefore(ctx context.Context, arg ListMessagesBeforeParams) ([]ListMessagesBeforeRow, error)
 	ListMessagesForMatch(ctx context.Context, matchID uuid.UUID) ([]Message, error)
-	ListPotentialMatches(ctx context.Context, id uuid.UUID) ([]ListPotentialMatchesRow, error)
+	ListPairConstraintsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]PairConstraint, error)
+	ListPairHistory(ctx context.Context, campaignID pgtype.UUID) ([]ListPairHistoryRow, error)
+	ListPotentialMatches(ctx context.Context, arg ListPotentialMatchesParams) ([]ListPotentialMatchesRow, error)
 	ListQuestions(ctx context.Context) ([]Question, error)
+	ListQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]Question, error)
 	ListSurveyResponsesByUser(ctx context.Context, userID uuid.UUID) ([]SurveyResponse, error)
-	ListSurveyResponsesWithQuestionsByUserCampaign(ctx context.Context, arg ListSurveyResponsesWithQuestionsByUserCampaignParams) ([]ListSurveyResponsesWithQuestionsByUserCampaignRow, error)
+	ListSurveyResponsesWithQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]ListSurveyResponsesWithQuestionsByCampaignRow, error)
+	ListSurveyResponsesWithQuestionsForUsers(ctx context.Context, arg ListSurveyResponsesWithQuestionsForUsersParams) ([]ListSurveyResponsesWithQuestionsForUsersRow, error)
 	ListTestimonials(ctx context.Context) ([]Testimonial, error)
+	ListTextVectorsByCampaign(ctx context.Context, campaignID uuid.UUID) ([]TextVector, error)
+	ListUserBlocks(ctx context.Context) ([]UserBlock, error)
+	ListUserBlocksByBlocker(ctx context.Context, blockerID uuid.UUID) ([]UserBlock, error)
+	ListUserEventsAfter(ctx context.Context, arg ListUserEventsAfterParams) ([]UserEvent, error)
 	ListUsersAdmin(ctx context.Context, arg ListUsersAdminParams) ([]ListUsersAdminRow, error)
-	MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) error
+	LockCampaignMatches(ctx context.Context, campaignID uuid.UUID) error
+	MarkCrushMutual(ctx context.Context, id uuid.UUID) error
+	MarkJobCancelled(ctx context.Context, arg MarkJobCancelledParams) error
+	MarkMatchMessagesRead(ctx context.Context, arg MarkMatchMessagesReadParams) ([]Message, error)
+	MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) ([]Message, error)
 	MatchesByTier(ctx context.Context) ([]MatchesByTierRow, error)
 	MatchesByTierByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]MatchesByTierByCampaignRow, error)
 	ProgramsWithCompletion(ctx context.Context) ([]ProgramsWithCompletionRow, error)
 	ProgramsWithCompletionByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]ProgramsWithCompletionByCampaignRow, error)
 	PublicStats(ctx context.Context) (PublicStatsRow, error)
+	PublishMatchRun(ctx context.Context, id uuid.UUID) (MatchRun, error)
 	RecordUserInteraction(ctx context.Context, arg RecordUserInteractionParams) (Interaction, error)
+	ReleaseJob(ctx context.Context, arg ReleaseJobParams) error
+	RequestJobCancel(ctx context.Context, id uuid.UUID) (Job, error)
 	RevealMatch(ctx context.Context, id uuid.UUID) (Match, error)
 	SearchUsersAdmin(ctx context.Context, arg SearchUsersAdminParams) ([]SearchUsersAdminRow, error)
 	SetUserSurveyCompleted(ctx context.Context, arg SetUserSurveyCompletedParams) error
+	SupersedeMatchesNotInRun(ctx context.Context, arg SupersedeMatchesNotInRunParams) (int64, error)
+	SupersedePublishedMatchRun(ctx context.Context, campaignID uuid.UUID) error
 	TopPrograms(ctx context.Context, limit int32) ([]TopProgramsRow, error)
 	TopProgramsByCampaign(ctx context.Context, arg TopProgramsByCampaignParams) ([]TopProgramsByCampaignRow, error)
-	UnlockMessagingByCampaign(ctx context.Context, campaignID pgtype.UUID) error
+	UnlockMatchMessaging(ctx context.Context, arg UnlockMatchMessagingParams) (Match, error)
+	UnlockMessagingByCampaign(ctx context.Context, arg UnlockMessagingByCampaignParams) ([]Match, error)
+	UnsendMessage(ctx context.Context, id uuid.UUID) (Message, error)
 	UpdateCampaign(ctx context.Context, arg UpdateCampaignParams) (Campaign, error)
 	UpdateCampaignStats(ctx context.Context, arg UpdateCampaignStatsParams) error
 	UpdateMatch(ctx context.Context, arg UpdateMatchParams) (Match, error)
 	UpdateMatchInterest(ctx context.Context, arg UpdateMatchInterestParams) (Match, error)
+	UpdateMatchesFromRun(ctx context.Context, arg UpdateMatchesFromRunParams) (int64, error)
+	UpdatePairConstraint(ctx context.Context, arg UpdatePairConstraintParams) (PairConstraint, error)
 	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
 	UpdateTestimonialApproval(ctx context.Context, arg UpdateTestimonialApprovalParams) (Testimonial, error)
 	UpdateUserAdmin(ctx context.Context, arg UpdateUserAdminParams) (User, error)
@@ -96,6 +158,7 @@ type Querier interface {
 	UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (UpdateUserPreferencesRow, error)
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpsertAdminSetting(ctx context.Context, arg UpsertAdminSettingParams) (AdminSetting, error)
+	UpsertTextVectors(ctx context.Context, arg UpsertTextVectorsParams) error
 	YearLevelsWithCompletion(ctx context.Context) ([]YearLevelsWithCompletionRow, error)
 	YearLevelsWithCompletionByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]YearLevelsWithCompletionByCampaignRow, error)
 }
@@ -5,28 +5,28 @@ SELECT COUNT(*) FROM users WHERE is_active = TRUE;
 SELECT COUNT(*) FROM users WHERE is_active = TRUE AND survey_completed = TRUE;
 
 -- name: CountMatches :one
-SELECT COUNT(*) FROM matches;
+SELECT COUNT(*) FROM matches WHERE superseded_at IS NULL;
 
 -- name: CountMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE campaign_id = $1;
+SELECT COUNT(*) FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL;
 
 -- name: CountMutualMatches :one
-SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE;
+SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND superseded_at IS NULL;
 
 -- name: CountMutualMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND campaign_id = $1;
+SELECT COUNT(*) FROM matches WHERE is_mutual_interest = TRUE AND campaign_id = $1 AND superseded_at IS NULL;
 
 -- name: CountRevealedMatches :one
-SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE;
+SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND superseded_at IS NULL;
 
 -- name: CountRevealedMatchesByCampaign :one
-SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND campaign_id = $1;
+SELECT COUNT(*) FROM matches WHERE is_revealed = TRUE AND campaign_id = $1 AND superseded_at IS NULL;
 
 -- name: AverageCompatibilityScore :one
-SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches;
+SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE superseded_at IS NULL;
 
 -- name: AverageCompatibilityScoreByCampaign :one
-SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE campaign_id = $1;
+SELECT COALESCE(AVG(compatibility_score), 0)::float8 FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL;
 
 -- name: TopPrograms :many
 SELECT program, COUNT(*) AS count
@@ -86,10 +86,11 @@ ORDER BY u.year_level ASC;
 -- name: MatchesByTier :many
 SELECT match_tier, COUNT(*) AS count
 FROM matches
+WHERE superseded_at IS NULL
 GROUP BY match_tier;
 
 -- name: MatchesByTierByCampaign :many
 SELECT match_tier, COUNT(*) AS count
 FROM matches
-WHERE campaign_id = $1
+WHERE campaign_id = $1 AND superseded_at IS NULL
 GROUP BY match_tier;
@@ -41,7 +41,8 @@ DELETE FROM campaigns WHERE id = $1;
 -- name: UpdateCampaignStats :exec
 UPDATE campaigns SET
     total_participants = $2,
-    total_matches_generated = $3
+    total_matches_generated = $3,
+    algorithm_version = $4
 WHERE id = $1;
 
 -- name: CountParticipantsByCampaign :one
@@ -21,3 +21,6 @@ SELECT * FROM crush_lists WHERE crush_email = $1 AND campaign_id = $2;
 
 -- name: ListCrushesForCampaign :many
 SELECT * FROM crush_lists WHERE campaign_id = $1 ORDER BY created_at DESC;
+
+-- name: MarkCrushMutual :exec
+UPDATE crush_lists SET is_mutual = TRUE WHERE id = $1;
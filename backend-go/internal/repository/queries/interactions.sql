@@ -7,3 +7,31 @@ RETURNING *;
 SELECT * FROM interactions
 WHERE match_id = $1 AND user_id <> $2 AND interaction_type = 'interest'
 LIMIT 1;
+
+-- name: ListMatchOutcomesByCampaign :many
+SELECT
+    m.id,
+    m.user1_id,
+    m.user2_id,
+    m.compatibility_score,
+    BOOL_OR(i.user_id = m.user1_id AND i.interaction_type = 'interest')::boolean AS user1_interested,
+    BOOL_OR(i.user_id = m.user2_id AND i.interaction_type = 'interest')::boolean AS user2_interested,
+    BOOL_OR(i.user_id = m.user1_id AND i.interaction_type IN ('interest', 'not_interested', 'pass'))::boolean AS user1_decided,
+    BOOL_OR(i.user_id = m.user2_id AND i.interaction_type IN ('interest', 'not_interested', 'pass'))::boolean AS user2_decided
+FROM matches m
+JOIN interactions i ON i.match_id = m.id
+WHERE m.campaign_id = $1
+GROUP BY m.id
+ORDER BY m.id;
+
+-- name: ListPairHistory :many
+SELECT
+    m.user1_id,
+    m.user2_id,
+    COALESCE(BOOL_OR(m.campaign_id IS NOT NULL AND m.campaign_id <> $1), FALSE)::boolean AS matched,
+    COALESCE(BOOL_OR(i.interaction_type IN ('not_interested', 'report')), FALSE)::boolean AS rejected
+FROM matches m
+LEFT JOIN interactions i ON i.match_id = m.id
+GROUP BY m.user1_id, m.user2_id
+HAVING BOOL_OR(m.campaign_id IS DISTINCT FROM $1)
+    OR BOOL_OR(i.interaction_type IN ('not_interested', 'report'));
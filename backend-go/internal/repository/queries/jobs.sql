@@ -0,0 +1,112 @@
+-- name: CreateJob :one
+INSERT INTO jobs (
+    job_type,
+    payload,
+    max_attempts,
+    created_by
+) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetJobByID :one
+SELECT * FROM jobs WHERE id = $1 LIMIT 1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE job_type = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_by = sqlc.arg(worker_id),
+    heartbeat_at = NOW(),
+    started_at = COALESCE(started_at, NOW()),
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE job_type = ANY(sqlc.arg(job_types)::text[])
+      AND cancel_requested = FALSE
+      AND attempts < max_attempts
+      AND (status = 'queued' OR (status = 'running' AND heartbeat_at < sqlc.arg(stale_before)))
+    ORDER BY created_at
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: HeartbeatJob :one
+UPDATE jobs
+SET
+    progress = $2,
+    heartbeat_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $3
+RETURNING cancel_requested;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET
+    status = 'succeeded',
+    progress = $2,
+    result = $3,
+    locked_by = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $4;
+
+-- name: FailJob :exec
+UPDATE jobs
+SET
+    status = 'failed',
+    progress = $2,
+    error_message = $3,
+    locked_by = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $4;
+
+-- name: MarkJobCancelled :exec
+UPDATE jobs
+SET
+    status = 'cancelled',
+    progress = $2,
+    locked_by = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $3;
+
+-- name: ReleaseJob :exec
+UPDATE jobs
+SET
+    status = 'queued',
+    attempts = GREATEST(attempts - 1, 0),
+    progress = $2,
+    locked_by = NULL,
+    heartbeat_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $3;
+
+-- name: RequestJobCancel :one
+UPDATE jobs
+SET
+    cancel_requested = TRUE,
+    status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END,
+    finished_at = CASE WHEN status = 'queued' THEN NOW() ELSE finished_at END,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('queued', 'running')
+RETURNING *;
+
+-- name: FailAbandonedJobs :execrows
+UPDATE jobs
+SET
+    status = CASE WHEN cancel_requested THEN 'cancelled' ELSE 'failed' END,
+    error_message = CASE WHEN cancel_requested THEN error_message ELSE 'worker stopped responding too many times' END,
+    locked_by = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE status = 'running'
+  AND heartbeat_at < sqlc.arg(stale_before)
+  AND (cancel_requested OR attempts >= max_attempts);
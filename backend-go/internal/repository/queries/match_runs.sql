@@ -0,0 +1,159 @@
+-- name: CreateMatchRun :one
+INSERT INTO match_runs (
+    campaign_id,
+    algorithm_version,
+    config,
+    created_by
+) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CompleteMatchRun :one
+UPDATE match_runs
+SET
+    status = 'completed',
+    total_users = $2,
+    candidate_pairs = $3,
+    total_matches = $4,
+    fairness = $5,
+    finished_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailMatchRun :exec
+UPDATE match_runs
+SET
+    status = 'failed',
+    error_message = $2,
+    finished_at = NOW()
+WHERE id = $1;
+
+-- name: GetMatchRunByID :one
+SELECT * FROM match_runs WHERE id = $1 LIMIT 1;
+
+-- name: ListMatchRunsByCampaign :many
+SELECT * FROM match_runs WHERE campaign_id = $1 ORDER BY started_at DESC;
+
+-- name: GetPublishedMatchRun :one
+SELECT * FROM match_runs WHERE campaign_id = $1 AND status = 'published' LIMIT 1;
+
+-- name: GetLastSupersededMatchRun :one
+SELECT * FROM match_runs
+WHERE campaign_id = $1 AND status = 'superseded'
+ORDER BY published_at DESC
+LIMIT 1;
+
+-- name: SupersedePublishedMatchRun :exec
+UPDATE match_runs SET status = 'superseded'
+WHERE campaign_id = $1 AND status = 'published';
+
+-- name: PublishMatchRun :one
+UPDATE match_runs SET status = 'published', published_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateMatchRunResult :exec
+INSERT INTO match_run_results (
+    run_id,
+    user1_id,
+    user2_id,
+    compatibility_score,
+    match_tier,
+    shared_interests,
+    rank_for_user1,
+    rank_for_user2,
+    is_mutual_crush
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: AppendMatchRunResult :exec
+WITH appended AS (
+    INSERT INTO match_run_results (
+        run_id,
+        user1_id,
+        user2_id,
+        compatibility_score,
+        match_tier,
+        shared_interests,
+        rank_for_user1,
+        rank_for_user2,
+        is_mutual_crush
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    ON CONFLICT (run_id, user1_id, user2_id) DO NOTHING
+    RETURNING run_id
+)
+UPDATE match_runs
+SET total_matches = total_matches + 1
+WHERE id IN (SELECT run_id FROM appended);
+
+-- name: ListMatchRunResults :many
+SELECT * FROM match_run_results
+WHERE run_id = $1
+ORDER BY compatibility_score DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountMatchRunResults :one
+SELECT COUNT(*) FROM match_run_results WHERE run_id = $1;
+
+-- name: UpdateMatchesFromRun :execrows
+UPDATE matches m
+SET
+    compatibility_score = r.compatibility_score,
+    match_tier = r.match_tier,
+    shared_interests = r.shared_interests,
+    rank_for_user1 = CASE WHEN m.user1_id = r.user1_id THEN r.rank_for_user1 ELSE r.rank_for_user2 END,
+    rank_for_user2 = CASE WHEN m.user1_id = r.user1_id THEN r.rank_for_user2 ELSE r.rank_for_user1 END,
+    is_mutual_crush = r.is_mutual_crush,
+    superseded_at = NULL,
+    updated_at = NOW()
+FROM match_run_results r
+WHERE r.run_id = $1
+  AND m.campaign_id = $2
+  AND ((m.user1_id = r.user1_id AND m.user2_id = r.user2_id) OR (m.user1_id = r.user2_id AND m.user2_id = r.user1_id));
+
+-- name: DeleteMatchesNotInRun :execrows
+DELETE FROM matches m
+WHERE m.campaign_id = $2
+  AND NOT EXISTS (
+      SELECT 1 FROM match_run_results r
+      WHERE r.run_id = $1
+        AND ((m.user1_id = r.user1_id AND m.user2_id = r.user2_id) OR (m.user1_id = r.user2_id AND m.user2_id = r.user1_id))
+  )
+  AND NOT EXISTS (SELECT 1 FROM messages msg WHERE msg.match_id = m.id)
+  AND NOT EXISTS (
+      SELECT 1 FROM interactions i
+      WHERE i.match_id = m.id AND i.interaction_type IN ('not_interested', 'report')
+  );
+
+-- name: SupersedeMatchesNotInRun :execrows
+UPDATE matches m
+SET superseded_at = NOW(), updated_at = NOW()
+WHERE m.campaign_id = $2
+  AND m.superseded_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM match_run_results r
+      WHERE r.run_id = $1
+        AND ((m.user1_id = r.user1_id AND m.user2_id = r.user2_id) OR (m.user1_id = r.user2_id AND m.user2_id = r.user1_id))
+  );
+
+-- name: InsertMatchesFromRun :many
+INSERT INTO matches (
+    campaign_id,
+    user1_id,
+    user2_id,
+    compatibility_score,
+    match_tier,
+    shared_interests,
+    rank_for_user1,
+    rank_for_user2,
+    is_mutual_crush,
+    is_revealed
+)
+SELECT $2, r.user1_id, r.user2_id, r.compatibility_score, r.match_tier, r.shared_interests, r.rank_for_user1, r.rank_for_user2, r.is_mutual_crush, FALSE
+FROM match_run_results r
+WHERE r.run_id = $1
+  AND NOT EXISTS (
+      SELECT 1 FROM matches m
+      WHERE m.campaign_id = $2
+        AND ((m.user1_id = r.user1_id AND m.user2_id = r.user2_id) OR (m.user1_id = r.user2_id AND m.user2_id = r.user1_id))
+  )
+ON CONFLICT (campaign_id, user1_id, user2_id) DO NOTHING
+RETURNING *;
@@ -1,8 +1,8 @@
 -- name: ListMatchesForUser :many
-SELECT * FROM matches WHERE user1_id = $1 OR user2_id = $1 ORDER BY compatibility_score DESC;
+SELECT * FROM matches WHERE (user1_id = $1 OR user2_id = $1) AND superseded_at IS NULL ORDER BY compatibility_score DESC;
 
 -- name: GetMatchByID :one
-SELECT * FROM matches WHERE id = $1 LIMIT 1;
+SELECT * FROM matches WHERE id = $1 AND superseded_at IS NULL LIMIT 1;
 
 -- name: RevealMatch :one
 UPDATE matches SET is_revealed = TRUE, revealed_at = NOW() WHERE id = $1 RETURNING *;
@@ -11,7 +11,16 @@ UPDATE matches SET is_revealed = TRUE, revealed_at = NOW() WHERE id = $1 RETURNI
 UPDATE matches
 SET
     is_mutual_interest = $2,
-    messaging_unlocked = $3,
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: UnlockMatchMessaging :one
+UPDATE matches
+SET
+    messaging_unlocked = TRUE,
+    messaging_unlocked_at = NOW(),
+    messaging_unlock_reason = $2,
+    updated_at = NOW()
+WHERE id = $1 AND NOT messaging_unlocked
+RETURNING *;
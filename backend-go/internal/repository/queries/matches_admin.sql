@@ -1,8 +1,11 @@
 -- name: ListMatches :many
-SELECT * FROM matches ORDER BY compatibility_score DESC LIMIT $1 OFFSET $2;
+SELECT * FROM matches WHERE superseded_at IS NULL ORDER BY compatibility_score DESC LIMIT $1 OFFSET $2;
+
+-- name: ListMatchesByCampaign :many
+SELECT * FROM matches WHERE campaign_id = $1 AND superseded_at IS NULL ORDER BY compatibility_score DESC;
 
 -- name: CountMatchesAll :one
-SELECT COUNT(*) FROM matches;
+SELECT COUNT(*) FROM matches WHERE superseded_at IS NULL;
 
 
 -- name: CreateMatch :one
@@ -20,6 +23,25 @@ INSERT INTO matches (
 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
+-- name: InsertMatchIfAbsent :many
+INSERT INTO matches (
+    campaign_id,
+    user1_id,
+    user2_id,
+    compatibility_score,
+    match_tier,
+    shared_interests,
+    rank_for_user1,
+    rank_for_user2,
+    is_mutual_crush,
+    is_revealed
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE)
+ON CONFLICT (campaign_id, user1_id, user2_id) DO NOTHING
+RETURNING *;
+
+-- name: LockCampaignMatches :exec
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg(campaign_id)::uuid::text, 0));
+
 -- name: UpdateMatch :one
 UPDATE matches
 SET
@@ -36,6 +58,12 @@ DELETE FROM matches WHERE id = $1;
 -- name: DeleteMatchesByCampaign :exec
 DELETE FROM matches WHERE campaign_id = $1;
 
--- name: UnlockMessagingByCampaign :exec
-UPDATE matches SET messaging_unlocked = TRUE, updated_at = NOW()
-WHERE campaign_id = $1;
+-- name: UnlockMessagingByCampaign :many
+UPDATE matches
+SET
+    messaging_unlocked = TRUE,
+    messaging_unlocked_at = NOW(),
+    messaging_unlock_reason = $2,
+    updated_at = NOW()
+WHERE campaign_id = $1 AND NOT messaging_unlocked
+RETURNING *;
@@ -1,21 +1,36 @@
--- name: ListSurveyResponsesWithQuestionsByUserCampaign :many
+-- name: ListSurveyResponsesWithQuestionsByCampaign :many
 SELECT
-    sr.id,
     sr.user_id,
-    sr.campaign_id,
     sr.question_id,
     sr.answer_text,
     sr.answer_value,
     sr.answer_json,
     sr.answer_type,
-    sr.created_at,
-    sr.updated_at,
+    sr.acceptable_answers,
+    sr.importance,
     q.category AS question_category,
-    q.weight AS question_weight
+    q.weight AS question_weight,
+    q.scoring AS question_scoring
 FROM survey_responses sr
 JOIN questions q ON q.id = sr.question_id
-WHERE sr.user_id = $1 AND sr.campaign_id = $2;
+JOIN users u ON u.id = sr.user_id
+WHERE sr.campaign_id = $1 AND u.survey_completed = TRUE AND u.is_active = TRUE;
 
--- name: CountCrushByUserEmailCampaign :one
-SELECT COUNT(*) FROM crush_lists
-WHERE user_id = $1 AND campaign_id = $2 AND crush_email = $3;
+-- name: ListSurveyResponsesWithQuestionsForUsers :many
+SELECT
+    sr.user_id,
+    sr.question_id,
+    sr.answer_text,
+    sr.answer_value,
+    sr.answer_json,
+    sr.answer_type,
+    sr.acceptable_answers,
+    sr.importance,
+    q.question_text,
+    q.category AS question_category,
+    q.weight AS question_weight,
+    q.scoring AS question_scoring
+FROM survey_responses sr
+JOIN questions q ON q.id = sr.question_id
+WHERE sr.user_id = ANY(sqlc.arg(user_ids)::uuid[]) AND sr.campaign_id = sqlc.arg(campaign_id)
+ORDER BY q.order_index;
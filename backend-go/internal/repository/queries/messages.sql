@@ -17,11 +17,126 @@ INSERT INTO messages (
 RETURNING *;
 
 -- name: CountUnreadMessages :one
-SELECT COUNT(*) FROM messages WHERE recipient_id = $1 AND is_read = FALSE;
+SELECT COUNT(*) FROM messages m
+WHERE m.recipient_id = $1 AND m.is_read = FALSE
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = m.recipient_id AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM user_blocks b
+      WHERE (b.blocker_id = m.recipient_id AND b.blocked_id = m.sender_id)
+         OR (b.blocker_id = m.sender_id AND b.blocked_id = m.recipient_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM matches mt
+      WHERE mt.id = m.match_id AND mt.superseded_at IS NOT NULL
+  );
 
 -- name: CountUnreadMessagesForMatch :one
 SELECT COUNT(*) FROM messages WHERE match_id = $1 AND recipient_id = $2 AND is_read = FALSE;
 
--- name: MarkMessagesRead :exec
+-- name: MarkMessagesRead :many
 UPDATE messages SET is_read = TRUE, read_at = NOW()
-WHERE id = ANY($1::uuid[]) AND recipient_id = $2 AND is_read = FALSE;
+WHERE id = ANY($1::uuid[]) AND recipient_id = $2 AND is_read = FALSE
+RETURNING *;
+
+-- name: ListMessagesBefore :many
+SELECT m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.is_read, m.sent_at, m.read_at, m.updated_at, m.edited_at, m.unsent_at,
+       u.first_name AS sender_first_name, u.last_name AS sender_last_name, u.profile_photo_url AS sender_profile_photo_url
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE m.match_id = sqlc.arg(match_id)
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = sqlc.arg(viewer_id) AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND (sqlc.narg(before_sent_at)::timestamptz IS NULL
+       OR (m.sent_at, m.id) < (sqlc.narg(before_sent_at)::timestamptz, sqlc.narg(before_id)::uuid))
+ORDER BY m.sent_at DESC, m.id DESC
+LIMIT sqlc.arg(page_size)::int;
+
+-- name: ListMessagesAfter :many
+SELECT m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.is_read, m.sent_at, m.read_at, m.updated_at, m.edited_at, m.unsent_at,
+       u.first_name AS sender_first_name, u.last_name AS sender_last_name, u.profile_photo_url AS sender_profile_photo_url
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE m.match_id = sqlc.arg(match_id)
+  AND NOT EXISTS (
+      SELECT 1 FROM conversation_deletions d
+      WHERE d.user_id = sqlc.arg(viewer_id) AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+  )
+  AND (m.sent_at, m.id) > (sqlc.arg(after_sent_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY m.sent_at ASC, m.id ASC
+LIMIT sqlc.arg(page_size)::int;
+
+-- name: ListConversationsPage :many
+WITH latest AS (
+    SELECT DISTINCT ON (m.match_id) m.id, m.match_id, m.sender_id, m.recipient_id, m.content, m.sent_at, m.unsent_at
+    FROM messages m
+    WHERE (m.sender_id = sqlc.arg(user_id) OR m.recipient_id = sqlc.arg(user_id))
+      AND NOT EXISTS (
+          SELECT 1 FROM conversation_deletions d
+          WHERE d.user_id = sqlc.arg(user_id) AND d.match_id = m.match_id AND m.sent_at <= d.deleted_at
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM user_blocks b
+          WHERE (b.blocker_id = m.sender_id AND b.blocked_id = m.recipient_id)
+             OR (b.blocker_id = m.recipient_id AND b.blocked_id = m.sender_id)
+      )
+    ORDER BY m.match_id, m.sent_at DESC, m.id DESC
+)
+SELECT l.id, l.match_id, l.sender_id, l.recipient_id, l.content, l.sent_at, l.unsent_at,
+       o.id AS other_user_id, o.first_name AS other_first_name, o.last_name AS other_last_name, o.profile_photo_url AS other_profile_photo_url,
+       (SELECT COUNT(*) FROM messages unread
+        WHERE unread.match_id = l.match_id AND unread.recipient_id = sqlc.arg(user_id) AND unread.is_read = FALSE
+          AND NOT EXISTS (
+              SELECT 1 FROM conversation_deletions d
+              WHERE d.user_id = sqlc.arg(user_id) AND d.match_id = unread.match_id AND unread.sent_at <= d.deleted_at
+          )) AS unread_count,
+       sqlc.embed(mt)
+FROM latest l
+JOIN users o ON o.id = CASE WHEN l.sender_id = sqlc.arg(user_id) THEN l.recipient_id ELSE l.sender_id END
+JOIN matches mt ON mt.id = l.match_id
+WHERE mt.superseded_at IS NULL
+  AND (sqlc.narg(before_sent_at)::timestamptz IS NULL
+       OR (l.sent_at, l.match_id) < (sqlc.narg(before_sent_at)::timestamptz, sqlc.narg(before_match_id)::uuid))
+ORDER BY l.sent_at DESC, l.match_id DESC
+LIMIT sqlc.arg(page_size)::int;
+
+-- name: GetMessageByID :one
+SELECT * FROM messages WHERE id = $1;
+
+-- name: EditMessage :one
+WITH previous AS (
+    INSERT INTO message_edits (message_id, previous_content)
+    SELECT id, content FROM messages WHERE id = $1 AND unsent_at IS NULL
+)
+UPDATE messages
+SET content = $2, edited_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND unsent_at IS NULL
+RETURNING *;
+
+-- name: UnsendMessage :one
+WITH previous AS (
+    INSERT INTO message_edits (message_id, previous_content)
+    SELECT id, content FROM messages WHERE id = $1 AND unsent_at IS NULL
+)
+UPDATE messages
+SET content = '', unsent_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND unsent_at IS NULL
+RETURNING *;
+
+-- name: ListMessageEdits :many
+SELECT * FROM message_edits WHERE message_id = $1 ORDER BY created_at;
+
+-- name: DeleteConversationForUser :one
+INSERT INTO conversation_deletions (user_id, match_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, match_id) DO UPDATE SET deleted_at = NOW()
+RETURNING *;
+
+-- name: MarkMatchMessagesRead :many
+UPDATE messages SET is_read = TRUE, read_at = NOW()
+WHERE match_id = $1 AND recipient_id = $2 AND is_read = FALSE
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: ListPairConstraintsByCampaign :many
+SELECT * FROM pair_constraints
+WHERE campaign_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetPairConstraintByID :one
+SELECT * FROM pair_constraints WHERE id = $1;
+
+-- name: CreatePairConstraint :one
+INSERT INTO pair_constraints (campaign_id, user1_id, user2_id, constraint_type, reason, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdatePairConstraint :one
+UPDATE pair_constraints
+SET
+    constraint_type = $2,
+    reason = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePairConstraint :exec
+DELETE FROM pair_constraints WHERE id = $1;
@@ -1,20 +1,37 @@
 -- name: ListPotentialMatches :many
 SELECT u.id, u.email, u.first_name, u.last_name, u.program, u.year_level, u.gender, u.seeking_gender, u.profile_photo_url, u.bio
 FROM users u
+JOIN users me ON me.id = sqlc.arg(user_id)
+CROSS JOIN LATERAL (SELECT EXTRACT(YEAR FROM age(u.date_of_birth))::int AS years) u_age
+CROSS JOIN LATERAL (SELECT EXTRACT(YEAR FROM age(me.date_of_birth))::int AS years) me_age
 WHERE u.survey_completed = TRUE
   AND u.is_active = TRUE
-  AND u.id != $1
+  AND u.id != sqlc.arg(user_id)
+  AND (cardinality(me.seeking_genders) = 0 OR u.genders && me.seeking_genders)
+  AND (cardinality(u.seeking_genders) = 0 OR me.genders && u.seeking_genders)
+  AND (sqlc.arg(min_age)::int <= 0 OR me_age.years >= sqlc.arg(min_age)::int)
+  AND (sqlc.arg(min_age)::int <= 0 OR u_age.years >= sqlc.arg(min_age)::int)
+  AND (me.min_partner_age IS NULL OR u_age.years >= me.min_partner_age)
+  AND (me.max_partner_age IS NULL OR u_age.years <= me.max_partner_age)
+  AND (u.min_partner_age IS NULL OR me_age.years >= u.min_partner_age)
+  AND (u.max_partner_age IS NULL OR me_age.years <= u.max_partner_age)
+  AND (sqlc.arg(max_age_gap)::int = 0 OR COALESCE(abs(u_age.years - me_age.years) <= sqlc.arg(max_age_gap)::int, TRUE))
   AND u.id NOT IN (
-    SELECT CASE WHEN m.user1_id = $1 THEN m.user2_id ELSE m.user1_id END
+    SELECT CASE WHEN m.user1_id = sqlc.arg(user_id) THEN m.user2_id ELSE m.user1_id END
     FROM matches m
-    WHERE m.user1_id = $1 OR m.user2_id = $1
+    WHERE m.user1_id = sqlc.arg(user_id) OR m.user2_id = sqlc.arg(user_id)
   )
   AND u.id NOT IN (
     SELECT i.user_id FROM interactions i
     JOIN matches m ON m.id = i.match_id
-    WHERE (m.user1_id = $1 OR m.user2_id = $1)
+    WHERE (m.user1_id = sqlc.arg(user_id) OR m.user2_id = sqlc.arg(user_id))
       AND i.interaction_type IN ('pass', 'interest', 'not_interested')
   )
+  AND NOT EXISTS (
+    SELECT 1 FROM user_blocks b
+    WHERE (b.blocker_id = sqlc.arg(user_id) AND b.blocked_id = u.id)
+       OR (b.blocker_id = u.id AND b.blocked_id = sqlc.arg(user_id))
+  )
 ORDER BY RANDOM()
 LIMIT 20;
 
@@ -32,7 +49,7 @@ VALUES (
     'potential',
     '{}'::jsonb
 )
-ON CONFLICT (user1_id, user2_id) DO UPDATE SET updated_at = NOW()
+ON CONFLICT (campaign_id, user1_id, user2_id) DO UPDATE SET updated_at = NOW()
 RETURNING *;
 
 -- name: GetMatchByUsers :one
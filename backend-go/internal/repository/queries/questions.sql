@@ -7,8 +7,9 @@ INSERT INTO questions (
     options,
     weight,
     is_active,
-    order_index
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    order_index,
+    scoring
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: UpdateQuestion :one
@@ -20,9 +21,15 @@ SET
     options = COALESCE($5, options),
     weight = COALESCE($6, weight),
     is_active = COALESCE($7, is_active),
-    order_index = COALESCE(NULLIF($8, 0), order_index)
+    order_index = COALESCE(NULLIF($8, 0), order_index),
+    scoring = COALESCE($9, scoring)
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteQuestion :exec
 DELETE FROM questions WHERE id = $1;
+
+-- name: ListQuestionsByCampaign :many
+SELECT * FROM questions
+WHERE campaign_id = $1
+ORDER BY order_index ASC;
@@ -15,14 +15,18 @@ INSERT INTO survey_responses (
     answer_text,
     answer_value,
     answer_json,
-    answer_type
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
+    answer_type,
+    acceptable_answers,
+    importance
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (user_id, question_id)
 DO UPDATE SET
     answer_text = EXCLUDED.answer_text,
     answer_value = EXCLUDED.answer_value,
     answer_json = EXCLUDED.answer_json,
     answer_type = EXCLUDED.answer_type,
+    acceptable_answers = EXCLUDED.acceptable_answers,
+    importance = EXCLUDED.importance,
     updated_at = NOW()
 RETURNING *;
 
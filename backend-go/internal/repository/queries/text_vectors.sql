@@ -0,0 +1,15 @@
+-- name: ListTextVectorsByCampaign :many
+SELECT * FROM text_vectors WHERE campaign_id = $1;
+
+-- name: UpsertTextVectors :exec
+INSERT INTO text_vectors (
+    campaign_id,
+    field,
+    fingerprint,
+    vectors
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (campaign_id, field) DO UPDATE
+SET
+    fingerprint = EXCLUDED.fingerprint,
+    vectors = EXCLUDED.vectors,
+    updated_at = NOW();
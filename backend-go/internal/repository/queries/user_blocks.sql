@@ -0,0 +1,28 @@
+-- name: CreateUserBlock :one
+INSERT INTO user_blocks (blocker_id, blocked_id, reason)
+VALUES ($1, $2, $3)
+ON CONFLICT (blocker_id, blocked_id) DO UPDATE SET reason = EXCLUDED.reason
+RETURNING *;
+
+-- name: DeleteUserBlock :execrows
+DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: ListUserBlocksByBlocker :many
+SELECT * FROM user_blocks
+WHERE blocker_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListUserBlocks :many
+SELECT * FROM user_blocks;
+
+-- name: ListBlockedUserIDs :many
+SELECT blocked_id AS user_id FROM user_blocks WHERE blocker_id = $1
+UNION
+SELECT blocker_id AS user_id FROM user_blocks WHERE blocked_id = $1;
+
+-- name: IsPairBlocked :one
+SELECT EXISTS (
+    SELECT 1 FROM user_blocks
+    WHERE (blocker_id = $1 AND blocked_id = $2)
+       OR (blocker_id = $2 AND blocked_id = $1)
+);
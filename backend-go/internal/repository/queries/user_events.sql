@@ -0,0 +1,21 @@
+-- name: CreateUserEvent :one
+INSERT INTO user_events (
+    user_id,
+    event_type,
+    payload,
+    dedupe_key
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (dedupe_key) DO NOTHING
+RETURNING *;
+
+-- name: ListUserEventsAfter :many
+SELECT * FROM user_events
+WHERE (user_id = $1 OR user_id IS NULL) AND id > $2
+ORDER BY id
+LIMIT $3;
+
+-- name: LatestUserEventID :one
+SELECT COALESCE(MAX(id), 0)::bigint FROM user_events;
+
+-- name: DeleteUserEventsBefore :execrows
+DELETE FROM user_events WHERE created_at < $1 AND dedupe_key IS NULL;
@@ -31,9 +31,11 @@ INSERT INTO users (
     preferences,
     last_login,
     is_active,
-    survey_completed
+    survey_completed,
+    genders,
+    seeking_genders
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
 ) RETURNING *;
 
 -- name: UpdateUserProfile :one
@@ -54,6 +56,10 @@ UPDATE users SET
     contact_preference = COALESCE($15, contact_preference),
     profile_visibility = COALESCE(NULLIF($16, ''), profile_visibility),
     preferences = COALESCE($17, preferences),
+    genders = COALESCE($18, genders),
+    seeking_genders = COALESCE($19, seeking_genders),
+    min_partner_age = COALESCE($20, min_partner_age),
+    max_partner_age = COALESCE($21, max_partner_age),
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -109,7 +115,7 @@ RETURNING *;
 DELETE FROM users WHERE id = $1;
 
 -- name: ListEligibleUsers :many
-SELECT id, email, first_name, last_name, program, year_level, gender, seeking_gender
+SELECT id, email, first_name, last_name, program, year_level, gender, seeking_gender, genders, seeking_genders, date_of_birth, min_partner_age, max_partner_age, bio
 FROM users
 WHERE survey_completed = TRUE AND is_active = TRUE
 ORDER BY first_name ASC;
@@ -21,9 +21,10 @@ INSERT INTO questions (
     options,
     weight,
     is_active,
-    order_index
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at
+    order_index,
+    scoring
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at, scoring
 `
 
 type CreateQuestionParams struct {
@@ -35,6 +36,7 @@ type CreateQuestionParams struct {
 	Weight       pgtype.Numeric `json:"weight"`
 	IsActive     bool           `json:"is_active"`
 	OrderIndex   int32          `json:"order_index"`
+	Scoring      []byte         `json:"scoring"`
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
@@ -47,6 +49,7 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		arg.Weight,
 		arg.IsActive,
 		arg.OrderIndex,
+		arg.Scoring,
 	)
 	var i Question
 	err := row.Scan(
@@ -60,6 +63,7 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.IsActive,
 		&i.OrderIndex,
 		&i.CreatedAt,
+		&i.Scoring,
 	)
 	return i, err
 }
@@ -73,6 +77,44 @@ func (q *Queries) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const listQuestionsByCampaign = `-- name: ListQuestionsByCampaign :many
+SELECT id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at, scoring FROM questions
+WHERE campaign_id = $1
+ORDER BY order_index ASC
+`
+
+func (q *Queries) ListQuestionsByCampaign(ctx context.Context, campaignID pgtype.UUID) ([]Question, error) {
+	rows, err := q.db.Query(ctx, listQuestionsByCampaign, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Question{}
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.Category,
+			&i.QuestionText,
+			&i.QuestionType,
+			&i.Options,
+			&i.Weight,
+			&i.IsActive,
+			&i.OrderIndex,
+			&i.CreatedAt,
+			&i.Scoring,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateQuestion = `-- name: UpdateQuestion :one
 UPDATE questions
 SET
@@ -82,9 +124,10 @@ SET
     options = COALESCE($5, options),
     weight = COALESCE($6, weight),
     is_active = COALESCE($7, is_active),
-    order_index = COALESCE(NULLIF($8, 0), order_index)
+    order_index = COALESCE(NULLIF($8, 0), order_index),
+    scoring = COALESCE($9, scoring)
 WHERE id = $1
-RETURNING id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at
+RETURNING id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at, scoring
 `
 
 type UpdateQuestionParams struct {
@@ -96,6 +139,7 @@ type UpdateQuestionParams struct {
 	Weight       pgtype.Numeric `json:"weight"`
 	IsActive     bool           `json:"is_active"`
 	Column8      interface{}    `json:"column_8"`
+	Scoring      []byte         `json:"scoring"`
 }
 
 func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
@@ -108,6 +152,7 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		arg.Weight,
 		arg.IsActive,
 		arg.Column8,
+		arg.Scoring,
 	)
 	var i Question
 	err := row.Scan(
@@ -121,6 +166,7 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		&i.IsActive,
 		&i.OrderIndex,
 		&i.CreatedAt,
+		&i.Scoring,
 	)
 	return i, err
 }
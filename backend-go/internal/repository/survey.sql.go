@@ -20,26 +20,32 @@ INSERT INTO survey_responses (
     answer_text,
     answer_value,
     answer_json,
-    answer_type
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
+    answer_type,
+    acceptable_answers,
+    importance
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (user_id, question_id)
 DO UPDATE SET
     answer_text = EXCLUDED.answer_text,
     answer_value = EXCLUDED.answer_value,
     answer_json = EXCLUDED.answer_json,
     answer_type = EXCLUDED.answer_type,
+    acceptable_answers = EXCLUDED.acceptable_answers,
+    importance = EXCLUDED.importance,
     updated_at = NOW()
-RETURNING id, user_id, campaign_id, question_id, answer_text, answer_value, answer_json, answer_type, created_at, updated_at
+RETURNING id, user_id, campaign_id, question_id, answer_text, answer_value, answer_json, answer_type, created_at, updated_at, acceptable_answers, importance
 `
 
 type CreateSurveyResponseParams struct {
-	UserID      uuid.UUID   `json:"user_id"`
-	CampaignID  pgtype.UUID `json:"campaign_id"`
-	QuestionID  uuid.UUID   `json:"question_id"`
-	AnswerText  pgtype.Text `json:"answer_text"`
-	AnswerValue pgtype.Int4 `json:"answer_value"`
-	AnswerJson  []byte      `json:"answer_json"`
-	AnswerType  string      `json:"answer_type"`
+	UserID            uuid.UUID   `json:"user_id"`
+	CampaignID        pgtype.UUID `json:"campaign_id"`
+	QuestionID        uuid.UUID   `json:"question_id"`
+	AnswerText        pgtype.Text `json:"answer_text"`
+	AnswerValue       pgtype.Int4 `json:"answer_value"`
+	AnswerJson        []byte      `json:"answer_json"`
+	AnswerType        string      `json:"answer_type"`
+	AcceptableAnswers []byte      `json:"acceptable_answers"`
+	Importance        string      `json:"importance"`
 }
 
 func (q *Queries) CreateSurveyResponse(ctx context.Context, arg CreateSurveyResponseParams) (SurveyResponse, error) {
@@ -51,6 +57,8 @@ func (q *Queries) CreateSurveyResponse(ctx context.Context, arg CreateSurveyResp
 		arg.AnswerValue,
 		arg.AnswerJson,
 		arg.AnswerType,
+		arg.AcceptableAnswers,
+		arg.Importance,
 	)
 	var i SurveyResponse
 	err := row.Scan(
@@ -64,12 +72,14 @@ func (q *Queries) CreateSurveyResponse(ctx context.Context, arg CreateSurveyResp
 		&i.AnswerType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AcceptableAnswers,
+		&i.Importance,
 	)
 	return i, err
 }
 
 const getQuestionByID = `-- name: GetQuestionByID :one
-SELECT id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at FROM questions WHERE id = $1 LIMIT 1
+SELECT id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at, scoring FROM questions WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetQuestionByID(ctx context.Context, id uuid.UUID) (Question, error) {
@@ -86,12 +96,13 @@ func (q *Queries) GetQuestionByID(ctx context.Context, id uuid.UUID) (Question,
 		&i.IsActive,
 		&i.OrderIndex,
 		&i.CreatedAt,
+		&i.Scoring,
 	)
 	return i, err
 }
 
 const listQuestions = `-- name: ListQuestions :many
-SELECT id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at FROM questions
+SELECT id, campaign_id, category, question_text, question_type, options, weight, is_active, order_index, created_at, scoring FROM questions
 WHERE campaign_id = (SELECT id FROM campaigns WHERE is_active = TRUE ORDER BY created_at DESC LIMIT 1)
   AND is_active = TRUE
 ORDER BY order_index ASC
@@ -117,6 +128,7 @@ func (q *Queries) ListQuestions(ctx context.Context) ([]Question, error) {
 			&i.IsActive,
 			&i.OrderIndex,
 			&i.CreatedAt,
+			&i.Scoring,
 		); err != nil {
 			return nil, err
 		}
@@ -129,7 +141,7 @@ func (q *Queries) ListQuestions(ctx context.Context) ([]Question, error) {
 }
 
 const listSurveyResponsesByUser = `-- name: ListSurveyResponsesByUser :many
-SELECT id, user_id, campaign_id, question_id, answer_text, answer_value, answer_json, answer_type, created_at, updated_at FROM survey_responses WHERE user_id = $1 ORDER BY created_at ASC
+SELECT id, user_id, campaign_id, question_id, answer_text, answer_value, answer_json, answer_type, created_at, updated_at, acceptable_answers, importance FROM survey_responses WHERE user_id = $1 ORDER BY created_at ASC
 `
 
 func (q *Queries) ListSurveyResponsesByUser(ctx context.Context, userID uuid.UUID) ([]SurveyResponse, error) {
@@ -152,6 +164,8 @@ func (q *Queries) ListSurveyResponsesByUser(ctx context.Context, userID uuid.UUI
 			&i.AnswerType,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AcceptableAnswers,
+			&i.Importance,
 		); err != nil {
 			return nil, err
 		}
@@ -60,10 +60,12 @@ INSERT INTO users (
     preferences,
     last_login,
     is_active,
-    survey_completed
+    survey_completed,
+    genders,
+    seeking_genders
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
-) RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
+) RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age
 `
 
 type CreateUserParams struct {
@@ -90,6 +92,8 @@ type CreateUserParams struct {
 	LastLogin         pgtype.Timestamptz `json:"last_login"`
 	IsActive          bool               `json:"is_active"`
 	SurveyCompleted   bool               `json:"survey_completed"`
+	Genders           []string           `json:"genders"`
+	SeekingGenders    []string           `json:"seeking_genders"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -117,6 +121,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.LastLogin,
 		arg.IsActive,
 		arg.SurveyCompleted,
+		arg.Genders,
+		arg.SeekingGenders,
 	)
 	var i User
 	err := row.Scan(
@@ -146,6 +152,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
@@ -160,7 +170,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed FROM users WHERE email = $1 LIMIT 1
+SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -193,12 +203,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
 
 const getUserByGoogleID = `-- name: GetUserByGoogleID :one
-SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed FROM users WHERE google_id = $1 LIMIT 1
+SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age FROM users WHERE google_id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID pgtype.Text) (User, error) {
@@ -231,12 +245,16 @@ func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID pgtype.Text) (
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed FROM users WHERE id = $1 LIMIT 1
+SELECT id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age FROM users WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -269,26 +287,36 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
 
 const listEligibleUsers = `-- name: ListEligibleUsers :many
-SELECT id, email, first_name, last_name, program, year_level, gender, seeking_gender
+SELECT id, email, first_name, last_name, program, year_level, gender, seeking_gender, genders, seeking_genders, date_of_birth, min_partner_age, max_partner_age, bio
 FROM users
 WHERE survey_completed = TRUE AND is_active = TRUE
 ORDER BY first_name ASC
 `
 
 type ListEligibleUsersRow struct {
-	ID            uuid.UUID   `json:"id"`
-	Email         string      `json:"email"`
-	FirstName     string      `json:"first_name"`
-	LastName      string      `json:"last_name"`
-	Program       pgtype.Text `json:"program"`
-	YearLevel     pgtype.Int4 `json:"year_level"`
-	Gender        pgtype.Text `json:"gender"`
-	SeekingGender pgtype.Text `json:"seeking_gender"`
+	ID             uuid.UUID   `json:"id"`
+	Email          string      `json:"email"`
+	FirstName      string      `json:"first_name"`
+	LastName       string      `json:"last_name"`
+	Program        pgtype.Text `json:"program"`
+	YearLevel      pgtype.Int4 `json:"year_level"`
+	Gender         pgtype.Text `json:"gender"`
+	SeekingGender  pgtype.Text `json:"seeking_gender"`
+	Genders        []string    `json:"genders"`
+	SeekingGenders []string    `json:"seeking_genders"`
+	DateOfBirth    pgtype.Date `json:"date_of_birth"`
+	MinPartnerAge  pgtype.Int4 `json:"min_partner_age"`
+	MaxPartnerAge  pgtype.Int4 `json:"max_partner_age"`
+	Bio            pgtype.Text `json:"bio"`
 }
 
 func (q *Queries) ListEligibleUsers(ctx context.Context) ([]ListEligibleUsersRow, error) {
@@ -309,6 +337,12 @@ func (q *Queries) ListEligibleUsers(ctx context.Context) ([]ListEligibleUsersRow
 			&i.YearLevel,
 			&i.Gender,
 			&i.SeekingGender,
+			&i.Genders,
+			&i.SeekingGenders,
+			&i.DateOfBirth,
+			&i.MinPartnerAge,
+			&i.MaxPartnerAge,
+			&i.Bio,
 		); err != nil {
 			return nil, err
 		}
@@ -463,7 +497,7 @@ UPDATE users SET
     is_active = COALESCE($8, is_active),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed
+RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age
 `
 
 type UpdateUserAdminParams struct {
@@ -516,6 +550,10 @@ func (q *Queries) UpdateUserAdmin(ctx context.Context, arg UpdateUserAdminParams
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
@@ -597,9 +635,13 @@ UPDATE users SET
     contact_preference = COALESCE($15, contact_preference),
     profile_visibility = COALESCE(NULLIF($16, ''), profile_visibility),
     preferences = COALESCE($17, preferences),
+    genders = COALESCE($18, genders),
+    seeking_genders = COALESCE($19, seeking_genders),
+    min_partner_age = COALESCE($20, min_partner_age),
+    max_partner_age = COALESCE($21, max_partner_age),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed
+RETURNING id, email, google_id, username, student_id, first_name, last_name, program, year_level, gender, seeking_gender, date_of_birth, profile_photo_url, bio, instagram_handle, facebook_profile, social_media_name, phone_number, contact_preference, profile_visibility, preferences, created_at, updated_at, last_login, is_active, survey_completed, genders, seeking_genders, min_partner_age, max_partner_age
 `
 
 type UpdateUserProfileParams struct {
@@ -620,6 +662,10 @@ type UpdateUserProfileParams struct {
 	ContactPreference pgtype.Text `json:"contact_preference"`
 	Column16          interface{} `json:"column_16"`
 	Preferences       []byte      `json:"preferences"`
+	Genders           []string    `json:"genders"`
+	SeekingGenders    []string    `json:"seeking_genders"`
+	MinPartnerAge     pgtype.Int4 `json:"min_partner_age"`
+	MaxPartnerAge     pgtype.Int4 `json:"max_partner_age"`
 }
 
 func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
@@ -641,6 +687,10 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		arg.ContactPreference,
 		arg.Column16,
 		arg.Preferences,
+		arg.Genders,
+		arg.SeekingGenders,
+		arg.MinPartnerAge,
+		arg.MaxPartnerAge,
 	)
 	var i User
 	err := row.Scan(
@@ -670,6 +720,10 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		&i.LastLogin,
 		&i.IsActive,
 		&i.SurveyCompleted,
+		&i.Genders,
+		&i.SeekingGenders,
+		&i.MinPartnerAge,
+		&i.MaxPartnerAge,
 	)
 	return i, err
 }
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// agePreference is a user's age and the partner ages they accept. A zero
+// bound means the user did not set one.
+type agePreference struct {
+	age   int
+	known bool
+	min   int
+	max   int
+}
+
+func newAgePreference(dateOfBirth pgtype.Date, minPartner pgtype.Int4, maxPartner pgtype.Int4, day time.Time) agePreference {
+	pref := agePreference{min: int(minPartner.Int32), max: int(maxPartner.Int32)}
+	pref.age, pref.known = ageOn(dateOfBirth, day)
+	return pref
+}
+
+// accepts reports whether other falls inside the partner age range. Someone
+// without a date of birth never satisfies a range.
+func (p agePreference) accepts(other agePreference) bool {
+	if p.min > 0 && (!other.known || other.age < p.min) {
+		return false
+	}
+	if p.max > 0 && (!other.known || other.age > p.max) {
+		return false
+	}
+	return true
+}
+
+// agesCompatible applies both users' ranges and the campaign's maximum age
+// gap. The gap is only enforced when both ages are known.
+func agesCompatible(a agePreference, b agePreference, maxGap int) bool {
+	if !a.accepts(b) || !b.accepts(a) {
+		return false
+	}
+	return maxGap <= 0 || !a.known || !b.known || absInt(a.age-b.age) <= maxGap
+}
+
+// ageOn returns the age in whole years on the given day.
+func ageOn(dateOfBirth pgtype.Date, day time.Time) (int, bool) {
+	if !dateOfBirth.Valid {
+		return 0, false
+	}
+	birth := dateOfBirth.Time
+	age := day.Year() - birth.Year()
+	if day.Month() < birth.Month() || day.Month() == birth.Month() && day.Day() < birth.Day() {
+		age--
+	}
+	return age, true
+}
+
+// excludeUnderage drops users younger than minAge, and users without a date
+// of birth, who cannot be shown to be old enough.
+func excludeUnderage(users []repository.ListEligibleUsersRow, minAge int, day time.Time) []repository.ListEligibleUsersRow {
+	if minAge <= 0 {
+		return users
+	}
+	kept := make([]repository.ListEligibleUsersRow, 0, len(users))
+	for _, user := range users {
+		if age, ok := ageOn(user.DateOfBirth, day); !ok || age < minAge {
+			continue
+		}
+		kept = append(kept, user)
+	}
+	return kept
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func birthday(year int, month time.Month, day int) pgtype.Date {
+	return pgtype.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Valid: true}
+}
+
+func TestAgeOn(t *testing.T) {
+	day := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		dob  pgtype.Date
+		want int
+	}{
+		{birthday(2006, time.March, 10), 20},
+		{birthday(2006, time.March, 11), 19},
+		{birthday(2005, time.December, 31), 20},
+	}
+	for _, tc := range cases {
+		if got, ok := ageOn(tc.dob, day); !ok || got != tc.want {
+			t.Fatalf("age for %v: expected %d, got %d", tc.dob.Time, tc.want, got)
+		}
+	}
+	if _, ok := ageOn(pgtype.Date{}, day); ok {
+		t.Fatalf("expected unknown age without a date of birth")
+	}
+}
+
+func TestAgesCompatible(t *testing.T) {
+	twenty := agePreference{age: 20, known: true}
+	twentySix := agePreference{age: 26, known: true}
+	unknown := agePreference{}
+
+	if !agesCompatible(twenty, twentySix, 0) {
+		t.Fatalf("expected no filter without preferences")
+	}
+	if agesCompatible(twenty, twentySix, 5) || !agesCompatible(twenty, twentySix, 6) {
+		t.Fatalf("expected the maximum age gap to apply")
+	}
+	if !agesCompatible(twenty, unknown, 1) {
+		t.Fatalf("expected the age gap to be skipped for unknown ages")
+	}
+
+	picky := agePreference{age: 26, known: true, min: 22, max: 30}
+	if agesCompatible(picky, twenty, 0) || agesCompatible(twenty, picky, 0) {
+		t.Fatalf("expected the partner range to apply in both directions")
+	}
+	if agesCompatible(picky, unknown, 0) {
+		t.Fatalf("expected an unknown age to fail a partner range")
+	}
+	if !agesCompatible(picky, twentySix, 0) {
+		t.Fatalf("expected an age inside the range to pass")
+	}
+}
+
+func TestExcludeUnderage(t *testing.T) {
+	day := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	adult := repository.ListEligibleUsersRow{ID: uuid.New(), DateOfBirth: birthday(2008, time.March, 10)}
+	minor := repository.ListEligibleUsersRow{ID: uuid.New(), DateOfBirth: birthday(2008, time.March, 11)}
+	unknown := repository.ListEligibleUsersRow{ID: uuid.New()}
+
+	kept := excludeUnderage([]repository.ListEligibleUsersRow{adult, minor, unknown}, 18, day)
+	if len(kept) != 1 || kept[0].ID != adult.ID {
+		t.Fatalf("expected the minor and the user without a birthday to be excluded, got %v", kept)
+	}
+}
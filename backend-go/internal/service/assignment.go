@@ -0,0 +1,427 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxImprovementPasses = 10
+	improvementEpsilon   = 1e-9
+)
+
+// assignPairs picks the pairs that become matches. Pairs are sorted by score
+// (highest first) in place; the returned slice keeps that order. Forced pairs
+// are always selected and count towards their users' caps. Whatever the
+// strategy, users below the minimum are then topped up where their
+// candidates allow it (which can break the stability of a stable matching),
+// the total is cut down to the campaign's budget sparing the quotas, and
+// same-program and same-year shares are capped.
+func assignPairs(cfg MatchingConfig, pairs []scoredPair) []scoredPair {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Score.Score > pairs[j].Score.Score
+	})
+
+	var a *allocation
+	switch cfg.Strategy {
+	case StrategyBMatching:
+		a = assignBMatching(pairs, cfg.MinMatchesPerUser, cfg.MaxMatchesPerUser)
+	case StrategyStable:
+		a = assignStable(pairs, cfg.MaxMatchesPerUser)
+	default:
+		a = assignGreedy(pairs, cfg.MaxMatchesPerUser)
+	}
+	if cfg.MinMatchesPerUser > 0 && cfg.Strategy != StrategyBMatching {
+		a.fillMinimumQuota(cfg.MinMatchesPerUser, cfg.MaxMatchesPerUser)
+	}
+	a.capTotal(cfg.MaxTotalMatches, cfg.MinMatchesPerUser)
+	a.capGroupShares(cfg.groupShares(), cfg.MinMatchesPerUser, cfg.MaxMatchesPerUser, cfg.MaxTotalMatches)
+	return a.selected()
+}
+
+// assignGreedy takes pairs best-first while both users are under the cap.
+func assignGreedy(pairs []scoredPair, maxPerUser int) *allocation {
+	a := newAllocation(pairs)
+	for i := range pairs {
+		if !a.chosen[i] && a.hasCapacity(i, maxPerUser) {
+			a.add(i)
+		}
+	}
+	return a
+}
+
+// assignBMatching approximates a maximum-weight b-matching where every user
+// has between minPerUser and maxPerUser matches. Minimum quotas are served
+// first (most constrained users first), the remaining capacity is filled
+// greedily, and then local moves are applied while they increase the total
+// score without pushing anyone below their minimum: adding a pair in place of
+// the weakest match of each full endpoint, and replacing one match with two
+// (an augmenting path of length three).
+func assignBMatching(pairs []scoredPair, minPerUser, maxPerUser int) *allocation {
+	a := newAllocation(pairs)
+	if minPerUser > 0 {
+		a.fillMinimumQuota(minPerUser, maxPerUser)
+	}
+	for i := range pairs {
+		if !a.chosen[i] && a.hasCapacity(i, maxPerUser) {
+			a.add(i)
+		}
+	}
+	a.improve(minPerUser, maxPerUser)
+	return a
+}
+
+// assignStable runs capacitated deferred acceptance (Gale-Shapley). The pool
+// is split into proposers and receivers by two-colouring the compatibility
+// graph; pairs inside the same side (odd cycles, e.g. users open to any
+// gender) cannot take part in deferred acceptance and are used afterwards to
+// fill leftover capacity.
+func assignStable(pairs []scoredPair, maxPerUser int) *allocation {
+	a := newAllocation(pairs)
+	proposer := bipartition(pairs)
+
+	lists := map[uuid.UUID][]int{}
+	order := []uuid.UUID{}
+	for i, pair := range pairs {
+		u, v := pair.User1.ID, pair.User2.ID
+		if proposer[u] == proposer[v] || a.chosen[i] {
+			continue
+		}
+		p := u
+		if !proposer[u] {
+			p = v
+		}
+		if _, ok := lists[p]; !ok {
+			order = append(order, p)
+		}
+		lists[p] = append(lists[p], i)
+	}
+
+	next := map[uuid.UUID]int{}
+	queue := append([]uuid.UUID{}, order...)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for a.degree(p) < maxPerUser && next[p] < len(lists[p]) {
+			i := lists[p][next[p]]
+			next[p]++
+			r := a.other(i, p)
+			if a.degree(r) < maxPerUser {
+				a.add(i)
+				continue
+			}
+			held := a.weakest(r)
+			if held < 0 || a.pairs[held].Score.Score >= a.pairs[i].Score.Score {
+				continue
+			}
+			a.remove(held)
+			a.add(i)
+			queue = append(queue, a.other(held, r))
+		}
+	}
+
+	for i := range pairs {
+		if !a.chosen[i] && a.hasCapacity(i, maxPerUser) {
+			a.add(i)
+		}
+	}
+	return a
+}
+
+// bipartition two-colours the graph formed by the pairs and reports which
+// users propose. Edges that close an odd cycle end up with both endpoints on
+// the same side.
+func bipartition(pairs []scoredPair) map[uuid.UUID]bool {
+	adjacency := map[uuid.UUID][]uuid.UUID{}
+	order := []uuid.UUID{}
+	for _, pair := range pairs {
+		u, v := pair.User1.ID, pair.User2.ID
+		if _, ok := adjacency[u]; !ok {
+			order = append(order, u)
+		}
+		if _, ok := adjacency[v]; !ok {
+			order = append(order, v)
+		}
+		adjacency[u] = append(adjacency[u], v)
+		adjacency[v] = append(adjacency[v], u)
+	}
+
+	proposer := map[uuid.UUID]bool{}
+	visited := map[uuid.UUID]bool{}
+	for _, start := range order {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		proposer[start] = true
+		queue := []uuid.UUID{start}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, v := range adjacency[u] {
+				if visited[v] {
+					continue
+				}
+				visited[v] = true
+				proposer[v] = !proposer[u]
+				queue = append(queue, v)
+			}
+		}
+	}
+	return proposer
+}
+
+type allocation struct {
+	pairs      []scoredPair
+	chosen     []bool
+	incident   map[uuid.UUID][]int
+	candidates map[uuid.UUID][]int
+	users      []uuid.UUID
+}
+
+func newAllocation(pairs []scoredPair) *allocation {
+	a := &allocation{
+		pairs:      pairs,
+		chosen:     make([]bool, len(pairs)),
+		incident:   map[uuid.UUID][]int{},
+		candidates: map[uuid.UUID][]int{},
+	}
+	for i, pair := range pairs {
+		for _, user := range []uuid.UUID{pair.User1.ID, pair.User2.ID} {
+			if _, ok := a.candidates[user]; !ok {
+				a.users = append(a.users, user)
+			}
+			a.candidates[user] = append(a.candidates[user], i)
+		}
+	}
+	for i, pair := range pairs {
+		if pair.Score.Forced {
+			a.add(i)
+		}
+	}
+	return a
+}
+
+func (a *allocation) degree(user uuid.UUID) int {
+	return len(a.incident[user])
+}
+
+func (a *allocation) other(i int, user uuid.UUID) uuid.UUID {
+	if a.pairs[i].User1.ID == user {
+		return a.pairs[i].User2.ID
+	}
+	return a.pairs[i].User1.ID
+}
+
+func (a *allocation) hasCapacity(i int, maxPerUser int) bool {
+	return a.degree(a.pairs[i].User1.ID) < maxPerUser && a.degree(a.pairs[i].User2.ID) < maxPerUser
+}
+
+func (a *allocation) add(i int) {
+	a.chosen[i] = true
+	a.incident[a.pairs[i].User1.ID] = append(a.incident[a.pairs[i].User1.ID], i)
+	a.incident[a.pairs[i].User2.ID] = append(a.incident[a.pairs[i].User2.ID], i)
+}
+
+func (a *allocation) remove(i int) {
+	a.chosen[i] = false
+	for _, user := range []uuid.UUID{a.pairs[i].User1.ID, a.pairs[i].User2.ID} {
+		edges := a.incident[user]
+		for k, edge := range edges {
+			if edge == i {
+				a.incident[user] = append(edges[:k], edges[k+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// weakest returns the lowest scoring chosen pair for the user that is not
+// forced, or -1.
+func (a *allocation) weakest(user uuid.UUID) int {
+	result := -1
+	for _, i := range a.incident[user] {
+		if a.pairs[i].Score.Forced {
+			continue
+		}
+		if result < 0 || a.pairs[i].Score.Score < a.pairs[result].Score.Score {
+			result = i
+		}
+	}
+	return result
+}
+
+// releasable returns the lowest scoring chosen pair of the user that is not
+// forced and can be dropped without taking the partner below minPerUser, or
+// -1. pending counts drops already planned for the same move.
+func (a *allocation) releasable(user uuid.UUID, minPerUser int, pending map[uuid.UUID]int) int {
+	result := -1
+	for _, i := range a.incident[user] {
+		partner := a.other(i, user)
+		if a.pairs[i].Score.Forced {
+			continue
+		}
+		if minPerUser > 0 && a.degree(partner)-pending[partner] <= minPerUser {
+			continue
+		}
+		if result < 0 || a.pairs[i].Score.Score < a.pairs[result].Score.Score {
+			result = i
+		}
+	}
+	return result
+}
+
+func (a *allocation) fillMinimumQuota(minPerUser, maxPerUser int) {
+	users := append([]uuid.UUID{}, a.users...)
+	sort.SliceStable(users, func(i, j int) bool {
+		return len(a.candidates[users[i]]) < len(a.candidates[users[j]])
+	})
+
+	for round := 1; round <= minPerUser; round++ {
+		for _, user := range users {
+			if a.degree(user) >= round {
+				continue
+			}
+			for _, i := range a.candidates[user] {
+				if a.chosen[i] {
+					continue
+				}
+				partner := a.other(i, user)
+				if a.degree(partner) < maxPerUser {
+					a.add(i)
+					break
+				}
+				if drop := a.releasable(partner, minPerUser, nil); drop >= 0 {
+					a.remove(drop)
+					a.add(i)
+					break
+				}
+			}
+		}
+	}
+}
+
+// capTotal drops the weakest matches until at most maxTotal are left. Matches
+// whose users both stay at or above minPerUser go first; the others only
+// when that is not enough. Forced pairs are never dropped, so they can keep
+// the total above maxTotal. A zero maxTotal leaves the total alone.
+func (a *allocation) capTotal(maxTotal, minPerUser int) {
+	total := len(a.selected())
+	for spareQuota := true; maxTotal > 0 && total > maxTotal; spareQuota = false {
+		// Pairs are sorted best first, so walking backwards visits the
+		// weakest matches first.
+		for i := len(a.pairs) - 1; i >= 0 && total > maxTotal; i-- {
+			if !a.chosen[i] || a.pairs[i].Score.Forced {
+				continue
+			}
+			if spareQuota && (a.degree(a.pairs[i].User1.ID) <= minPerUser || a.degree(a.pairs[i].User2.ID) <= minPerUser) {
+				continue
+			}
+			a.remove(i)
+			total--
+		}
+		if !spareQuota {
+			return
+		}
+	}
+}
+
+func (a *allocation) improve(minPerUser, maxPerUser int) {
+	for pass := 0; pass < maxImprovementPasses; pass++ {
+		improved := false
+		for i := range a.pairs {
+			if a.chosen[i] {
+				if !a.pairs[i].Score.Forced && a.trySplit(i, maxPerUser) {
+					improved = true
+				}
+				continue
+			}
+			if a.tryInsert(i, minPerUser, maxPerUser) {
+				improved = true
+			}
+		}
+		if !improved {
+			return
+		}
+	}
+}
+
+// tryInsert adds pair i, dropping the weakest releasable match of any
+// endpoint that is already full, when that raises the total score.
+func (a *allocation) tryInsert(i int, minPerUser, maxPerUser int) bool {
+	pair := a.pairs[i]
+	gain := pair.Score.Score
+	pending := map[uuid.UUID]int{}
+	drops := []int{}
+	for _, user := range []uuid.UUID{pair.User1.ID, pair.User2.ID} {
+		if a.degree(user) < maxPerUser {
+			continue
+		}
+		drop := a.releasable(user, minPerUser, pending)
+		if drop < 0 {
+			return false
+		}
+		pending[a.other(drop, user)]++
+		drops = append(drops, drop)
+		gain -= a.pairs[drop].Score.Score
+	}
+	if gain <= improvementEpsilon {
+		return false
+	}
+	for _, drop := range drops {
+		a.remove(drop)
+	}
+	a.add(i)
+	return true
+}
+
+// trySplit replaces chosen pair i with the best open pair of each endpoint
+// when the two together outscore it. Both endpoints keep their match count.
+func (a *allocation) trySplit(i int, maxPerUser int) bool {
+	u, v := a.pairs[i].User1.ID, a.pairs[i].User2.ID
+	first := a.bestOpen(u, v, uuid.Nil, maxPerUser)
+	if first < 0 {
+		return false
+	}
+	second := a.bestOpen(v, u, a.other(first, u), maxPerUser)
+	if second < 0 {
+		return false
+	}
+	gain := a.pairs[first].Score.Score + a.pairs[second].Score.Score - a.pairs[i].Score.Score
+	if gain <= improvementEpsilon {
+		return false
+	}
+	a.remove(i)
+	a.add(first)
+	a.add(second)
+	return true
+}
+
+// bestOpen returns the highest scoring unchosen pair of user whose partner
+// has spare capacity, skipping the given partners, or -1.
+func (a *allocation) bestOpen(user uuid.UUID, skip uuid.UUID, skipAlso uuid.UUID, maxPerUser int) int {
+	for _, i := range a.candidates[user] {
+		if a.chosen[i] {
+			continue
+		}
+		partner := a.other(i, user)
+		if partner == skip || partner == skipAlso {
+			continue
+		}
+		if a.degree(partner) < maxPerUser {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *allocation) selected() []scoredPair {
+	result := make([]scoredPair, 0, len(a.pairs)/2)
+	for i, pair := range a.pairs {
+		if a.chosen[i] {
+			result = append(result, pair)
+		}
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func testUsers(n int) []*repository.ListEligibleUsersRow {
+	users := make([]*repository.ListEligibleUsersRow, n)
+	for i := range users {
+		users[i] = &repository.ListEligibleUsersRow{ID: uuid.New()}
+	}
+	return users
+}
+
+func testPair(u1, u2 *repository.ListEligibleUsersRow, score float64) scoredPair {
+	return scoredPair{User1: u1, User2: u2, Score: matchScore{Score: score}}
+}
+
+func degrees(pairs []scoredPair) map[uuid.UUID]int {
+	counts := map[uuid.UUID]int{}
+	for _, pair := range pairs {
+		counts[pair.User1.ID]++
+		counts[pair.User2.ID]++
+	}
+	return counts
+}
+
+func totalScore(pairs []scoredPair) float64 {
+	total := 0.0
+	for _, pair := range pairs {
+		total += pair.Score.Score
+	}
+	return total
+}
+
+func TestBMatchingBeatsGreedyOnPath(t *testing.T) {
+	// a-b is the single best pair, but taking it blocks a-c and b-d which
+	// together are worth more when each user can hold one match.
+	u := testUsers(4)
+	a, b, c, d := u[0], u[1], u[2], u[3]
+	pairs := []scoredPair{
+		testPair(a, b, 90),
+		testPair(a, c, 80),
+		testPair(b, d, 80),
+	}
+
+	greedy := assignPairs(MatchingConfig{Strategy: StrategyGreedy, MaxMatchesPerUser: 1}, append([]scoredPair{}, pairs...))
+	optimal := assignPairs(MatchingConfig{Strategy: StrategyBMatching, MaxMatchesPerUser: 1}, append([]scoredPair{}, pairs...))
+
+	if totalScore(greedy) != 90 {
+		t.Fatalf("expected greedy total 90, got %v", totalScore(greedy))
+	}
+	if totalScore(optimal) != 160 {
+		t.Fatalf("expected b-matching total 160, got %v", totalScore(optimal))
+	}
+}
+
+func TestBMatchingHonorsMinimumQuota(t *testing.T) {
+	// a, b and c form a high scoring triangle; with a cap of two greedy fills
+	// a before d is considered and leaves d without a match.
+	u := testUsers(4)
+	a, b, c, d := u[0], u[1], u[2], u[3]
+	pairs := []scoredPair{
+		testPair(a, b, 95),
+		testPair(a, c, 94),
+		testPair(b, c, 90),
+		testPair(a, d, 60),
+	}
+
+	greedy := degrees(assignPairs(MatchingConfig{Strategy: StrategyGreedy, MaxMatchesPerUser: 2}, append([]scoredPair{}, pairs...)))
+	if greedy[d.ID] != 0 {
+		t.Fatalf("expected greedy to leave d unmatched, got %v", greedy)
+	}
+
+	cfg := MatchingConfig{Strategy: StrategyBMatching, MinMatchesPerUser: 1, MaxMatchesPerUser: 2}
+	result := degrees(assignPairs(cfg, pairs))
+	for _, user := range u {
+		if result[user.ID] < 1 || result[user.ID] > 2 {
+			t.Fatalf("expected between one and two matches per user, got %v", result)
+		}
+	}
+}
+
+func TestStableMatchingHasNoBlockingPair(t *testing.T) {
+	u := testUsers(6)
+	m1, m2, m3, f1, f2, f3 := u[0], u[1], u[2], u[3], u[4], u[5]
+	pairs := []scoredPair{
+		testPair(m1, f1, 90), testPair(m1, f2, 70), testPair(m1, f3, 60),
+		testPair(m2, f1, 85), testPair(m2, f2, 80), testPair(m2, f3, 55),
+		testPair(m3, f1, 75), testPair(m3, f2, 65), testPair(m3, f3, 70),
+	}
+
+	selected := assignPairs(MatchingConfig{Strategy: StrategyStable, MaxMatchesPerUser: 1}, pairs)
+	if len(selected) != 3 {
+		t.Fatalf("expected a perfect matching, got %d pairs", len(selected))
+	}
+
+	best := map[uuid.UUID]float64{}
+	for _, pair := range selected {
+		best[pair.User1.ID] = pair.Score.Score
+		best[pair.User2.ID] = pair.Score.Score
+	}
+	for _, pair := range pairs {
+		if pair.Score.Score > best[pair.User1.ID] && pair.Score.Score > best[pair.User2.ID] {
+			t.Fatalf("blocking pair with score %v", pair.Score.Score)
+		}
+	}
+}
+
+func TestTotalCapSparesMinimumQuota(t *testing.T) {
+	// Greedy takes the a, b, c triangle and the quota fill swaps b-c for
+	// c-d. Cutting the weakest match to meet the budget would leave d
+	// without one again.
+	u := testUsers(4)
+	a, b, c, d := u[0], u[1], u[2], u[3]
+	pairs := []scoredPair{
+		testPair(a, b, 90),
+		testPair(a, c, 85),
+		testPair(b, c, 80),
+		testPair(c, d, 30),
+	}
+
+	cfg := MatchingConfig{Strategy: StrategyGreedy, MinMatchesPerUser: 1, MaxMatchesPerUser: 2, MaxTotalMatches: 2}
+	selected := assignPairs(cfg, pairs)
+	result := degrees(selected)
+	if len(selected) != 2 {
+		t.Fatalf("expected the total to be capped at two, got %d", len(selected))
+	}
+	for _, user := range u {
+		if result[user.ID] != 1 {
+			t.Fatalf("expected the cap to keep everyone's match, got %v", result)
+		}
+	}
+}
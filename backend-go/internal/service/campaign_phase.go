@@ -0,0 +1,77 @@
+package service
+
+import (
+	"time"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// Campaign phases, in the order a campaign goes through them.
+const (
+	PhasePreLaunch       = "pre_launch"
+	PhaseSurveyOpen      = "survey_open"
+	PhaseSurveyClosed    = "survey_closed"
+	PhaseProfileUpdate   = "profile_update"
+	PhaseResultsReleased = "results_released"
+)
+
+var campaignPhases = []string{PhasePreLaunch, PhaseSurveyOpen, PhaseSurveyClosed, PhaseProfileUpdate, PhaseResultsReleased}
+
+// CampaignPhase returns the phase the campaign is in at now.
+func CampaignPhase(campaign repository.Campaign, now time.Time) string {
+	if now.Before(campaign.SurveyOpenDate) {
+		return PhasePreLaunch
+	}
+	if now.Before(campaign.SurveyCloseDate) {
+		return PhaseSurveyOpen
+	}
+	if now.Before(campaign.ProfileUpdateStartDate) {
+		return PhaseSurveyClosed
+	}
+	if now.Before(campaign.ProfileUpdateEndDate) {
+		return PhaseProfileUpdate
+	}
+	return PhaseResultsReleased
+}
+
+// ActionAllowed reports whether users may take the action during phase.
+func ActionAllowed(phase string, action string) bool {
+	permissions := map[string]map[string]bool{
+		"view_landing": {
+			PhasePreLaunch:       true,
+			PhaseSurveyOpen:      true,
+			PhaseSurveyClosed:    true,
+			PhaseProfileUpdate:   true,
+			PhaseResultsReleased: true,
+		},
+		"sign_up": {
+			PhaseSurveyOpen: true,
+		},
+		"take_survey": {
+			PhaseSurveyOpen: true,
+		},
+		"edit_survey": {
+			PhaseSurveyOpen: true,
+		},
+		"submit_crush_list": {
+			PhaseSurveyOpen: true,
+		},
+		"edit_profile": {
+			PhaseSurveyOpen:    true,
+			PhaseProfileUpdate: true,
+		},
+		"view_matches": {
+			PhaseProfileUpdate:   true,
+			PhaseResultsReleased: true,
+		},
+		"send_messages": {
+			PhaseProfileUpdate:   true,
+			PhaseResultsReleased: true,
+		},
+	}
+
+	if phasePermissions, ok := permissions[action]; ok {
+		return phasePermissions[phase]
+	}
+	return false
+}
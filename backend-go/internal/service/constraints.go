@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// Pair constraints are organizer overrides stored per campaign. A
+// must-not-match pair is never scored; a must-match pair is always matched
+// when both users are eligible, bypassing preferences, filters, the minimum
+// score and the per-user cap.
+const (
+	PairMustMatch    = "must_match"
+	PairMustNotMatch = "must_not_match"
+)
+
+func ValidPairConstraint(constraintType string) bool {
+	return constraintType == PairMustMatch || constraintType == PairMustNotMatch
+}
+
+func (s *MatchingService) loadPairConstraints(ctx context.Context, campaignID uuid.UUID, idx *scoringIndex) error {
+	constraints, err := s.store.ListPairConstraintsByCampaign(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	for _, constraint := range constraints {
+		idx.addConstraint(constraint.User1ID, constraint.User2ID, constraint.ConstraintType)
+	}
+	return nil
+}
+
+// loadUserBlocks turns every block into a must-not-match constraint. Blocks
+// are loaded after the organizer's constraints and win over them: a
+// must-match pair one of whose users blocked the other is not matched.
+func (s *MatchingService) loadUserBlocks(ctx context.Context, idx *scoringIndex) error {
+	blocks, err := s.store.ListUserBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		idx.addConstraint(block.BlockerID, block.BlockedID, PairMustNotMatch)
+	}
+	return nil
+}
+
+func (idx *scoringIndex) addConstraint(user1 uuid.UUID, user2 uuid.UUID, constraintType string) {
+	for _, pair := range [][2]uuid.UUID{{user1, user2}, {user2, user1}} {
+		partners, ok := idx.constraints[pair[0]]
+		if !ok {
+			partners = map[uuid.UUID]string{}
+			idx.constraints[pair[0]] = partners
+		}
+		partners[pair[1]] = constraintType
+	}
+	if constraintType == PairMustMatch {
+		idx.forced = append(idx.forced, [2]uuid.UUID{user1, user2})
+	}
+}
+
+// constrained reports whether the pair has a constraint, in which case the
+// regular pair loop skips it.
+func (idx *scoringIndex) constrained(user1 uuid.UUID, user2 uuid.UUID) bool {
+	_, ok := idx.constraints[user1][user2]
+	return ok
+}
+
+// forcedPairs scores the must-match pairs whose users are both eligible.
+// Vetoes do not apply; the pair keeps its real score for tiers and ranks.
+func (idx *scoringIndex) forcedPairs(users []repository.ListEligibleUsersRow) []scoredPair {
+	if len(idx.forced) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*repository.ListEligibleUsersRow, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	var pairs []scoredPair
+	for _, forced := range idx.forced {
+		if idx.constraints[forced[0]][forced[1]] != PairMustMatch {
+			continue
+		}
+		user1, ok1 := byID[forced[0]]
+		user2, ok2 := byID[forced[1]]
+		if !ok1 || !ok2 {
+			continue
+		}
+		score := idx.compatibility(user1, user2, 1)
+		score.Forced = true
+		pairs = append(pairs, scoredPair{User1: user1, User2: user2, Score: score})
+	}
+	return pairs
+}
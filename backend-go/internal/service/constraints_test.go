@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestScorePairsSkipsConstrainedPairs(t *testing.T) {
+	users, idx := syntheticCampaign(10, 1)
+	idx.addConstraint(users[2].ID, users[5].ID, PairMustNotMatch)
+	idx.addConstraint(users[7].ID, users[1].ID, PairMustMatch)
+
+	pairs, err := scorePairs(context.Background(), idx, candidatePool{left: users}, 0, 2, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pairs) != len(users)*(len(users)-1)/2-2 {
+		t.Fatalf("expected both constrained pairs to be skipped, got %d pairs", len(pairs))
+	}
+	for _, pair := range pairs {
+		if idx.constrained(pair.User1.ID, pair.User2.ID) {
+			t.Fatalf("expected constrained pairs to be left out of the pair loop")
+		}
+	}
+
+	forced := idx.forcedPairs(users[:5])
+	if len(forced) != 0 {
+		t.Fatalf("expected no forced pair without both users eligible, got %d", len(forced))
+	}
+	forced = idx.forcedPairs(users)
+	if len(forced) != 1 || !forced[0].Score.Forced || forced[0].User1.ID != users[7].ID {
+		t.Fatalf("expected the must-match pair to be scored, got %+v", forced)
+	}
+}
+
+func TestAssignPairsKeepsForcedPairs(t *testing.T) {
+	for _, strategy := range []string{StrategyGreedy, StrategyBMatching, StrategyStable} {
+		users := testUsers(4)
+		forced := testPair(users[0], users[1], 10)
+		forced.Score.Forced = true
+		pairs := []scoredPair{
+			testPair(users[0], users[2], 90),
+			testPair(users[0], users[3], 80),
+			testPair(users[1], users[2], 85),
+			forced,
+		}
+
+		cfg := DefaultMatchingConfig()
+		cfg.Strategy = strategy
+		cfg.MaxMatchesPerUser = 1
+		selected := assignPairs(cfg, pairs)
+
+		found := false
+		for _, pair := range selected {
+			found = found || pair.Score.Forced
+		}
+		if !found {
+			t.Fatalf("%s: expected the forced pair to be selected, got %d pairs", strategy, len(selected))
+		}
+		if counts := degrees(selected); counts[users[0].ID] != 1 || counts[users[1].ID] != 1 {
+			t.Fatalf("%s: expected forced pairs to use up the cap, got %v", strategy, counts)
+		}
+	}
+}
+
+func TestMatchRunsLeaveOutBlockedPairs(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 4)
+	var users []uuid.UUID
+	for i := 0; i < 4; i++ {
+		user, err := store.GetUserByEmail(ctx, fmt.Sprintf("user%d@example.com", i))
+		if err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+	// A block wins over the organizer's must-match, whichever side placed it.
+	pair := pairKey(users[0], users[1])
+	if _, err := store.CreatePairConstraint(ctx, repository.CreatePairConstraintParams{
+		CampaignID:     campaign.ID,
+		User1ID:        pair[0],
+		User2ID:        pair[1],
+		ConstraintType: PairMustMatch,
+	}); err != nil {
+		t.Fatalf("create constraint: %v", err)
+	}
+	for _, block := range [][2]uuid.UUID{{users[1], users[0]}, {users[2], users[3]}} {
+		if _, err := store.CreateUserBlock(ctx, repository.CreateUserBlockParams{BlockerID: block[0], BlockedID: block[1]}); err != nil {
+			t.Fatalf("create block: %v", err)
+		}
+	}
+
+	run, err := NewMatchingService(store, nil).CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	results, err := store.ListMatchRunResults(ctx, repository.ListMatchRunResultsParams{RunID: run.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list results: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatalf("expected the unblocked pairs to still be matched")
+	}
+	for _, result := range results {
+		matched := map[uuid.UUID]bool{result.User1ID: true, result.User2ID: true}
+		if matched[users[0]] && matched[users[1]] || matched[users[2]] && matched[users[3]] {
+			t.Fatalf("expected blocked pairs to be left out, got %v and %v", result.User1ID, result.User2ID)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+)
+
+// EventRetention is how long the event log keeps events for clients to
+// catch up on.
+const EventRetention = 7 * 24 * time.Hour
+
+// EventWatcher raises the events no request causes, which for now is the
+// active campaign moving into its next phase as its dates pass, and prunes
+// the event log. events should log through a realtime.Recorder: phase
+// changes are keyed, so the recorder lets through only the first of the
+// checks (on any instance) to notice one.
+type EventWatcher struct {
+	store  repository.Querier
+	events realtime.Publisher
+}
+
+func NewEventWatcher(store repository.Querier, events realtime.Publisher) *EventWatcher {
+	return &EventWatcher{store: store, events: events}
+}
+
+// Run checks every interval until ctx is cancelled.
+func (w *EventWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("event watcher: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check announces the phase the active campaign is in at now, unless it
+// already has been, and drops events older than EventRetention.
+func (w *EventWatcher) Check(ctx context.Context, now time.Time) error {
+	campaign, err := w.store.GetActiveCampaign(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		phase := CampaignPhase(campaign, now)
+		event := realtime.NewBroadcast(realtime.EventPhaseChanged, map[string]any{
+			"campaignId": campaign.ID,
+			"phase":      phase,
+		})
+		event.Key = "phase:" + campaign.ID.String() + ":" + phase
+		if err := w.events.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	_, err = w.store.DeleteUserEventsBefore(ctx, now.Add(-EventRetention))
+	return err
+}
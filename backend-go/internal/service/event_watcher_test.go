@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/realtime"
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestEventWatcherAnnouncesEachPhaseOnce(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	now := time.Now()
+	if _, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Spring",
+		SurveyOpenDate:         now.Add(-time.Hour),
+		SurveyCloseDate:        now.Add(time.Hour),
+		ProfileUpdateStartDate: now.Add(2 * time.Hour),
+		ProfileUpdateEndDate:   now.Add(3 * time.Hour),
+		ResultsReleaseDate:     now.Add(3 * time.Hour),
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+	}); err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	hub := realtime.NewHub()
+	watcher := NewEventWatcher(store, realtime.NewRecorder(store, hub))
+	user, err := store.CreateUser(ctx, repository.CreateUserParams{Email: "user@example.com", IsActive: true})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	sub := hub.Subscribe(user.ID)
+
+	// Two instances noticing the same phase announce it once between them.
+	for _, at := range []time.Time{now, now, now.Add(90 * time.Minute)} {
+		if err := watcher.Check(ctx, at); err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+	for _, want := range []string{PhaseSurveyOpen, PhaseSurveyClosed} {
+		event := <-sub.Events()
+		if event.Type != realtime.EventPhaseChanged || !event.Broadcast || !strings.Contains(string(event.Data), `"phase":"`+want+`"`) {
+			t.Fatalf("expected a change to %s, got %+v", want, event)
+		}
+	}
+	select {
+	case event := <-sub.Events():
+		t.Fatalf("expected each phase once, got %+v", event)
+	default:
+	}
+
+	// Pruning keeps the phase entries, which stop the phases being
+	// announced again.
+	if _, err := store.CreateUserEvent(ctx, repository.CreateUserEventParams{
+		UserID:    pgtype.UUID{Bytes: user.ID, Valid: true},
+		EventType: realtime.EventMessageCreated,
+		Payload:   []byte("{}"),
+	}); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	if err := watcher.Check(ctx, now.Add(EventRetention+time.Hour)); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	logged, err := store.ListUserEventsAfter(ctx, repository.ListUserEventsAfterParams{
+		UserID: pgtype.UUID{Bytes: user.ID, Valid: true},
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(logged) != 3 || logged[2].EventType != realtime.EventPhaseChanged {
+		t.Fatalf("expected the phase changes to outlive pruning, got %+v", logged)
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+const explainedQuestions = 3
+
+var ErrMatchWithoutCampaign = errors.New("match does not belong to a campaign")
+
+// MatchExplanation tells one side of a match why the pair scored the way it
+// did. Until either of them reveals the match it only carries category scores
+// and bonuses; the question-by-question comparison is added after the reveal.
+type MatchExplanation struct {
+	AnswersRevealed  bool                  `json:"answersRevealed"`
+	Categories       []CategoryExplanation `json:"categories"`
+	Agreements       []QuestionComparison  `json:"agreements"`
+	Differences      []QuestionComparison  `json:"differences"`
+	SharedSelections []SharedSelection     `json:"sharedSelections"`
+	Bonuses          []MatchBonus          `json:"bonuses"`
+}
+
+type CategoryExplanation struct {
+	Category string  `json:"category"`
+	Weight   float64 `json:"weight"`
+	Score    float64 `json:"score"`
+}
+
+// QuestionComparison is one question both users answered. Similarity is on
+// a 0-100 scale.
+type QuestionComparison struct {
+	QuestionID  uuid.UUID `json:"questionId"`
+	Question    string    `json:"question"`
+	Category    string    `json:"category"`
+	Similarity  float64   `json:"similarity"`
+	YourAnswer  any       `json:"yourAnswer"`
+	TheirAnswer any       `json:"theirAnswer"`
+}
+
+// SharedSelection lists the options both users picked on a multi-select
+// question.
+type SharedSelection struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Question   string    `json:"question"`
+	Items      []string  `json:"items"`
+}
+
+type MatchBonus struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Multiplier  float64 `json:"multiplier,omitempty"`
+	Points      float64 `json:"points,omitempty"`
+}
+
+// ExplainMatch rebuilds the score breakdown of a stored match from the
+// current survey answers and campaign config, as seen by viewer.
+func (s *MatchingService) ExplainMatch(ctx context.Context, match repository.Match, viewer uuid.UUID) (*MatchExplanation, error) {
+	if !match.CampaignID.Valid {
+		return nil, ErrMatchWithoutCampaign
+	}
+	campaignID := uuid.UUID(match.CampaignID.Bytes)
+	cfg, err := s.loadMatchingConfig(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	otherID := match.User1ID
+	if otherID == viewer {
+		otherID = match.User2ID
+	}
+	viewerUser, err := s.store.GetUserByID(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+	otherUser, err := s.store.GetUserByID(ctx, otherID)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := newScoringIndex(cfg)
+	idx.emails[viewerUser.ID] = strings.ToLower(viewerUser.Email)
+	idx.emails[otherUser.ID] = strings.ToLower(otherUser.Email)
+
+	responses, err := s.store.ListSurveyResponsesWithQuestionsForUsers(ctx, repository.ListSurveyResponsesWithQuestionsForUsersParams{
+		UserIds:    []uuid.UUID{viewerUser.ID, otherUser.ID},
+		CampaignID: match.CampaignID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	questions := map[uuid.UUID]string{}
+	for _, row := range responses {
+		questions[row.QuestionID] = row.QuestionText
+		idx.addAnswer(row.UserID, answer{
+			QuestionID: row.QuestionID,
+			Category:   normalizeCategory(row.QuestionCategory),
+			Weight:     numericFloat(row.QuestionWeight),
+			AnswerType: row.AnswerType,
+			Value:      row.AnswerValue,
+			Text:       textValue(row.AnswerText),
+			List:       listFromJSON(row.AnswerJson),
+			Acceptable: acceptableFromJSON(row.AcceptableAnswers),
+			Importance: row.Importance,
+			Scoring:    row.QuestionScoring,
+		})
+	}
+	idx.addBio(viewerUser.ID, viewerUser.Bio)
+	idx.addBio(otherUser.ID, otherUser.Bio)
+	questions[bioQuestionID] = "Bio"
+	if err := s.loadTextVectors(ctx, campaignID, idx, textVectorsCached); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range []uuid.UUID{viewerUser.ID, otherUser.ID} {
+		crushes, err := s.store.ListCrushesForUserCampaign(ctx, repository.ListCrushesForUserCampaignParams{
+			UserID:     userID,
+			CampaignID: campaignID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, crush := range crushes {
+			idx.addCrush(crush.UserID, crush.CrushEmail)
+		}
+	}
+
+	return idx.explain(viewerUser, otherUser, questions, match.IsRevealed), nil
+}
+
+func (idx *scoringIndex) explain(viewer repository.User, other repository.User, questions map[uuid.UUID]string, revealed bool) *MatchExplanation {
+	explanation := &MatchExplanation{
+		AnswersRevealed:  revealed,
+		Categories:       make([]CategoryExplanation, 0, len(scoringCategories)),
+		Agreements:       []QuestionComparison{},
+		Differences:      []QuestionComparison{},
+		SharedSelections: []SharedSelection{},
+		Bonuses:          []MatchBonus{},
+	}
+
+	mine := idx.answers[viewer.ID]
+	theirs := idx.answers[other.ID]
+	var compared []QuestionComparison
+	for category, name := range scoringCategories {
+		explanation.Categories = append(explanation.Categories, CategoryExplanation{
+			Category: name,
+			Weight:   idx.weights[category],
+			Score:    math.Round(categoryScore(mine, theirs, category)),
+		})
+		// Next to the viewer's own answer, a per-question similarity or
+		// agreement would give away what the other person chose.
+		if !revealed || mine == nil || theirs == nil {
+			continue
+		}
+
+		list1 := mine.byCategory[category]
+		list2 := theirs.byCategory[category]
+		for i, j := 0, 0; i < len(list1) && j < len(list2); {
+			if list1[i].question < list2[j].question {
+				i++
+				continue
+			}
+			if list1[i].question > list2[j].question {
+				j++
+				continue
+			}
+			a, b := list1[i], list2[j]
+			compared = append(compared, QuestionComparison{
+				QuestionID:  a.QuestionID,
+				Question:    questions[a.QuestionID],
+				Category:    name,
+				Similarity:  math.Round(similarityScore(&a, &b) * 100),
+				YourAnswer:  displayAnswer(a),
+				TheirAnswer: displayAnswer(b),
+			})
+
+			if a.AnswerType == "multiple_select" {
+				if items := intersectSorted(a.List, b.List); len(items) > 0 {
+					explanation.SharedSelections = append(explanation.SharedSelections, SharedSelection{
+						QuestionID: a.QuestionID,
+						Question:   questions[a.QuestionID],
+						Items:      items,
+					})
+				}
+			}
+			i++
+			j++
+		}
+	}
+
+	sort.SliceStable(compared, func(i, j int) bool {
+		return compared[i].Similarity > compared[j].Similarity
+	})
+	for _, comparison := range compared {
+		if len(explanation.Agreements) == explainedQuestions || comparison.Similarity < 50 {
+			break
+		}
+		explanation.Agreements = append(explanation.Agreements, comparison)
+	}
+	for i := len(compared) - 1; i >= 0; i-- {
+		if len(explanation.Differences) == explainedQuestions || compared[i].Similarity >= 50 {
+			break
+		}
+		explanation.Differences = append(explanation.Differences, compared[i])
+	}
+
+	explanation.Bonuses = append(explanation.Bonuses, idx.visibleCrushBonus(viewer.ID, other.ID)...)
+	if textValue(viewer.Program) != "" && textValue(viewer.Program) == textValue(other.Program) {
+		explanation.Bonuses = append(explanation.Bonuses, MatchBonus{Type: "program", Description: "Same program", Points: 2})
+	}
+	if viewer.YearLevel.Valid && other.YearLevel.Valid && absInt(int(viewer.YearLevel.Int32)-int(other.YearLevel.Int32)) <= 1 {
+		explanation.Bonuses = append(explanation.Bonuses, MatchBonus{Type: "year", Description: "Close year level", Points: 1})
+	}
+	return explanation
+}
+
+// visibleCrushBonus reports the crush multiplier only when it gives nothing
+// away: a mutual crush, or a crush the viewer placed themselves. A one-sided
+// crush from the other person stays hidden.
+func (idx *scoringIndex) visibleCrushBonus(viewer uuid.UUID, other uuid.UUID) []MatchBonus {
+	bonus, isMutual, hasCrush := idx.crushBonus(viewer, other)
+	if !hasCrush {
+		return nil
+	}
+	if isMutual {
+		return []MatchBonus{{Type: "crush", Description: "You both listed each other as a crush", Multiplier: bonus}}
+	}
+	if _, ok := idx.crushes[viewer][idx.emails[other]]; ok {
+		return []MatchBonus{{Type: "crush", Description: "You listed them as a crush", Multiplier: bonus}}
+	}
+	return nil
+}
+
+func displayAnswer(a answer) any {
+	switch a.AnswerType {
+	case "scale", "ranking":
+		if a.Value.Valid {
+			return a.Value.Int32
+		}
+		return nil
+	case "multiple_select":
+		if a.List == nil {
+			return []string{}
+		}
+		return a.List
+	}
+	if a.Text == "" {
+		return nil
+	}
+	return a.Text
+}
+
+// intersectSorted returns the values present in both sorted sets.
+func intersectSorted(set1 []string, set2 []string) []string {
+	var result []string
+	for i, j := 0, 0; i < len(set1) && j < len(set2); {
+		switch {
+		case set1[i] == set2[j]:
+			result = append(result, set1[i])
+			i++
+			j++
+		case set1[i] < set2[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func explainFixture() (*scoringIndex, repository.User, repository.User, map[uuid.UUID]string) {
+	viewer := repository.User{ID: uuid.New(), Email: "viewer@example.com", Program: pgtype.Text{String: "BSCS", Valid: true}, YearLevel: pgtype.Int4{Int32: 2, Valid: true}}
+	other := repository.User{ID: uuid.New(), Email: "other@example.com", Program: pgtype.Text{String: "BSIT", Valid: true}, YearLevel: pgtype.Int4{Int32: 3, Valid: true}}
+
+	idx := newScoringIndex(DefaultMatchingConfig())
+	idx.emails[viewer.ID] = viewer.Email
+	idx.emails[other.ID] = other.Email
+
+	hobbies, pet, outgoing := uuid.New(), uuid.New(), uuid.New()
+	questions := map[uuid.UUID]string{hobbies: "Hobbies", pet: "Favorite pet", outgoing: "How outgoing are you?"}
+	idx.addAnswer(viewer.ID, answer{QuestionID: hobbies, Category: "interests", AnswerType: "multiple_select", List: []string{"chess", "hiking", "music"}})
+	idx.addAnswer(other.ID, answer{QuestionID: hobbies, Category: "interests", AnswerType: "multiple_select", List: []string{"music", "chess", "gaming"}})
+	idx.addAnswer(viewer.ID, answer{QuestionID: pet, Category: "lifestyle", AnswerType: "multiple_choice", Text: "cat"})
+	idx.addAnswer(other.ID, answer{QuestionID: pet, Category: "lifestyle", AnswerType: "multiple_choice", Text: "dog"})
+	idx.addAnswer(viewer.ID, answer{QuestionID: outgoing, Category: "personality", AnswerType: "scale", Value: pgtype.Int4{Int32: 4, Valid: true}})
+	idx.addAnswer(other.ID, answer{QuestionID: outgoing, Category: "personality", AnswerType: "scale", Value: pgtype.Int4{Int32: 4, Valid: true}})
+	return idx, viewer, other, questions
+}
+
+func TestExplainHidesOtherAnswersBeforeReveal(t *testing.T) {
+	idx, viewer, other, questions := explainFixture()
+
+	explanation := idx.explain(viewer, other, questions, false)
+	if len(explanation.Agreements) != 0 || len(explanation.Differences) != 0 || len(explanation.SharedSelections) != 0 {
+		t.Fatalf("expected no per-question comparison before reveal, got %+v", explanation)
+	}
+	if len(explanation.Categories) == 0 {
+		t.Fatalf("expected category scores before reveal")
+	}
+
+	revealed := idx.explain(viewer, other, questions, true)
+	if len(revealed.Agreements) != 2 || revealed.Agreements[0].Question != "How outgoing are you?" || revealed.Agreements[0].Similarity != 100 {
+		t.Fatalf("unexpected agreements: %+v", revealed.Agreements)
+	}
+	if len(revealed.Differences) != 1 || revealed.Differences[0].Question != "Favorite pet" {
+		t.Fatalf("unexpected differences: %+v", revealed.Differences)
+	}
+	if revealed.Differences[0].YourAnswer != "cat" || revealed.Differences[0].TheirAnswer != "dog" {
+		t.Fatalf("expected both answers after reveal, got %+v", revealed.Differences[0])
+	}
+	if len(revealed.SharedSelections) != 1 || len(revealed.SharedSelections[0].Items) != 2 {
+		t.Fatalf("expected chess and music to be shared, got %+v", revealed.SharedSelections)
+	}
+}
+
+func TestExplainCrushBonusPrivacy(t *testing.T) {
+	idx, viewer, other, questions := explainFixture()
+
+	idx.addCrush(other.ID, viewer.Email)
+	if bonuses := idx.explain(viewer, other, questions, false).Bonuses; len(bonuses) != 1 || bonuses[0].Type != "year" {
+		t.Fatalf("expected only the year bonus while the crush is one-sided, got %+v", bonuses)
+	}
+	if bonuses := idx.explain(other, viewer, questions, false).Bonuses; len(bonuses) != 2 || bonuses[0].Type != "crush" {
+		t.Fatalf("expected the crush to be shown to the person who placed it, got %+v", bonuses)
+	}
+
+	idx.addCrush(viewer.ID, other.Email)
+	bonuses := idx.explain(viewer, other, questions, false).Bonuses
+	if len(bonuses) != 2 || bonuses[0].Multiplier != idx.crush.Mutual {
+		t.Fatalf("expected the mutual crush bonus, got %+v", bonuses)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// FairnessReport describes how evenly a run spreads matches over the
+// eligible users and how many matches stay within a program or year level.
+type FairnessReport struct {
+	Gini             float64 `json:"gini"`
+	MinMatches       int     `json:"minMatches"`
+	MaxMatches       int     `json:"maxMatches"`
+	UnmatchedUsers   int     `json:"unmatchedUsers"`
+	BelowMinimum     int     `json:"belowMinimum"`
+	SameProgramShare float64 `json:"sameProgramShare"`
+	SameYearShare    float64 `json:"sameYearShare"`
+}
+
+func fairnessReport(users []repository.ListEligibleUsersRow, matches []plannedMatch, minPerUser int) FairnessReport {
+	report := FairnessReport{}
+	perUser := map[uuid.UUID]int{}
+	sameProgramCount, sameYearCount := 0, 0
+	for _, match := range matches {
+		perUser[match.pair.User1.ID]++
+		perUser[match.pair.User2.ID]++
+		if sameProgram(match.pair) {
+			sameProgramCount++
+		}
+		if sameYear(match.pair) {
+			sameYearCount++
+		}
+	}
+	if len(matches) > 0 {
+		report.SameProgramShare = roundShare(float64(sameProgramCount) / float64(len(matches)))
+		report.SameYearShare = roundShare(float64(sameYearCount) / float64(len(matches)))
+	}
+
+	counts := make([]int, 0, len(users))
+	for i, user := range users {
+		count := perUser[user.ID]
+		counts = append(counts, count)
+		if i == 0 || count < report.MinMatches {
+			report.MinMatches = count
+		}
+		if count > report.MaxMatches {
+			report.MaxMatches = count
+		}
+		if count == 0 {
+			report.UnmatchedUsers++
+		}
+		if count < minPerUser {
+			report.BelowMinimum++
+		}
+	}
+	report.Gini = roundShare(gini(counts))
+	return report
+}
+
+// gini is the Gini coefficient of the counts: 0 when everyone has the same
+// number of matches, approaching 1 when a few users hold all of them.
+func gini(counts []int) float64 {
+	sorted := append([]int{}, counts...)
+	sort.Ints(sorted)
+	sum, weighted := 0.0, 0.0
+	for i, count := range sorted {
+		sum += float64(count)
+		weighted += float64(i+1) * float64(count)
+	}
+	if sum == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+func roundShare(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func sameProgram(pair scoredPair) bool {
+	program := textValue(pair.User1.Program)
+	return program != "" && program == textValue(pair.User2.Program)
+}
+
+func sameYear(pair scoredPair) bool {
+	return pair.User1.YearLevel.Valid && pair.User2.YearLevel.Valid && pair.User1.YearLevel.Int32 == pair.User2.YearLevel.Int32
+}
+
+// groupShare caps the share of all matches whose two users fall in the same
+// group, such as the same program. A configured share of 0 means no cap.
+type groupShare struct {
+	same  func(pair scoredPair) bool
+	share float64
+}
+
+func (c MatchingConfig) groupShares() []groupShare {
+	var shares []groupShare
+	if c.MaxSameProgramShare > 0 && c.MaxSameProgramShare < 1 {
+		shares = append(shares, groupShare{same: sameProgram, share: c.MaxSameProgramShare})
+	}
+	if c.MaxSameYearShare > 0 && c.MaxSameYearShare < 1 {
+		shares = append(shares, groupShare{same: sameYear, share: c.MaxSameYearShare})
+	}
+	return shares
+}
+
+// capGroupShares drops the weakest same-group matches until every share is
+// within its cap, refilling the freed slots of both users with their best
+// open cross-group pair while the total stays within a non-zero maxTotal.
+// Matches whose users would fall below minPerUser are dropped last, and
+// forced pairs are never dropped.
+func (a *allocation) capGroupShares(shares []groupShare, minPerUser, maxPerUser, maxTotal int) {
+	if len(shares) == 0 {
+		return
+	}
+	mixed := func(i int) bool {
+		for _, share := range shares {
+			if share.same(a.pairs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	total := 0
+	counts := make([]int, len(shares))
+	var droppable []int
+	// Pairs are sorted best first, so walking backwards lists the weakest
+	// matches first.
+	for i := len(a.pairs) - 1; i >= 0; i-- {
+		if !a.chosen[i] {
+			continue
+		}
+		total++
+		for k, share := range shares {
+			if share.same(a.pairs[i]) {
+				counts[k]++
+			}
+		}
+		if !a.pairs[i].Score.Forced && !mixed(i) {
+			droppable = append(droppable, i)
+		}
+	}
+
+	for {
+		over := -1
+		for k, share := range shares {
+			if float64(counts[k]) > share.share*float64(total)+improvementEpsilon {
+				over = k
+				break
+			}
+		}
+		if over < 0 {
+			return
+		}
+
+		drop, fallback := -1, -1
+		for _, i := range droppable {
+			if !a.chosen[i] || !shares[over].same(a.pairs[i]) {
+				continue
+			}
+			if fallback < 0 {
+				fallback = i
+			}
+			if a.degree(a.pairs[i].User1.ID) > minPerUser && a.degree(a.pairs[i].User2.ID) > minPerUser {
+				drop = i
+				break
+			}
+		}
+		if drop < 0 {
+			drop = fallback
+		}
+		if drop < 0 {
+			return
+		}
+
+		a.remove(drop)
+		total--
+		for k, share := range shares {
+			if share.same(a.pairs[drop]) {
+				counts[k]--
+			}
+		}
+		for _, user := range []uuid.UUID{a.pairs[drop].User1.ID, a.pairs[drop].User2.ID} {
+			if maxTotal > 0 && total >= maxTotal {
+				break
+			}
+			if i := a.bestOpenWhere(user, maxPerUser, mixed); i >= 0 {
+				a.add(i)
+				total++
+			}
+		}
+	}
+}
+
+// bestOpenWhere returns the highest scoring unchosen pair of user that
+// satisfies keep and whose users both have spare capacity, or -1.
+func (a *allocation) bestOpenWhere(user uuid.UUID, maxPerUser int, keep func(i int) bool) int {
+	if a.degree(user) >= maxPerUser {
+		return -1
+	}
+	for _, i := range a.candidates[user] {
+		if a.chosen[i] || !keep(i) {
+			continue
+		}
+		if a.degree(a.other(i, user)) < maxPerUser {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func TestGini(t *testing.T) {
+	if got := gini([]int{3, 3, 3, 3}); got != 0 {
+		t.Fatalf("expected equal counts to score 0, got %v", got)
+	}
+	if got := gini([]int{0, 0, 0, 4}); math.Abs(got-0.75) > 1e-9 {
+		t.Fatalf("expected 0.75, got %v", got)
+	}
+	if got := gini([]int{0, 0}); got != 0 {
+		t.Fatalf("expected no matches to score 0, got %v", got)
+	}
+}
+
+func TestMinimumQuotaAppliesToEveryStrategy(t *testing.T) {
+	for _, strategy := range []string{StrategyGreedy, StrategyStable} {
+		u := testUsers(4)
+		a, b, c, d := u[0], u[1], u[2], u[3]
+		pairs := []scoredPair{
+			testPair(a, b, 95),
+			testPair(a, c, 94),
+			testPair(b, c, 90),
+			testPair(a, d, 60),
+		}
+		cfg := MatchingConfig{Strategy: strategy, MinMatchesPerUser: 1, MaxMatchesPerUser: 2}
+		if counts := degrees(assignPairs(cfg, pairs)); counts[d.ID] != 1 || counts[a.ID] > 2 {
+			t.Fatalf("%s: expected d to get a match within caps, got %v", strategy, counts)
+		}
+	}
+}
+
+func TestCapGroupSharesSwapsSameProgramMatches(t *testing.T) {
+	program := func(name string) pgtype.Text { return pgtype.Text{String: name, Valid: true} }
+	u := testUsers(4)
+	u[0].Program, u[1].Program = program("BSCS"), program("BSCS")
+	u[2].Program, u[3].Program = program("BSN"), program("BSN")
+	pairs := []scoredPair{
+		testPair(u[0], u[1], 95),
+		testPair(u[2], u[3], 90),
+		testPair(u[0], u[2], 70),
+		testPair(u[1], u[3], 65),
+	}
+
+	cfg := MatchingConfig{Strategy: StrategyGreedy, MaxMatchesPerUser: 1}
+	if selected := assignPairs(cfg, append([]scoredPair{}, pairs...)); len(selected) != 2 || !sameProgram(selected[0]) || !sameProgram(selected[1]) {
+		t.Fatalf("expected greedy to pick both same-program pairs without a cap")
+	}
+
+	cfg.MaxSameProgramShare = 0.5
+	selected := assignPairs(cfg, append([]scoredPair{}, pairs...))
+	same := 0
+	for _, pair := range selected {
+		if sameProgram(pair) {
+			same++
+		}
+	}
+	// Keeping either same-program pair leaves the other two users without a
+	// cross-program partner, so only the cross-program pairs fit the cap.
+	if len(selected) != 2 || same != 0 {
+		t.Fatalf("expected both users to be rematched across programs, got %d of %d", same, len(selected))
+	}
+}
+
+func TestFairnessReport(t *testing.T) {
+	users := []repository.ListEligibleUsersRow{{}, {}, {}, {}}
+	for i := range users {
+		users[i].ID = testUsers(1)[0].ID
+		users[i].YearLevel = pgtype.Int4{Int32: int32(1 + i%2), Valid: true}
+	}
+	matches := []plannedMatch{
+		{pair: testPair(&users[0], &users[1], 80)},
+		{pair: testPair(&users[0], &users[2], 70)},
+	}
+
+	report := fairnessReport(users, matches, 1)
+	if report.MinMatches != 0 || report.MaxMatches != 2 || report.UnmatchedUsers != 1 || report.BelowMinimum != 1 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	if report.SameYearShare != 0.5 || report.SameProgramShare != 0 {
+		t.Fatalf("unexpected shares: %+v", report)
+	}
+	if report.Gini != 0.375 {
+		t.Fatalf("expected a Gini of 0.375, got %v", report.Gini)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Genders are stored as sets of these canonical values. An empty seeking set
+// means the user has not narrowed who they want to meet. GenderUnrecognized
+// marks a legacy preference the gender sets migration could not read; nobody
+// has it, so that user matches nobody until they update their profile.
+const (
+	GenderMan          = "man"
+	GenderWoman        = "woman"
+	GenderNonbinary    = "nonbinary"
+	GenderUnrecognized = "unrecognized"
+)
+
+var allGenders = []string{GenderMan, GenderNonbinary, GenderWoman}
+
+var genderAliases = map[string]string{
+	"m": GenderMan, "male": GenderMan, "males": GenderMan, "man": GenderMan, "men": GenderMan,
+	"boy": GenderMan, "boys": GenderMan, "guy": GenderMan, "guys": GenderMan,
+	"transman": GenderMan, "transmen": GenderMan,
+
+	"f": GenderWoman, "female": GenderWoman, "females": GenderWoman, "woman": GenderWoman, "women": GenderWoman,
+	"girl": GenderWoman, "girls": GenderWoman, "lady": GenderWoman, "ladies": GenderWoman,
+	"transwoman": GenderWoman, "transwomen": GenderWoman,
+
+	"nonbinary": GenderNonbinary, "nb": GenderNonbinary, "enby": GenderNonbinary,
+	"genderqueer": GenderNonbinary, "genderfluid": GenderNonbinary, "agender": GenderNonbinary,
+}
+
+// seekingAliases only make sense for who someone is looking for.
+var seekingAliases = map[string][]string{
+	"any":      allGenders,
+	"anyone":   allGenders,
+	"all":      allGenders,
+	"everyone": allGenders,
+	"both":     {GenderMan, GenderWoman},
+}
+
+var genderSeparators = regexp.MustCompile(`[,/;&|+]|\band\b|\bor\b`)
+var genderFiller = regexp.MustCompile(`[\s_-]+`)
+
+// NormalizeGenders turns a free-text gender such as "Non-binary" or
+// "female / non binary" into a sorted set of canonical values.
+func NormalizeGenders(value string) ([]string, error) {
+	return normalizeGenderSet(value, false)
+}
+
+// NormalizeSeeking is NormalizeGenders for preferences, which also accepts
+// "any" and "both".
+func NormalizeSeeking(value string) ([]string, error) {
+	return normalizeGenderSet(value, true)
+}
+
+func normalizeGenderSet(value string, seeking bool) ([]string, error) {
+	set := map[string]bool{}
+	for _, token := range genderSeparators.Split(strings.ToLower(value), -1) {
+		token = genderFiller.ReplaceAllString(strings.TrimSpace(token), "")
+		if token == "" {
+			continue
+		}
+		if gender, ok := genderAliases[token]; ok {
+			set[gender] = true
+			continue
+		}
+		if expanded, ok := seekingAliases[token]; ok && seeking {
+			for _, gender := range expanded {
+				set[gender] = true
+			}
+			continue
+		}
+		return nil, fmt.Errorf("unrecognized gender %q", token)
+	}
+
+	result := make([]string, 0, len(set))
+	for gender := range set {
+		result = append(result, gender)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// acceptsGender reports whether someone seeking the given genders is open to
+// a person with the given genders. Both sets must be sorted, as stored.
+func acceptsGender(seeking []string, genders []string) bool {
+	return len(seeking) == 0 || sharesAny(seeking, genders)
+}
+
+// GendersCompatible applies the pairing rule in both directions, so the
+// result never depends on which user is passed first.
+func GendersCompatible(genders1 []string, seeking1 []string, genders2 []string, seeking2 []string) bool {
+	return acceptsGender(seeking1, genders2) && acceptsGender(seeking2, genders1)
+}
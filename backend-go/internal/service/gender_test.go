@@ -0,0 +1,121 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func TestNormalizeGenders(t *testing.T) {
+	cases := []struct {
+		value   string
+		seeking bool
+		want    []string
+	}{
+		{"Female", false, []string{GenderWoman}},
+		{"male", false, []string{GenderMan}},
+		{"Non-binary", false, []string{GenderNonbinary}},
+		{"non binary", false, []string{GenderNonbinary}},
+		{"Trans woman", false, []string{GenderWoman}},
+		{"", false, []string{}},
+		{"Any", true, []string{GenderMan, GenderNonbinary, GenderWoman}},
+		{"both", true, []string{GenderMan, GenderWoman}},
+		{"women and non-binary people", true, nil},
+		{"women / nonbinary", true, []string{GenderNonbinary, GenderWoman}},
+		{"Male, female", true, []string{GenderMan, GenderWoman}},
+	}
+	for _, tc := range cases {
+		got, err := normalizeGenderSet(tc.value, tc.seeking)
+		if tc.want == nil {
+			if err == nil {
+				t.Fatalf("expected %q to be rejected, got %v", tc.value, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("normalize %q: expected %v, got %v (%v)", tc.value, tc.want, got, err)
+		}
+	}
+
+	if _, err := NormalizeGenders("any"); err == nil {
+		t.Fatalf("expected \"any\" to be rejected as a gender")
+	}
+}
+
+func TestGendersCompatibleIsSymmetric(t *testing.T) {
+	woman := []string{GenderWoman}
+	man := []string{GenderMan}
+	nonbinary := []string{GenderNonbinary}
+	anyone := []string{GenderMan, GenderNonbinary, GenderWoman}
+
+	cases := []struct {
+		name           string
+		g1, s1, g2, s2 []string
+		compatible     bool
+	}{
+		{"woman seeking men never matches a man seeking men", woman, man, man, man, false},
+		{"straight pair", woman, man, man, woman, true},
+		{"nonbinary seeking anyone and woman seeking nonbinary", nonbinary, anyone, woman, nonbinary, true},
+		{"one-way interest is not enough", woman, nonbinary, nonbinary, man, false},
+		{"unset preference is open to everyone", woman, nil, man, woman, true},
+		{"unrecognized preference matches nobody", woman, []string{GenderUnrecognized}, man, woman, false},
+		{"unknown gender is not sought by anyone specific", nil, nil, man, woman, false},
+	}
+	for _, tc := range cases {
+		forward := GendersCompatible(tc.g1, tc.s1, tc.g2, tc.s2)
+		backward := GendersCompatible(tc.g2, tc.s2, tc.g1, tc.s1)
+		if forward != tc.compatible || backward != tc.compatible {
+			t.Fatalf("%s: expected %v, got %v and %v", tc.name, tc.compatible, forward, backward)
+		}
+	}
+}
+
+func TestCandidatePoolsCoverEveryCompatiblePairOnce(t *testing.T) {
+	profiles := [][2][]string{
+		{{GenderWoman}, {GenderMan}},
+		{{GenderMan}, {GenderWoman}},
+		{{GenderWoman}, {GenderWoman}},
+		{{GenderMan}, {GenderMan}},
+		{{GenderNonbinary}, {GenderMan, GenderNonbinary, GenderWoman}},
+		{{GenderWoman}, {GenderMan, GenderNonbinary, GenderWoman}},
+		{{GenderMan}, {GenderNonbinary}},
+		{{GenderNonbinary, GenderWoman}, nil},
+		{nil, nil},
+	}
+	var users []repository.ListEligibleUsersRow
+	for i := 0; i < 3; i++ {
+		for _, profile := range profiles {
+			users = append(users, repository.ListEligibleUsersRow{ID: uuid.New(), Genders: profile[0], SeekingGenders: profile[1]})
+		}
+	}
+
+	seen := map[[2]uuid.UUID]int{}
+	for _, pool := range buildCandidatePools(users) {
+		for i := range pool.left {
+			partners := pool.right
+			if partners == nil {
+				partners = pool.left[i+1:]
+			}
+			for _, partner := range partners {
+				seen[pairKey(pool.left[i].ID, partner.ID)]++
+			}
+		}
+	}
+
+	for i := range users {
+		for j := i + 1; j < len(users); j++ {
+			key := pairKey(users[i].ID, users[j].ID)
+			want := 0
+			if meetsPreferences(users[i], users[j]) {
+				want = 1
+			}
+			if seen[key] != want {
+				t.Fatalf("pair %v/%v seeking %v/%v considered %d times, expected %d",
+					users[i].Genders, users[j].Genders, users[i].SeekingGenders, users[j].SeekingGenders, seen[key], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pairHistory is what earlier campaigns say about a pair: whether they were
+// matched, and whether either of them turned the other down or reported them.
+type pairHistory struct {
+	matched  bool
+	rejected bool
+}
+
+// loadPairHistory reads every pair with history outside the campaign,
+// including potential matches from browsing, which carry pass and report
+// interactions but no campaign. A rejection within the campaign counts too,
+// so rerunning it does not pair the two again.
+func (s *MatchingService) loadPairHistory(ctx context.Context, campaignID uuid.UUID, idx *scoringIndex) error {
+	rows, err := s.store.ListPairHistory(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		idx.addHistory(row.User1ID, row.User2ID, pairHistory{matched: row.Matched, rejected: row.Rejected})
+	}
+	return nil
+}
+
+// addHistory records a pair under both users so the pair loop can look it up
+// without normalizing the order.
+func (idx *scoringIndex) addHistory(user1 uuid.UUID, user2 uuid.UUID, history pairHistory) {
+	for _, pair := range [][2]uuid.UUID{{user1, user2}, {user2, user1}} {
+		partners, ok := idx.history[pair[0]]
+		if !ok {
+			partners = map[uuid.UUID]pairHistory{}
+			idx.history[pair[0]] = partners
+		}
+		past := partners[pair[1]]
+		past.matched = past.matched || history.matched
+		past.rejected = past.rejected || history.rejected
+		partners[pair[1]] = past
+	}
+}
+
+// rematchMultiplier applies the campaign's rematch policy to a pair. It
+// returns the score multiplier, or false when the pair must not be matched.
+func (idx *scoringIndex) rematchMultiplier(user1 uuid.UUID, user2 uuid.UUID) (float64, bool) {
+	past, ok := idx.history[user1][user2]
+	if !ok {
+		return 1, true
+	}
+	if past.rejected {
+		return 0, false
+	}
+	if !past.matched {
+		return 1, true
+	}
+	switch idx.rematchPolicy {
+	case RematchAvoid:
+		return 0, false
+	case RematchBoost:
+		return idx.rematch, true
+	}
+	return 1, true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestRematchPolicies(t *testing.T) {
+	user1 := &repository.ListEligibleUsersRow{ID: uuid.New()}
+	user2 := &repository.ListEligibleUsersRow{ID: uuid.New()}
+	stranger := &repository.ListEligibleUsersRow{ID: uuid.New()}
+	score := func(policy string, history *pairHistory, partner *repository.ListEligibleUsersRow) matchScore {
+		cfg := DefaultMatchingConfig()
+		cfg.RematchPolicy = policy
+		idx := newScoringIndex(cfg)
+		if history != nil {
+			idx.addHistory(user2.ID, user1.ID, *history)
+		}
+		return idx.calculateCompatibility(user1, partner)
+	}
+
+	matched := &pairHistory{matched: true}
+	fresh := score(RematchAllow, nil, user2)
+	if got := score(RematchAvoid, matched, user2); !got.Vetoed {
+		t.Fatalf("expected avoid to veto a previous pair")
+	}
+	if got := score(RematchAvoid, matched, stranger); got.Vetoed || got.Score != fresh.Score {
+		t.Fatalf("expected history not to affect other pairs, got %+v", got)
+	}
+	if got := score(RematchAllow, matched, user2); got.Vetoed || got.Score != fresh.Score {
+		t.Fatalf("expected allow to score a previous pair normally, got %+v", got)
+	}
+	if got := score(RematchBoost, matched, user2); got.Vetoed || got.Score <= fresh.Score {
+		t.Fatalf("expected boost to raise %v, got %+v", fresh.Score, got)
+	}
+
+	rejected := &pairHistory{matched: true, rejected: true}
+	for _, policy := range []string{RematchAvoid, RematchAllow, RematchBoost} {
+		if got := score(policy, rejected, user2); !got.Vetoed {
+			t.Fatalf("expected %s to veto a pair that was turned down", policy)
+		}
+	}
+	if got := score(RematchAllow, &pairHistory{rejected: true}, user2); !got.Vetoed {
+		t.Fatalf("expected a report from browsing to veto the pair")
+	}
+}
+
+func TestAddHistoryMergesBothOrders(t *testing.T) {
+	user1, user2 := uuid.New(), uuid.New()
+	idx := newScoringIndex(DefaultMatchingConfig())
+	idx.addHistory(user1, user2, pairHistory{matched: true})
+	idx.addHistory(user2, user1, pairHistory{rejected: true})
+
+	for _, past := range []pairHistory{idx.history[user1][user2], idx.history[user2][user1]} {
+		if !past.matched || !past.rejected {
+			t.Fatalf("expected both rows to merge, got %+v", past)
+		}
+	}
+}
+
+func TestPairHistoryCountsRejectionsInTheCampaign(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 4)
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+	users := make([]repository.User, 4)
+	for i := range users {
+		users[i], _ = store.GetUserByEmail(ctx, fmt.Sprintf("user%d@example.com", i))
+	}
+	match := func(user1, user2 repository.User) repository.Match {
+		t.Helper()
+		created, err := store.CreateMatch(ctx, repository.CreateMatchParams{
+			CampaignID:         campaignID,
+			User1ID:            user1.ID,
+			User2ID:            user2.ID,
+			CompatibilityScore: NumericFromFloat(80),
+			SharedInterests:    []byte("{}"),
+		})
+		if err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+		return created
+	}
+	turnedDown := match(users[0], users[1])
+	match(users[2], users[3])
+	if _, err := store.CreateInteraction(ctx, repository.CreateInteractionParams{
+		MatchID:         turnedDown.ID,
+		UserID:          users[0].ID,
+		InteractionType: "not_interested",
+	}); err != nil {
+		t.Fatalf("create interaction: %v", err)
+	}
+
+	idx := newScoringIndex(DefaultMatchingConfig())
+	if err := NewMatchingService(store, nil).loadPairHistory(ctx, campaign.ID, idx); err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	if past := idx.history[users[0].ID][users[1].ID]; !past.rejected || past.matched {
+		t.Fatalf("expected a rejection in this campaign to count, got %+v", past)
+	}
+	if _, ok := idx.history[users[2].ID][users[3].ID]; ok {
+		t.Fatalf("expected a match in this campaign not to count as a past match")
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/jobs"
+	"wizardmatch-backend/internal/repository"
+)
+
+const JobMatchNewUsers = "match_new_users"
+
+// MatchNewUsersPayload selects the users to match incrementally. Without
+// user IDs every eligible user who has no match in the campaign yet is new.
+type MatchNewUsersPayload struct {
+	CampaignID uuid.UUID   `json:"campaignId"`
+	UserIDs    []uuid.UUID `json:"userIds,omitempty"`
+}
+
+// IncrementalResult reports what an incremental matching pass added.
+type IncrementalResult struct {
+	CampaignID      uuid.UUID     `json:"campaignId"`
+	NewUsers        int           `json:"newUsers"`
+	CandidatePairs  int           `json:"candidatePairs"`
+	Added           int64         `json:"added"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Progress        MatchProgress `json:"progress"`
+}
+
+// existingMatches summarizes a campaign's live matches per user. Held has
+// every match as a pair; users who are no longer eligible only carry their ID.
+type existingMatches struct {
+	pairs    map[[2]uuid.UUID]bool
+	counts   map[uuid.UUID]int
+	maxRanks map[uuid.UUID]int
+	held     []scoredPair
+}
+
+func summarizeMatches(matches []repository.Match, users []repository.ListEligibleUsersRow) existingMatches {
+	existing := existingMatches{
+		pairs:    map[[2]uuid.UUID]bool{},
+		counts:   map[uuid.UUID]int{},
+		maxRanks: map[uuid.UUID]int{},
+	}
+	byID := make(map[uuid.UUID]*repository.ListEligibleUsersRow, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	user := func(id uuid.UUID) *repository.ListEligibleUsersRow {
+		if byID[id] == nil {
+			byID[id] = &repository.ListEligibleUsersRow{ID: id}
+		}
+		return byID[id]
+	}
+	for _, match := range matches {
+		existing.pairs[pairKey(match.User1ID, match.User2ID)] = true
+		existing.counts[match.User1ID]++
+		existing.counts[match.User2ID]++
+		existing.raiseRank(match.User1ID, match.RankForUser1)
+		existing.raiseRank(match.User2ID, match.RankForUser2)
+		existing.held = append(existing.held, scoredPair{
+			User1: user(match.User1ID),
+			User2: user(match.User2ID),
+			Score: matchScore{Score: numericFloat(match.CompatibilityScore)},
+		})
+	}
+	return existing
+}
+
+func (e existingMatches) raiseRank(user uuid.UUID, rank pgtype.Int4) {
+	if rank.Valid && int(rank.Int32) > e.maxRanks[user] {
+		e.maxRanks[user] = int(rank.Int32)
+	}
+}
+
+// MatchNewUsers matches users who became eligible after the campaign's
+// matches were generated. Only pairs involving a new user are scored, users
+// keep their existing matches and ranks, and new matches fill whatever room
+// is left under the per-user and campaign limits and the fairness caps.
+// Existing match rows are never modified, so revealed pairs and open
+// conversations are unaffected, and new matches are added to the published
+// run so republishing it keeps them.
+func (s *MatchingService) MatchNewUsers(ctx context.Context, campaignID uuid.UUID, userIDs []uuid.UUID) (IncrementalResult, error) {
+	result := IncrementalResult{CampaignID: campaignID}
+	cfg, err := s.loadMatchingConfig(ctx, campaignID)
+	if err != nil {
+		return result, err
+	}
+
+	s.progress.setStage(StageLoading)
+	users, err := s.store.ListEligibleUsers(ctx)
+	if err != nil {
+		return result, err
+	}
+	users = excludeUnderage(users, cfg.MinAge, time.Now())
+	idx, err := s.loadScoringIndex(ctx, campaignID, users, cfg, textVectorsRebuild)
+	if err != nil {
+		return result, err
+	}
+
+	var added []repository.Match
+	err = s.withTx(ctx, func(store repository.Querier) error {
+		// Serializes incremental passes for the campaign so two late
+		// completers cannot both take a user's last free slot.
+		if err := store.LockCampaignMatches(ctx, campaignID); err != nil {
+			return err
+		}
+		matches, err := store.ListMatchesByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+		if err != nil {
+			return err
+		}
+		existing := summarizeMatches(matches, users)
+		// New matches join the published run's results, or the next publish
+		// or rollback would delete them as pairs the run does not have.
+		published, err := store.GetPublishedMatchRun(ctx, campaignID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		isNew := newUserSet(users, userIDs, existing.counts)
+		result.NewUsers = len(isNew)
+		if len(isNew) == 0 {
+			return nil
+		}
+
+		pools := incrementalPools(buildCandidatePools(users), isNew)
+		pairsTotal := int64(0)
+		for _, pool := range pools {
+			pairsTotal += pool.pairs()
+		}
+		s.progress.setPairsTotal(pairsTotal)
+		s.progress.setStage(StageScoring)
+
+		var scored []scoredPair
+		for _, pool := range pools {
+			poolPairs, err := scorePairs(ctx, idx, pool, cfg.MinScore, s.workers, s.progress)
+			if err != nil {
+				return err
+			}
+			for _, pair := range poolPairs {
+				if !existing.pairs[pairKey(pair.User1.ID, pair.User2.ID)] {
+					scored = append(scored, pair)
+				}
+			}
+		}
+		for _, pair := range idx.forcedPairs(users) {
+			if (isNew[pair.User1.ID] || isNew[pair.User2.ID]) && !existing.pairs[pairKey(pair.User1.ID, pair.User2.ID)] {
+				scored = append(scored, pair)
+			}
+		}
+		result.CandidatePairs = len(scored)
+
+		planned := assignIncremental(cfg, scored, existing)
+		s.progress.setMatchesPlanned(int64(len(planned)))
+		s.progress.setStage(StageWriting)
+		for _, match := range planned {
+			shared, _ := json.Marshal(match.pair.Score.Breakdown)
+			inserted, err := store.InsertMatchIfAbsent(ctx, repository.InsertMatchIfAbsentParams{
+				CampaignID:         pgtype.UUID{Bytes: campaignID, Valid: true},
+				User1ID:            match.pair.User1.ID,
+				User2ID:            match.pair.User2.ID,
+				CompatibilityScore: NumericFromFloat(match.pair.Score.Score),
+				MatchTier:          pgtype.Text{String: match.tier, Valid: true},
+				SharedInterests:    shared,
+				RankForUser1:       pgtype.Int4{Int32: int32(match.rank1), Valid: true},
+				RankForUser2:       pgtype.Int4{Int32: int32(match.rank2), Valid: true},
+				IsMutualCrush:      match.pair.Score.IsMutual,
+			})
+			if err != nil {
+				return err
+			}
+			for _, row := range inserted {
+				if published.ID == uuid.Nil {
+					break
+				}
+				if err := appendToRun(ctx, store, published.ID, row); err != nil {
+					return err
+				}
+			}
+			added = append(added, inserted...)
+			s.progress.addMatchesWritten(int64(len(inserted)))
+		}
+		result.Added = int64(len(added))
+		return nil
+	})
+	if err != nil {
+		return IncrementalResult{}, err
+	}
+	s.announceMatches(ctx, campaignID, added)
+	return result, nil
+}
+
+// newUserSet returns the eligible users to match incrementally: the given
+// IDs, or everyone without a match yet when none are given.
+func newUserSet(users []repository.ListEligibleUsersRow, userIDs []uuid.UUID, counts map[uuid.UUID]int) map[uuid.UUID]bool {
+	requested := map[uuid.UUID]bool{}
+	for _, id := range userIDs {
+		requested[id] = true
+	}
+	isNew := map[uuid.UUID]bool{}
+	for _, user := range users {
+		if len(requested) > 0 && requested[user.ID] || len(requested) == 0 && counts[user.ID] == 0 {
+			isNew[user.ID] = true
+		}
+	}
+	return isNew
+}
+
+// incrementalPools narrows candidate pools to pairs with at least one new
+// user: new users with each other and new users with everyone already
+// matched, each pair appearing once.
+func incrementalPools(pools []candidatePool, isNew map[uuid.UUID]bool) []candidatePool {
+	split := func(users []repository.ListEligibleUsersRow) (fresh, old []repository.ListEligibleUsersRow) {
+		for _, user := range users {
+			if isNew[user.ID] {
+				fresh = append(fresh, user)
+			} else {
+				old = append(old, user)
+			}
+		}
+		return fresh, old
+	}
+
+	var narrowed []candidatePool
+	add := func(left, right []repository.ListEligibleUsersRow) {
+		if len(left) > 0 && len(right) > 0 {
+			narrowed = append(narrowed, candidatePool{left: left, right: right})
+		}
+	}
+	for _, pool := range pools {
+		freshLeft, oldLeft := split(pool.left)
+		if pool.right == nil {
+			if len(freshLeft) > 1 {
+				narrowed = append(narrowed, candidatePool{left: freshLeft})
+			}
+			add(freshLeft, oldLeft)
+			continue
+		}
+		freshRight, _ := split(pool.right)
+		add(freshLeft, pool.right)
+		add(oldLeft, freshRight)
+	}
+	return narrowed
+}
+
+// assignIncremental picks new matches the way a full run's greedy strategy
+// would. Existing matches join the allocation as fixed pairs, so they count
+// towards the per-user cap, the minimum quota and the group shares but are
+// never dropped. New pairs are taken best-first while both users have room
+// left and the campaign stays under its total (forced pairs are always
+// taken), then users below the minimum are topped up, the total is cut back
+// to the budget and same-group shares are capped. Ranks continue after each
+// user's existing matches.
+func assignIncremental(cfg MatchingConfig, pairs []scoredPair, existing existingMatches) []plannedMatch {
+	all := make([]scoredPair, 0, len(existing.held)+len(pairs))
+	for _, pair := range existing.held {
+		pair.Score.Forced = true
+		all = append(all, pair)
+	}
+	all = append(all, pairs...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Score.Score > all[j].Score.Score
+	})
+
+	a := newAllocation(all)
+	chosen := len(a.selected())
+	for i := range all {
+		if a.chosen[i] || chosen >= cfg.MaxTotalMatches || !a.hasCapacity(i, cfg.MaxMatchesPerUser) {
+			continue
+		}
+		a.add(i)
+		chosen++
+	}
+	if cfg.MinMatchesPerUser > 0 {
+		a.fillMinimumQuota(cfg.MinMatchesPerUser, cfg.MaxMatchesPerUser)
+	}
+	a.capTotal(cfg.MaxTotalMatches, cfg.MinMatchesPerUser)
+	a.capGroupShares(cfg.groupShares(), cfg.MinMatchesPerUser, cfg.MaxMatchesPerUser, cfg.MaxTotalMatches)
+
+	ranks := map[uuid.UUID]int{}
+	for user, rank := range existing.maxRanks {
+		ranks[user] = rank
+	}
+	var planned []plannedMatch
+	for _, pair := range a.selected() {
+		user1, user2 := pair.User1.ID, pair.User2.ID
+		if existing.pairs[pairKey(user1, user2)] {
+			continue
+		}
+		ranks[user1]++
+		ranks[user2]++
+		planned = append(planned, plannedMatch{
+			pair:  pair,
+			tier:  cfg.Tier(pair.Score.Score),
+			rank1: ranks[user1],
+			rank2: ranks[user2],
+		})
+	}
+	return planned
+}
+
+// RunMatchNewUsersJob is the jobs.Handler for JobMatchNewUsers.
+func (s *MatchingService) RunMatchNewUsersJob(ctx context.Context, job repository.Job, progress *jobs.Progress) (any, error) {
+	var payload MatchNewUsersPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid job payload: %w", err)
+	}
+	if payload.CampaignID == uuid.Nil {
+		return nil, fmt.Errorf("job payload is missing campaignId")
+	}
+
+	started := time.Now()
+	tracker := &ProgressTracker{}
+	progress.Track(func() any { return tracker.Snapshot() })
+
+	result, err := s.WithProgress(tracker).MatchNewUsers(ctx, payload.CampaignID, payload.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+	tracker.setStage(StageDone)
+	result.DurationSeconds = time.Since(started).Seconds()
+	result.Progress = tracker.Snapshot()
+	return result, nil
+}
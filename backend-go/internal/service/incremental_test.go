@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestIncrementalPoolsOnlyPairNewUsers(t *testing.T) {
+	users := make([]repository.ListEligibleUsersRow, 6)
+	for i := range users {
+		users[i] = repository.ListEligibleUsersRow{ID: uuid.New()}
+	}
+	isNew := map[uuid.UUID]bool{users[0].ID: true, users[3].ID: true}
+	pools := []candidatePool{
+		{left: users[:3]},
+		{left: users[:3], right: users[3:]},
+	}
+
+	seen := map[[2]uuid.UUID]int{}
+	for _, pool := range incrementalPools(pools, isNew) {
+		if pool.right != nil && (len(pool.left) == 0 || len(pool.right) == 0) {
+			t.Fatalf("expected empty pools to be dropped, got %+v", pool)
+		}
+		for i, user1 := range pool.left {
+			partners := pool.right
+			if partners == nil {
+				partners = pool.left[i+1:]
+			}
+			for _, user2 := range partners {
+				if !isNew[user1.ID] && !isNew[user2.ID] {
+					t.Fatalf("expected every pair to include a new user")
+				}
+				seen[pairKey(user1.ID, user2.ID)]++
+			}
+		}
+	}
+
+	// users[0] with users[1], users[2] and all of users[3:]; users[3] with
+	// users[1] and users[2].
+	if len(seen) != 7 {
+		t.Fatalf("expected 7 pairs, got %d", len(seen))
+	}
+	for key, count := range seen {
+		if count != 1 {
+			t.Fatalf("expected %v to be scored once, got %d", key, count)
+		}
+	}
+}
+
+func TestAssignIncrementalRespectsQuotasAndRanks(t *testing.T) {
+	users := testUsers(4)
+	late, full, open, other := users[0], users[1], users[2], users[3]
+	existing := summarizeMatches([]repository.Match{
+		{User1ID: full.ID, User2ID: open.ID, RankForUser1: pgtype.Int4{Int32: 1, Valid: true}, RankForUser2: pgtype.Int4{Int32: 1, Valid: true}},
+		{User1ID: full.ID, User2ID: other.ID, RankForUser1: pgtype.Int4{Int32: 2, Valid: true}, RankForUser2: pgtype.Int4{Int32: 1, Valid: true}},
+	}, nil)
+
+	cfg := DefaultMatchingConfig()
+	cfg.MaxMatchesPerUser = 2
+	planned := assignIncremental(cfg, []scoredPair{
+		testPair(late, open, 70),
+		testPair(late, full, 90),
+		testPair(late, other, 80),
+	}, existing)
+
+	if len(planned) != 2 {
+		t.Fatalf("expected 2 new matches, got %d", len(planned))
+	}
+	if planned[0].pair.User2 != other || planned[1].pair.User2 != open {
+		t.Fatalf("expected the full user to be skipped and pairs taken best-first")
+	}
+	if planned[0].rank1 != 1 || planned[0].rank2 != 2 || planned[1].rank1 != 2 || planned[1].rank2 != 2 {
+		t.Fatalf("expected ranks to continue after existing matches, got %+v", planned)
+	}
+	if planned[0].tier != cfg.Tier(80) {
+		t.Fatalf("expected tier %q, got %q", cfg.Tier(80), planned[0].tier)
+	}
+
+	cfg.MaxTotalMatches = 3
+	if planned := assignIncremental(cfg, []scoredPair{testPair(late, open, 70), testPair(late, other, 80)}, existing); len(planned) != 1 {
+		t.Fatalf("expected the campaign total to cap new matches, got %d", len(planned))
+	}
+}
+
+func TestNewUserSet(t *testing.T) {
+	matched, unmatched := uuid.New(), uuid.New()
+	users := []repository.ListEligibleUsersRow{{ID: matched}, {ID: unmatched}}
+	counts := map[uuid.UUID]int{matched: 3}
+
+	if got := newUserSet(users, nil, counts); len(got) != 1 || !got[unmatched] {
+		t.Fatalf("expected only the unmatched user, got %v", got)
+	}
+	if got := newUserSet(users, []uuid.UUID{matched, uuid.New()}, counts); len(got) != 1 || !got[matched] {
+		t.Fatalf("expected only requested eligible users, got %v", got)
+	}
+}
+
+func TestIncrementalMatchesSurvivePublishAndRollback(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 6)
+	matcher := NewMatchingService(store, nil)
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+
+	first, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if _, err := matcher.PublishMatchRun(ctx, first.ID); err != nil {
+		t.Fatalf("publish run: %v", err)
+	}
+
+	questions, err := store.ListQuestionsByCampaign(ctx, campaignID)
+	if err != nil || len(questions) != 1 {
+		t.Fatalf("list questions: %v", err)
+	}
+	late := seedUser(t, store, campaignID, questions[0].ID, 6)
+	result, err := matcher.MatchNewUsers(ctx, campaign.ID, nil)
+	if err != nil {
+		t.Fatalf("match new users: %v", err)
+	}
+	if result.NewUsers != 1 || result.Added == 0 {
+		t.Fatalf("expected the late user to be matched, got %+v", result)
+	}
+	run, _ := store.GetMatchRunByID(ctx, first.ID)
+	if results, _ := store.CountMatchRunResults(ctx, first.ID); results != int64(first.TotalMatches)+result.Added || run.TotalMatches != int32(results) {
+		t.Fatalf("expected the published run to gain %d results, got %d (total %d)", result.Added, results, run.TotalMatches)
+	}
+
+	second, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create second run: %v", err)
+	}
+	if _, err := matcher.PublishMatchRun(ctx, second.ID); err != nil {
+		t.Fatalf("publish second run: %v", err)
+	}
+	rolledBack, err := matcher.RollbackMatchRun(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("roll back: %v", err)
+	}
+	if rolledBack.Run.ID != first.ID {
+		t.Fatalf("expected the first run to be live again, got %+v", rolledBack.Run)
+	}
+	live, _ := store.ListMatchesByCampaign(ctx, campaignID)
+	matched := 0
+	for _, match := range live {
+		if match.User1ID == late.ID || match.User2ID == late.ID {
+			matched++
+		}
+	}
+	if int64(matched) != result.Added {
+		t.Fatalf("expected the late user's %d matches to survive, got %d", result.Added, matched)
+	}
+}
+
+func TestAssignIncrementalCapsGroupShares(t *testing.T) {
+	users := testUsers(4)
+	for i, user := range users {
+		program := "Arts"
+		if i == 3 {
+			program = "Law"
+		}
+		user.Program = pgtype.Text{String: program, Valid: true}
+	}
+	late, matched, partner, other := users[0], users[1], users[2], users[3]
+	existing := summarizeMatches([]repository.Match{
+		{User1ID: matched.ID, User2ID: partner.ID, CompatibilityScore: NumericFromFloat(60)},
+	}, []repository.ListEligibleUsersRow{*matched, *partner})
+
+	cfg := DefaultMatchingConfig()
+	cfg.MaxSameProgramShare = 0.5
+	planned := assignIncremental(cfg, []scoredPair{
+		testPair(late, matched, 90),
+		testPair(late, other, 70),
+	}, existing)
+	if len(planned) != 1 || planned[0].pair.User2 != other {
+		t.Fatalf("expected the existing same-program match to push the new one across programs, got %+v", planned)
+	}
+
+	cfg = DefaultMatchingConfig()
+	cfg.MaxMatchesPerUser = 1
+	cfg.MinMatchesPerUser = 1
+	if planned := assignIncremental(cfg, []scoredPair{testPair(late, matched, 90)}, existing); len(planned) != 0 {
+		t.Fatalf("expected existing matches to be kept over the minimum quota, got %+v", planned)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wizardmatch-backend/internal/jobs"
+	"wizardmatch-backend/internal/repository"
+)
+
+const JobGenerateMatches = "generate_matches"
+
+const (
+	StageLoading    = "loading"
+	StageScoring    = "scoring"
+	StageWriting    = "writing"
+	StagePublishing = "publishing"
+	StageDone       = "done"
+)
+
+type GenerateMatchesPayload struct {
+	CampaignID uuid.UUID `json:"campaignId"`
+	Publish    bool      `json:"publish"`
+}
+
+type MatchProgress struct {
+	Stage          string `json:"stage"`
+	PairsTotal     int64  `json:"pairsTotal"`
+	PairsScored    int64  `json:"pairsScored"`
+	MatchesPlanned int64  `json:"matchesPlanned"`
+	MatchesWritten int64  `json:"matchesWritten"`
+}
+
+// ProgressTracker collects progress from the matching pipeline. It is safe
+// for concurrent use and all methods accept a nil receiver.
+type ProgressTracker struct {
+	mu             sync.Mutex
+	stage          string
+	pairsTotal     atomic.Int64
+	pairsScored    atomic.Int64
+	matchesPlanned atomic.Int64
+	matchesWritten atomic.Int64
+}
+
+func (p *ProgressTracker) Snapshot() MatchProgress {
+	if p == nil {
+		return MatchProgress{}
+	}
+	p.mu.Lock()
+	stage := p.stage
+	p.mu.Unlock()
+	return MatchProgress{
+		Stage:          stage,
+		PairsTotal:     p.pairsTotal.Load(),
+		PairsScored:    p.pairsScored.Load(),
+		MatchesPlanned: p.matchesPlanned.Load(),
+		MatchesWritten: p.matchesWritten.Load(),
+	}
+}
+
+func (p *ProgressTracker) setStage(stage string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.stage = stage
+	p.mu.Unlock()
+}
+
+func (p *ProgressTracker) setPairsTotal(total int64) {
+	if p != nil {
+		p.pairsTotal.Store(total)
+	}
+}
+
+func (p *ProgressTracker) addPairsScored(count int64) {
+	if p != nil {
+		p.pairsScored.Add(count)
+	}
+}
+
+func (p *ProgressTracker) setMatchesPlanned(count int64) {
+	if p != nil {
+		p.matchesPlanned.Store(count)
+	}
+}
+
+func (p *ProgressTracker) addMatchesWritten(count int64) {
+	if p != nil {
+		p.matchesWritten.Add(count)
+	}
+}
+
+// GenerateMatchesReport is the result stored on a finished generation job.
+type GenerateMatchesReport struct {
+	RunID            uuid.UUID       `json:"runId"`
+	CampaignID       uuid.UUID       `json:"campaignId"`
+	AlgorithmVersion string          `json:"algorithmVersion"`
+	TotalUsers       int32           `json:"totalUsers"`
+	CandidatePairs   int32           `json:"candidatePairs"`
+	TotalMatches     int32           `json:"totalMatches"`
+	Fairness         json.RawMessage `json:"fairness,omitempty"`
+	Published        bool            `json:"published"`
+	Kept             int64           `json:"kept"`
+	Added            int64           `json:"added"`
+	Removed          int64           `json:"removed"`
+	Superseded       int64           `json:"superseded"`
+	DurationSeconds  float64         `json:"durationSeconds"`
+	Progress         MatchProgress   `json:"progress"`
+}
+
+// RunGenerateMatchesJob is the jobs.Handler for JobGenerateMatches.
+func (s *MatchingService) RunGenerateMatchesJob(ctx context.Context, job repository.Job, progress *jobs.Progress) (any, error) {
+	var payload GenerateMatchesPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid job payload: %w", err)
+	}
+	if payload.CampaignID == uuid.Nil {
+		return nil, fmt.Errorf("job payload is missing campaignId")
+	}
+
+	started := time.Now()
+	tracker := &ProgressTracker{}
+	progress.Track(func() any { return tracker.Snapshot() })
+	matcher := s.WithProgress(tracker)
+
+	run, err := matcher.CreateMatchRun(ctx, payload.CampaignID, job.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := GenerateMatchesReport{
+		RunID:            run.ID,
+		CampaignID:       run.CampaignID,
+		AlgorithmVersion: run.AlgorithmVersion,
+		TotalUsers:       run.TotalUsers,
+		CandidatePairs:   run.CandidatePairs,
+		TotalMatches:     run.TotalMatches,
+		Fairness:         run.Fairness,
+	}
+	if payload.Publish {
+		tracker.setStage(StagePublishing)
+		published, err := matcher.PublishMatchRun(ctx, run.ID)
+		if err != nil {
+			return nil, err
+		}
+		report.Published = true
+		report.Kept = published.Kept
+		report.Added = published.Added
+		report.Removed = published.Removed
+		report.Superseded = published.Superseded
+	}
+
+	tracker.setStage(StageDone)
+	report.DurationSeconds = time.Since(started).Seconds()
+	report.Progress = tracker.Snapshot()
+	return report, nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+const (
+	MatchRunRunning    = "running"
+	MatchRunCompleted  = "completed"
+	MatchRunFailed     = "failed"
+	MatchRunPublished  = "published"
+	MatchRunSuperseded = "superseded"
+)
+
+var (
+	ErrMatchRunNotPublishable = errors.New("match run cannot be published")
+	ErrNoPreviousMatchRun     = errors.New("no previously published match run")
+)
+
+// PublishResult reports how publishing a run changed the live matches.
+// Kept pairs are updated in place so their conversations survive; dropped
+// pairs with messages, a rejection or a report are superseded rather than
+// removed.
+type PublishResult struct {
+	Run        repository.MatchRun `json:"run"`
+	Kept       int64               `json:"kept"`
+	Added      int64               `json:"added"`
+	Removed    int64               `json:"removed"`
+	Superseded int64               `json:"superseded"`
+}
+
+// CreateMatchRun runs the matching pipeline for the campaign and stores the
+// result as a new run without touching the live matches.
+func (s *MatchingService) CreateMatchRun(ctx context.Context, campaignID uuid.UUID, createdBy pgtype.UUID) (repository.MatchRun, error) {
+	cfg, err := s.loadMatchingConfig(ctx, campaignID)
+	if err != nil {
+		return repository.MatchRun{}, err
+	}
+	rawConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return repository.MatchRun{}, err
+	}
+
+	run, err := s.store.CreateMatchRun(ctx, repository.CreateMatchRunParams{
+		CampaignID:       campaignID,
+		AlgorithmVersion: cfg.AlgorithmVersion(),
+		Config:           rawConfig,
+		CreatedBy:        createdBy,
+	})
+	if err != nil {
+		return repository.MatchRun{}, err
+	}
+
+	completed, err := s.executeMatchRun(ctx, run, cfg)
+	if err != nil {
+		_ = s.store.FailMatchRun(context.WithoutCancel(ctx), repository.FailMatchRunParams{
+			ID:           run.ID,
+			ErrorMessage: pgtype.Text{String: err.Error(), Valid: true},
+		})
+		return run, err
+	}
+	return completed, nil
+}
+
+func (s *MatchingService) executeMatchRun(ctx context.Context, run repository.MatchRun, cfg MatchingConfig) (repository.MatchRun, error) {
+	plan, err := s.planMatches(ctx, run.CampaignID, cfg, textVectorsRebuild)
+	if err != nil {
+		return run, err
+	}
+
+	s.progress.setStage(StageWriting)
+	var completed repository.MatchRun
+	err = s.withTx(ctx, func(store repository.Querier) error {
+		for _, match := range plan.matches {
+			shared, _ := json.Marshal(match.pair.Score.Breakdown)
+			if err := store.CreateMatchRunResult(ctx, repository.CreateMatchRunResultParams{
+				RunID:              run.ID,
+				User1ID:            match.pair.User1.ID,
+				User2ID:            match.pair.User2.ID,
+				CompatibilityScore: NumericFromFloat(match.pair.Score.Score),
+				MatchTier:          pgtype.Text{String: match.tier, Valid: true},
+				SharedInterests:    shared,
+				RankForUser1:       pgtype.Int4{Int32: int32(match.rank1), Valid: true},
+				RankForUser2:       pgtype.Int4{Int32: int32(match.rank2), Valid: true},
+				IsMutualCrush:      match.pair.Score.IsMutual,
+			}); err != nil {
+				return err
+			}
+			s.progress.addMatchesWritten(1)
+		}
+
+		fairness, err := json.Marshal(plan.fairness)
+		if err != nil {
+			return err
+		}
+		completed, err = store.CompleteMatchRun(ctx, repository.CompleteMatchRunParams{
+			ID:             run.ID,
+			TotalUsers:     int32(len(plan.users)),
+			CandidatePairs: int32(plan.candidates),
+			TotalMatches:   int32(len(plan.matches)),
+			Fairness:       fairness,
+		})
+		return err
+	})
+	return completed, err
+}
+
+// PublishMatchRun makes the run's results the live matches of its campaign.
+// Pairs matched both before and after keep their match row (and with it
+// messages, interactions and reveal state); pairs no longer in the run are
+// removed, or hidden when they have messages, a rejection or a report, and
+// new pairs are inserted.
+func (s *MatchingService) PublishMatchRun(ctx context.Context, runID uuid.UUID) (PublishResult, error) {
+	run, err := s.store.GetMatchRunByID(ctx, runID)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	if run.Status != MatchRunCompleted && run.Status != MatchRunSuperseded {
+		return PublishResult{}, ErrMatchRunNotPublishable
+	}
+
+	result := PublishResult{}
+	campaignID := pgtype.UUID{Bytes: run.CampaignID, Valid: true}
+	var added []repository.Match
+	err = s.withTx(ctx, func(store repository.Querier) error {
+		var err error
+		result.Kept, err = store.UpdateMatchesFromRun(ctx, repository.UpdateMatchesFromRunParams{RunID: run.ID, CampaignID: campaignID})
+		if err != nil {
+			return err
+		}
+		result.Removed, err = store.DeleteMatchesNotInRun(ctx, repository.DeleteMatchesNotInRunParams{RunID: run.ID, CampaignID: campaignID})
+		if err != nil {
+			return err
+		}
+		result.Superseded, err = store.SupersedeMatchesNotInRun(ctx, repository.SupersedeMatchesNotInRunParams{RunID: run.ID, CampaignID: campaignID})
+		if err != nil {
+			return err
+		}
+		added, err = store.InsertMatchesFromRun(ctx, repository.InsertMatchesFromRunParams{RunID: run.ID, CampaignID: campaignID})
+		if err != nil {
+			return err
+		}
+		result.Added = int64(len(added))
+
+		if err := store.SupersedePublishedMatchRun(ctx, run.CampaignID); err != nil {
+			return err
+		}
+		result.Run, err = store.PublishMatchRun(ctx, run.ID)
+		if err != nil {
+			return err
+		}
+
+		return store.UpdateCampaignStats(ctx, repository.UpdateCampaignStatsParams{
+			ID:                    run.CampaignID,
+			TotalParticipants:     pgtype.Int4{Int32: run.TotalUsers, Valid: true},
+			TotalMatchesGenerated: pgtype.Int4{Int32: int32(result.Kept + result.Added), Valid: true},
+			AlgorithmVersion:      pgtype.Text{String: run.AlgorithmVersion, Valid: true},
+		})
+	})
+	if err != nil {
+		return PublishResult{}, err
+	}
+	s.announceMatches(ctx, run.CampaignID, added)
+	return result, nil
+}
+
+// CreateManualMatch adds a match an organizer picked. When its campaign has a
+// published run the pair joins the run's results, or the next publish or
+// rollback would remove it as a pair the run does not have.
+func (s *MatchingService) CreateManualMatch(ctx context.Context, arg repository.CreateMatchParams) (repository.Match, error) {
+	var match repository.Match
+	err := s.withTx(ctx, func(store repository.Querier) error {
+		var err error
+		match, err = store.CreateMatch(ctx, arg)
+		if err != nil || !arg.CampaignID.Valid {
+			return err
+		}
+		published, err := store.GetPublishedMatchRun(ctx, arg.CampaignID.Bytes)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return appendToRun(ctx, store, published.ID, match)
+	})
+	return match, err
+}
+
+// appendToRun adds a live match to the results of the run it was published
+// with.
+func appendToRun(ctx context.Context, store repository.Querier, runID uuid.UUID, match repository.Match) error {
+	return store.AppendMatchRunResult(ctx, repository.AppendMatchRunResultParams{
+		RunID:              runID,
+		User1ID:            match.User1ID,
+		User2ID:            match.User2ID,
+		CompatibilityScore: match.CompatibilityScore,
+		MatchTier:          match.MatchTier,
+		SharedInterests:    match.SharedInterests,
+		RankForUser1:       match.RankForUser1,
+		RankForUser2:       match.RankForUser2,
+		IsMutualCrush:      match.IsMutualCrush,
+	})
+}
+
+// RollbackMatchRun republishes the run that was live before the current one.
+func (s *MatchingService) RollbackMatchRun(ctx context.Context, campaignID uuid.UUID) (PublishResult, error) {
+	previous, err := s.store.GetLastSupersededMatchRun(ctx, campaignID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PublishResult{}, ErrNoPreviousMatchRun
+	}
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return s.PublishMatchRun(ctx, previous.ID)
+}
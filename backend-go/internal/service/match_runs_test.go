@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+// seedCampaign fills store with an active campaign whose users, half women
+// and half men seeking each other, have all answered its one question.
+func seedCampaign(t *testing.T, store *memory.Store, users int) repository.Campaign {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	campaign, err := store.CreateCampaign(ctx, repository.CreateCampaignParams{
+		Name:                   "Test",
+		SurveyOpenDate:         now.Add(-48 * time.Hour),
+		SurveyCloseDate:        now.Add(-24 * time.Hour),
+		ProfileUpdateStartDate: now,
+		ProfileUpdateEndDate:   now.Add(24 * time.Hour),
+		ResultsReleaseDate:     now.Add(48 * time.Hour),
+		IsActive:               pgtype.Bool{Bool: true, Valid: true},
+		Config:                 []byte("{}"),
+	})
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+	question, err := store.CreateQuestion(ctx, repository.CreateQuestionParams{
+		CampaignID:   campaignID,
+		Category:     "personality",
+		QuestionText: "How outgoing are you?",
+		QuestionType: "scale",
+		Weight:       pgtype.Numeric{Int: big.NewInt(100), Exp: -2, Valid: true},
+		IsActive:     true,
+	})
+	if err != nil {
+		t.Fatalf("create question: %v", err)
+	}
+
+	for i := 0; i < users; i++ {
+		seedUser(t, store, campaignID, question.ID, i)
+	}
+	return campaign
+}
+
+// seedUser adds the i-th user of a seeded campaign, alternating women and
+// men, with an answer to the campaign's question.
+func seedUser(t *testing.T, store *memory.Store, campaignID pgtype.UUID, questionID uuid.UUID, i int) repository.User {
+	t.Helper()
+	ctx := context.Background()
+	gender, seeking := GenderWoman, GenderMan
+	if i%2 == 1 {
+		gender, seeking = seeking, gender
+	}
+	user, err := store.CreateUser(ctx, repository.CreateUserParams{
+		Email:           fmt.Sprintf("user%d@example.com", i),
+		FirstName:       fmt.Sprintf("User%d", i),
+		LastName:        "Test",
+		IsActive:        true,
+		SurveyCompleted: true,
+		Genders:         []string{gender},
+		SeekingGenders:  []string{seeking},
+		DateOfBirth:     birthday(2000, time.January, 1),
+	})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := store.CreateSurveyResponse(ctx, repository.CreateSurveyResponseParams{
+		UserID:      user.ID,
+		CampaignID:  campaignID,
+		QuestionID:  questionID,
+		AnswerValue: pgtype.Int4{Int32: int32(1 + i%5), Valid: true},
+		AnswerType:  "scale",
+		Importance:  ImportanceSomewhat,
+	}); err != nil {
+		t.Fatalf("create response: %v", err)
+	}
+	return user
+}
+
+func TestMatchRunPublishAndRollback(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 8)
+	matcher := NewMatchingService(store, nil)
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+
+	first, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if first.Status != MatchRunCompleted || first.TotalUsers != 8 || first.TotalMatches == 0 {
+		t.Fatalf("unexpected run: %+v", first)
+	}
+	if live, _ := store.ListMatchesByCampaign(ctx, campaignID); len(live) != 0 {
+		t.Fatalf("expected a run to leave live matches alone, got %d", len(live))
+	}
+
+	published, err := matcher.PublishMatchRun(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("publish run: %v", err)
+	}
+	if published.Added != int64(first.TotalMatches) || published.Kept != 0 || published.Removed != 0 {
+		t.Fatalf("unexpected publish result: %+v", published)
+	}
+	live, _ := store.ListMatchesByCampaign(ctx, campaignID)
+	if len(live) != int(first.TotalMatches) {
+		t.Fatalf("expected %d live matches, got %d", first.TotalMatches, len(live))
+	}
+
+	// The same population matches the same way, so republishing keeps every
+	// match row.
+	second, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create second run: %v", err)
+	}
+	republished, err := matcher.PublishMatchRun(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("publish second run: %v", err)
+	}
+	if republished.Kept != int64(len(live)) || republished.Added != 0 || republished.Removed != 0 {
+		t.Fatalf("unexpected republish result: %+v", republished)
+	}
+	if run, _ := store.GetMatchRunByID(ctx, first.ID); run.Status != MatchRunSuperseded {
+		t.Fatalf("expected the first run to be superseded, got %s", run.Status)
+	}
+
+	rolledBack, err := matcher.RollbackMatchRun(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("roll back: %v", err)
+	}
+	if rolledBack.Run.ID != first.ID || rolledBack.Run.Status != MatchRunPublished {
+		t.Fatalf("expected the first run to be live again, got %+v", rolledBack.Run)
+	}
+	if _, err := matcher.PublishMatchRun(ctx, uuid.New()); err == nil {
+		t.Fatalf("expected publishing an unknown run to fail")
+	}
+	if _, err := matcher.PublishMatchRun(ctx, first.ID); !errors.Is(err, ErrMatchRunNotPublishable) {
+		t.Fatalf("expected the live run to be unpublishable, got %v", err)
+	}
+}
+
+func TestPublishKeepsManualAndDiscussedMatches(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 8)
+	matcher := NewMatchingService(store, nil)
+	campaignID := pgtype.UUID{Bytes: campaign.ID, Valid: true}
+
+	first, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if _, err := matcher.PublishMatchRun(ctx, first.ID); err != nil {
+		t.Fatalf("publish run: %v", err)
+	}
+
+	// Two women, whom no run pairs.
+	user0, _ := store.GetUserByEmail(ctx, "user0@example.com")
+	user2, _ := store.GetUserByEmail(ctx, "user2@example.com")
+	manual, err := matcher.CreateManualMatch(ctx, repository.CreateMatchParams{
+		CampaignID:         campaignID,
+		User1ID:            user0.ID,
+		User2ID:            user2.ID,
+		CompatibilityScore: NumericFromFloat(50),
+		MatchTier:          pgtype.Text{String: "perfect", Valid: true},
+		SharedInterests:    []byte("{}"),
+	})
+	if err != nil {
+		t.Fatalf("create manual match: %v", err)
+	}
+	if results, _ := store.CountMatchRunResults(ctx, first.ID); results != int64(first.TotalMatches)+1 {
+		t.Fatalf("expected the manual match to join the published run, got %d results", results)
+	}
+	if _, err := store.CreateMessage(ctx, repository.CreateMessageParams{
+		MatchID:     manual.ID,
+		SenderID:    user0.ID,
+		RecipientID: user2.ID,
+		Content:     "hi",
+	}); err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	// A new run does not have the pair, but the conversation is kept for
+	// moderation while the match is hidden.
+	second, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{})
+	if err != nil {
+		t.Fatalf("create second run: %v", err)
+	}
+	published, err := matcher.PublishMatchRun(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("publish second run: %v", err)
+	}
+	if published.Removed != 0 || published.Superseded != 1 {
+		t.Fatalf("expected the manual match to be superseded, got %+v", published)
+	}
+	if _, err := store.GetMatchByID(ctx, manual.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected the superseded match to be hidden, got %v", err)
+	}
+	if unread, _ := store.CountUnreadMessages(ctx, user2.ID); unread != 0 {
+		t.Fatalf("expected no unread messages on a superseded match, got %d", unread)
+	}
+
+	// Rolling back brings the same match back with its conversation.
+	if _, err := matcher.RollbackMatchRun(ctx, campaign.ID); err != nil {
+		t.Fatalf("roll back: %v", err)
+	}
+	restored, err := store.GetMatchByID(ctx, manual.ID)
+	if err != nil || restored.SupersededAt.Valid {
+		t.Fatalf("expected the manual match to be live again, got %+v (%v)", restored, err)
+	}
+	if messages, _ := store.ListMessagesForMatch(ctx, manual.ID); len(messages) != 1 {
+		t.Fatalf("expected the conversation to survive, got %d messages", len(messages))
+	}
+}
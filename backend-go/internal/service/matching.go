@@ -4,256 +4,245 @@ import (
 	"context"
 	"encoding/json"
 	"math"
-	"sort"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"wizardmatch-backend/internal/realtime"
 	"wizardmatch-backend/internal/repository"
 )
 
+// TxBeginner starts database transactions; *pgxpool.Pool satisfies it.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type MatchingService struct {
-	store *repository.Queries
+	store    repository.Querier
+	db       TxBeginner
+	workers  int
+	progress *ProgressTracker
+	events   realtime.Publisher
 }
 
-func NewMatchingService(store *repository.Queries) *MatchingService {
-	return &MatchingService{store: store}
+// NewMatchingService returns a service reading from store. When db is set,
+// writes run in transactions begun on it, so store must query the same
+// database; without db, writes go straight to store.
+func NewMatchingService(store repository.Querier, db TxBeginner) *MatchingService {
+	return &MatchingService{store: store, db: db, workers: runtime.GOMAXPROCS(0)}
 }
 
-type matchScore struct {
-	Score     float64
-	Breakdown map[string]float64
-	IsMutual  bool
-	HasCrush  bool
+// WithProgress returns a copy of the service that reports into progress.
+func (s *MatchingService) WithProgress(progress *ProgressTracker) *MatchingService {
+	copied := *s
+	copied.progress = progress
+	return &copied
 }
 
-var weights = map[string]float64{
-	"demographics": 0.10,
-	"personality":  0.30,
-	"values":       0.25,
-	"lifestyle":    0.20,
-	"interests":    0.15,
+// WithEvents returns a copy of the service that tells users about the
+// matches it creates.
+func (s *MatchingService) WithEvents(events realtime.Publisher) *MatchingService {
+	copied := *s
+	copied.events = events
+	return &copied
 }
 
-func (s *MatchingService) GenerateAllMatches(ctx context.Context, campaignID uuid.UUID) (int, int, error) {
-	users, err := s.store.ListEligibleUsers(ctx)
-	if err != nil {
-		return 0, 0, err
+// announceMatches tells both users of each new match about it, once the
+// campaign lets users see their matches. Until then users learn of their
+// matches from the phase change.
+func (s *MatchingService) announceMatches(ctx context.Context, campaignID uuid.UUID, matches []repository.Match) {
+	if s.events == nil || len(matches) == 0 {
+		return
 	}
-
-	if err := s.store.DeleteMatchesByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true}); err != nil {
-		return 0, 0, err
+	campaign, err := s.store.GetCampaignByID(ctx, campaignID)
+	if err != nil || !ActionAllowed(CampaignPhase(campaign, time.Now()), "view_matches") {
+		return
 	}
+	for _, match := range matches {
+		_ = s.events.Publish(ctx, realtime.NewEvent(realtime.EventMatchCreated, map[string]any{
+			"matchId":    match.ID,
+			"campaignId": campaignID,
+		}, match.User1ID, match.User2ID))
+	}
+}
 
-	poolGroups := s.groupByPreference(users)
-	created := 0
-
-	for _, pool := range poolGroups {
-		scored := s.scorePairs(ctx, pool, campaignID)
-		sort.Slice(scored, func(i, j int) bool {
-			return scored[i].Score.Score > scored[j].Score.Score
-		})
-
-		userCounts := map[uuid.UUID]int{}
-		for _, pair := range scored {
-			if created >= 10000 {
-				break
-			}
-			if userCounts[pair.User1.ID] >= 7 || userCounts[pair.User2.ID] >= 7 {
-				continue
-			}
-			if pair.Score.Score < 50 && !pair.Score.IsMutual {
-				continue
-			}
-
-			shared, _ := json.Marshal(pair.Score.Breakdown)
-			scoreNumeric := toNumeric(pair.Score.Score)
-			_, err := s.store.CreateMatch(ctx, repository.CreateMatchParams{
-				CampaignID:         pgtype.UUID{Bytes: campaignID, Valid: true},
-				User1ID:            pair.User1.ID,
-				User2ID:            pair.User2.ID,
-				CompatibilityScore: scoreNumeric,
-				MatchTier:          pgtype.Text{String: matchTier(pair.Score.Score), Valid: true},
-				SharedInterests:    shared,
-				RankForUser1:       pgtype.Int4{Int32: int32(userCounts[pair.User1.ID] + 1), Valid: true},
-				RankForUser2:       pgtype.Int4{Int32: int32(userCounts[pair.User2.ID] + 1), Valid: true},
-				IsMutualCrush:      pair.Score.IsMutual,
-				IsRevealed:         false,
-			})
-			if err != nil {
-				continue
-			}
-			created++
-			userCounts[pair.User1.ID]++
-			userCounts[pair.User2.ID]++
-		}
+// withTx runs fn against a transaction when the service has a database to
+// begin one on, and directly against the store otherwise.
+func (s *MatchingService) withTx(ctx context.Context, fn func(store repository.Querier) error) error {
+	if s.db == nil {
+		return fn(s.store)
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	if err := s.store.UpdateCampaignStats(ctx, repository.UpdateCampaignStatsParams{
-		ID:                    campaignID,
-		TotalParticipants:     pgtype.Int4{Int32: int32(len(users)), Valid: true},
-		TotalMatchesGenerated: pgtype.Int4{Int32: int32(created), Valid: true},
-	}); err != nil {
-		return created, len(users), err
+	if err := fn(repository.New(tx)); err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
+
+type scoreBreakdown struct {
+	Demographics float64 `json:"demographics"`
+	Personality  float64 `json:"personality"`
+	Values       float64 `json:"values"`
+	Lifestyle    float64 `json:"lifestyle"`
+	Interests    float64 `json:"interests"`
+}
 
-	return created, len(users), nil
+type matchScore struct {
+	Score     float64
+	Breakdown scoreBreakdown
+	IsMutual  bool
+	HasCrush  bool
+	Vetoed    bool
+	Forced    bool
 }
 
 type scoredPair struct {
-	User1 repository.ListEligibleUsersRow
-	User2 repository.ListEligibleUsersRow
+	User1 *repository.ListEligibleUsersRow
+	User2 *repository.ListEligibleUsersRow
 	Score matchScore
 }
 
-func (s *MatchingService) scorePairs(ctx context.Context, pool []repository.ListEligibleUsersRow, campaignID uuid.UUID) []scoredPair {
-	results := []scoredPair{}
-	for i := 0; i < len(pool); i++ {
-		for j := i + 1; j < len(pool); j++ {
-			user1 := pool[i]
-			user2 := pool[j]
-			score := s.calculateCompatibility(ctx, user1, user2, campaignID)
-			results = append(results, scoredPair{User1: user1, User2: user2, Score: score})
-		}
-	}
-	return results
+// matchPlan is the outcome of a matching run before anything is written.
+type matchPlan struct {
+	campaignID uuid.UUID
+	config     MatchingConfig
+	users      []repository.ListEligibleUsersRow
+	candidates int
+	matches    []plannedMatch
+	fairness   FairnessReport
+	blocking   int
 }
 
-func (s *MatchingService) calculateCompatibility(ctx context.Context, user1 repository.ListEligibleUsersRow, user2 repository.ListEligibleUsersRow, campaignID uuid.UUID) matchScore {
-	if !meetsPreferences(user1, user2) {
-		return matchScore{Score: 0, Breakdown: baseBreakdown()}
-	}
-
-	responses1 := s.responsesByCategory(ctx, user1.ID, campaignID)
-	responses2 := s.responsesByCategory(ctx, user2.ID, campaignID)
-
-	demographics := categoryScore(responses1["demographics"], responses2["demographics"], "demographics")
-	personality := categoryScore(responses1["personality"], responses2["personality"], "personality")
-	values := categoryScore(responses1["values"], responses2["values"], "values")
-	lifestyle := categoryScore(responses1["lifestyle"], responses2["lifestyle"], "lifestyle")
-	interests := categoryScore(responses1["interests"], responses2["interests"], "interests")
-
-	score := demographics*weights["demographics"] + personality*weights["personality"] + values*weights["values"] + lifestyle*weights["lifestyle"] + interests*weights["interests"]
-
-	bonus, isMutual, hasCrush := s.crushBonus(ctx, user1.ID, user2.ID, campaignID)
-	if bonus > 1 {
-		score = score * bonus
-	}
-
-	if textValue(user1.Program) != "" && textValue(user1.Program) == textValue(user2.Program) {
-		score += 2
-	}
-	if user1.YearLevel.Valid && user2.YearLevel.Valid && absInt(int(user1.YearLevel.Int32)-int(user2.YearLevel.Int32)) <= 1 {
-		score += 1
-	}
-
-	if score > 100 {
-		score = 100
-	}
+type plannedMatch struct {
+	pair  scoredPair
+	tier  string
+	rank1 int
+	rank2 int
+}
 
-	return matchScore{
-		Score: score,
-		Breakdown: map[string]float64{
-			"demographics": math.Round(demographics),
-			"personality":  math.Round(personality),
-			"values":       math.Round(values),
-			"lifestyle":    math.Round(lifestyle),
-			"interests":    math.Round(interests),
-		},
-		IsMutual: isMutual,
-		HasCrush: hasCrush,
+func (s *MatchingService) loadMatchingConfig(ctx context.Context, campaignID uuid.UUID) (MatchingConfig, error) {
+	campaign, err := s.store.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return MatchingConfig{}, err
 	}
+	return ParseMatchingConfig(campaign.Config)
 }
 
-func (s *MatchingService) responsesByCategory(ctx context.Context, userID uuid.UUID, campaignID uuid.UUID) map[string][]repository.ListSurveyResponsesWithQuestionsByUserCampaignRow {
-	rows, _ := s.store.ListSurveyResponsesWithQuestionsByUserCampaign(ctx, repository.ListSurveyResponsesWithQuestionsByUserCampaignParams{
-		UserID:     userID,
-		CampaignID: pgtype.UUID{Bytes: campaignID, Valid: true},
-	})
-
-	grouped := map[string][]repository.ListSurveyResponsesWithQuestionsByUserCampaignRow{}
-	for _, row := range rows {
-		category := normalizeCategory(row.QuestionCategory)
-		grouped[category] = append(grouped[category], row)
+// planMatches scores and assigns the campaign's eligible users. Stale text
+// vectors are only stored when vectors is textVectorsRebuild.
+func (s *MatchingService) planMatches(ctx context.Context, campaignID uuid.UUID, cfg MatchingConfig, vectors textVectorMode) (*matchPlan, error) {
+	s.progress.setStage(StageLoading)
+	users, err := s.store.ListEligibleUsers(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return grouped
-}
+	users = excludeUnderage(users, cfg.MinAge, time.Now())
 
-func (s *MatchingService) crushBonus(ctx context.Context, user1 uuid.UUID, user2 uuid.UUID, campaignID uuid.UUID) (float64, bool, bool) {
-	user1Email := s.lookupEmail(ctx, user1)
-	user2Email := s.lookupEmail(ctx, user2)
-	if user1Email == "" || user2Email == "" {
-		return 1, false, false
+	idx, err := s.loadScoringIndex(ctx, campaignID, users, cfg, vectors)
+	if err != nil {
+		return nil, err
 	}
 
-	count1, _ := s.store.CountCrushByUserEmailCampaign(ctx, repository.CountCrushByUserEmailCampaignParams{
-		UserID:     user1,
-		CampaignID: campaignID,
-		CrushEmail: user2Email,
-	})
-	count2, _ := s.store.CountCrushByUserEmailCampaign(ctx, repository.CountCrushByUserEmailCampaignParams{
-		UserID:     user2,
-		CampaignID: campaignID,
-		CrushEmail: user1Email,
-	})
-
-	if count1 > 0 && count2 > 0 {
-		return 1.20, true, true
+	pools := buildCandidatePools(users)
+	pairsTotal := int64(0)
+	for _, pool := range pools {
+		pairsTotal += pool.pairs()
 	}
-	if count1 > 0 || count2 > 0 {
-		return 1.10, false, true
+	s.progress.setPairsTotal(pairsTotal)
+	s.progress.setStage(StageScoring)
+
+	// A user can appear in several pools, so pairs are assigned in one pass
+	// over all of them to keep per-user limits global.
+	var scored []scoredPair
+	for _, pool := range pools {
+		poolPairs, err := scorePairs(ctx, idx, pool, cfg.MinScore, s.workers, s.progress)
+		if err != nil {
+			return nil, err
+		}
+		scored = append(scored, poolPairs...)
+	}
+	scored = append(scored, idx.forcedPairs(users)...)
+
+	plan := &matchPlan{campaignID: campaignID, config: cfg, users: users, candidates: len(scored)}
+	userCounts := map[uuid.UUID]int{}
+	for _, pair := range assignPairs(cfg, scored) {
+		userCounts[pair.User1.ID]++
+		userCounts[pair.User2.ID]++
+		plan.matches = append(plan.matches, plannedMatch{
+			pair:  pair,
+			tier:  cfg.Tier(pair.Score.Score),
+			rank1: userCounts[pair.User1.ID],
+			rank2: userCounts[pair.User2.ID],
+		})
 	}
-	return 1, false, false
+	plan.fairness = fairnessReport(users, plan.matches, cfg.MinMatchesPerUser)
+	plan.blocking = blockingPairs(scored, plan.matches, cfg.MaxMatchesPerUser)
+	s.progress.setMatchesPlanned(int64(len(plan.matches)))
+	return plan, nil
 }
 
-func (s *MatchingService) lookupEmail(ctx context.Context, userID uuid.UUID) string {
-	user, err := s.store.GetUserByID(ctx, userID)
-	if err != nil {
-		return ""
-	}
-	return user.Email
+// candidatePool is a block of pairs to score: every user in left with every
+// user in right, or every pair within left when right is nil.
+type candidatePool struct {
+	left  []repository.ListEligibleUsersRow
+	right []repository.ListEligibleUsersRow
 }
 
-func groupKey(user repository.ListEligibleUsersRow) string {
-	seeking := strings.ToLower(textValue(user.SeekingGender))
-	if seeking == "" || seeking == "any" {
-		return "any"
+func (p candidatePool) pairs() int64 {
+	if p.right == nil {
+		return int64(len(p.left)) * int64(len(p.left)-1) / 2
 	}
-	return strings.ToLower(textValue(user.Gender)) + "_" + seeking
+	return int64(len(p.left)) * int64(len(p.right))
 }
 
-func (s *MatchingService) groupByPreference(users []repository.ListEligibleUsersRow) [][]repository.ListEligibleUsersRow {
-	groups := map[string][]repository.ListEligibleUsersRow{}
+// buildCandidatePools groups users with identical gender and seeking sets.
+// Whether two users may be paired depends only on those sets, so checking
+// one representative per pair of groups yields every mutually compatible
+// pair exactly once and nothing else.
+func buildCandidatePools(users []repository.ListEligibleUsersRow) []candidatePool {
+	var groups [][]repository.ListEligibleUsersRow
+	index := map[string]int{}
 	for _, user := range users {
-		key := groupKey(user)
-		groups[key] = append(groups[key], user)
+		user.Genders = sortedSet(user.Genders)
+		user.SeekingGenders = sortedSet(user.SeekingGenders)
+		key := strings.Join(user.Genders, ",") + "|" + strings.Join(user.SeekingGenders, ",")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], user)
 	}
 
-	result := make([][]repository.ListEligibleUsersRow, 0, len(groups))
-	for _, group := range groups {
-		result = append(result, group)
+	var pools []candidatePool
+	for i := range groups {
+		for j := i; j < len(groups); j++ {
+			if !meetsPreferences(groups[i][0], groups[j][0]) {
+				continue
+			}
+			if i == j {
+				pools = append(pools, candidatePool{left: groups[i]})
+			} else {
+				pools = append(pools, candidatePool{left: groups[i], right: groups[j]})
+			}
+		}
 	}
-	return result
+	return pools
 }
 
 func meetsPreferences(user1 repository.ListEligibleUsersRow, user2 repository.ListEligibleUsersRow) bool {
-	user1Seeking := strings.ToLower(textValue(user1.SeekingGender))
-	user2Seeking := strings.ToLower(textValue(user2.SeekingGender))
-	user1Gender := strings.ToLower(textValue(user1.Gender))
-	user2Gender := strings.ToLower(textValue(user2.Gender))
-
-	if user1Seeking != "" && user1Seeking != "any" {
-		if !strings.Contains(user1Seeking, user2Gender) {
-			return false
-		}
-	}
-	if user2Seeking != "" && user2Seeking != "any" {
-		if !strings.Contains(user2Seeking, user1Gender) {
-			return false
-		}
-	}
-	return true
+	return GendersCompatible(user1.Genders, user1.SeekingGenders, user2.Genders, user2.SeekingGenders)
 }
 
 func normalizeCategory(category string) string {
@@ -274,58 +263,6 @@ func normalizeCategory(category string) string {
 	return key
 }
 
-func categoryScore(r1 []repository.ListSurveyResponsesWithQuestionsByUserCampaignRow, r2 []repository.ListSurveyResponsesWithQuestionsByUserCampaignRow, category string) float64 {
-	if len(r1) == 0 || len(r2) == 0 {
-		return 50
-	}
-
-	weightSum := 0.0
-	weightedScore := 0.0
-	for _, resp := range r1 {
-		match := findResponse(r2, resp.QuestionID)
-		if match == nil {
-			continue
-		}
-		weight := numericFloat(resp.QuestionWeight)
-		if weight == 0 {
-			weight = 1
-		}
-		similarity := similarityScore(resp, *match, category)
-		weightedScore += similarity * weight
-		weightSum += weight
-	}
-
-	if weightSum == 0 {
-		return 50
-	}
-	return (weightedScore / weightSum) * 100
-}
-
-func similarityScore(r1 repository.ListSurveyResponsesWithQuestionsByUserCampaignRow, r2 repository.ListSurveyResponsesWithQuestionsByUserCampaignRow, category string) float64 {
-	answerType := r1.AnswerType
-	if answerType == "scale" || answerType == "ranking" {
-		if !r1.AnswerValue.Valid || !r2.AnswerValue.Valid {
-			return 0.5
-		}
-		v1 := float64(r1.AnswerValue.Int32)
-		v2 := float64(r2.AnswerValue.Int32)
-		if category == "personality" || category == "values" {
-			return gaussianSimilarity(v1, v2, 1.5)
-		}
-		return inverseDistance(v1, v2, 10)
-	}
-
-	if answerType == "multiple_choice" {
-		return exactMatch(textValue(r1.AnswerText), textValue(r2.AnswerText))
-	}
-
-	if answerType == "multiple_select" {
-		return jaccardSimilarity(listFromJSON(r1.AnswerJson), listFromJSON(r2.AnswerJson))
-	}
-
-	return 0.5
-}
-
 func gaussianSimilarity(val1 float64, val2 float64, tolerance float64) float64 {
 	diff := math.Abs(val1 - val2)
 	return math.Exp(-(diff * diff) / (2 * tolerance * tolerance))
@@ -345,38 +282,31 @@ func exactMatch(val1 string, val2 string) float64 {
 	return 0
 }
 
+// jaccardSimilarity expects both sets sorted and free of duplicates.
 func jaccardSimilarity(set1 []string, set2 []string) float64 {
 	if len(set1) == 0 || len(set2) == 0 {
 		return 0
 	}
 	intersection := 0
-	set := map[string]struct{}{}
-	for _, v := range set1 {
-		set[v] = struct{}{}
-	}
-	union := len(set)
-	for _, v := range set2 {
-		if _, ok := set[v]; ok {
+	for i, j := 0, 0; i < len(set1) && j < len(set2); {
+		switch {
+		case set1[i] == set2[j]:
 			intersection++
-		} else {
-			union++
+			i++
+			j++
+		case set1[i] < set2[j]:
+			i++
+		default:
+			j++
 		}
 	}
+	union := len(set1) + len(set2) - intersection
 	if union == 0 {
 		return 0
 	}
 	return float64(intersection) / float64(union)
 }
 
-func findResponse(responses []repository.ListSurveyResponsesWithQuestionsByUserCampaignRow, questionID uuid.UUID) *repository.ListSurveyResponsesWithQuestionsByUserCampaignRow {
-	for _, r := range responses {
-		if r.QuestionID == questionID {
-			return &r
-		}
-	}
-	return nil
-}
-
 func listFromJSON(value []byte) []string {
 	if len(value) == 0 {
 		return nil
@@ -404,35 +334,12 @@ func numericFloat(value pgtype.Numeric) float64 {
 	return floatValue.Float64
 }
 
-func baseBreakdown() map[string]float64 {
-	return map[string]float64{
-		"demographics": 0,
-		"personality":  0,
-		"values":       0,
-		"lifestyle":    0,
-		"interests":    0,
-	}
-}
-
-func matchTier(score float64) string {
-	if score >= 95 {
-		return "perfect"
-	}
-	if score >= 85 {
-		return "excellent"
-	}
-	if score >= 75 {
-		return "great"
-	}
-	if score >= 65 {
-		return "good"
-	}
-	return "fair"
-}
-
-func toNumeric(score float64) pgtype.Numeric {
+// NumericFromFloat rounds value to the two decimal places of the NUMERIC
+// columns scores and weights are stored in. pgtype.Numeric only scans
+// strings, so the value goes in as its decimal text.
+func NumericFromFloat(value float64) pgtype.Numeric {
 	var numeric pgtype.Numeric
-	if err := numeric.Scan(score); err != nil {
+	if err := numeric.Scan(strconv.FormatFloat(value, 'f', 2, 64)); err != nil {
 		return pgtype.Numeric{Valid: false}
 	}
 	return numeric
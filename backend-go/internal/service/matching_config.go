@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	StrategyGreedy    = "greedy"
+	StrategyBMatching = "b_matching"
+	StrategyStable    = "stable"
+)
+
+// Rematch policies decide what happens to pairs matched in an earlier
+// campaign. Pairs where either user said not interested or reported the
+// other are never matched again, whatever the policy.
+const (
+	RematchAvoid = "avoid"
+	RematchAllow = "allow"
+	RematchBoost = "boost"
+)
+
+var rematchPolicies = map[string]bool{RematchAvoid: true, RematchAllow: true, RematchBoost: true}
+
+var algorithmVersions = map[string]string{
+	StrategyGreedy:    "greedy-v1",
+	StrategyBMatching: "b_matching-v1",
+	StrategyStable:    "stable-v1",
+}
+
+// MatchingConfig is the "matching" section of campaigns.config.
+type MatchingConfig struct {
+	Strategy            string             `json:"strategy"`
+	MinMatchesPerUser   int                `json:"minMatchesPerUser"`
+	MaxMatchesPerUser   int                `json:"maxMatchesPerUser"`
+	MaxTotalMatches     int                `json:"maxTotalMatches"`
+	MaxSameProgramShare float64            `json:"maxSameProgramShare"`
+	MaxSameYearShare    float64            `json:"maxSameYearShare"`
+	MinScore            float64            `json:"minScore"`
+	MinAge              int                `json:"minAge"`
+	MaxAgeGap           int                `json:"maxAgeGap"`
+	BioWeight           float64            `json:"bioWeight"`
+	RematchPolicy       string             `json:"rematchPolicy"`
+	RematchMultiplier   float64            `json:"rematchMultiplier"`
+	Weights             map[string]float64 `json:"weights"`
+	CrushMultipliers    CrushMultipliers   `json:"crushMultipliers"`
+	Tiers               []MatchTier        `json:"tiers"`
+}
+
+type CrushMultipliers struct {
+	OneSided float64 `json:"oneSided"`
+	Mutual   float64 `json:"mutual"`
+}
+
+// MatchTier labels every match scoring at least MinScore.
+type MatchTier struct {
+	Name     string  `json:"name"`
+	MinScore float64 `json:"minScore"`
+}
+
+type campaignConfig struct {
+	Matching       json.RawMessage `json:"matching"`
+	Messaging      json.RawMessage `json:"messaging"`
+	MatchesPerUser int             `json:"matchesPerUser"`
+	CrushBonus     float64         `json:"crushBonus"`
+}
+
+func DefaultMatchingConfig() MatchingConfig {
+	return MatchingConfig{
+		Strategy:            StrategyGreedy,
+		MinMatchesPerUser:   0,
+		MaxMatchesPerUser:   7,
+		MaxTotalMatches:     10000,
+		MaxSameProgramShare: 0,
+		MaxSameYearShare:    0,
+		MinScore:            50,
+		MinAge:              18,
+		MaxAgeGap:           0,
+		BioWeight:           0.5,
+		RematchPolicy:       RematchAvoid,
+		RematchMultiplier:   1.10,
+		Weights: map[string]float64{
+			"demographics": 0.10,
+			"personality":  0.30,
+			"values":       0.25,
+			"lifestyle":    0.20,
+			"interests":    0.15,
+		},
+		CrushMultipliers: CrushMultipliers{OneSided: 1.10, Mutual: 1.20},
+		Tiers: []MatchTier{
+			{Name: "perfect", MinScore: 95},
+			{Name: "excellent", MinScore: 85},
+			{Name: "great", MinScore: 75},
+			{Name: "good", MinScore: 65},
+			{Name: "fair", MinScore: 0},
+		},
+	}
+}
+
+// ParseMatchingConfig reads the matching section of a campaign config,
+// filling anything left unset with defaults. The legacy top-level
+// "matchesPerUser" and "crushBonus" keys are honored unless the matching
+// section overrides them.
+func ParseMatchingConfig(raw []byte) (MatchingConfig, error) {
+	cfg := DefaultMatchingConfig()
+	if len(raw) == 0 {
+		return cfg, nil
+	}
+
+	var parsed campaignConfig
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return cfg, fmt.Errorf("invalid campaign config: %w", err)
+	}
+	if parsed.MatchesPerUser > 0 {
+		cfg.MaxMatchesPerUser = parsed.MatchesPerUser
+	}
+	if parsed.CrushBonus > 0 {
+		cfg.CrushMultipliers = CrushMultipliers{OneSided: 1 + parsed.CrushBonus/2, Mutual: 1 + parsed.CrushBonus}
+	}
+	if len(parsed.Matching) > 0 && string(parsed.Matching) != "null" {
+		defaults := cfg.Weights
+		cfg.Weights = nil
+		if err := json.Unmarshal(parsed.Matching, &cfg); err != nil {
+			return cfg, fmt.Errorf("invalid matching config: %w", err)
+		}
+		if cfg.Weights == nil {
+			cfg.Weights = defaults
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	sort.SliceStable(cfg.Tiers, func(i, j int) bool {
+		return cfg.Tiers[i].MinScore > cfg.Tiers[j].MinScore
+	})
+	return cfg, nil
+}
+
+func (c MatchingConfig) Validate() error {
+	if _, ok := algorithmVersions[c.Strategy]; !ok {
+		return fmt.Errorf("unknown matching strategy %q", c.Strategy)
+	}
+	if c.MaxMatchesPerUser < 1 {
+		return fmt.Errorf("maxMatchesPerUser must be at least 1")
+	}
+	if c.MinMatchesPerUser < 0 {
+		return fmt.Errorf("minMatchesPerUser must not be negative")
+	}
+	if c.MinMatchesPerUser > c.MaxMatchesPerUser {
+		return fmt.Errorf("minMatchesPerUser (%d) exceeds maxMatchesPerUser (%d)", c.MinMatchesPerUser, c.MaxMatchesPerUser)
+	}
+	if c.MaxTotalMatches < 1 {
+		return fmt.Errorf("maxTotalMatches must be at least 1")
+	}
+	if c.MaxSameProgramShare < 0 || c.MaxSameProgramShare > 1 {
+		return fmt.Errorf("maxSameProgramShare must be between 0 and 1")
+	}
+	if c.MaxSameYearShare < 0 || c.MaxSameYearShare > 1 {
+		return fmt.Errorf("maxSameYearShare must be between 0 and 1")
+	}
+	if c.MinScore < 0 || c.MinScore > 100 {
+		return fmt.Errorf("minScore must be between 0 and 100")
+	}
+	if c.MinAge < 0 {
+		return fmt.Errorf("minAge must not be negative")
+	}
+	if c.MaxAgeGap < 0 {
+		return fmt.Errorf("maxAgeGap must not be negative")
+	}
+	if c.BioWeight < 0 {
+		return fmt.Errorf("bioWeight must not be negative")
+	}
+	if !rematchPolicies[c.RematchPolicy] {
+		return fmt.Errorf("unknown rematch policy %q", c.RematchPolicy)
+	}
+	if c.RematchMultiplier < 1 {
+		return fmt.Errorf("rematchMultiplier must be at least 1")
+	}
+
+	sum := 0.0
+	for category, weight := range c.Weights {
+		if categoryIndex(category) < 0 {
+			return fmt.Errorf("unknown weight category %q", category)
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight for %s must not be negative", category)
+		}
+		sum += weight
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		return fmt.Errorf("weights must sum to 1, got %g", sum)
+	}
+
+	if c.CrushMultipliers.OneSided < 1 || c.CrushMultipliers.Mutual < 1 {
+		return fmt.Errorf("crush multipliers must be at least 1")
+	}
+	if c.CrushMultipliers.Mutual < c.CrushMultipliers.OneSided {
+		return fmt.Errorf("mutual crush multiplier must not be lower than the one-sided multiplier")
+	}
+
+	if len(c.Tiers) == 0 {
+		return fmt.Errorf("at least one match tier is required")
+	}
+	names := map[string]bool{}
+	hasFloor := false
+	for _, tier := range c.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("match tier name is required")
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("duplicate match tier %q", tier.Name)
+		}
+		names[tier.Name] = true
+		if tier.MinScore < 0 || tier.MinScore > 100 {
+			return fmt.Errorf("minScore for tier %s must be between 0 and 100", tier.Name)
+		}
+		if tier.MinScore == 0 {
+			hasFloor = true
+		}
+	}
+	if !hasFloor {
+		return fmt.Errorf("one match tier must start at 0")
+	}
+	return nil
+}
+
+func (c MatchingConfig) AlgorithmVersion() string {
+	return algorithmVersions[c.Strategy]
+}
+
+// Tier returns the name of the highest tier the score reaches. Tiers are
+// kept sorted by ParseMatchingConfig.
+func (c MatchingConfig) Tier(score float64) string {
+	for _, tier := range c.Tiers {
+		if score >= tier.MinScore {
+			return tier.Name
+		}
+	}
+	return c.Tiers[len(c.Tiers)-1].Name
+}
+
+// categoryWeights returns the weights indexed like scoringCategories.
+func (c MatchingConfig) categoryWeights() []float64 {
+	result := make([]float64, len(scoringCategories))
+	for i, category := range scoringCategories {
+		result[i] = c.Weights[category]
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package service
+
+import "testing"
+
+func TestParseMatchingConfig(t *testing.T) {
+	cfg, err := ParseMatchingConfig([]byte(`{"matchesPerUser": 5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Strategy != StrategyGreedy || cfg.MaxMatchesPerUser != 5 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = ParseMatchingConfig([]byte(`{"matching": {"strategy": "stable"}}`))
+	if err != nil || cfg.AlgorithmVersion() != "stable-v1" {
+		t.Fatalf("expected stable strategy, got %+v (%v)", cfg, err)
+	}
+
+	if _, err := ParseMatchingConfig([]byte(`{"matching": {"strategy": "random"}}`)); err == nil {
+		t.Fatalf("expected unknown strategy to fail")
+	}
+}
+
+func TestParseMatchingConfigOverrides(t *testing.T) {
+	cfg, err := ParseMatchingConfig([]byte(`{"crushBonus": 0.3, "matching": {
+		"minScore": 40,
+		"maxTotalMatches": 500,
+		"weights": {"personality": 0.5, "values": 0.5},
+		"tiers": [{"name": "low", "minScore": 0}, {"name": "high", "minScore": 80}]
+	}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MinScore != 40 || cfg.MaxTotalMatches != 500 || cfg.MaxMatchesPerUser != 7 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Weights["demographics"] != 0 || cfg.Weights["personality"] != 0.5 {
+		t.Fatalf("expected weights to be replaced, got %v", cfg.Weights)
+	}
+	if cfg.CrushMultipliers.Mutual != 1.3 || cfg.CrushMultipliers.OneSided != 1.15 {
+		t.Fatalf("expected legacy crush bonus to apply, got %+v", cfg.CrushMultipliers)
+	}
+	if cfg.Tier(85) != "high" || cfg.Tier(79) != "low" {
+		t.Fatalf("expected tiers to be sorted by threshold, got %v", cfg.Tiers)
+	}
+}
+
+func TestParseMatchingConfigRejectsInvalid(t *testing.T) {
+	invalid := []string{
+		`{"matching": {"weights": {"personality": 0.5, "values": 0.3}}}`,
+		`{"matching": {"weights": {"personality": 0.5, "astrology": 0.5}}}`,
+		`{"matching": {"minScore": 120}}`,
+		`{"matching": {"maxTotalMatches": 0}}`,
+		`{"matching": {"crushMultipliers": {"oneSided": 1.3, "mutual": 1.1}}}`,
+		`{"matching": {"tiers": [{"name": "great", "minScore": 70}]}}`,
+		`{"matching": {"tiers": [{"name": "fair", "minScore": 0}, {"name": "fair", "minScore": 50}]}}`,
+		`{"matching": {"rematchPolicy": "prefer"}}`,
+		`{"matching": {"maxSameProgramShare": 1.5}}`,
+		`{"matching": {"rematchPolicy": "boost", "rematchMultiplier": 0.9}}`,
+	}
+	for _, raw := range invalid {
+		if _, err := ParseMatchingConfig([]byte(raw)); err == nil {
+			t.Fatalf("expected %s to be rejected", raw)
+		}
+	}
+}
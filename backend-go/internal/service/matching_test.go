@@ -1,21 +1,45 @@
 package service
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestMatchTier(t *testing.T) {
-	if matchTier(96) != "perfect" {
+	cfg := DefaultMatchingConfig()
+	if cfg.Tier(96) != "perfect" {
 		t.Fatalf("expected perfect tier")
 	}
-	if matchTier(86) != "excellent" {
+	if cfg.Tier(86) != "excellent" {
 		t.Fatalf("expected excellent tier")
 	}
-	if matchTier(76) != "great" {
+	if cfg.Tier(76) != "great" {
 		t.Fatalf("expected great tier")
 	}
-	if matchTier(66) != "good" {
+	if cfg.Tier(66) != "good" {
 		t.Fatalf("expected good tier")
 	}
-	if matchTier(50) != "fair" {
+	if cfg.Tier(50) != "fair" {
 		t.Fatalf("expected fair tier")
 	}
 }
+
+func TestNumericFromFloat(t *testing.T) {
+	for _, tc := range []struct {
+		value float64
+		want  float64
+	}{
+		{87.456, 87.46},
+		{100, 100},
+		{0, 0},
+		{0.5, 0.5},
+	} {
+		numeric := NumericFromFloat(tc.value)
+		if !numeric.Valid {
+			t.Fatalf("expected %v to make a valid numeric", tc.value)
+		}
+		if got := numericFloat(numeric); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("expected %v to round-trip as %v, got %v", tc.value, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Unlock rules decide how a match is opened for messaging. Under
+// UnlockOnMutualInterest a match opens once both users said they are
+// interested; under UnlockByAdmin only an organizer's bulk unlock opens it.
+const (
+	UnlockOnMutualInterest = "mutual_interest"
+	UnlockByAdmin          = "admin"
+)
+
+// Unlock reasons recorded on matches.messaging_unlock_reason.
+const (
+	UnlockReasonMutualInterest = "mutual_interest"
+	UnlockReasonAdmin          = "admin"
+)
+
+var unlockRules = map[string]bool{UnlockOnMutualInterest: true, UnlockByAdmin: true}
+
+// MessagingPolicy is the "messaging" section of campaigns.config. Phases
+// lists the campaign phases during which unlocked matches may message; left
+// empty, the campaign's send_messages permission decides.
+type MessagingPolicy struct {
+	Unlock string   `json:"unlock"`
+	Phases []string `json:"phases"`
+}
+
+func DefaultMessagingPolicy() MessagingPolicy {
+	return MessagingPolicy{Unlock: UnlockOnMutualInterest}
+}
+
+// ParseMessagingPolicy reads the messaging section of a campaign config,
+// filling anything left unset with defaults.
+func ParseMessagingPolicy(raw []byte) (MessagingPolicy, error) {
+	policy := DefaultMessagingPolicy()
+	if len(raw) == 0 {
+		return policy, nil
+	}
+
+	var parsed campaignConfig
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return policy, fmt.Errorf("invalid campaign config: %w", err)
+	}
+	if len(parsed.Messaging) > 0 && string(parsed.Messaging) != "null" {
+		if err := json.Unmarshal(parsed.Messaging, &policy); err != nil {
+			return policy, fmt.Errorf("invalid messaging config: %w", err)
+		}
+	}
+	return policy, policy.Validate()
+}
+
+func (p MessagingPolicy) Validate() error {
+	if !unlockRules[p.Unlock] {
+		return fmt.Errorf("unknown messaging unlock rule %q", p.Unlock)
+	}
+	for _, phase := range p.Phases {
+		if !slices.Contains(campaignPhases, phase) {
+			return fmt.Errorf("unknown campaign phase %q", phase)
+		}
+	}
+	return nil
+}
+
+// UnlocksOnMutualInterest reports whether mutual interest opens a match.
+func (p MessagingPolicy) UnlocksOnMutualInterest() bool {
+	return p.Unlock == UnlockOnMutualInterest
+}
@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestParseMessagingPolicy(t *testing.T) {
+	policy, err := ParseMessagingPolicy([]byte(`{"matchesPerUser": 5}`))
+	if err != nil || !policy.UnlocksOnMutualInterest() || len(policy.Phases) != 0 {
+		t.Fatalf("expected the default policy, got %+v (%v)", policy, err)
+	}
+
+	policy, err = ParseMessagingPolicy([]byte(`{"messaging": {"unlock": "admin", "phases": ["results_released"]}}`))
+	if err != nil || policy.UnlocksOnMutualInterest() || policy.Phases[0] != "results_released" {
+		t.Fatalf("unexpected policy: %+v (%v)", policy, err)
+	}
+
+	for _, raw := range []string{
+		`{"messaging": {"unlock": "always"}}`,
+		`{"messaging": {"phases": ["after_party"]}}`,
+		`{"messaging": []}`,
+	} {
+		if _, err := ParseMessagingPolicy([]byte(raw)); err == nil {
+			t.Fatalf("expected %s to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Importance levels a user can give their answer, mirroring how much they
+// care about the other person's answer to the same question. A mandatory
+// question with acceptable answers is a dealbreaker.
+const (
+	ImportanceIrrelevant = "irrelevant"
+	ImportanceALittle    = "a_little"
+	ImportanceSomewhat   = "somewhat"
+	ImportanceVery       = "very"
+	ImportanceMandatory  = "mandatory"
+)
+
+var importanceWeights = map[string]float64{
+	ImportanceIrrelevant: 0,
+	ImportanceALittle:    1,
+	ImportanceSomewhat:   10,
+	ImportanceVery:       50,
+	ImportanceMandatory:  250,
+}
+
+func ValidImportance(importance string) bool {
+	_, ok := importanceWeights[importance]
+	return ok
+}
+
+// importanceWeight treats a missing importance as the column default.
+func importanceWeight(importance string) float64 {
+	if weight, ok := importanceWeights[importance]; ok {
+		return weight
+	}
+	return importanceWeights[ImportanceSomewhat]
+}
+
+// acceptableFromJSON decodes the acceptable answers stored with a response.
+// Options are strings and scale values numbers; both are kept as strings so
+// they compare against answer text and formatted answer values alike.
+func acceptableFromJSON(value []byte) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	var raw []any
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil
+	}
+	result := make([]string, 0, len(raw))
+	for _, item := range raw {
+		switch v := item.(type) {
+		case string:
+			result = append(result, v)
+		case float64:
+			result = append(result, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	return result
+}
+
+// accepts reports whether partner's answer is one chooser finds acceptable.
+// A multi-select answer is acceptable when any of its options is.
+func accepts(chooser answer, partner answer) bool {
+	switch partner.AnswerType {
+	case "multiple_select":
+		return sharesAny(chooser.Acceptable, partner.List)
+	case "scale", "ranking":
+		return partner.Value.Valid && containsSorted(chooser.Acceptable, strconv.Itoa(int(partner.Value.Int32)))
+	}
+	return containsSorted(chooser.Acceptable, partner.Text)
+}
+
+// satisfaction is how happy chooser is with partner's answer: all or nothing
+// when chooser picked acceptable answers, the plain similarity otherwise.
+func satisfaction(chooser answer, partner answer, similarity float64) float64 {
+	if len(chooser.Acceptable) == 0 {
+		return similarity
+	}
+	if accepts(chooser, partner) {
+		return 1
+	}
+	return 0
+}
+
+// violatesDealbreaker reports whether either user answered a question in a
+// way the other marked as unacceptable and mandatory. Questions only one of
+// them answered never veto the pair.
+func violatesDealbreaker(r1 *answerSet, r2 *answerSet) bool {
+	if r1 == nil || r2 == nil {
+		return false
+	}
+	return rejects(r1, r2) || rejects(r2, r1)
+}
+
+func rejects(chooser *answerSet, partner *answerSet) bool {
+	for _, dealbreaker := range chooser.dealbreakers {
+		list := partner.byCategory[dealbreaker.category]
+		i := sort.Search(len(list), func(i int) bool { return list[i].question >= dealbreaker.question })
+		if i < len(list) && list[i].question == dealbreaker.question && !accepts(dealbreaker, list[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSorted(set []string, value string) bool {
+	i := sort.SearchStrings(set, value)
+	return i < len(set) && set[i] == value
+}
+
+// sharesAny reports whether two sorted sets have a value in common.
+func sharesAny(set1 []string, set2 []string) bool {
+	for i, j := 0, 0; i < len(set1) && j < len(set2); {
+		switch {
+		case set1[i] == set2[j]:
+			return true
+		case set1[i] < set2[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return false
+}
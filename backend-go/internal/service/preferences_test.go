@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+func TestAcceptableFromJSON(t *testing.T) {
+	got := acceptableFromJSON([]byte(`["cat", 3, 4.5, null]`))
+	if len(got) != 3 || got[0] != "cat" || got[1] != "3" || got[2] != "4.5" {
+		t.Fatalf("unexpected acceptable answers: %v", got)
+	}
+}
+
+func TestDealbreakerVetoesPair(t *testing.T) {
+	idx := newScoringIndex(DefaultMatchingConfig())
+	user1 := repository.ListEligibleUsersRow{ID: uuid.New()}
+	user2 := repository.ListEligibleUsersRow{ID: uuid.New()}
+	smoking := uuid.New()
+
+	idx.addAnswer(user1.ID, answer{QuestionID: smoking, Category: "lifestyle", AnswerType: "multiple_choice", Text: "never", Acceptable: []string{"never"}, Importance: ImportanceMandatory})
+	idx.addAnswer(user2.ID, answer{QuestionID: smoking, Category: "lifestyle", AnswerType: "multiple_choice", Text: "daily"})
+	if score := idx.calculateCompatibility(&user1, &user2); !score.Vetoed {
+		t.Fatalf("expected the dealbreaker to veto the pair, got %+v", score)
+	}
+	if score := idx.calculateCompatibility(&user2, &user1); !score.Vetoed {
+		t.Fatalf("expected the veto to apply regardless of order, got %+v", score)
+	}
+
+	pairs, err := scorePairs(context.Background(), idx, candidatePool{left: []repository.ListEligibleUsersRow{user1, user2}}, 0, 1, nil)
+	if err != nil || len(pairs) != 0 {
+		t.Fatalf("expected vetoed pair to be dropped, got %v %v", pairs, err)
+	}
+}
+
+func TestImportanceWeightsEachSide(t *testing.T) {
+	pet, outgoing := uuid.New(), uuid.New()
+	lifestyle := categoryIndex("lifestyle")
+	score := func(outgoingValue int32) float64 {
+		idx := newScoringIndex(DefaultMatchingConfig())
+		user1, user2 := uuid.New(), uuid.New()
+		// user1 only cares about pets and accepts user2's answer; user2 only
+		// cares about how outgoing user1 is.
+		idx.addAnswer(user1, answer{QuestionID: pet, Category: "lifestyle", AnswerType: "multiple_choice", Text: "cat", Acceptable: []string{"cat", "dog"}, Importance: ImportanceVery})
+		idx.addAnswer(user2, answer{QuestionID: pet, Category: "lifestyle", AnswerType: "multiple_choice", Text: "dog", Importance: ImportanceIrrelevant})
+		idx.addAnswer(user1, answer{QuestionID: outgoing, Category: "lifestyle", AnswerType: "scale", Value: pgtype.Int4{Int32: outgoingValue, Valid: true}, Importance: ImportanceIrrelevant})
+		idx.addAnswer(user2, answer{QuestionID: outgoing, Category: "lifestyle", AnswerType: "scale", Value: pgtype.Int4{Int32: 5, Valid: true}, Acceptable: []string{"4", "5"}, Importance: ImportanceVery})
+		return categoryScore(idx.answers[user1], idx.answers[user2], lifestyle)
+	}
+
+	if got := score(1); got != 0 {
+		t.Fatalf("expected user2's unmet preference to zero the category, got %v", got)
+	}
+	if got := score(4); got != 100 {
+		t.Fatalf("expected both preferences to be met, got %v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+const histogramBucketWidth = 10
+
+// MatchPreview describes what a matching run would write, compared with the
+// matches currently stored for the campaign.
+type MatchPreview struct {
+	CampaignID         uuid.UUID         `json:"campaignId"`
+	AlgorithmVersion   string            `json:"algorithmVersion"`
+	Config             MatchingConfig    `json:"config"`
+	TotalUsers         int               `json:"totalUsers"`
+	CandidatePairs     int               `json:"candidatePairs"`
+	Matches            int               `json:"matches"`
+	MutualCrushMatches int               `json:"mutualCrushMatches"`
+	AverageScore       float64           `json:"averageScore"`
+	ScoreHistogram     []HistogramBucket `json:"scoreHistogram"`
+	TierDistribution   map[string]int    `json:"tierDistribution"`
+	UnmatchedUsers     []uuid.UUID       `json:"unmatchedUsers"`
+	Fairness           FairnessReport    `json:"fairness"`
+	BlockingPairs      int               `json:"blockingPairs"`
+	Diff               MatchDiff         `json:"diff"`
+}
+
+type HistogramBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// MatchDiff compares planned pairs with stored ones. A pair is kept when the
+// same two users are matched in both, regardless of order.
+type MatchDiff struct {
+	Existing     int `json:"existing"`
+	Kept         int `json:"kept"`
+	Added        int `json:"added"`
+	Removed      int `json:"removed"`
+	ScoreChanged int `json:"scoreChanged"`
+	TierChanged  int `json:"tierChanged"`
+}
+
+// PreviewMatches runs the full matching pipeline for the campaign without
+// touching stored matches and reports what publishing a new run would do.
+func (s *MatchingService) PreviewMatches(ctx context.Context, campaignID uuid.UUID) (*MatchPreview, error) {
+	cfg, err := s.loadMatchingConfig(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planMatches(ctx, campaignID, cfg, textVectorsFresh)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListMatchesByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	return buildPreview(plan, existing), nil
+}
+
+func buildPreview(plan *matchPlan, existing []repository.Match) *MatchPreview {
+	preview := &MatchPreview{
+		CampaignID:       plan.campaignID,
+		AlgorithmVersion: plan.config.AlgorithmVersion(),
+		Config:           plan.config,
+		TotalUsers:       len(plan.users),
+		CandidatePairs:   plan.candidates,
+		Matches:          len(plan.matches),
+		ScoreHistogram:   make([]HistogramBucket, 100/histogramBucketWidth),
+		TierDistribution: map[string]int{},
+		UnmatchedUsers:   []uuid.UUID{},
+		Fairness:         plan.fairness,
+		BlockingPairs:    plan.blocking,
+	}
+	for i := range preview.ScoreHistogram {
+		preview.ScoreHistogram[i].Min = float64(i * histogramBucketWidth)
+		preview.ScoreHistogram[i].Max = float64((i + 1) * histogramBucketWidth)
+	}
+	for _, tier := range plan.config.Tiers {
+		preview.TierDistribution[tier.Name] = 0
+	}
+
+	matched := map[uuid.UUID]bool{}
+	planned := map[[2]uuid.UUID]plannedMatch{}
+	total := 0.0
+	for _, match := range plan.matches {
+		score := match.pair.Score.Score
+		total += score
+		if match.pair.Score.IsMutual {
+			preview.MutualCrushMatches++
+		}
+		bucket := int(score) / histogramBucketWidth
+		if bucket >= len(preview.ScoreHistogram) {
+			bucket = len(preview.ScoreHistogram) - 1
+		}
+		if bucket < 0 {
+			bucket = 0
+		}
+		preview.ScoreHistogram[bucket].Count++
+		preview.TierDistribution[match.tier]++
+		matched[match.pair.User1.ID] = true
+		matched[match.pair.User2.ID] = true
+		planned[pairKey(match.pair.User1.ID, match.pair.User2.ID)] = match
+	}
+	if len(plan.matches) > 0 {
+		preview.AverageScore = math.Round(total/float64(len(plan.matches))*100) / 100
+	}
+	for _, user := range plan.users {
+		if !matched[user.ID] {
+			preview.UnmatchedUsers = append(preview.UnmatchedUsers, user.ID)
+		}
+	}
+
+	preview.Diff.Existing = len(existing)
+	seen := map[[2]uuid.UUID]bool{}
+	for _, match := range existing {
+		key := pairKey(match.User1ID, match.User2ID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		next, ok := planned[key]
+		if !ok {
+			preview.Diff.Removed++
+			continue
+		}
+		preview.Diff.Kept++
+		if math.Abs(numericFloat(match.CompatibilityScore)-next.pair.Score.Score) >= 0.01 {
+			preview.Diff.ScoreChanged++
+		}
+		if textValue(match.MatchTier) != next.tier {
+			preview.Diff.TierChanged++
+		}
+	}
+	preview.Diff.Added = len(planned) - preview.Diff.Kept
+	return preview
+}
+
+func pairKey(user1 uuid.UUID, user2 uuid.UUID) [2]uuid.UUID {
+	if user1.String() > user2.String() {
+		return [2]uuid.UUID{user2, user1}
+	}
+	return [2]uuid.UUID{user1, user2}
+}
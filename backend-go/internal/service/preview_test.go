@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestBuildPreview(t *testing.T) {
+	u := testUsers(5)
+	a, b, c, d := u[0], u[1], u[2], u[3]
+	cfg := DefaultMatchingConfig()
+	plan := &matchPlan{config: cfg, candidates: 4}
+	for _, user := range u {
+		plan.users = append(plan.users, *user)
+	}
+	for _, pair := range []scoredPair{testPair(a, b, 96), testPair(c, d, 72.5)} {
+		plan.matches = append(plan.matches, plannedMatch{pair: pair, tier: cfg.Tier(pair.Score.Score)})
+	}
+
+	existing := []repository.Match{
+		{User1ID: b.ID, User2ID: a.ID, CompatibilityScore: NumericFromFloat(90), MatchTier: pgtype.Text{String: "excellent", Valid: true}},
+		{User1ID: a.ID, User2ID: c.ID, CompatibilityScore: NumericFromFloat(80), MatchTier: pgtype.Text{String: "great", Valid: true}},
+	}
+
+	preview := buildPreview(plan, existing)
+	if preview.Matches != 2 || preview.AverageScore != 84.25 {
+		t.Fatalf("unexpected totals: %+v", preview)
+	}
+	if preview.ScoreHistogram[9].Count != 1 || preview.ScoreHistogram[7].Count != 1 {
+		t.Fatalf("unexpected histogram: %+v", preview.ScoreHistogram)
+	}
+	if preview.TierDistribution["perfect"] != 1 || preview.TierDistribution["good"] != 1 || preview.TierDistribution["fair"] != 0 {
+		t.Fatalf("unexpected tiers: %v", preview.TierDistribution)
+	}
+	if len(preview.UnmatchedUsers) != 1 || preview.UnmatchedUsers[0] != u[4].ID {
+		t.Fatalf("expected the fifth user to be unmatched, got %v", preview.UnmatchedUsers)
+	}
+	diff := preview.Diff
+	if diff.Existing != 2 || diff.Kept != 1 || diff.Added != 1 || diff.Removed != 1 || diff.ScoreChanged != 1 || diff.TierChanged != 1 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+
+	// Pairs stored with the score and tier they would get again count as
+	// kept and unchanged.
+	unchanged := buildPreview(plan, []repository.Match{
+		{User1ID: a.ID, User2ID: b.ID, CompatibilityScore: NumericFromFloat(96), MatchTier: pgtype.Text{String: "perfect", Valid: true}},
+		{User1ID: d.ID, User2ID: c.ID, CompatibilityScore: NumericFromFloat(72.5), MatchTier: pgtype.Text{String: "good", Valid: true}},
+	}).Diff
+	if unchanged.Kept != 2 || unchanged.Added != 0 || unchanged.ScoreChanged != 0 || unchanged.TierChanged != 0 {
+		t.Fatalf("expected both pairs kept unchanged, got %+v", unchanged)
+	}
+}
+
+func TestPreviewLeavesTextVectorsAlone(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 0)
+	for i, bio := range []string{"Film photography and ramen", "Ramen and film cameras"} {
+		gender, seeking := GenderWoman, GenderMan
+		if i == 1 {
+			gender, seeking = seeking, gender
+		}
+		if _, err := store.CreateUser(ctx, repository.CreateUserParams{
+			Email:           fmt.Sprintf("bio%d@example.com", i),
+			IsActive:        true,
+			SurveyCompleted: true,
+			Genders:         []string{gender},
+			SeekingGenders:  []string{seeking},
+			Bio:             pgtype.Text{String: bio, Valid: true},
+			DateOfBirth:     birthday(2000, time.January, 1),
+		}); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	matcher := NewMatchingService(store, nil)
+
+	if _, err := matcher.PreviewMatches(ctx, campaign.ID); err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if vectors, _ := store.ListTextVectorsByCampaign(ctx, campaign.ID); len(vectors) != 0 {
+		t.Fatalf("expected a preview to store no text vectors, got %d", len(vectors))
+	}
+	if _, err := matcher.CreateMatchRun(ctx, campaign.ID, pgtype.UUID{}); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if vectors, _ := store.ListTextVectorsByCampaign(ctx, campaign.ID); len(vectors) != 1 {
+		t.Fatalf("expected a run to store the bio vectors, got %d", len(vectors))
+	}
+}
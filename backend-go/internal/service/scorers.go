@@ -0,0 +1,275 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Scorer compares two answers to the same question and returns a similarity
+// between 0 and 1. Answers arrive already decoded and with lists sorted.
+type Scorer interface {
+	Similarity(a *answer, b *answer) float64
+}
+
+// ScorerFactory builds a Scorer from a question's scoring spec. It receives
+// the whole spec object so each scorer can decode its own parameters.
+type ScorerFactory func(spec json.RawMessage) (Scorer, error)
+
+var scorerRegistry = map[string]ScorerFactory{}
+
+// RegisterScorer makes a scorer available to questions under the given type
+// name, e.g. {"type": "complementary", "min": 1, "max": 5}.
+func RegisterScorer(name string, factory ScorerFactory) {
+	if _, exists := scorerRegistry[name]; exists {
+		panic(fmt.Sprintf("scorer %q registered twice", name))
+	}
+	scorerRegistry[name] = factory
+}
+
+func init() {
+	RegisterScorer("gaussian", newGaussianScorer)
+	RegisterScorer("distance", newDistanceScorer)
+	RegisterScorer("ordinal", newOrdinalScorer)
+	RegisterScorer("complementary", newComplementaryScorer)
+	RegisterScorer("exact", func(json.RawMessage) (Scorer, error) { return exactScorer{}, nil })
+	RegisterScorer("jaccard", func(json.RawMessage) (Scorer, error) { return jaccardScorer{}, nil })
+	RegisterScorer("matrix", newMatrixScorer)
+	RegisterScorer("text", func(json.RawMessage) (Scorer, error) { return freeTextScorer, nil })
+}
+
+// ParseScoring builds the scorer declared in a question's scoring column. An
+// empty spec returns nil, meaning the question uses the default scorer.
+func ParseScoring(raw []byte) (Scorer, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var spec struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("invalid scoring spec: %w", err)
+	}
+	factory, ok := scorerRegistry[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown scorer %q", spec.Type)
+	}
+	return factory(raw)
+}
+
+var (
+	neutral          Scorer = neutralScorer{}
+	traitScorer      Scorer = gaussianScorer{tolerance: 1.5}
+	scaleScorer      Scorer = distanceScorer{max: 10}
+	choiceScorer     Scorer = exactScorer{}
+	selectionScorer  Scorer = jaccardScorer{}
+	freeTextScorer   Scorer = textScorer{}
+	scaleAnswerTypes        = map[string]bool{"scale": true, "ranking": true}
+)
+
+// defaultScorer is used for questions without a scoring spec. Personality and
+// values scales forgive small differences; other scales score by distance.
+func defaultScorer(answerType string, category string) Scorer {
+	switch {
+	case scaleAnswerTypes[answerType] && (category == "personality" || category == "values"):
+		return traitScorer
+	case scaleAnswerTypes[answerType]:
+		return scaleScorer
+	case answerType == "multiple_choice":
+		return choiceScorer
+	case answerType == "multiple_select":
+		return selectionScorer
+	case answerType == "text":
+		return freeTextScorer
+	}
+	return neutral
+}
+
+// questionScorer resolves the scorer for a question, falling back to the
+// default when the spec is missing or no longer valid.
+func questionScorer(a answer) Scorer {
+	if scorer, err := ParseScoring(a.Scoring); err == nil && scorer != nil {
+		return scorer
+	}
+	return defaultScorer(a.AnswerType, a.Category)
+}
+
+type neutralScorer struct{}
+
+func (neutralScorer) Similarity(*answer, *answer) float64 {
+	return 0.5
+}
+
+// numericValues returns both answer values, or false when either user left
+// the scale blank.
+func numericValues(a *answer, b *answer) (float64, float64, bool) {
+	if !a.Value.Valid || !b.Value.Valid {
+		return 0, 0, false
+	}
+	return float64(a.Value.Int32), float64(b.Value.Int32), true
+}
+
+type gaussianScorer struct {
+	tolerance float64
+}
+
+func newGaussianScorer(spec json.RawMessage) (Scorer, error) {
+	var params struct {
+		Tolerance float64 `json:"tolerance"`
+	}
+	if err := json.Unmarshal(spec, &params); err != nil {
+		return nil, err
+	}
+	if params.Tolerance <= 0 {
+		return nil, fmt.Errorf("gaussian scorer needs a positive tolerance")
+	}
+	return gaussianScorer{tolerance: params.Tolerance}, nil
+}
+
+func (s gaussianScorer) Similarity(a *answer, b *answer) float64 {
+	v1, v2, ok := numericValues(a, b)
+	if !ok {
+		return 0.5
+	}
+	return gaussianSimilarity(v1, v2, s.tolerance)
+}
+
+type distanceScorer struct {
+	max float64
+}
+
+func newDistanceScorer(spec json.RawMessage) (Scorer, error) {
+	var params struct {
+		Max float64 `json:"max"`
+	}
+	if err := json.Unmarshal(spec, &params); err != nil {
+		return nil, err
+	}
+	if params.Max <= 0 {
+		return nil, fmt.Errorf("distance scorer needs a positive max")
+	}
+	return distanceScorer{max: params.Max}, nil
+}
+
+func (s distanceScorer) Similarity(a *answer, b *answer) float64 {
+	v1, v2, ok := numericValues(a, b)
+	if !ok {
+		return 0.5
+	}
+	return inverseDistance(v1, v2, s.max)
+}
+
+// scaleRange is the min and max a scale or ranking answer can take.
+type scaleRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+func parseScaleRange(spec json.RawMessage, name string) (scaleRange, error) {
+	var params scaleRange
+	if err := json.Unmarshal(spec, &params); err != nil {
+		return params, err
+	}
+	if params.Max <= params.Min {
+		return params, fmt.Errorf("%s scorer needs max greater than min", name)
+	}
+	return params, nil
+}
+
+// spread is the distance between two values as a fraction of the range.
+func (r scaleRange) spread(v1 float64, v2 float64) float64 {
+	return math.Min(math.Abs(v1-v2)/(r.Max-r.Min), 1)
+}
+
+// ordinalScorer compares ranks: adjacent positions are close, opposite ends
+// of the range share nothing.
+type ordinalScorer struct {
+	scale scaleRange
+}
+
+func newOrdinalScorer(spec json.RawMessage) (Scorer, error) {
+	scale, err := parseScaleRange(spec, "ordinal")
+	if err != nil {
+		return nil, err
+	}
+	return ordinalScorer{scale: scale}, nil
+}
+
+func (s ordinalScorer) Similarity(a *answer, b *answer) float64 {
+	v1, v2, ok := numericValues(a, b)
+	if !ok {
+		return 0.5
+	}
+	return 1 - s.scale.spread(v1, v2)
+}
+
+// complementaryScorer is for questions where opposite answers go well
+// together, such as planner versus spontaneous.
+type complementaryScorer struct {
+	scale scaleRange
+}
+
+func newComplementaryScorer(spec json.RawMessage) (Scorer, error) {
+	scale, err := parseScaleRange(spec, "complementary")
+	if err != nil {
+		return nil, err
+	}
+	return complementaryScorer{scale: scale}, nil
+}
+
+func (s complementaryScorer) Similarity(a *answer, b *answer) float64 {
+	v1, v2, ok := numericValues(a, b)
+	if !ok {
+		return 0.5
+	}
+	return s.scale.spread(v1, v2)
+}
+
+type exactScorer struct{}
+
+func (exactScorer) Similarity(a *answer, b *answer) float64 {
+	return exactMatch(a.Text, b.Text)
+}
+
+type jaccardScorer struct{}
+
+func (jaccardScorer) Similarity(a *answer, b *answer) float64 {
+	return jaccardSimilarity(a.List, b.List)
+}
+
+// matrixScorer looks up how well two multiple-choice options go together.
+// The matrix only needs one direction of each pair; identical answers score
+// 1 and unlisted pairs 0 unless the matrix says otherwise.
+type matrixScorer struct {
+	matrix map[string]map[string]float64
+}
+
+func newMatrixScorer(spec json.RawMessage) (Scorer, error) {
+	var params struct {
+		Matrix map[string]map[string]float64 `json:"matrix"`
+	}
+	if err := json.Unmarshal(spec, &params); err != nil {
+		return nil, err
+	}
+	if len(params.Matrix) == 0 {
+		return nil, fmt.Errorf("matrix scorer needs a matrix")
+	}
+	for from, row := range params.Matrix {
+		for to, value := range row {
+			if value < 0 || value > 1 {
+				return nil, fmt.Errorf("matrix similarity for %q and %q must be between 0 and 1", from, to)
+			}
+		}
+	}
+	return matrixScorer{matrix: params.Matrix}, nil
+}
+
+func (s matrixScorer) Similarity(a *answer, b *answer) float64 {
+	if value, ok := s.matrix[a.Text][b.Text]; ok {
+		return value
+	}
+	if value, ok := s.matrix[b.Text][a.Text]; ok {
+		return value
+	}
+	return exactMatch(a.Text, b.Text)
+}
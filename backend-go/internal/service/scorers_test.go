@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func scaleAnswer(value int32) *answer {
+	return &answer{AnswerType: "scale", Value: pgtype.Int4{Int32: value, Valid: true}}
+}
+
+func choiceAnswer(text string) *answer {
+	return &answer{AnswerType: "multiple_choice", Text: text}
+}
+
+func mustParseScoring(t *testing.T, spec string) Scorer {
+	t.Helper()
+	scorer, err := ParseScoring([]byte(spec))
+	if err != nil || scorer == nil {
+		t.Fatalf("parse %s: %v", spec, err)
+	}
+	return scorer
+}
+
+func TestParseScoringRejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{
+		`{"type": "telepathy"}`,
+		`{"type": "complementary", "min": 5, "max": 1}`,
+		`{"type": "gaussian"}`,
+		`{"type": "matrix", "matrix": {"cat": {"dog": 2}}}`,
+		`[1, 2]`,
+	} {
+		if _, err := ParseScoring([]byte(spec)); err == nil {
+			t.Fatalf("expected %s to be rejected", spec)
+		}
+	}
+	if scorer, err := ParseScoring(nil); scorer != nil || err != nil {
+		t.Fatalf("expected no scorer for an empty spec, got %v %v", scorer, err)
+	}
+}
+
+func TestNumericScorers(t *testing.T) {
+	ordinal := mustParseScoring(t, `{"type": "ordinal", "min": 1, "max": 5}`)
+	complementary := mustParseScoring(t, `{"type": "complementary", "min": 1, "max": 5}`)
+
+	cases := []struct {
+		scorer Scorer
+		v1, v2 int32
+		want   float64
+	}{
+		{ordinal, 2, 2, 1},
+		{ordinal, 1, 2, 0.75},
+		{ordinal, 1, 5, 0},
+		{complementary, 1, 5, 1},
+		{complementary, 3, 3, 0},
+		{complementary, 2, 3, 0.25},
+	}
+	for _, tc := range cases {
+		if got := tc.scorer.Similarity(scaleAnswer(tc.v1), scaleAnswer(tc.v2)); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("%T(%d, %d): expected %v, got %v", tc.scorer, tc.v1, tc.v2, tc.want, got)
+		}
+	}
+	if got := complementary.Similarity(scaleAnswer(1), &answer{AnswerType: "scale"}); got != 0.5 {
+		t.Fatalf("expected a blank answer to score neutral, got %v", got)
+	}
+}
+
+func TestMatrixScorer(t *testing.T) {
+	scorer := mustParseScoring(t, `{"type": "matrix", "matrix": {"Cat": {"Dog": 0.4}, "Fish": {"Fish": 0.8}}}`)
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"Cat", "Dog", 0.4},
+		{"Dog", "Cat", 0.4},
+		{"Cat", "Cat", 1},
+		{"Fish", "Fish", 0.8},
+		{"Cat", "Fish", 0},
+	}
+	for _, tc := range cases {
+		if got := scorer.Similarity(choiceAnswer(tc.a), choiceAnswer(tc.b)); got != tc.want {
+			t.Fatalf("%s/%s: expected %v, got %v", tc.a, tc.b, tc.want, got)
+		}
+	}
+}
+
+func TestQuestionScoringOverridesDefault(t *testing.T) {
+	planner := uuid.New()
+	spec := json.RawMessage(`{"type": "complementary", "min": 1, "max": 5}`)
+	score := func(scoring []byte) float64 {
+		idx := newScoringIndex(DefaultMatchingConfig())
+		user1, user2 := uuid.New(), uuid.New()
+		idx.addAnswer(user1, answer{QuestionID: planner, Category: "personality", AnswerType: "scale", Value: pgtype.Int4{Int32: 1, Valid: true}, Scoring: scoring})
+		idx.addAnswer(user2, answer{QuestionID: planner, Category: "personality", AnswerType: "scale", Value: pgtype.Int4{Int32: 5, Valid: true}, Scoring: scoring})
+		return categoryScore(idx.answers[user1], idx.answers[user2], categoryIndex("personality"))
+	}
+
+	if got := score(nil); got > 5 {
+		t.Fatalf("expected opposite answers to score low by default, got %v", got)
+	}
+	if got := score(spec); got != 100 {
+		t.Fatalf("expected opposite answers to be ideal for a complementary question, got %v", got)
+	}
+	if got := score([]byte(`{"type": "telepathy"}`)); got > 5 {
+		t.Fatalf("expected an unknown scorer to fall back to the default, got %v", got)
+	}
+}
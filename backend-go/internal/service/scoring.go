@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+var scoringCategories = []string{"demographics", "personality", "values", "lifestyle", "interests"}
+
+// answer is a survey response flattened for scoring. JSON answers are decoded
+// (and multi-select lists sorted) once at load time so the pair loop never
+// touches encoding/json or allocates.
+type answer struct {
+	QuestionID uuid.UUID
+	Category   string
+	Weight     float64
+	AnswerType string
+	Value      pgtype.Int4
+	Text       string
+	List       []string
+	Acceptable []string
+	Importance string
+	Scoring    []byte
+
+	question    int
+	category    int
+	importance  float64
+	dealbreaker bool
+	scorer      Scorer
+	vector      *textVector
+}
+
+// answerSet keeps a user's answers per scoring category, ordered by the dense
+// question index so two users can be compared with a merge join.
+type answerSet struct {
+	byCategory   [][]answer
+	dealbreakers []answer
+}
+
+// scoringIndex holds everything needed to score a campaign in memory:
+// survey answers and bios, user emails, ages, crush lists, pair history, pair
+// constraints and user blocks, each loaded with one query, plus each
+// question's scorer and the campaign's category weights, crush multipliers,
+// age gap, bio weight and rematch policy.
+type scoringIndex struct {
+	questions     map[uuid.UUID]int
+	scorers       []Scorer
+	answers       map[uuid.UUID]*answerSet
+	emails        map[uuid.UUID]string
+	ages          map[uuid.UUID]agePreference
+	crushes       map[uuid.UUID]map[string]struct{}
+	history       map[uuid.UUID]map[uuid.UUID]pairHistory
+	constraints   map[uuid.UUID]map[uuid.UUID]string
+	forced        [][2]uuid.UUID
+	weights       []float64
+	crush         CrushMultipliers
+	maxAgeGap     int
+	bioWeight     float64
+	rematchPolicy string
+	rematch       float64
+}
+
+func newScoringIndex(cfg MatchingConfig) *scoringIndex {
+	return &scoringIndex{
+		questions:     map[uuid.UUID]int{},
+		answers:       map[uuid.UUID]*answerSet{},
+		emails:        map[uuid.UUID]string{},
+		ages:          map[uuid.UUID]agePreference{},
+		crushes:       map[uuid.UUID]map[string]struct{}{},
+		history:       map[uuid.UUID]map[uuid.UUID]pairHistory{},
+		constraints:   map[uuid.UUID]map[uuid.UUID]string{},
+		weights:       cfg.categoryWeights(),
+		crush:         cfg.CrushMultipliers,
+		maxAgeGap:     cfg.MaxAgeGap,
+		bioWeight:     cfg.BioWeight,
+		rematchPolicy: cfg.RematchPolicy,
+		rematch:       cfg.RematchMultiplier,
+	}
+}
+
+func categoryIndex(category string) int {
+	for i, name := range scoringCategories {
+		if name == category {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *MatchingService) loadScoringIndex(ctx context.Context, campaignID uuid.UUID, users []repository.ListEligibleUsersRow, cfg MatchingConfig, vectors textVectorMode) (*scoringIndex, error) {
+	idx := newScoringIndex(cfg)
+	today := time.Now()
+	for _, user := range users {
+		idx.emails[user.ID] = strings.ToLower(user.Email)
+		idx.ages[user.ID] = newAgePreference(user.DateOfBirth, user.MinPartnerAge, user.MaxPartnerAge, today)
+	}
+
+	responses, err := s.store.ListSurveyResponsesWithQuestionsByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range responses {
+		idx.addAnswer(row.UserID, answer{
+			QuestionID: row.QuestionID,
+			Category:   normalizeCategory(row.QuestionCategory),
+			Weight:     numericFloat(row.QuestionWeight),
+			AnswerType: row.AnswerType,
+			Value:      row.AnswerValue,
+			Text:       textValue(row.AnswerText),
+			List:       listFromJSON(row.AnswerJson),
+			Acceptable: acceptableFromJSON(row.AcceptableAnswers),
+			Importance: row.Importance,
+			Scoring:    row.QuestionScoring,
+		})
+	}
+	for _, user := range users {
+		idx.addBio(user.ID, user.Bio)
+	}
+	if err := s.loadTextVectors(ctx, campaignID, idx, vectors); err != nil {
+		return nil, err
+	}
+
+	crushes, err := s.store.ListCrushesForCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	for _, crush := range crushes {
+		idx.addCrush(crush.UserID, crush.CrushEmail)
+	}
+
+	if err := s.loadPairHistory(ctx, campaignID, idx); err != nil {
+		return nil, err
+	}
+	if err := s.loadPairConstraints(ctx, campaignID, idx); err != nil {
+		return nil, err
+	}
+	if err := s.loadUserBlocks(ctx, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *scoringIndex) addAnswer(userID uuid.UUID, a answer) {
+	category := categoryIndex(a.Category)
+	if category < 0 {
+		return
+	}
+	question, ok := idx.questions[a.QuestionID]
+	if !ok {
+		question = len(idx.questions)
+		idx.questions[a.QuestionID] = question
+		idx.scorers = append(idx.scorers, questionScorer(a))
+	}
+	a.question = question
+	a.scorer = idx.scorers[question]
+	a.category = category
+	a.List = sortedSet(a.List)
+	a.Acceptable = sortedSet(a.Acceptable)
+	a.importance = importanceWeight(a.Importance)
+	a.dealbreaker = a.Importance == ImportanceMandatory && len(a.Acceptable) > 0
+
+	set, ok := idx.answers[userID]
+	if !ok {
+		set = &answerSet{byCategory: make([][]answer, len(scoringCategories))}
+		idx.answers[userID] = set
+	}
+	list := append(set.byCategory[category], a)
+	for i := len(list) - 1; i > 0 && list[i].question < list[i-1].question; i-- {
+		list[i], list[i-1] = list[i-1], list[i]
+	}
+	set.byCategory[category] = list
+	if a.dealbreaker {
+		set.dealbreakers = append(set.dealbreakers, a)
+	}
+}
+
+func (idx *scoringIndex) addCrush(userID uuid.UUID, email string) {
+	set, ok := idx.crushes[userID]
+	if !ok {
+		set = map[string]struct{}{}
+		idx.crushes[userID] = set
+	}
+	set[strings.ToLower(strings.TrimSpace(email))] = struct{}{}
+}
+
+// scorePairs scores every pair in the pool on a bounded worker pool and keeps
+// the ones worth assigning: compatible pairs scoring at least minScore, plus
+// mutual crushes regardless of score. Pairs failing a dealbreaker or an age
+// filter are always dropped, and pairs with an organizer constraint are left
+// to forcedPairs. Results are returned in row order so a
+// run is deterministic no matter how the work was scheduled.
+func scorePairs(ctx context.Context, idx *scoringIndex, pool candidatePool, minScore float64, workers int, progress *ProgressTracker) ([]scoredPair, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	rows := make([][]scoredPair, len(pool.left))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				user1 := &pool.left[i]
+				partners := pool.right
+				if partners == nil {
+					partners = pool.left[i+1:]
+				}
+				var kept []scoredPair
+				for j := range partners {
+					user2 := &partners[j]
+					if idx.constrained(user1.ID, user2.ID) {
+						continue
+					}
+					if !agesCompatible(idx.ages[user1.ID], idx.ages[user2.ID], idx.maxAgeGap) {
+						continue
+					}
+					score := idx.calculateCompatibility(user1, user2)
+					if score.Vetoed || score.Score < minScore && !score.IsMutual {
+						continue
+					}
+					kept = append(kept, scoredPair{User1: user1, User2: user2, Score: score})
+				}
+				rows[i] = kept
+				progress.addPairsScored(int64(len(partners)))
+			}
+		}()
+	}
+	for i := range pool.left {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, row := range rows {
+		total += len(row)
+	}
+	results := make([]scoredPair, 0, total)
+	for _, row := range rows {
+		results = append(results, row...)
+	}
+	return results, nil
+}
+
+func (idx *scoringIndex) calculateCompatibility(user1 *repository.ListEligibleUsersRow, user2 *repository.ListEligibleUsersRow) matchScore {
+	responses1 := idx.answers[user1.ID]
+	responses2 := idx.answers[user2.ID]
+	if violatesDealbreaker(responses1, responses2) {
+		return matchScore{Vetoed: true}
+	}
+	rematch, allowed := idx.rematchMultiplier(user1.ID, user2.ID)
+	if !allowed {
+		return matchScore{Vetoed: true}
+	}
+	return idx.compatibility(user1, user2, rematch)
+}
+
+// compatibility scores a pair without applying vetoes.
+func (idx *scoringIndex) compatibility(user1 *repository.ListEligibleUsersRow, user2 *repository.ListEligibleUsersRow, rematch float64) matchScore {
+	responses1 := idx.answers[user1.ID]
+	responses2 := idx.answers[user2.ID]
+	demographics := categoryScore(responses1, responses2, 0)
+	personality := categoryScore(responses1, responses2, 1)
+	values := categoryScore(responses1, responses2, 2)
+	lifestyle := categoryScore(responses1, responses2, 3)
+	interests := categoryScore(responses1, responses2, 4)
+
+	score := demographics*idx.weights[0] + personality*idx.weights[1] + values*idx.weights[2] + lifestyle*idx.weights[3] + interests*idx.weights[4]
+
+	bonus, isMutual, hasCrush := idx.crushBonus(user1.ID, user2.ID)
+	if bonus > 1 {
+		score = score * bonus
+	}
+	score *= rematch
+
+	if textValue(user1.Program) != "" && textValue(user1.Program) == textValue(user2.Program) {
+		score += 2
+	}
+	if user1.YearLevel.Valid && user2.YearLevel.Valid && absInt(int(user1.YearLevel.Int32)-int(user2.YearLevel.Int32)) <= 1 {
+		score += 1
+	}
+
+	if score > 100 {
+		score = 100
+	}
+
+	return matchScore{
+		Score: score,
+		Breakdown: scoreBreakdown{
+			Demographics: math.Round(demographics),
+			Personality:  math.Round(personality),
+			Values:       math.Round(values),
+			Lifestyle:    math.Round(lifestyle),
+			Interests:    math.Round(interests),
+		},
+		IsMutual: isMutual,
+		HasCrush: hasCrush,
+	}
+}
+
+func (idx *scoringIndex) crushBonus(user1 uuid.UUID, user2 uuid.UUID) (float64, bool, bool) {
+	user1Email := idx.emails[user1]
+	user2Email := idx.emails[user2]
+	if user1Email == "" || user2Email == "" {
+		return 1, false, false
+	}
+
+	_, likes1 := idx.crushes[user1][user2Email]
+	_, likes2 := idx.crushes[user2][user1Email]
+
+	if likes1 && likes2 {
+		return idx.crush.Mutual, true, true
+	}
+	if likes1 || likes2 {
+		return idx.crush.OneSided, false, true
+	}
+	return 1, false, false
+}
+
+func categoryScore(r1 *answerSet, r2 *answerSet, category int) float64 {
+	if r1 == nil || r2 == nil || len(r1.byCategory[category]) == 0 || len(r2.byCategory[category]) == 0 {
+		return 50
+	}
+
+	list1 := r1.byCategory[category]
+	list2 := r2.byCategory[category]
+	weightSum1, weightSum2 := 0.0, 0.0
+	weightedScore1, weightedScore2 := 0.0, 0.0
+	for i, j := 0, 0; i < len(list1) && j < len(list2); {
+		if list1[i].question < list2[j].question {
+			i++
+			continue
+		}
+		if list1[i].question > list2[j].question {
+			j++
+			continue
+		}
+		weight := list1[i].Weight
+		if weight == 0 {
+			weight = 1
+		}
+		similarity := similarityScore(&list1[i], &list2[j])
+		weight1 := weight * list1[i].importance
+		weight2 := weight * list2[j].importance
+		weightedScore1 += satisfaction(list1[i], list2[j], similarity) * weight1
+		weightedScore2 += satisfaction(list2[j], list1[i], similarity) * weight2
+		weightSum1 += weight1
+		weightSum2 += weight2
+		i++
+		j++
+	}
+
+	// Each side is scored by how well the other's answers fit what they care
+	// about; the category score is the geometric mean of the two.
+	switch {
+	case weightSum1 == 0 && weightSum2 == 0:
+		return 50
+	case weightSum1 == 0:
+		return (weightedScore2 / weightSum2) * 100
+	case weightSum2 == 0:
+		return (weightedScore1 / weightSum1) * 100
+	}
+	return math.Sqrt((weightedScore1/weightSum1)*(weightedScore2/weightSum2)) * 100
+}
+
+func similarityScore(r1 *answer, r2 *answer) float64 {
+	if r1.scorer == nil {
+		return 0.5
+	}
+	return r1.scorer.Similarity(r1, r2)
+}
+
+// sortedSet returns the distinct values of list in sorted order.
+func sortedSet(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	result := append([]string{}, list...)
+	sort.Strings(result)
+	unique := result[:1]
+	for _, value := range result[1:] {
+		if value != unique[len(unique)-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
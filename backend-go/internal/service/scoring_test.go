@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+var syntheticInterests = []string{"Music", "Movies", "Reading", "Travel", "Gaming", "Sports", "Art", "Tech", "Food", "Fashion"}
+
+// syntheticCampaign builds n users who all seek "any" (a single pool, the
+// worst case) with answers to a fifteen question survey mirroring the seed.
+func syntheticCampaign(n int, seed int64) ([]repository.ListEligibleUsersRow, *scoringIndex) {
+	rng := rand.New(rand.NewSource(seed))
+	type question struct {
+		id         uuid.UUID
+		category   string
+		answerType string
+	}
+	questions := []question{}
+	for _, category := range []string{"demographics", "personality", "values", "lifestyle", "interests"} {
+		questions = append(questions,
+			question{uuid.New(), category, "scale"},
+			question{uuid.New(), category, "multiple_choice"},
+			question{uuid.New(), category, "multiple_select"},
+		)
+	}
+
+	users := make([]repository.ListEligibleUsersRow, n)
+	idx := newScoringIndex(DefaultMatchingConfig())
+	for i := range users {
+		user := repository.ListEligibleUsersRow{
+			ID:             uuid.New(),
+			Email:          fmt.Sprintf("user%d@example.com", i),
+			Program:        pgtype.Text{String: fmt.Sprintf("Program %d", rng.Intn(6)), Valid: true},
+			YearLevel:      pgtype.Int4{Int32: int32(1 + rng.Intn(5)), Valid: true},
+			Gender:         pgtype.Text{String: "nonbinary", Valid: true},
+			SeekingGender:  pgtype.Text{String: "any", Valid: true},
+			Genders:        []string{GenderNonbinary},
+			SeekingGenders: allGenders,
+		}
+		users[i] = user
+		idx.emails[user.ID] = user.Email
+
+		for _, q := range questions {
+			a := answer{QuestionID: q.id, Category: q.category, Weight: 1, AnswerType: q.answerType}
+			switch q.answerType {
+			case "scale":
+				a.Value = pgtype.Int4{Int32: int32(1 + rng.Intn(5)), Valid: true}
+			case "multiple_choice":
+				a.Text = fmt.Sprintf("option %d", rng.Intn(5))
+			case "multiple_select":
+				for _, interest := range syntheticInterests {
+					if rng.Intn(3) == 0 {
+						a.List = append(a.List, interest)
+					}
+				}
+			}
+			idx.addAnswer(user.ID, a)
+		}
+	}
+	for i := 0; i < n/10; i++ {
+		from := users[rng.Intn(n)]
+		to := users[rng.Intn(n)]
+		idx.addCrush(from.ID, to.Email)
+	}
+	return users, idx
+}
+
+func TestScorePairsIsDeterministic(t *testing.T) {
+	users, idx := syntheticCampaign(60, 1)
+	serial, err := scorePairs(context.Background(), idx, candidatePool{left: users}, 0, 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	progress := &ProgressTracker{}
+	parallel, err := scorePairs(context.Background(), idx, candidatePool{left: users}, 0, 8, progress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(serial) != len(users)*(len(users)-1)/2 {
+		t.Fatalf("expected every pair to be scored, got %d", len(serial))
+	}
+	if len(serial) != len(parallel) {
+		t.Fatalf("expected %d pairs from parallel run, got %d", len(serial), len(parallel))
+	}
+	for i := range serial {
+		if serial[i].User1.ID != parallel[i].User1.ID || serial[i].User2.ID != parallel[i].User2.ID || serial[i].Score != parallel[i].Score {
+			t.Fatalf("pair %d differs between serial and parallel scoring", i)
+		}
+	}
+	if scored := progress.Snapshot().PairsScored; scored != int64(len(serial)) {
+		t.Fatalf("expected progress to count %d pairs, got %d", len(serial), scored)
+	}
+}
+
+func TestScorePairsStopsWhenCancelled(t *testing.T) {
+	users, idx := syntheticCampaign(40, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := scorePairs(ctx, idx, candidatePool{left: users}, 0, 2, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCrushBonusFromIndex(t *testing.T) {
+	idx := newScoringIndex(DefaultMatchingConfig())
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	idx.emails[a] = "a@example.com"
+	idx.emails[b] = "b@example.com"
+	idx.emails[c] = "c@example.com"
+	idx.addCrush(a, "B@example.com ")
+	idx.addCrush(b, "a@example.com")
+	idx.addCrush(c, "a@example.com")
+
+	if bonus, mutual, _ := idx.crushBonus(a, b); bonus != 1.20 || !mutual {
+		t.Fatalf("expected mutual crush bonus, got %v %v", bonus, mutual)
+	}
+	if bonus, mutual, hasCrush := idx.crushBonus(a, c); bonus != 1.10 || mutual || !hasCrush {
+		t.Fatalf("expected one-sided crush bonus, got %v %v %v", bonus, mutual, hasCrush)
+	}
+}
+
+func benchmarkScorePairs(b *testing.B, n int) {
+	users, idx := syntheticCampaign(n, 42)
+	pairs := float64(n) * float64(n-1) / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := scorePairs(context.Background(), idx, candidatePool{left: users}, 50, 0, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(pairs*float64(b.N)/b.Elapsed().Seconds(), "pairs/s")
+}
+
+func BenchmarkScorePairs1k(b *testing.B) {
+	benchmarkScorePairs(b, 1000)
+}
+
+func BenchmarkScorePairs5k(b *testing.B) {
+	benchmarkScorePairs(b, 5000)
+}
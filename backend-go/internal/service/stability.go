@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/google/uuid"
+)
+
+// blockingPairs counts the candidate pairs that would rather be matched with
+// each other than keep the matching as planned: the pair is not matched, and
+// each user either has room left under maxPerUser or scores the pair higher
+// than their weakest match. A stable matching has none.
+func blockingPairs(candidates []scoredPair, matches []plannedMatch, maxPerUser int) int {
+	matched := make(map[[2]uuid.UUID]bool, len(matches))
+	degree := map[uuid.UUID]int{}
+	weakest := map[uuid.UUID]float64{}
+	for _, match := range matches {
+		score := match.pair.Score.Score
+		matched[pairKey(match.pair.User1.ID, match.pair.User2.ID)] = true
+		for _, user := range []uuid.UUID{match.pair.User1.ID, match.pair.User2.ID} {
+			if degree[user] == 0 || score < weakest[user] {
+				weakest[user] = score
+			}
+			degree[user]++
+		}
+	}
+
+	wants := func(user uuid.UUID, score float64) bool {
+		return degree[user] < maxPerUser || score > weakest[user]+improvementEpsilon
+	}
+	count := 0
+	for _, pair := range candidates {
+		if matched[pairKey(pair.User1.ID, pair.User2.ID)] {
+			continue
+		}
+		if wants(pair.User1.ID, pair.Score.Score) && wants(pair.User2.ID, pair.Score.Score) {
+			count++
+		}
+	}
+	return count
+}
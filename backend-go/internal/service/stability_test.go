@@ -0,0 +1,37 @@
+package service
+
+import "testing"
+
+func TestBlockingPairs(t *testing.T) {
+	u := testUsers(4)
+	a, b, c, d := u[0], u[1], u[2], u[3]
+	candidates := []scoredPair{
+		testPair(a, b, 90),
+		testPair(a, c, 80),
+		testPair(c, d, 70),
+		testPair(b, d, 60),
+	}
+	planned := func(pairs ...scoredPair) []plannedMatch {
+		var matches []plannedMatch
+		for _, pair := range pairs {
+			matches = append(matches, plannedMatch{pair: pair})
+		}
+		return matches
+	}
+
+	stable := planned(candidates[0], candidates[2])
+	if got := blockingPairs(candidates, stable, 1); got != 0 {
+		t.Fatalf("expected the best-first matching to be stable, got %d blocking pairs", got)
+	}
+
+	// a and b both prefer each other over the partners they were given.
+	unstable := planned(candidates[1], candidates[3])
+	if got := blockingPairs(candidates, unstable, 1); got != 1 {
+		t.Fatalf("expected 1 blocking pair, got %d", got)
+	}
+
+	// With room for two matches each, every unmatched candidate blocks.
+	if got := blockingPairs(candidates, stable, 2); got != 2 {
+		t.Fatalf("expected 2 blocking pairs with spare capacity, got %d", got)
+	}
+}
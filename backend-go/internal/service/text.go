@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+// bioQuestionID keys bios in the scoring index, where they are compared like
+// an answer to a free-text interests question.
+var bioQuestionID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("wizardmatch:bio"))
+
+const bioField = "bio"
+
+// stopwords are dropped before weighting. The list covers common English and
+// Filipino function words, which would otherwise dominate short answers.
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		a about above after again all also am an and any are as at be because been
+		before being below between both but by can could did do does doing down
+		during each few for from further had has have having he her here hers
+		herself him himself his how i if in into is it its itself just like me
+		more most my myself no nor not now of off on once only or other our ours
+		ourselves out over own really same she should so some such than that the
+		their theirs them themselves then there these they this those through to
+		too under until up very was we were what when where which while who whom
+		why will with would you your yours yourself yourselves im ive dont
+		ako ang at ay ba din daw ito ka kami kay ko kasi lang mga mo na naman nang
+		ng ni nila niya pa po rin sa si siya tayo yung
+	`) {
+		stopwords[word] = true
+	}
+}
+
+// tokenize lowercases text and splits it into words, dropping punctuation,
+// apostrophes, single characters and stopwords.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(strings.ReplaceAll(text, "'", "")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) < 2 || stopwords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// buildTextVectors computes an L2-normalized TF-IDF vector for each document
+// in the corpus, using sublinear term frequency and smoothed inverse document
+// frequency. Documents with no usable words get no vector.
+func buildTextVectors(corpus map[uuid.UUID]string) map[uuid.UUID]map[string]float64 {
+	counts := make(map[uuid.UUID]map[string]int, len(corpus))
+	documentFrequency := map[string]int{}
+	for id, text := range corpus {
+		terms := map[string]int{}
+		for _, token := range tokenize(text) {
+			terms[token]++
+		}
+		if len(terms) == 0 {
+			continue
+		}
+		counts[id] = terms
+		for term := range terms {
+			documentFrequency[term]++
+		}
+	}
+
+	documents := float64(len(corpus))
+	vectors := make(map[uuid.UUID]map[string]float64, len(counts))
+	for id, terms := range counts {
+		vector := make(map[string]float64, len(terms))
+		norm := 0.0
+		for term, count := range terms {
+			idf := math.Log((1+documents)/(1+float64(documentFrequency[term]))) + 1
+			weight := (1 + math.Log(float64(count))) * idf
+			vector[term] = weight
+			norm += weight * weight
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		vectors[id] = vector
+	}
+	return vectors
+}
+
+// corpusFingerprint identifies the exact set of texts vectors were built from.
+func corpusFingerprint(corpus map[uuid.UUID]string) string {
+	ids := make([]uuid.UUID, 0, len(corpus))
+	for id := range corpus {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	hash := sha256.New()
+	for _, id := range ids {
+		hash.Write(id[:])
+		hash.Write([]byte(corpus[id]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// textVector is a sparse TF-IDF vector with terms interned per question and
+// sorted, so two vectors can be compared with a merge join.
+type textVector struct {
+	terms   []int32
+	weights []float64
+}
+
+// cosine assumes both vectors are normalized, so it is just the dot product.
+func (v *textVector) cosine(other *textVector) float64 {
+	dot := 0.0
+	for i, j := 0, 0; i < len(v.terms) && j < len(other.terms); {
+		switch {
+		case v.terms[i] == other.terms[j]:
+			dot += v.weights[i] * other.weights[j]
+			i++
+			j++
+		case v.terms[i] < other.terms[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return math.Min(dot, 1)
+}
+
+// textScorer compares free-text answers by cosine similarity. Answers without
+// a vector, because they were empty or only stopwords, score neutral.
+type textScorer struct{}
+
+func (textScorer) Similarity(a *answer, b *answer) float64 {
+	if a.vector == nil || b.vector == nil {
+		return 0.5
+	}
+	return a.vector.cosine(b.vector)
+}
+
+func (idx *scoringIndex) addBio(userID uuid.UUID, bio pgtype.Text) {
+	if idx.bioWeight <= 0 || strings.TrimSpace(textValue(bio)) == "" {
+		return
+	}
+	idx.addAnswer(userID, answer{
+		QuestionID: bioQuestionID,
+		Category:   "interests",
+		Weight:     idx.bioWeight,
+		AnswerType: "text",
+		Text:       bio.String,
+	})
+}
+
+// textCorpora collects the free-text answers in the index by question.
+func (idx *scoringIndex) textCorpora() map[uuid.UUID]map[uuid.UUID]string {
+	corpora := map[uuid.UUID]map[uuid.UUID]string{}
+	for userID, set := range idx.answers {
+		for _, list := range set.byCategory {
+			for _, a := range list {
+				if a.AnswerType != "text" || strings.TrimSpace(a.Text) == "" {
+					continue
+				}
+				if corpora[a.QuestionID] == nil {
+					corpora[a.QuestionID] = map[uuid.UUID]string{}
+				}
+				corpora[a.QuestionID][userID] = a.Text
+			}
+		}
+	}
+	return corpora
+}
+
+// applyTextVectors interns the question's vocabulary and attaches each
+// user's vector to their answer.
+func (idx *scoringIndex) applyTextVectors(questionID uuid.UUID, vectors map[uuid.UUID]map[string]float64) {
+	question, ok := idx.questions[questionID]
+	if !ok {
+		return
+	}
+	var vocabulary []string
+	seen := map[string]bool{}
+	for _, vector := range vectors {
+		for term := range vector {
+			if !seen[term] {
+				seen[term] = true
+				vocabulary = append(vocabulary, term)
+			}
+		}
+	}
+	sort.Strings(vocabulary)
+	termIDs := make(map[string]int32, len(vocabulary))
+	for i, term := range vocabulary {
+		termIDs[term] = int32(i)
+	}
+
+	for userID, weights := range vectors {
+		set, ok := idx.answers[userID]
+		if !ok {
+			continue
+		}
+		vector := &textVector{terms: make([]int32, 0, len(weights)), weights: make([]float64, len(weights))}
+		for term := range weights {
+			vector.terms = append(vector.terms, termIDs[term])
+		}
+		sort.Slice(vector.terms, func(i, j int) bool { return vector.terms[i] < vector.terms[j] })
+		for i, id := range vector.terms {
+			vector.weights[i] = weights[vocabulary[id]]
+		}
+		for _, list := range set.byCategory {
+			for i := range list {
+				if list[i].question == question {
+					list[i].vector = vector
+				}
+			}
+		}
+	}
+}
+
+func textField(questionID uuid.UUID) string {
+	if questionID == bioQuestionID {
+		return bioField
+	}
+	return questionID.String()
+}
+
+// textVectorMode says what loadTextVectors does with cached vectors that no
+// longer match the campaign's texts.
+type textVectorMode int
+
+const (
+	// textVectorsCached uses the cached vectors as they are.
+	textVectorsCached textVectorMode = iota
+	// textVectorsFresh recomputes stale vectors without storing them.
+	textVectorsFresh
+	// textVectorsRebuild recomputes stale vectors and stores them.
+	textVectorsRebuild
+)
+
+// loadTextVectors attaches TF-IDF vectors to the free-text answers in idx.
+// Vectors are cached per campaign and field and are stale once the field's
+// texts changed since they were built; mode decides what happens then.
+func (s *MatchingService) loadTextVectors(ctx context.Context, campaignID uuid.UUID, idx *scoringIndex, mode textVectorMode) error {
+	corpora := idx.textCorpora()
+	if len(corpora) == 0 {
+		return nil
+	}
+	cached, err := s.store.ListTextVectorsByCampaign(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	byField := make(map[string]repository.TextVector, len(cached))
+	for _, row := range cached {
+		byField[row.Field] = row
+	}
+
+	for questionID, corpus := range corpora {
+		field := textField(questionID)
+		fingerprint := corpusFingerprint(corpus)
+		var vectors map[uuid.UUID]map[string]float64
+		row, ok := byField[field]
+		if ok && (mode == textVectorsCached || row.Fingerprint == fingerprint) {
+			if err := json.Unmarshal(row.Vectors, &vectors); err != nil {
+				vectors = nil
+			}
+		}
+		if vectors == nil && mode != textVectorsCached {
+			vectors = buildTextVectors(corpus)
+			if mode == textVectorsRebuild {
+				encoded, err := json.Marshal(vectors)
+				if err != nil {
+					return err
+				}
+				if err := s.store.UpsertTextVectors(ctx, repository.UpsertTextVectorsParams{
+					CampaignID:  campaignID,
+					Field:       field,
+					Fingerprint: fingerprint,
+					Vectors:     encoded,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		idx.applyTextVectors(questionID, vectors)
+	}
+	return nil
+}
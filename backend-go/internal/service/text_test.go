@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("I'm into K-pop, hiking and 3D printing! Mahilig ako sa kape.")
+	want := []string{"pop", "hiking", "3d", "printing", "mahilig", "kape"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTextVectorsRankSimilarAnswers(t *testing.T) {
+	hiker, climber, gamer, blank := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	question := uuid.New()
+	corpus := map[uuid.UUID]string{
+		hiker:   "Hiking mountains on weekends and camping under the stars",
+		climber: "Weekend camping trips, rock climbing and mountains",
+		gamer:   "Staying in for video games and anime marathons",
+		blank:   "the and of",
+	}
+	vectors := buildTextVectors(corpus)
+	if _, ok := vectors[blank]; ok {
+		t.Fatalf("expected no vector for a stopword-only answer")
+	}
+	norm := 0.0
+	for _, weight := range vectors[hiker] {
+		norm += weight * weight
+	}
+	if math.Abs(norm-1) > 1e-9 {
+		t.Fatalf("expected a unit vector, got squared norm %v", norm)
+	}
+
+	idx := newScoringIndex(DefaultMatchingConfig())
+	for id, text := range corpus {
+		idx.addAnswer(id, answer{QuestionID: question, Category: "interests", AnswerType: "text", Text: text})
+	}
+	idx.applyTextVectors(question, vectors)
+	similarity := func(a, b uuid.UUID) float64 {
+		return similarityScore(&idx.answers[a].byCategory[4][0], &idx.answers[b].byCategory[4][0])
+	}
+
+	if similarity(hiker, climber) <= similarity(hiker, gamer) {
+		t.Fatalf("expected outdoor answers to be closer than %v, got %v", similarity(hiker, gamer), similarity(hiker, climber))
+	}
+	if got := similarity(hiker, hiker); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected identical answers to score 1, got %v", got)
+	}
+	if got := similarity(hiker, blank); got != 0.5 {
+		t.Fatalf("expected an answer without a vector to score neutral, got %v", got)
+	}
+}
+
+func TestCorpusFingerprint(t *testing.T) {
+	user1, user2 := uuid.New(), uuid.New()
+	corpus := map[uuid.UUID]string{user1: "coffee", user2: "tea"}
+	fingerprint := corpusFingerprint(corpus)
+	if corpusFingerprint(map[uuid.UUID]string{user2: "tea", user1: "coffee"}) != fingerprint {
+		t.Fatalf("expected the fingerprint not to depend on map order")
+	}
+	corpus[user2] = "matcha"
+	if corpusFingerprint(corpus) == fingerprint {
+		t.Fatalf("expected an edited answer to change the fingerprint")
+	}
+}
+
+func TestBiosAreScoredAsInterests(t *testing.T) {
+	cfg := DefaultMatchingConfig()
+	user1, user2 := uuid.New(), uuid.New()
+	idx := newScoringIndex(cfg)
+	idx.addBio(user1, pgtype.Text{String: "Film photography and late night ramen", Valid: true})
+	idx.addBio(user2, pgtype.Text{String: "Ramen hunting, film cameras, photography walks", Valid: true})
+	idx.addBio(uuid.New(), pgtype.Text{String: "   ", Valid: true})
+
+	corpora := idx.textCorpora()
+	if len(corpora) != 1 || len(corpora[bioQuestionID]) != 2 {
+		t.Fatalf("expected one bio corpus with two bios, got %v", corpora)
+	}
+	idx.applyTextVectors(bioQuestionID, buildTextVectors(corpora[bioQuestionID]))
+	if got := categoryScore(idx.answers[user1], idx.answers[user2], categoryIndex("interests")); got <= 0 || got >= 100 {
+		t.Fatalf("expected a partial bio similarity, got %v", got)
+	}
+
+	cfg.BioWeight = 0
+	idx = newScoringIndex(cfg)
+	idx.addBio(user1, pgtype.Text{String: "Film photography", Valid: true})
+	if len(idx.answers) != 0 {
+		t.Fatalf("expected bios to be ignored with a zero bio weight")
+	}
+}
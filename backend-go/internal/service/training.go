@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+)
+
+var ErrNoTrainingData = errors.New("no matches with feedback from both users in the selected campaigns")
+
+// TrainingOptions controls how TrainWeights fits and evaluates its models.
+type TrainingOptions struct {
+	CampaignIDs  []uuid.UUID
+	Holdout      float64
+	Epochs       int
+	LearningRate float64
+	L2           float64
+	Seed         int64
+}
+
+func DefaultTrainingOptions() TrainingOptions {
+	return TrainingOptions{Holdout: 0.2, Epochs: 2000, LearningRate: 0.5, L2: 0.01, Seed: 1}
+}
+
+// TrainingMetrics evaluates one model on one split. AUC is NaN when the
+// split lacks either positive or negative examples.
+type TrainingMetrics struct {
+	Model     string
+	Split     string
+	Examples  int
+	Positives int
+	LogLoss   float64
+	Accuracy  float64
+	AUC       float64
+}
+
+// QuestionWeight is the proposed weight for one survey question.
+type QuestionWeight struct {
+	QuestionID     uuid.UUID `json:"questionId"`
+	CampaignID     uuid.UUID `json:"campaignId"`
+	Question       string    `json:"question"`
+	Category       string    `json:"category"`
+	CurrentWeight  float64   `json:"currentWeight"`
+	ProposedWeight float64   `json:"proposedWeight"`
+}
+
+// TrainingReport holds the evaluation of the fitted models and the weights
+// they propose. Matching is the base campaign's config with the category and
+// bio weights replaced, ready to use as the "matching" section of a new
+// campaign's config.
+type TrainingReport struct {
+	Examples             int
+	Skipped              int
+	Metrics              []TrainingMetrics
+	CategoryCoefficients map[string]float64
+	Matching             MatchingConfig
+	QuestionWeights      []QuestionWeight
+}
+
+// trainingExample is a match where both users gave feedback. Mutual interest
+// is the positive label.
+type trainingExample struct {
+	categories []float64
+	questions  map[uuid.UUID]float64
+	baseline   float64
+	label      float64
+}
+
+// outcomeLabel turns a match's interactions into a label. Matches where one
+// side expressed interest and the other never answered are left out.
+func outcomeLabel(outcome repository.ListMatchOutcomesByCampaignRow) (float64, bool) {
+	if outcome.User1Interested && outcome.User2Interested {
+		return 1, true
+	}
+	if outcome.User1Decided && !outcome.User1Interested || outcome.User2Decided && !outcome.User2Interested {
+		return 0, true
+	}
+	return 0, false
+}
+
+// TrainWeights fits logistic regressions predicting mutual interest from the
+// category scores and per-question similarities of past matches, and turns
+// the coefficients into proposed weights. The config of the first campaign
+// is used as the base for the proposal.
+func (s *MatchingService) TrainWeights(ctx context.Context, opts TrainingOptions) (*TrainingReport, error) {
+	report := &TrainingReport{}
+	var examples []trainingExample
+	questions := map[uuid.UUID]repository.Question{}
+	for i, campaignID := range opts.CampaignIDs {
+		cfg, err := s.loadMatchingConfig(ctx, campaignID)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			report.Matching = cfg
+		}
+		campaignExamples, skipped, err := s.trainingExamples(ctx, campaignID, cfg)
+		if err != nil {
+			return nil, err
+		}
+		examples = append(examples, campaignExamples...)
+		report.Skipped += skipped
+
+		campaignQuestions, err := s.store.ListQuestionsByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, question := range campaignQuestions {
+			questions[question.ID] = question
+		}
+	}
+	if len(examples) == 0 {
+		return nil, ErrNoTrainingData
+	}
+	report.Examples = len(examples)
+
+	train, test := splitExamples(examples, opts.Holdout, opts.Seed)
+	report.Metrics = append(report.Metrics,
+		evaluate("current score", "train", train, func(ex trainingExample) float64 { return ex.baseline }),
+		evaluate("current score", "holdout", test, func(ex trainingExample) float64 { return ex.baseline }),
+	)
+
+	categoryFeatures := func(ex trainingExample) []float64 { return ex.categories }
+	categoryModel := fitLogistic(features(train, categoryFeatures), labels(train), opts)
+	report.Metrics = append(report.Metrics,
+		evaluate("category weights", "train", train, func(ex trainingExample) float64 { return categoryModel.predict(categoryFeatures(ex)) }),
+		evaluate("category weights", "holdout", test, func(ex trainingExample) float64 { return categoryModel.predict(categoryFeatures(ex)) }),
+	)
+	report.CategoryCoefficients = map[string]float64{}
+	for i, name := range scoringCategories {
+		report.CategoryCoefficients[name] = categoryModel.weights[i]
+	}
+	if weights, ok := proposeCategoryWeights(categoryModel.weights); ok {
+		report.Matching.Weights = weights
+	}
+
+	// Unanswered questions contribute nothing; answered ones are centered on
+	// the neutral similarity so the coefficient reflects agreement.
+	columns := questionColumns(examples)
+	questionFeatures := func(ex trainingExample) []float64 {
+		row := make([]float64, len(columns))
+		for i, questionID := range columns {
+			if similarity, ok := ex.questions[questionID]; ok {
+				row[i] = similarity - 0.5
+			}
+		}
+		return row
+	}
+	questionModel := fitLogistic(features(train, questionFeatures), labels(train), opts)
+	report.Metrics = append(report.Metrics,
+		evaluate("question weights", "train", train, func(ex trainingExample) float64 { return questionModel.predict(questionFeatures(ex)) }),
+		evaluate("question weights", "holdout", test, func(ex trainingExample) float64 { return questionModel.predict(questionFeatures(ex)) }),
+	)
+	report.QuestionWeights, report.Matching.BioWeight = proposeQuestionWeights(columns, questionModel.weights, questions, report.Matching.BioWeight)
+	return report, nil
+}
+
+func (s *MatchingService) trainingExamples(ctx context.Context, campaignID uuid.UUID, cfg MatchingConfig) ([]trainingExample, int, error) {
+	outcomes, err := s.store.ListMatchOutcomesByCampaign(ctx, pgtype.UUID{Bytes: campaignID, Valid: true})
+	if err != nil || len(outcomes) == 0 {
+		return nil, 0, err
+	}
+	users, err := s.store.ListEligibleUsers(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	idx, err := s.loadScoringIndex(ctx, campaignID, users, cfg, textVectorsFresh)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]*repository.ListEligibleUsersRow, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	// The baseline re-scores each pair with the current weights rather than
+	// trusting the score stored when the match was made, so the proposal is
+	// compared against what matching would do today. Pair history is left
+	// out, since the outcome being predicted is often the very rejection that
+	// would veto the pair.
+	var examples []trainingExample
+	skipped := 0
+	for _, outcome := range outcomes {
+		label, ok := outcomeLabel(outcome)
+		user1, user2 := byID[outcome.User1ID], byID[outcome.User2ID]
+		answers1, answers2 := idx.answers[outcome.User1ID], idx.answers[outcome.User2ID]
+		if !ok || user1 == nil || user2 == nil || answers1 == nil || answers2 == nil {
+			skipped++
+			continue
+		}
+		example := trainingExample{
+			categories: make([]float64, len(scoringCategories)),
+			questions:  idx.questionSimilarities(answers1, answers2),
+			baseline:   idx.compatibility(user1, user2, 1).Score / 100,
+			label:      label,
+		}
+		for category := range scoringCategories {
+			example.categories[category] = categoryScore(answers1, answers2, category) / 100
+		}
+		examples = append(examples, example)
+	}
+	return examples, skipped, nil
+}
+
+// questionSimilarities returns the similarity of every question both users
+// answered.
+func (idx *scoringIndex) questionSimilarities(r1 *answerSet, r2 *answerSet) map[uuid.UUID]float64 {
+	result := map[uuid.UUID]float64{}
+	for category := range scoringCategories {
+		list1, list2 := r1.byCategory[category], r2.byCategory[category]
+		for i, j := 0, 0; i < len(list1) && j < len(list2); {
+			switch {
+			case list1[i].question < list2[j].question:
+				i++
+			case list1[i].question > list2[j].question:
+				j++
+			default:
+				result[list1[i].QuestionID] = similarityScore(&list1[i], &list2[j])
+				i++
+				j++
+			}
+		}
+	}
+	return result
+}
+
+func questionColumns(examples []trainingExample) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var columns []uuid.UUID
+	for _, ex := range examples {
+		for questionID := range ex.questions {
+			if !seen[questionID] {
+				seen[questionID] = true
+				columns = append(columns, questionID)
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].String() < columns[j].String() })
+	return columns
+}
+
+// splitExamples shuffles deterministically and holds out a fraction of the
+// examples for evaluation.
+func splitExamples(examples []trainingExample, holdout float64, seed int64) ([]trainingExample, []trainingExample) {
+	shuffled := append([]trainingExample{}, examples...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	held := int(math.Round(float64(len(shuffled)) * holdout))
+	if held >= len(shuffled) {
+		held = len(shuffled) - 1
+	}
+	if held < 0 {
+		held = 0
+	}
+	return shuffled[held:], shuffled[:held]
+}
+
+func features(examples []trainingExample, row func(trainingExample) []float64) [][]float64 {
+	x := make([][]float64, len(examples))
+	for i, ex := range examples {
+		x[i] = row(ex)
+	}
+	return x
+}
+
+func labels(examples []trainingExample) []float64 {
+	y := make([]float64, len(examples))
+	for i, ex := range examples {
+		y[i] = ex.label
+	}
+	return y
+}
+
+type logisticModel struct {
+	weights []float64
+	bias    float64
+}
+
+func (m logisticModel) predict(x []float64) float64 {
+	z := m.bias
+	for i, value := range x {
+		z += m.weights[i] * value
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// fitLogistic runs full-batch gradient descent on the L2-regularized log
+// loss. The bias is not regularized.
+func fitLogistic(x [][]float64, y []float64, opts TrainingOptions) logisticModel {
+	model := logisticModel{}
+	if len(x) == 0 {
+		return model
+	}
+	model.weights = make([]float64, len(x[0]))
+	gradient := make([]float64, len(model.weights))
+	n := float64(len(x))
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		for i := range gradient {
+			gradient[i] = 0
+		}
+		biasGradient := 0.0
+		for i, row := range x {
+			residual := model.predict(row) - y[i]
+			for j, value := range row {
+				gradient[j] += residual * value
+			}
+			biasGradient += residual
+		}
+		for j := range model.weights {
+			model.weights[j] -= opts.LearningRate * (gradient[j]/n + opts.L2*model.weights[j])
+		}
+		model.bias -= opts.LearningRate * biasGradient / n
+	}
+	return model
+}
+
+func evaluate(model string, split string, examples []trainingExample, predict func(trainingExample) float64) TrainingMetrics {
+	metrics := TrainingMetrics{Model: model, Split: split, Examples: len(examples), AUC: math.NaN()}
+	if len(examples) == 0 {
+		return metrics
+	}
+	scores := make([]float64, len(examples))
+	correct := 0
+	for i, ex := range examples {
+		p := math.Min(math.Max(predict(ex), 1e-9), 1-1e-9)
+		scores[i] = p
+		metrics.LogLoss -= ex.label*math.Log(p) + (1-ex.label)*math.Log(1-p)
+		if (p >= 0.5) == (ex.label == 1) {
+			correct++
+		}
+		if ex.label == 1 {
+			metrics.Positives++
+		}
+	}
+	metrics.LogLoss /= float64(len(examples))
+	metrics.Accuracy = float64(correct) / float64(len(examples))
+	metrics.AUC = auc(scores, labels(examples))
+	return metrics
+}
+
+// auc is the probability that a random positive is scored above a random
+// negative, computed from rank sums with ties sharing their average rank.
+func auc(scores []float64, y []float64) float64 {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return scores[order[a]] < scores[order[b]] })
+
+	positives, rankSum := 0.0, 0.0
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && scores[order[end]] == scores[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			if y[i] == 1 {
+				positives++
+				rankSum += rank
+			}
+		}
+		start = end
+	}
+	negatives := float64(len(scores)) - positives
+	if positives == 0 || negatives == 0 {
+		return math.NaN()
+	}
+	return (rankSum - positives*(positives+1)/2) / (positives * negatives)
+}
+
+// proposeCategoryWeights normalizes the positive category coefficients into
+// weights summing to 1, rounded to three decimals. It fails when no category
+// helped predict interest.
+func proposeCategoryWeights(coefficients []float64) (map[string]float64, bool) {
+	total := 0.0
+	for _, c := range coefficients {
+		total += math.Max(c, 0)
+	}
+	if total == 0 {
+		return nil, false
+	}
+	weights := map[string]float64{}
+	largest, assigned := "", 0.0
+	for i, name := range scoringCategories {
+		weight := math.Round(math.Max(coefficients[i], 0)/total*1000) / 1000
+		weights[name] = weight
+		assigned += weight
+		if largest == "" || weight > weights[largest] {
+			largest = name
+		}
+	}
+	// Rounding can leave the sum a little off 1, which Validate rejects.
+	weights[largest] = math.Round((weights[largest]+1-assigned)*1000) / 1000
+	return weights, true
+}
+
+// proposeQuestionWeights rescales positive coefficients within each category
+// so the average proposed weight matches the current average, keeping
+// category scores comparable. Weights stay within what questions.weight can
+// store; a zero weight would be read as 1, so the floor is 0.01. Bios are
+// treated as a question and their weight returned separately.
+func proposeQuestionWeights(columns []uuid.UUID, coefficients []float64, questions map[uuid.UUID]repository.Question, bioWeight float64) ([]QuestionWeight, float64) {
+	type entry struct {
+		weight      QuestionWeight
+		coefficient float64
+	}
+	byCategory := map[string][]*entry{}
+	var bio *entry
+	for i, questionID := range columns {
+		e := &entry{coefficient: math.Max(coefficients[i], 0)}
+		if questionID == bioQuestionID {
+			e.weight = QuestionWeight{QuestionID: questionID, Category: "interests", CurrentWeight: bioWeight}
+			bio = e
+		} else if question, ok := questions[questionID]; ok {
+			e.weight = QuestionWeight{
+				QuestionID:    questionID,
+				CampaignID:    uuid.UUID(question.CampaignID.Bytes),
+				Question:      question.QuestionText,
+				Category:      normalizeCategory(question.Category),
+				CurrentWeight: numericFloat(question.Weight),
+			}
+		} else {
+			continue
+		}
+		byCategory[e.weight.Category] = append(byCategory[e.weight.Category], e)
+	}
+
+	var result []QuestionWeight
+	for _, entries := range byCategory {
+		currentTotal, coefficientTotal := 0.0, 0.0
+		for _, e := range entries {
+			currentTotal += e.weight.CurrentWeight
+			coefficientTotal += e.coefficient
+		}
+		for _, e := range entries {
+			e.weight.ProposedWeight = e.weight.CurrentWeight
+			if coefficientTotal > 0 {
+				proposed := e.coefficient / coefficientTotal * currentTotal
+				e.weight.ProposedWeight = math.Round(math.Min(math.Max(proposed, 0.01), 9.99)*100) / 100
+			}
+			if e != bio {
+				result = append(result, e.weight)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category < result[j].Category
+		}
+		return result[i].Question < result[j].Question
+	})
+	if bio != nil {
+		bioWeight = bio.weight.ProposedWeight
+	}
+	return result, bioWeight
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"wizardmatch-backend/internal/repository"
+	"wizardmatch-backend/internal/repository/memory"
+)
+
+func TestOutcomeLabel(t *testing.T) {
+	cases := []struct {
+		outcome repository.ListMatchOutcomesByCampaignRow
+		label   float64
+		ok      bool
+	}{
+		{repository.ListMatchOutcomesByCampaignRow{User1Interested: true, User2Interested: true, User1Decided: true, User2Decided: true}, 1, true},
+		{repository.ListMatchOutcomesByCampaignRow{User1Interested: true, User1Decided: true, User2Decided: true}, 0, true},
+		{repository.ListMatchOutcomesByCampaignRow{User2Decided: true}, 0, true},
+		{repository.ListMatchOutcomesByCampaignRow{User1Interested: true, User1Decided: true}, 0, false},
+	}
+	for i, tc := range cases {
+		if label, ok := outcomeLabel(tc.outcome); label != tc.label || ok != tc.ok {
+			t.Fatalf("case %d: expected %v/%v, got %v/%v", i, tc.label, tc.ok, label, ok)
+		}
+	}
+}
+
+func TestAUC(t *testing.T) {
+	if got := auc([]float64{0.1, 0.4, 0.35, 0.8}, []float64{0, 0, 1, 1}); math.Abs(got-0.75) > 1e-9 {
+		t.Fatalf("expected 0.75, got %v", got)
+	}
+	if got := auc([]float64{0.5, 0.5}, []float64{0, 1}); got != 0.5 {
+		t.Fatalf("expected ties to count half, got %v", got)
+	}
+	if got := auc([]float64{0.2, 0.9}, []float64{1, 1}); !math.IsNaN(got) {
+		t.Fatalf("expected NaN without negatives, got %v", got)
+	}
+}
+
+func TestTrainedCategoryWeightsFavorPredictiveCategory(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	values := categoryIndex("values")
+	var examples []trainingExample
+	for i := 0; i < 600; i++ {
+		ex := trainingExample{categories: make([]float64, len(scoringCategories))}
+		for c := range ex.categories {
+			ex.categories[c] = rng.Float64()
+		}
+		// Only agreement on values drives mutual interest.
+		if rng.Float64() < ex.categories[values] {
+			ex.label = 1
+		}
+		examples = append(examples, ex)
+	}
+
+	opts := DefaultTrainingOptions()
+	train, test := splitExamples(examples, opts.Holdout, opts.Seed)
+	if len(test) != 120 || len(train) != 480 {
+		t.Fatalf("unexpected split %d/%d", len(train), len(test))
+	}
+	model := fitLogistic(features(train, func(ex trainingExample) []float64 { return ex.categories }), labels(train), opts)
+	metrics := evaluate("category weights", "holdout", test, func(ex trainingExample) float64 { return model.predict(ex.categories) })
+	if metrics.AUC < 0.7 {
+		t.Fatalf("expected the model to learn the signal, got %+v", metrics)
+	}
+
+	weights, ok := proposeCategoryWeights(model.weights)
+	if !ok {
+		t.Fatalf("expected a proposal from coefficients %v", model.weights)
+	}
+	for name, weight := range weights {
+		if name != "values" && weight >= weights["values"] {
+			t.Fatalf("expected values to dominate, got %v", weights)
+		}
+	}
+	cfg := DefaultMatchingConfig()
+	cfg.Weights = weights
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected proposed weights to be a valid config: %v", err)
+	}
+
+	if _, ok := proposeCategoryWeights([]float64{-1, 0, -0.5, 0, 0}); ok {
+		t.Fatalf("expected no proposal when no category helps")
+	}
+}
+
+func TestProposeQuestionWeights(t *testing.T) {
+	campaign := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	weight := func(hundredths int64) pgtype.Numeric {
+		return pgtype.Numeric{Int: big.NewInt(hundredths), Exp: -2, Valid: true}
+	}
+	pets, sleep, music := uuid.New(), uuid.New(), uuid.New()
+	questions := map[uuid.UUID]repository.Question{
+		pets:  {ID: pets, CampaignID: campaign, Category: "lifestyle", QuestionText: "Pets?", Weight: weight(100)},
+		sleep: {ID: sleep, CampaignID: campaign, Category: "lifestyle", QuestionText: "Sleep?", Weight: weight(100)},
+		music: {ID: music, CampaignID: campaign, Category: "fun", QuestionText: "Music?", Weight: weight(80)},
+	}
+
+	proposed, bio := proposeQuestionWeights(
+		[]uuid.UUID{pets, sleep, music, bioQuestionID},
+		[]float64{3, -1, 0.5, 0.5},
+		questions, 0.5,
+	)
+	got := map[uuid.UUID]float64{}
+	for _, q := range proposed {
+		got[q.QuestionID] = q.ProposedWeight
+	}
+	if got[pets] != 2 || got[sleep] != 0.01 {
+		t.Fatalf("expected lifestyle weight to move to pets, got %v", got)
+	}
+	if got[music] != 0.65 || bio != 0.65 {
+		t.Fatalf("expected interests weight to be shared by music and bio, got %v and %v", got[music], bio)
+	}
+}
+
+func TestTrainingBaselineRescoresPairs(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 4)
+	matcher := NewMatchingService(store, nil)
+	users, err := store.ListEligibleUsers(ctx)
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	// A stored score of zero must not leak into the baseline.
+	for i, interaction := range []string{"interest", "pass"} {
+		match, err := store.CreateMatch(ctx, repository.CreateMatchParams{
+			CampaignID:         pgtype.UUID{Bytes: campaign.ID, Valid: true},
+			User1ID:            users[2*i].ID,
+			User2ID:            users[2*i+1].ID,
+			CompatibilityScore: NumericFromFloat(0),
+		})
+		if err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+		for _, userID := range []uuid.UUID{match.User1ID, match.User2ID} {
+			if _, err := store.CreateInteraction(ctx, repository.CreateInteractionParams{MatchID: match.ID, UserID: userID, InteractionType: interaction}); err != nil {
+				t.Fatalf("create interaction: %v", err)
+			}
+		}
+	}
+
+	cfg, err := matcher.loadMatchingConfig(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	examples, skipped, err := matcher.trainingExamples(ctx, campaign.ID, cfg)
+	if err != nil {
+		t.Fatalf("training examples: %v", err)
+	}
+	if len(examples) != 2 || skipped != 0 {
+		t.Fatalf("expected 2 examples, got %d (%d skipped)", len(examples), skipped)
+	}
+	for _, ex := range examples {
+		if ex.baseline <= 0 || ex.baseline > 1 {
+			t.Fatalf("expected a re-scored baseline, got %v", ex.baseline)
+		}
+	}
+}
+
+func TestTrainingBaselineIgnoresPairHistory(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	campaign := seedCampaign(t, store, 2)
+	matcher := NewMatchingService(store, nil)
+	users, err := store.ListEligibleUsers(ctx)
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	match, err := store.CreateMatch(ctx, repository.CreateMatchParams{
+		CampaignID:         pgtype.UUID{Bytes: campaign.ID, Valid: true},
+		User1ID:            users[0].ID,
+		User2ID:            users[1].ID,
+		CompatibilityScore: NumericFromFloat(80),
+	})
+	if err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	// The rejection is both the label and pair history that vetoes a rematch.
+	if _, err := store.CreateInteraction(ctx, repository.CreateInteractionParams{MatchID: match.ID, UserID: users[0].ID, InteractionType: "not_interested"}); err != nil {
+		t.Fatalf("create interaction: %v", err)
+	}
+
+	cfg, err := matcher.loadMatchingConfig(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	examples, _, err := matcher.trainingExamples(ctx, campaign.ID, cfg)
+	if err != nil {
+		t.Fatalf("training examples: %v", err)
+	}
+	if len(examples) != 1 || examples[0].label != 0 || examples[0].baseline <= 0 {
+		t.Fatalf("expected a rejected pair to keep its baseline score, got %+v", examples)
+	}
+}
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE match_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'running',
+    algorithm_version TEXT NOT NULL,
+    config JSONB NOT NULL,
+    total_users INTEGER NOT NULL DEFAULT 0,
+    candidate_pairs INTEGER NOT NULL DEFAULT 0,
+    total_matches INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    published_at TIMESTAMPTZ
+);
+
+CREATE TABLE match_run_results (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES match_runs(id) ON DELETE CASCADE,
+    user1_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user2_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    compatibility_score NUMERIC(5,2) NOT NULL,
+    match_tier TEXT,
+    shared_interests JSONB,
+    rank_for_user1 INTEGER,
+    rank_for_user2 INTEGER,
+    is_mutual_crush BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (run_id, user1_id, user2_id)
+);
+
+CREATE INDEX idx_match_runs_campaign ON match_runs (campaign_id, started_at DESC);
+CREATE UNIQUE INDEX idx_match_runs_published ON match_runs (campaign_id) WHERE status = 'published';
+CREATE INDEX idx_match_run_results_run ON match_run_results (run_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS match_run_results;
+DROP TABLE IF EXISTS match_runs;
+-- +goose StatementEnd
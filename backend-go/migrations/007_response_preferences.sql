@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE survey_responses
+    ADD COLUMN acceptable_answers JSONB,
+    ADD COLUMN importance TEXT NOT NULL DEFAULT 'somewhat'
+        CHECK (importance IN ('irrelevant', 'a_little', 'somewhat', 'very', 'mandatory'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE survey_responses
+    DROP COLUMN IF EXISTS importance,
+    DROP COLUMN IF EXISTS acceptable_answers;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users
+    ADD COLUMN genders TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN seeking_genders TEXT[] NOT NULL DEFAULT '{}';
+
+-- Backfill from the free-text columns using the same aliases as the API.
+-- Unrecognized genders are left empty. An unrecognized preference becomes
+-- 'unrecognized', which matches nobody, rather than empty, which would mean
+-- open to anyone.
+CREATE FUNCTION pg_temp.normalize_genders(value TEXT, seeking BOOLEAN) RETURNS TEXT[] AS $$
+    SELECT CASE
+        WHEN seeking AND cardinality(genders) = 0 AND trim(COALESCE(value, '')) <> ''
+            THEN ARRAY['unrecognized']
+        ELSE genders
+    END
+    FROM (
+        SELECT COALESCE(array_agg(DISTINCT gender ORDER BY gender), '{}') AS genders
+        FROM (
+            SELECT unnest(CASE
+                WHEN token IN ('m', 'male', 'males', 'man', 'men', 'boy', 'boys', 'guy', 'guys', 'transman', 'transmen')
+                    THEN ARRAY['man']
+                WHEN token IN ('f', 'female', 'females', 'woman', 'women', 'girl', 'girls', 'lady', 'ladies', 'transwoman', 'transwomen')
+                    THEN ARRAY['woman']
+                WHEN token IN ('nonbinary', 'nb', 'enby', 'genderqueer', 'genderfluid', 'agender')
+                    THEN ARRAY['nonbinary']
+                WHEN seeking AND token IN ('any', 'anyone', 'all', 'everyone')
+                    THEN ARRAY['man', 'nonbinary', 'woman']
+                WHEN seeking AND token = 'both'
+                    THEN ARRAY['man', 'woman']
+                ELSE ARRAY[]::TEXT[]
+            END) AS gender
+            FROM (
+                SELECT regexp_replace(trim(part), '[\s_-]+', '', 'g') AS token
+                FROM regexp_split_to_table(lower(COALESCE(value, '')), '[,/;&|+]|\mand\M|\mor\M') AS part
+            ) tokens
+        ) recognized
+    ) normalized;
+$$ LANGUAGE sql IMMUTABLE;
+
+UPDATE users SET
+    genders = pg_temp.normalize_genders(gender, FALSE),
+    seeking_genders = pg_temp.normalize_genders(seeking_gender, TRUE);
+
+DROP FUNCTION pg_temp.normalize_genders(TEXT, BOOLEAN);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS seeking_genders,
+    DROP COLUMN IF EXISTS genders;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users
+    ADD COLUMN min_partner_age INTEGER CHECK (min_partner_age > 0),
+    ADD COLUMN max_partner_age INTEGER CHECK (max_partner_age > 0),
+    ADD CONSTRAINT users_partner_age_range CHECK (min_partner_age <= max_partner_age);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_partner_age_range,
+    DROP COLUMN IF EXISTS max_partner_age,
+    DROP COLUMN IF EXISTS min_partner_age;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- scoring names the similarity function used to compare two answers, e.g.
+-- {"type": "complementary", "min": 1, "max": 5}. NULL keeps the default for
+-- the question type and category.
+ALTER TABLE questions
+    ADD COLUMN scoring JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS scoring;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- TF-IDF vectors for free-text answers and bios, one row per campaign and
+-- field (a question id, or "bio"). The fingerprint hashes the texts the
+-- vectors were built from, so a match run only rebuilds fields whose answers
+-- changed since the previous run.
+CREATE TABLE text_vectors (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    vectors JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, field)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS text_vectors;
+-- +goose StatementEnd
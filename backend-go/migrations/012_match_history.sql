@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A pair may be matched again in a later campaign, so uniqueness is per
+-- campaign. Potential matches have no campaign and stay unique per pair.
+ALTER TABLE matches
+    DROP CONSTRAINT IF EXISTS matches_user1_id_user2_id_key,
+    ADD CONSTRAINT matches_campaign_pair_key UNIQUE NULLS NOT DISTINCT (campaign_id, user1_id, user2_id);
+
+CREATE INDEX idx_matches_pair ON matches (user1_id, user2_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_matches_pair;
+
+-- Keep the most recent match of pairs matched in several campaigns.
+DELETE FROM matches m
+USING matches newer
+WHERE m.user1_id = newer.user1_id
+  AND m.user2_id = newer.user2_id
+  AND (m.created_at, m.id) < (newer.created_at, newer.id);
+
+ALTER TABLE matches
+    DROP CONSTRAINT IF EXISTS matches_campaign_pair_key,
+    ADD CONSTRAINT matches_user1_id_user2_id_key UNIQUE (user1_id, user2_id);
+-- +goose StatementEnd
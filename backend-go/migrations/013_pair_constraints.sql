@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Organizer overrides for match generation: pairs that must never be matched
+-- and pairs that must be. Pairs are stored with user1_id < user2_id so each
+-- pair has at most one constraint per campaign.
+CREATE TABLE pair_constraints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    user1_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user2_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    constraint_type TEXT NOT NULL CHECK (constraint_type IN ('must_match', 'must_not_match')),
+    reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (user1_id < user2_id),
+    UNIQUE (campaign_id, user1_id, user2_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pair_constraints;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Fairness report of each run: the Gini coefficient of match counts per
+-- eligible user, the spread of counts and the share of same-program and
+-- same-year matches.
+ALTER TABLE match_runs ADD COLUMN fairness JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE match_runs DROP COLUMN IF EXISTS fairness;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- When and why each match was opened for messaging: 'mutual_interest' once
+-- both users are interested and the campaign's messaging policy allows it,
+-- 'admin' for an organizer's bulk unlock.
+ALTER TABLE matches
+    ADD COLUMN messaging_unlocked_at TIMESTAMPTZ,
+    ADD COLUMN messaging_unlock_reason TEXT;
+
+UPDATE matches
+SET messaging_unlocked_at = updated_at,
+    messaging_unlock_reason = CASE WHEN is_mutual_interest THEN 'mutual_interest' ELSE 'admin' END
+WHERE messaging_unlocked;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE matches
+    DROP COLUMN IF EXISTS messaging_unlock_reason,
+    DROP COLUMN IF EXISTS messaging_unlocked_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Log of the events pushed to users, so an event stream can resume from the
+-- last event a client saw. Rows without a user are broadcast to everyone.
+-- dedupe_key lets several API instances raise the same event, such as a
+-- campaign changing phase, while only one of them records it.
+CREATE TABLE user_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    dedupe_key TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_events_user ON user_events (user_id, id);
+CREATE INDEX idx_user_events_created ON user_events (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_events;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Message history is paged by (sent_at, id) within a match, and
+-- conversations are found through the messages a user sent or received.
+CREATE INDEX idx_messages_match_sent ON messages (match_id, sent_at, id);
+DROP INDEX IF EXISTS idx_messages_match;
+CREATE INDEX idx_messages_sender ON messages (sender_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_sender;
+CREATE INDEX idx_messages_match ON messages (match_id);
+DROP INDEX IF EXISTS idx_messages_match_sent;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- edited_at is set by the sender's latest edit; an unsent message keeps its
+-- row as a tombstone with unsent_at set and its content cleared.
+ALTER TABLE messages
+    ADD COLUMN edited_at TIMESTAMPTZ,
+    ADD COLUMN unsent_at TIMESTAMPTZ;
+
+-- What a message said before each edit, and before it was unsent, kept for
+-- moderation.
+CREATE TABLE message_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    previous_content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_edits_message ON message_edits (message_id, created_at);
+
+-- A user deleting a conversation hides its messages up to deleted_at from
+-- their own view only; the other user keeps the full history.
+CREATE TABLE conversation_deletions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    match_id UUID NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, match_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS conversation_deletions;
+DROP TABLE IF EXISTS message_edits;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS unsent_at,
+    DROP COLUMN IF EXISTS edited_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A block hides the two users from each other everywhere: matches, potential
+-- matches, conversations and crushes. It works in both directions whoever
+-- placed it, and keeps the pair out of every later match run.
+CREATE TABLE user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_user_blocks_blocked ON user_blocks (blocked_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_blocks;
+-- +goose StatementEnd
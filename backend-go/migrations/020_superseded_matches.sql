@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Publishing a run deletes the live matches it no longer has, except those
+-- with messages, a rejection or a report: they are kept for moderation and
+-- pair history with superseded_at set and hidden from both users. A later
+-- publish that brings the pair back clears it.
+ALTER TABLE matches
+    ADD COLUMN superseded_at TIMESTAMPTZ;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM matches WHERE superseded_at IS NOT NULL;
+
+ALTER TABLE matches
+    DROP COLUMN IF EXISTS superseded_at;
+-- +goose StatementEnd